@@ -0,0 +1,99 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "os"
+    "strconv"
+    "text/tabwriter"
+    "time"
+
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/migrations"
+    "freight-broker/backend/internal/requestctx"
+    "freight-broker/backend/internal/services"
+
+    "github.com/jinzhu/gorm"
+)
+
+// runCommand runs a one-off subcommand instead of starting the API server.
+func runCommand(name string, args []string, reconciliationService *services.ReconciliationService) error {
+    switch name {
+    case "reconcile":
+        return runReconcile(args, reconciliationService)
+    default:
+        return fmt.Errorf("unknown command: %s", name)
+    }
+}
+
+func runReconcile(args []string, reconciliationService *services.ReconciliationService) error {
+    flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+    repair := flags.String("repair", "", "repair direction: local (TMS overwrites local) or tms (local is pushed to TMS)")
+    if err := flags.Parse(args); err != nil {
+        return err
+    }
+
+    report, err := reconciliationService.Reconcile(requestctx.WithSystem(context.Background()), &dto.ReconciliationRequest{Repair: *repair})
+    if err != nil {
+        return err
+    }
+
+    encoder := json.NewEncoder(os.Stdout)
+    encoder.SetIndent("", "  ")
+    return encoder.Encode(report)
+}
+
+// runMigrate runs the migrate subcommand. It is handled before the schema
+// check at startup, so it also works on a database that needs migrating.
+func runMigrate(args []string, db *gorm.DB) error {
+    migrator, err := migrations.New(db)
+    if err != nil {
+        return err
+    }
+    if len(args) == 0 {
+        return fmt.Errorf("usage: migrate up|down|status|to <version>")
+    }
+
+    switch args[0] {
+    case "up":
+        return migrator.Up()
+    case "down":
+        return migrator.Down()
+    case "to":
+        if len(args) != 2 {
+            return fmt.Errorf("usage: migrate to <version>")
+        }
+        version, err := strconv.ParseInt(args[1], 10, 64)
+        if err != nil || version < 0 {
+            return fmt.Errorf("invalid version %q", args[1])
+        }
+        return migrator.To(version)
+    case "status":
+        return printMigrationStatus(migrator)
+    default:
+        return fmt.Errorf("unknown migrate command: %s", args[0])
+    }
+}
+
+func printMigrationStatus(migrator *migrations.Migrator) error {
+    statuses, err := migrator.Status()
+    if err != nil {
+        return err
+    }
+
+    w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+    fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+    for _, status := range statuses {
+        applied := "pending"
+        if status.AppliedAt != nil {
+            applied = status.AppliedAt.Format(time.RFC3339)
+        }
+        if status.Unknown {
+            applied += " (unknown to this binary)"
+        }
+        fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+    }
+    return w.Flush()
+}
@@ -6,6 +6,7 @@ import (
     "log"
     "os"
     "os/signal"
+    "sort"
     "syscall"
     "time"
     "net/http"
@@ -13,8 +14,11 @@ import (
     "freight-broker/backend/configs"
     "freight-broker/backend/internal/services"
     "freight-broker/backend/internal/controllers"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/migrations"
     "freight-broker/backend/internal/models"
     "freight-broker/backend/internal/middleware"
+    "freight-broker/backend/internal/requestctx"
     "github.com/gin-gonic/gin"
     "github.com/jinzhu/gorm"
     "github.com/gin-contrib/cors"
@@ -34,48 +38,129 @@ func main() {
     }
     defer db.Close()
 
-    if err := setupModels(db); err != nil {
-        log.Fatalf("Failed to setup database models: %v", err)
+    if len(os.Args) > 1 && os.Args[1] == "migrate" {
+        if err := runMigrate(os.Args[2:], db); err != nil {
+            log.Fatalf("Command migrate failed: %v", err)
+        }
+        return
+    }
+
+    if err := setupSchema(db, config); err != nil {
+        log.Fatalf("Failed to setup database schema: %v", err)
     }
 
-    authService := services.NewAuthService(config.JWTSecret)
-    tmsService := services.NewTurvoService(services.TMSServiceConfig{
-        APIKey:       config.TurvoAPIKey,
-        ClientID:     config.ClientName,
-        ClientSecret: config.ClientSecret,
-        IsSandbox:    config.IsSandbox,
-        TurvoUsername:  config.TurvoUsername,
-        TurvoPassword:  config.TurvoPassword,
+    authService, err := setupAuthService(db, config)
+    if err != nil {
+        log.Fatalf("Failed to setup authentication: %v", err)
+    }
+    userService := services.NewUserService(db, services.UserServiceConfig{
+        MaxFailedLogins: config.LoginMaxFailures,
+        LockoutDuration: config.LoginLockoutDuration,
+        ResetTokenTTL:   config.PasswordResetTTL,
     })
-    loadService := services.NewLoadService(db, tmsService)
+    tenantService := services.NewTenantService(db, userService)
+    apiKeyService := services.NewAPIKeyService(db, services.APIKeyServiceConfig{
+        RateLimitPerMinute: config.APIKeyRateLimitPerMinute,
+    })
+    defaultTenant, err := tenantService.EnsureDefaultTenant(context.Background())
+    if err != nil {
+        log.Fatalf("Failed to setup default tenant: %v", err)
+    }
+    tmsRegistry, err := setupTMSProviders(config, tenantService)
+    if err != nil {
+        log.Fatalf("Failed to setup TMS providers: %v", err)
+    }
+
+    loadService := services.NewLoadService(db, tmsRegistry, services.LoadServiceConfig{
+        IdempotencyKeyTTL: config.IdempotencyKeyTTL,
+    })
+    outboxService := services.NewOutboxService(db)
+    outboxWorker := services.NewOutboxWorker(db, tmsRegistry, services.OutboxWorkerConfig{
+        PollInterval: config.OutboxPollInterval,
+        MaxAttempts:  config.OutboxMaxAttempts,
+    })
+    loadImportService := services.NewLoadImportService(db, loadService)
+    loadImportWorker := services.NewLoadImportWorker(db, loadService, services.LoadImportWorkerConfig{
+        PollInterval: config.LoadImportPollInterval,
+    })
+    reconciliationService := services.NewReconciliationService(db, tmsRegistry)
+    shipmentSyncJob := services.NewShipmentSyncJob(db, tmsRegistry, services.ShipmentSyncConfig{
+        Interval: config.ShipmentSyncInterval,
+    })
+
+    // A TMS outage must not keep the API down: calls re-authenticate on
+    // first use and the outbox retries until the provider is reachable.
+    tmsProviders := allTMSProviders(tmsRegistry)
+    for _, label := range sortedKeys(tmsProviders) {
+        if err := tmsProviders[label].Authenticate(context.Background()); err != nil {
+            log.Printf("Warning: failed to authenticate with TMS provider %s: %v", label, err)
+        }
+    }
+
+    if len(os.Args) > 1 {
+        if err := runCommand(os.Args[1], os.Args[2:], reconciliationService); err != nil {
+            log.Fatalf("Command %s failed: %v", os.Args[1], err)
+        }
+        return
+    }
 
-    if err := tmsService.Authenticate(context.Background()); err != nil {
-        log.Fatalf("Failed to authenticate with Turvo: %v", err)
+    if config.AdminPassword != "" {
+        created, err := userService.EnsureAdmin(context.Background(), defaultTenant.ID, config.AdminUsername, config.AdminPassword)
+        if err != nil {
+            log.Fatalf("Failed to create admin user: %v", err)
+        }
+        if created {
+            log.Printf("Created admin user %s", config.AdminUsername)
+        }
+    } else {
+        log.Println("Warning: ADMIN_PASSWORD is not set; no admin user is created on an empty database")
     }
 
     // Initialize controllers
-    authController := controllers.NewAuthController(authService)
-    loadController := controllers.NewLoadController(loadService, tmsService)
+    authController := controllers.NewAuthController(authService, userService)
+    userController := controllers.NewUserController(userService)
+    tenantController := controllers.NewTenantController(tenantService)
+    apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+    loadController := controllers.NewLoadController(loadService)
+    loadImportController := controllers.NewLoadImportController(loadImportService)
+    auditController := controllers.NewAuditController(services.NewAuditService(db))
+    outboxController := controllers.NewOutboxController(outboxService)
+    reconciliationController := controllers.NewReconciliationController(reconciliationService)
+    healthController := controllers.NewHealthController(services.NewHealthService(tmsRegistry))
+
+    workerCtx, stopWorkers := context.WithCancel(requestctx.WithSystem(context.Background()))
+    defer stopWorkers()
+    go outboxWorker.Run(workerCtx)
+    go shipmentSyncJob.Run(workerCtx)
+    go loadImportWorker.Run(workerCtx)
+    for _, provider := range tmsProviders {
+        if refresher, ok := provider.(interfaces.TokenRefresher); ok {
+            go refresher.RunTokenRefresh(workerCtx)
+        }
+    }
 
     gin.SetMode(getGinMode())
     r := gin.New()
+    // Handlers pass the gin context to services, which read request values
+    // such as the actor from the request's context.
+    r.ContextWithFallback = true
 
     // Add middleware
     r.Use(cors.New(cors.Config{
         AllowOrigins:     []string{"*"},
-        AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
-        ExposeHeaders:    []string{"Content-Length"},
+        AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+        AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", middleware.APIKeyHeader, middleware.RequestIDHeader, controllers.IdempotencyKeyHeader},
+        ExposeHeaders:    []string{"Content-Length", "Content-Disposition", "Location", middleware.RequestIDHeader, controllers.IdempotentReplayedHeader},
         AllowCredentials: false,
         MaxAge:           12 * time.Hour,
     }))
     r.Use(gin.Recovery())
+    r.Use(middleware.RequestID())
     r.Use(gin.Logger())
+    r.Use(middleware.ErrorHandler())
     
     // Health check endpoint
-    r.GET("/health", func(c *gin.Context) {
-        c.JSON(200, gin.H{"status": "healthy"})
-    })
+    r.GET("/health", healthController.Health)
     
     
 
@@ -84,16 +169,87 @@ func main() {
         auth := api.Group("/auth")
         {
             auth.POST("/login", authController.Login)
+            auth.POST("/password-reset", authController.ResetPassword)
+            auth.POST("/refresh", authController.Refresh)
         }
 
         protected := api.Group("")
-        protected.Use(middleware.JWTAuthMiddleware(authService))
+        protected.Use(middleware.AuthMiddleware(authService, apiKeyService))
         {
+            protected.POST("/auth/password", middleware.RequireUser(), authController.ChangePassword)
+            protected.POST("/auth/logout", middleware.RequireUser(), authController.Logout)
+
             loads := protected.Group("/loads")
             {
-                loads.POST("/", loadController.CreateLoad)
-                loads.GET("/", loadController.ListLoads)
-                loads.GET("/:id", loadController.GetLoad)
+                loads.POST("/", middleware.RequirePermission(models.PermLoadsCreate), loadController.CreateLoad)
+                loads.GET("/", middleware.RequirePermission(models.PermLoadsRead), loadController.ListLoads)
+                loads.GET("/export", middleware.RequirePermission(models.PermLoadsRead), loadController.ExportLoads)
+                loads.POST("/import", middleware.RequirePermission(models.PermLoadsCreate), loadImportController.ImportLoads)
+                loads.GET("/import/template", middleware.RequirePermission(models.PermLoadsCreate), loadImportController.GetTemplate)
+                loads.GET("/import/:id", middleware.RequirePermission(models.PermLoadsCreate), loadImportController.GetImport)
+                loads.GET("/import/:id/result", middleware.RequirePermission(models.PermLoadsCreate), loadImportController.GetResult)
+                loads.GET("/:id", middleware.RequirePermission(models.PermLoadsRead), loadController.GetLoad)
+                loads.PUT("/:id", middleware.RequirePermission(models.PermLoadsUpdate), loadController.UpdateLoad)
+                loads.PATCH("/:id", middleware.RequirePermission(models.PermLoadsUpdate), loadController.UpdateLoad)
+                loads.DELETE("/:id", middleware.RequirePermission(models.PermLoadsCancel), loadController.CancelLoad)
+                loads.GET("/:id/stops", middleware.RequirePermission(models.PermLoadsRead), loadController.GetStops)
+                loads.PUT("/:id/stops", middleware.RequirePermission(models.PermLoadsUpdate), loadController.ReplaceStops)
+                loads.POST("/:id/status", middleware.RequirePermission(models.PermLoadsStatus), loadController.ChangeStatus)
+                loads.GET("/:id/status/history", middleware.RequirePermission(models.PermLoadsRead), loadController.GetStatusHistory)
+                loads.GET("/:id/audit", middleware.RequirePermission(models.PermAuditRead), auditController.GetLoadAudit)
+            }
+
+            admin := protected.Group("/admin")
+            {
+                // Operations and tenants span the whole deployment, so only
+                // the default tenant's admins may use them.
+                platform := middleware.RequireTenant(defaultTenant.ID.String())
+
+                operations := admin.Group("")
+                operations.Use(middleware.RequirePermission(models.PermOperations), platform)
+                {
+                    operations.GET("/outbox", outboxController.ListMessages)
+                    operations.POST("/outbox/:id/replay", outboxController.ReplayMessage)
+                    operations.GET("/reconciliation", reconciliationController.GetReport)
+                    operations.POST("/reconciliation", reconciliationController.Repair)
+                }
+
+                users := admin.Group("/users")
+                users.Use(middleware.RequirePermission(models.PermUsersManage))
+                {
+                    users.POST("", userController.CreateUser)
+                    users.GET("", userController.ListUsers)
+                    users.GET("/:id", userController.GetUser)
+                    users.PATCH("/:id", userController.UpdateUser)
+                    users.DELETE("/:id", userController.DeleteUser)
+                    users.POST("/:id/password-reset", userController.CreateResetToken)
+                }
+
+                apiKeys := admin.Group("/api-keys")
+                apiKeys.Use(middleware.RequirePermission(models.PermAPIKeysManage))
+                {
+                    apiKeys.POST("", apiKeyController.CreateAPIKey)
+                    apiKeys.GET("", apiKeyController.ListAPIKeys)
+                    apiKeys.GET("/:id", apiKeyController.GetAPIKey)
+                    apiKeys.POST("/:id/rotate", apiKeyController.RotateAPIKey)
+                    apiKeys.DELETE("/:id", apiKeyController.RevokeAPIKey)
+                }
+
+                audit := admin.Group("/audit")
+                audit.Use(middleware.RequirePermission(models.PermAuditSearch))
+                {
+                    audit.GET("", auditController.SearchAudit)
+                    audit.GET("/verify", auditController.VerifyAuditLog)
+                }
+
+                tenants := admin.Group("/tenants")
+                tenants.Use(middleware.RequirePermission(models.PermTenantsManage), platform)
+                {
+                    tenants.POST("", tenantController.CreateTenant)
+                    tenants.GET("", tenantController.ListTenants)
+                    tenants.GET("/:id", tenantController.GetTenant)
+                    tenants.PATCH("/:id", tenantController.UpdateTenant)
+                }
             }
         }
     }
@@ -117,6 +273,7 @@ func main() {
     signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
     <-quit
     log.Println("Shutting down server...")
+    stopWorkers()
 
     // Give outstanding requests 5 seconds to complete
     ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -144,10 +301,140 @@ func setupDatabase(config *configs.Config) (*gorm.DB, error) {
     return db, nil
 }
 
-func setupModels(db *gorm.DB) error {
-    return db.AutoMigrate(
-        &models.Load{},
-    ).Error
+// setupSchema refuses a database migrated by a newer release, then applies
+// any pending migrations unless DB_MIGRATE_ON_START is false, in which case
+// they must be applied with the migrate command first.
+func setupSchema(db *gorm.DB, config *configs.Config) error {
+    migrator, err := migrations.New(db)
+    if err != nil {
+        return err
+    }
+    pending, err := migrator.Check()
+    if err != nil {
+        return err
+    }
+    if pending == 0 {
+        return nil
+    }
+    if !config.MigrateOnStart {
+        return fmt.Errorf("%d migrations are pending; run the migrate up command", pending)
+    }
+    return migrator.Up()
+}
+
+// setupAuthService loads the token signing keys. JWT_SECRET is the HS256
+// key "default", which also verifies tokens issued before keys had IDs;
+// JWT_KEYS adds more keys and JWT_ACTIVE_KEY picks the one that signs.
+func setupAuthService(db *gorm.DB, config *configs.Config) (*services.AuthService, error) {
+    authConfig := services.AuthServiceConfig{
+        ActiveKeyID:     config.JWTActiveKey,
+        LegacyKeyID:     "default",
+        AccessTokenTTL:  config.AccessTokenTTL,
+        RefreshTokenTTL: config.RefreshTokenTTL,
+    }
+    if config.JWTSecret != "" {
+        authConfig.Keys = append(authConfig.Keys, services.NewHMACKey("default", config.JWTSecret))
+    }
+
+    for _, id := range sortedKeys(config.JWTKeys) {
+        key, err := services.ParseSigningKey(id, config.JWTKeys[id])
+        if err != nil {
+            return nil, err
+        }
+        authConfig.Keys = append(authConfig.Keys, key)
+    }
+    if len(authConfig.Keys) == 0 {
+        return nil, fmt.Errorf("JWT_SECRET or JWT_KEYS must be set")
+    }
+
+    return services.NewAuthService(db, authConfig)
+}
+
+// setupTMSProviders registers the TMS adapters enabled by config. Turvo is
+// registered when it is the default or has credentials; the generic REST
+// adapter when a config file is given. Tenants listed in the tenant TMS
+// config get their own adapters instead of these.
+func setupTMSProviders(config *configs.Config, tenantService *services.TenantService) (*services.TMSRegistry, error) {
+    registry := services.NewTMSRegistry(config.TMSProvider, config.TMSCustomerProviders)
+    transport := services.TMSTransportConfig{
+        MaxRetries:       config.TMSMaxRetries,
+        RatePerMinute:    config.TMSRatePerMinute,
+        Burst:            config.TMSRateBurst,
+        BreakerThreshold: config.TMSBreakerThreshold,
+        BreakerCooldown:  config.TMSBreakerCooldown,
+    }
+
+    if config.TMSProvider == services.TMSProviderTurvo || config.TurvoUsername != "" {
+        registry.Register(services.TMSProviderTurvo, services.NewTurvoService(services.TMSServiceConfig{
+            APIKey:       config.TurvoAPIKey,
+            ClientID:     config.ClientName,
+            ClientSecret: config.ClientSecret,
+            IsSandbox:    config.IsSandbox,
+            TurvoUsername:  config.TurvoUsername,
+            TurvoPassword:  config.TurvoPassword,
+            BaseURL:        config.TurvoBaseURL,
+            AuthURL:        config.TurvoAuthURL,
+            Transport:      transport,
+        }))
+    }
+
+    if config.RESTTMSConfigPath != "" {
+        restConfig, err := services.LoadRESTTMSConfig(config.RESTTMSConfigPath)
+        if err != nil {
+            return nil, err
+        }
+        restConfig.Transport = transport
+        registry.Register(services.TMSProviderREST, services.NewRESTTMSService(restConfig))
+    }
+
+    if config.TenantTMSConfigPath != "" {
+        tenantConfigs, err := services.LoadTenantTMSConfig(config.TenantTMSConfigPath)
+        if err != nil {
+            return nil, err
+        }
+        for _, slug := range sortedKeys(tenantConfigs) {
+            // The default tenant's loads include those first seen in the
+            // shared TMS, so it always uses the shared settings.
+            if slug == models.DefaultTenantSlug {
+                return nil, fmt.Errorf("tenant TMS config: the %s tenant uses the global TMS settings", slug)
+            }
+            tenant, err := tenantService.TenantBySlug(context.Background(), slug)
+            if err != nil {
+                return nil, fmt.Errorf("tenant TMS config: %w", err)
+            }
+            registry.RegisterTenant(tenant.ID.String(), services.NewTenantTMSRegistry(tenantConfigs[slug], transport))
+        }
+    }
+
+    if err := registry.Validate(); err != nil {
+        return nil, err
+    }
+    return registry, nil
+}
+
+// allTMSProviders returns the shared adapters and every tenant's own, keyed
+// by provider name prefixed with the tenant ID for a tenant's.
+func allTMSProviders(registry *services.TMSRegistry) map[string]interfaces.TMSService {
+    providers := make(map[string]interfaces.TMSService)
+    for _, name := range registry.ProviderNames() {
+        providers[name], _ = registry.Provider(name)
+    }
+    for _, tenantID := range registry.TenantIDs() {
+        tenantRegistry := registry.ForTenant(tenantID)
+        for _, name := range tenantRegistry.ProviderNames() {
+            providers[tenantID+"/"+name], _ = tenantRegistry.Provider(name)
+        }
+    }
+    return providers
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
 }
 
 func getGinMode() string {
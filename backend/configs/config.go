@@ -3,6 +3,9 @@ package configs
 import (
     "fmt"
     "os"
+    "strconv"
+    "strings"
+    "time"
     "github.com/joho/godotenv"
 )
 
@@ -12,13 +15,40 @@ type Config struct {
     DBUser     string
     DBPassword string
     DBName     string
+    MigrateOnStart bool
     TurvoAPIKey   string
     TurvoUsername   string
     TurvoPassword   string
+    TurvoBaseURL    string
+    TurvoAuthURL    string
     ClientName    string
     ClientSecret  string
     IsSandbox     bool
     JWTSecret     string
+    JWTKeys       map[string]string
+    JWTActiveKey  string
+    AccessTokenTTL  time.Duration
+    RefreshTokenTTL time.Duration
+    OutboxPollInterval time.Duration
+    OutboxMaxAttempts  int
+    ShipmentSyncInterval time.Duration
+    LoadImportPollInterval time.Duration
+    TMSProvider          string
+    TMSCustomerProviders map[string]string
+    RESTTMSConfigPath    string
+    TenantTMSConfigPath  string
+    TMSMaxRetries        int
+    TMSRatePerMinute     int
+    TMSRateBurst         int
+    TMSBreakerThreshold  int
+    TMSBreakerCooldown   time.Duration
+    AdminUsername        string
+    AdminPassword        string
+    LoginMaxFailures     int
+    LoginLockoutDuration time.Duration
+    PasswordResetTTL     time.Duration
+    APIKeyRateLimitPerMinute int
+    IdempotencyKeyTTL        time.Duration
 }
 
 func LoadConfig() (*Config, error) {
@@ -34,13 +64,40 @@ func LoadConfig() (*Config, error) {
         DBUser:     getEnv("DB_USER", "postgres"),
         DBPassword: getEnv("DB_PASSWORD", ""),
         DBName:     getEnv("DB_NAME", "freight_broker"),
+        MigrateOnStart: getEnv("DB_MIGRATE_ON_START", "true") != "false",
         TurvoAPIKey:   getEnv("TURVO_API_KEY", ""),
         TurvoUsername:   getEnv("TURVO_USERNAME", ""),
         TurvoPassword:   getEnv("TURVO_PASSWORD", ""),
+        TurvoBaseURL:    getEnv("TURVO_BASE_URL", ""),
+        TurvoAuthURL:    getEnv("TURVO_AUTH_URL", ""),
         ClientName:    getEnv("CLIENT_NAME", ""),
         ClientSecret:  getEnv("CLIENT_SECRET", ""),
         IsSandbox:     getEnv("ENVIRONMENT", "sandbox") == "sandbox",
         JWTSecret:     getEnv("JWT_SECRET", ""),
+        JWTKeys:       getEnvMap("JWT_KEYS"),
+        JWTActiveKey:  getEnv("JWT_ACTIVE_KEY", ""),
+        AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+        RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+        OutboxPollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", 5*time.Second),
+        OutboxMaxAttempts:  getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+        ShipmentSyncInterval: getEnvDuration("SHIPMENT_SYNC_INTERVAL", 15*time.Minute),
+        LoadImportPollInterval: getEnvDuration("LOAD_IMPORT_POLL_INTERVAL", 5*time.Second),
+        TMSProvider:          getEnv("TMS_PROVIDER", "turvo"),
+        TMSCustomerProviders: getEnvMap("TMS_CUSTOMER_PROVIDERS"),
+        RESTTMSConfigPath:    getEnv("REST_TMS_CONFIG", ""),
+        TenantTMSConfigPath:  getEnv("TENANT_TMS_CONFIG", ""),
+        TMSMaxRetries:        getEnvInt("TMS_MAX_RETRIES", 3),
+        TMSRatePerMinute:     getEnvInt("TMS_RATE_LIMIT_PER_MINUTE", 300),
+        TMSRateBurst:         getEnvInt("TMS_RATE_LIMIT_BURST", 10),
+        TMSBreakerThreshold:  getEnvInt("TMS_BREAKER_THRESHOLD", 5),
+        TMSBreakerCooldown:   getEnvDuration("TMS_BREAKER_COOLDOWN", 30*time.Second),
+        AdminUsername:        getEnv("ADMIN_USERNAME", "admin"),
+        AdminPassword:        getEnv("ADMIN_PASSWORD", ""),
+        LoginMaxFailures:     getEnvInt("LOGIN_MAX_FAILURES", 5),
+        LoginLockoutDuration: getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+        PasswordResetTTL:     getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+        APIKeyRateLimitPerMinute: getEnvInt("API_KEY_RATE_LIMIT_PER_MINUTE", 120),
+        IdempotencyKeyTTL:        getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
     }, nil
 }
 
@@ -50,4 +107,33 @@ func getEnv(key, defaultValue string) string {
         return defaultValue
     }
     return value
-}
\ No newline at end of file
+}
+
+func getEnvInt(key string, defaultValue int) int {
+    value, err := strconv.Atoi(os.Getenv(key))
+    if err != nil {
+        return defaultValue
+    }
+    return value
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+    value, err := time.ParseDuration(os.Getenv(key))
+    if err != nil {
+        return defaultValue
+    }
+    return value
+}
+
+// getEnvMap parses "key=value" pairs separated by semicolons.
+func getEnvMap(key string) map[string]string {
+    result := make(map[string]string)
+    for _, pair := range strings.Split(os.Getenv(key), ";") {
+        parts := strings.SplitN(pair, "=", 2)
+        if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+            continue
+        }
+        result[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+    }
+    return result
+}
@@ -0,0 +1,157 @@
+// Package apperrors defines the typed errors returned by services and TMS
+// adapters. middleware.ErrorHandler maps them to HTTP responses.
+package apperrors
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "time"
+)
+
+// Code is a stable, machine-readable error identifier clients can branch on.
+type Code string
+
+const (
+    CodeValidation          Code = "VALIDATION_FAILED"
+    CodeUnauthorized        Code = "UNAUTHORIZED"
+    CodeForbidden           Code = "FORBIDDEN"
+    CodeNotFound            Code = "NOT_FOUND"
+    CodeConflict            Code = "CONFLICT"
+    CodeRateLimited         Code = "RATE_LIMITED"
+    CodeUpstreamUnavailable Code = "UPSTREAM_UNAVAILABLE"
+    CodeUpstreamRejected    Code = "UPSTREAM_REJECTED"
+    CodeInternal            Code = "INTERNAL_ERROR"
+)
+
+// FieldError describes one invalid request field.
+type FieldError struct {
+    Field   string `json:"field"`
+    Message string `json:"message"`
+}
+
+type Error struct {
+    Code    Code
+    Message string
+    // Details is further text for the client. Unlike Err, which is only
+    // logged, it must be written to be shown.
+    Details string
+    Fields  []FieldError
+    // RetryAfter is how long a rate-limited caller should wait, if known.
+    RetryAfter time.Duration
+    // Location is the path of the existing resource a conflict is about.
+    Location string
+    Err        error
+}
+
+func (e *Error) Error() string {
+    if e.Err != nil {
+        return fmt.Sprintf("%s: %v", e.Message, e.Err)
+    }
+    return e.Message
+}
+
+func (e *Error) Unwrap() error {
+    return e.Err
+}
+
+func Validation(message string, fields ...FieldError) *Error {
+    return &Error{Code: CodeValidation, Message: message, Fields: fields}
+}
+
+func Unauthorized(message string) *Error {
+    return &Error{Code: CodeUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+    return &Error{Code: CodeForbidden, Message: message}
+}
+
+func NotFound(message string) *Error {
+    return &Error{Code: CodeNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+    return &Error{Code: CodeConflict, Message: message}
+}
+
+// Duplicate reports a conflict with an existing resource at location.
+func Duplicate(message, location string) *Error {
+    return &Error{Code: CodeConflict, Message: message, Location: location}
+}
+
+func RateLimited(message string, retryAfter time.Duration) *Error {
+    return &Error{Code: CodeRateLimited, Message: message, RetryAfter: retryAfter}
+}
+
+func UpstreamUnavailable(message string, err error) *Error {
+    return &Error{Code: CodeUpstreamUnavailable, Message: message, Err: err}
+}
+
+// UpstreamRejected reports a request the TMS refused. It is the server's
+// request, not the caller's, so it is not reported as a client error.
+func UpstreamRejected(status int) *Error {
+    return &Error{
+        Code:    CodeUpstreamRejected,
+        Message: "TMS rejected the request",
+        Details: fmt.Sprintf("TMS responded with status %d", status),
+    }
+}
+
+// As returns the first typed error in err's chain.
+func As(err error) (*Error, bool) {
+    var appErr *Error
+    if errors.As(err, &appErr) {
+        return appErr, true
+    }
+    return nil, false
+}
+
+// Is reports whether err's chain carries a typed error with the given code.
+func Is(err error, code Code) bool {
+    appErr, ok := As(err)
+    return ok && appErr.Code == code
+}
+
+func HTTPStatus(code Code) int {
+    switch code {
+    case CodeValidation:
+        return http.StatusBadRequest
+    case CodeUnauthorized:
+        return http.StatusUnauthorized
+    case CodeForbidden:
+        return http.StatusForbidden
+    case CodeNotFound:
+        return http.StatusNotFound
+    case CodeConflict:
+        return http.StatusConflict
+    case CodeRateLimited:
+        return http.StatusTooManyRequests
+    case CodeUpstreamUnavailable:
+        return http.StatusServiceUnavailable
+    case CodeUpstreamRejected:
+        return http.StatusBadGateway
+    }
+    return http.StatusInternalServerError
+}
+
+// FromHTTPStatus classifies a failed upstream response. Only a missing
+// resource and rate limiting mean the same to our caller; other 4xx
+// responses are about our request to the upstream, and a 401 about our
+// credentials, so they are never reported as the caller's own error.
+// message must not hold the upstream's response body, which is for logs.
+func FromHTTPStatus(status int, message string) *Error {
+    switch {
+    case status == http.StatusNotFound:
+        return NotFound(message)
+    case status == http.StatusTooManyRequests:
+        return RateLimited(message, 0)
+    case status == http.StatusUnauthorized:
+        return UpstreamUnavailable("TMS rejected our credentials", errors.New(message))
+    case status >= 400 && status < 500:
+        appErr := UpstreamRejected(status)
+        appErr.Err = errors.New(message)
+        return appErr
+    }
+    return UpstreamUnavailable(message, nil)
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+// APIKeyController serves the admin endpoints that manage a tenant's API
+// keys.
+type APIKeyController struct {
+    apiKeyService interfaces.APIKeyService
+}
+
+func NewAPIKeyController(apiKeyService interfaces.APIKeyService) *APIKeyController {
+    return &APIKeyController{
+        apiKeyService: apiKeyService,
+    }
+}
+
+func (c *APIKeyController) CreateAPIKey(ctx *gin.Context) {
+    var req dto.CreateAPIKeyRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    apiKey, err := c.apiKeyService.CreateAPIKey(ctx, &req)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusCreated, apiKey)
+}
+
+func (c *APIKeyController) ListAPIKeys(ctx *gin.Context) {
+    resp, err := c.apiKeyService.ListAPIKeys(ctx)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *APIKeyController) GetAPIKey(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid API key ID format"))
+        return
+    }
+
+    apiKey, err := c.apiKeyService.GetAPIKey(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, apiKey)
+}
+
+// RotateAPIKey replaces the key's secret and returns the new key.
+func (c *APIKeyController) RotateAPIKey(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid API key ID format"))
+        return
+    }
+
+    apiKey, err := c.apiKeyService.RotateAPIKey(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, apiKey)
+}
+
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid API key ID format"))
+        return
+    }
+
+    if err := c.apiKeyService.RevokeAPIKey(ctx, id); err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+// AuditController serves a load's audit trail and the admin search over a
+// tenant's audit log.
+type AuditController struct {
+    auditService interfaces.AuditService
+}
+
+func NewAuditController(auditService interfaces.AuditService) *AuditController {
+    return &AuditController{
+        auditService: auditService,
+    }
+}
+
+func (c *AuditController) GetLoadAudit(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid load ID format"))
+        return
+    }
+
+    audit, err := c.auditService.GetLoadAudit(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, audit)
+}
+
+func (c *AuditController) SearchAudit(ctx *gin.Context) {
+    query, err := parseAuditSearchQuery(ctx)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    resp, err := c.auditService.SearchAudit(ctx, query)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    resp.Page = query.Page
+    resp.Size = query.Size
+
+    ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *AuditController) VerifyAuditLog(ctx *gin.Context) {
+    resp, err := c.auditService.VerifyAuditLog(ctx)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, resp)
+}
+
+func parseAuditSearchQuery(ctx *gin.Context) (*dto.AuditSearchQuery, error) {
+    page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+    if err != nil || page < 1 {
+        return nil, invalidPageError()
+    }
+    pageSize, err := strconv.Atoi(ctx.DefaultQuery("size", "50"))
+    if err != nil || pageSize < 1 || pageSize > 100 {
+        return nil, invalidPageSizeError()
+    }
+
+    query := &dto.AuditSearchQuery{
+        LoadID:    strings.TrimSpace(ctx.Query("loadId")),
+        ActorID:   strings.TrimSpace(ctx.Query("actorId")),
+        Action:    strings.TrimSpace(ctx.Query("action")),
+        RequestID: strings.TrimSpace(ctx.Query("requestId")),
+        Page:      page,
+        Size:      pageSize,
+    }
+
+    var fields []apperrors.FieldError
+    if query.LoadID != "" {
+        if _, err := uuid.Parse(query.LoadID); err != nil {
+            fields = append(fields, apperrors.FieldError{Field: "loadId", Message: "must be a valid UUID"})
+        }
+    }
+    switch query.Action {
+    case "", models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionStatus, models.AuditActionCancel:
+    default:
+        fields = append(fields, apperrors.FieldError{Field: "action", Message: "must be one of create, update, status_change, cancel"})
+    }
+    dateParam := func(name string, endOfRange bool) *time.Time {
+        value := strings.TrimSpace(ctx.Query(name))
+        if value == "" {
+            return nil
+        }
+        t, err := parseDateParam(value, endOfRange)
+        if err != nil {
+            fields = append(fields, apperrors.FieldError{Field: name, Message: "must be a date (YYYY-MM-DD) or an RFC3339 timestamp"})
+            return nil
+        }
+        return &t
+    }
+    query.From = dateParam("from", false)
+    query.To = dateParam("to", true)
+
+    if len(fields) > 0 {
+        return nil, apperrors.Validation("Invalid query parameters", fields...)
+    }
+    return query, nil
+}
@@ -4,12 +4,16 @@ package controllers
 
 import (
     "net/http"
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
     "github.com/gin-gonic/gin"
 	"freight-broker/backend/internal/services"
 )
 
 type AuthController struct {
     authService *services.AuthService
+    userService interfaces.UserService
 }
 
 type LoginRequest struct {
@@ -17,39 +21,104 @@ type LoginRequest struct {
     Password string `json:"password" binding:"required"`
 }
 
-type LoginResponse struct {
-    Token string `json:"token"`
-}
-
-func NewAuthController(authService *services.AuthService) *AuthController {
+func NewAuthController(authService *services.AuthService, userService interfaces.UserService) *AuthController {
     return &AuthController{
         authService: authService,
+        userService: userService,
     }
 }
 
 func (c *AuthController) Login(ctx *gin.Context) {
     var req LoginRequest
     if err := ctx.ShouldBindJSON(&req); err != nil {
-        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        ctx.Error(invalidBodyError(err))
         return
     }
 
-    // In a real app, we would validate credentials against a DB
-    if !validateCredentials(req.Username, req.Password) {
-        ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid credentials"})
+    user, err := c.userService.Authenticate(ctx, req.Username, req.Password)
+    if err != nil {
+        ctx.Error(err)
         return
     }
 
-    token, err := c.authService.GenerateToken("user123", req.Username, "broker")
+    tokens, err := c.authService.IssueTokens(ctx, user)
     if err != nil {
-        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate token"})
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, tokens)
+}
+
+// Refresh exchanges a refresh token for new access and refresh tokens.
+func (c *AuthController) Refresh(ctx *gin.Context) {
+    var req dto.RefreshRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    tokens, err := c.authService.Refresh(ctx, req.RefreshToken)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the request's access token and, if given, the refresh
+// token. The body is optional.
+func (c *AuthController) Logout(ctx *gin.Context) {
+    var req dto.LogoutRequest
+    if ctx.Request.ContentLength != 0 {
+        if err := ctx.ShouldBindJSON(&req); err != nil {
+            ctx.Error(invalidBodyError(err))
+            return
+        }
+    }
+
+    claims, ok := ctx.Get("claims")
+    if !ok {
+        ctx.Error(apperrors.Unauthorized("authorization header required"))
+        return
+    }
+    if err := c.authService.Logout(ctx, claims.(*services.Claims), req.RefreshToken); err != nil {
+        ctx.Error(err)
         return
     }
 
-    ctx.JSON(http.StatusOK, LoginResponse{Token: token})
+    ctx.Status(http.StatusNoContent)
 }
 
-func validateCredentials(username, password string) bool {
-    // Not a real app implementation
-    return username == "admin" && password == "password"
+// ChangePassword changes the signed-in user's own password.
+func (c *AuthController) ChangePassword(ctx *gin.Context) {
+    var req dto.ChangePasswordRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    if err := c.userService.ChangePassword(ctx, ctx.GetString("userID"), &req); err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.Status(http.StatusNoContent)
+}
+
+// ResetPassword sets a new password with a reset token issued by an admin.
+func (c *AuthController) ResetPassword(ctx *gin.Context) {
+    var req dto.ResetPasswordRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    if err := c.userService.ResetPassword(ctx, &req); err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.Status(http.StatusNoContent)
 }
\ No newline at end of file
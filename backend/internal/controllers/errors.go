@@ -0,0 +1,31 @@
+package controllers
+
+import (
+    "freight-broker/backend/internal/apperrors"
+)
+
+// Request errors shared by the controllers. They are passed to ctx.Error and
+// rendered by middleware.ErrorHandler.
+
+func invalidBodyError(err error) error {
+    if fields := bindingFieldErrors(err); len(fields) > 0 {
+        return apperrors.Validation("Validation failed", fields...)
+    }
+    return apperrors.Validation("Invalid request format",
+        apperrors.FieldError{Field: "body", Message: err.Error()})
+}
+
+func invalidIDError(message string) error {
+    return apperrors.Validation(message,
+        apperrors.FieldError{Field: "id", Message: "must be a valid UUID"})
+}
+
+func invalidPageError() error {
+    return apperrors.Validation("Invalid page parameter",
+        apperrors.FieldError{Field: "page", Message: "must be a positive integer"})
+}
+
+func invalidPageSizeError() error {
+    return apperrors.Validation("Invalid size parameter",
+        apperrors.FieldError{Field: "size", Message: "must be a positive integer between 1 and 100"})
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+    "freight-broker/backend/internal/interfaces"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+type HealthController struct {
+    healthService interfaces.HealthService
+}
+
+func NewHealthController(healthService interfaces.HealthService) *HealthController {
+    return &HealthController{
+        healthService: healthService,
+    }
+}
+
+func (c *HealthController) Health(ctx *gin.Context) {
+    ctx.JSON(http.StatusOK, c.healthService.Check(ctx))
+}
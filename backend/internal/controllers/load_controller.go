@@ -1,14 +1,11 @@
 package controllers
 
 import (
-	"fmt"
+	"freight-broker/backend/internal/apperrors"
 	"freight-broker/backend/internal/dto"
-	tmsDTO "freight-broker/backend/internal/dto/tms"
 	"freight-broker/backend/internal/interfaces"
-	"log"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,16 +13,21 @@ import (
 
 type LoadController struct {
     loadService interfaces.LoadService
-    tmsService  interfaces.TMSService
 }
 
-func NewLoadController(loadService interfaces.LoadService, tmsService interfaces.TMSService) *LoadController {
+func NewLoadController(loadService interfaces.LoadService) *LoadController {
     return &LoadController{
         loadService: loadService,
-        tmsService:  tmsService,
     }
 }
 
+// IdempotencyKeyHeader makes a create safe to retry: repeating it with the
+// same key returns the first response instead of creating another load.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed for a repeated key.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
 func (c *LoadController) CreateLoad(ctx *gin.Context) {
 
     ctx.Header("Access-Control-Allow-Origin", "*")
@@ -34,56 +36,34 @@ func (c *LoadController) CreateLoad(ctx *gin.Context) {
     var req dto.CreateLoadRequest
 
     if err := ctx.ShouldBindJSON(&req); err != nil {
-        ctx.JSON(http.StatusBadRequest, gin.H{
-            "error": "Invalid request format",
-            "details": err.Error(),
-        })
+        ctx.Error(invalidBodyError(err))
         return
     }
 
-    if err := c.validateCreateLoadRequest(&req); err != nil {
-        ctx.JSON(http.StatusBadRequest, gin.H{
-            "error": "Validation failed",
-            "details": err.Error(),
-        })
-        return
-    }
-
-    if !c.tmsService.IsTokenValid() {
-        if err := c.tmsService.Authenticate(ctx); err != nil {
-            ctx.JSON(http.StatusServiceUnavailable, gin.H{
-                "error": "Failed to authenticate with TMS",
-                "details": err.Error(),
-            })
+    key := ctx.GetHeader(IdempotencyKeyHeader)
+    if key == "" {
+        loadResp, err := c.loadService.CreateLoad(ctx, &req)
+        if err != nil {
+            ctx.Error(err)
             return
         }
+        ctx.JSON(http.StatusCreated, loadResp)
+        return
     }
 
-    shipmentReq := c.convertToShipmentRequest(&req)
-
-    _, err := c.tmsService.CreateShipment(ctx, shipmentReq)
-    
-    //bypassing error validation currently because of strange error response
-
-    // if err != nil {
-    //     ctx.JSON(http.StatusServiceUnavailable, gin.H{
-    //         "error": "Failed to create shipment in TMS",
-    //         "details": err.Error(),
-    //     })
-    //     return
-    // }
-    log.Print(err)
-
-
-    loadResp, err := c.loadService.CreateLoad(ctx, &req)
+    if len(key) > 255 {
+        ctx.Error(apperrors.Validation("Invalid idempotency key",
+            apperrors.FieldError{Field: IdempotencyKeyHeader, Message: "must be at most 255 characters"}))
+        return
+    }
+    loadResp, replayed, err := c.loadService.CreateLoadIdempotent(ctx, key, &req)
     if err != nil {
-        ctx.JSON(http.StatusInternalServerError, gin.H{
-            "error": "Failed to create load in local database",
-            "details": err.Error(),
-        })
+        ctx.Error(err)
         return
     }
-
+    if replayed {
+        ctx.Header(IdempotentReplayedHeader, "true")
+    }
     ctx.JSON(http.StatusCreated, loadResp)
 }
 
@@ -94,27 +74,13 @@ func (c *LoadController) GetLoad(ctx *gin.Context) {
     id := ctx.Param("id")
 
     if _, err := uuid.Parse(id); err != nil {
-        ctx.JSON(http.StatusBadRequest, gin.H{
-            "error": "Invalid load ID format",
-            "details": "Load ID must be a valid UUID",
-        })
+        ctx.Error(invalidIDError("Invalid load ID format"))
         return
     }
 
     loadResp, err := c.loadService.GetLoad(ctx, id)
     if err != nil {
-        if err.Error() == "load not found" {
-            ctx.JSON(http.StatusNotFound, gin.H{
-                "error": "Load not found",
-                "details": err.Error(),
-            })
-            return
-        }
-        
-        ctx.JSON(http.StatusInternalServerError, gin.H{
-            "error": "Failed to get load",
-            "details": err.Error(),
-        })
+        ctx.Error(err)
         return
     }
 
@@ -130,92 +96,160 @@ func (c *LoadController) ListLoads(ctx *gin.Context) {
 
     page, err := strconv.Atoi(pageStr)
     if err != nil || page < 1 {
-        ctx.JSON(http.StatusBadRequest, gin.H{
-            "error": "Invalid page parameter",
-            "details": "Page must be a positive integer",
-        })
+        ctx.Error(invalidPageError())
         return
     }
 
     pageSize, err := strconv.Atoi(pageSizeStr)
     if err != nil || pageSize < 1 || pageSize > 100 {
-        ctx.JSON(http.StatusBadRequest, gin.H{
-            "error": "Invalid size parameter",
-            "details": "Size must be a positive integer between 1 and 100",
-        })
+        ctx.Error(invalidPageSizeError())
         return
     }
 
-    loadsResp, err := c.loadService.ListLoads(ctx, page, pageSize)
+    query, err := parseListLoadsQuery(ctx)
     if err != nil {
-        ctx.JSON(http.StatusInternalServerError, gin.H{
-            "error": "Failed to list loads",
-            "details": err.Error(),
-        })
+        ctx.Error(err)
         return
     }
+    query.Page = page
+    query.PageSize = pageSize
 
-    loadsResp.Page = page
-    loadsResp.Size = pageSize
+    loadsResp, err := c.loadService.ListLoads(ctx, query)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
 
     ctx.JSON(http.StatusOK, loadsResp)
 }
 
+func (c *LoadController) UpdateLoad(ctx *gin.Context) {
+    id := ctx.Param("id")
 
-func (c *LoadController) validateCreateLoadRequest(req *dto.CreateLoadRequest) error {
-    if req.FreightLoadID == "" {
-        return fmt.Errorf("freight load ID is required")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid load ID format"))
+        return
+    }
+
+    var req dto.UpdateLoadRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
     }
-    if req.Customer == nil {
-        return fmt.Errorf("customer information is required")
+
+    loadResp, err := c.loadService.UpdateLoad(ctx, id, &req)
+    if err != nil {
+        ctx.Error(err)
+        return
     }
-    if req.Pickup == nil {
-        return fmt.Errorf("pickup information is required")
+
+    ctx.JSON(http.StatusOK, loadResp)
+}
+
+// GetStops returns a load's route in sequence order.
+func (c *LoadController) GetStops(ctx *gin.Context) {
+    id := ctx.Param("id")
+
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid load ID format"))
+        return
     }
-    if req.Consignee == nil {
-        return fmt.Errorf("consignee information is required")
+
+    loadResp, err := c.loadService.GetLoad(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
     }
-    return nil
+
+    ctx.JSON(http.StatusOK, dto.StopsResponse{Stops: loadResp.Stops})
 }
 
-func (c *LoadController) convertToShipmentRequest(req *dto.CreateLoadRequest) tmsDTO.CreateShipmentRequest {
-    pickupTime, _ := time.Parse(time.RFC3339, req.Pickup["scheduledTime"].(string))
-    deliveryTime, _ := time.Parse(time.RFC3339, req.Consignee["scheduledTime"].(string))
-
-    pickupLocation := req.Pickup["address"].(map[string]interface{})
-    consigneeLocation := req.Consignee["address"].(map[string]interface{})
-
-    status := tmsDTO.Status{
-        Code: tmsDTO.StatusCode{
-            Key:   req.Status.Code.Key,
-            Value: req.Status.Code.Value,
-        },
-    }
-
-    return tmsDTO.CreateShipmentRequest{
-        LTLShipment: true,
-        StartDate: tmsDTO.DateInfo{
-            Date:     pickupTime,
-            TimeZone: "America/New_York",
-        },
-        EndDate: tmsDTO.DateInfo{
-            Date:     deliveryTime,
-            TimeZone: "America/New_York",
-        },
-        Status: status,
-        Lane: tmsDTO.Lane{
-            Start: fmt.Sprintf("%s, %s", 
-                pickupLocation["city"].(string),
-                pickupLocation["state"].(string)),
-            End: fmt.Sprintf("%s, %s",
-                consigneeLocation["city"].(string),
-                consigneeLocation["state"].(string)),
-        },
-        CustomerOrder: []tmsDTO.CustomerOrder{{
-            CustomerOrderSourceId: req.FreightLoadID,
-            Customer: tmsDTO.CustomerInfo{
-                Name: req.Customer["name"].(string),
-            },
-        }},
-    }
-}
\ No newline at end of file
+// ReplaceStops replaces a load's whole route.
+func (c *LoadController) ReplaceStops(ctx *gin.Context) {
+    id := ctx.Param("id")
+
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid load ID format"))
+        return
+    }
+
+    var req dto.ReplaceStopsRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    loadResp, err := c.loadService.UpdateLoad(ctx, id, &dto.UpdateLoadRequest{Stops: req.Stops})
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, dto.StopsResponse{Stops: loadResp.Stops})
+}
+
+func (c *LoadController) CancelLoad(ctx *gin.Context) {
+    id := ctx.Param("id")
+
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid load ID format"))
+        return
+    }
+
+    // The cancellation reason is optional, so an empty body is accepted.
+    var req dto.CancelLoadRequest
+    if ctx.Request.ContentLength > 0 {
+        if err := ctx.ShouldBindJSON(&req); err != nil {
+            ctx.Error(invalidBodyError(err))
+            return
+        }
+    }
+
+    loadResp, err := c.loadService.CancelLoad(ctx, id, &req)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, loadResp)
+}
+
+func (c *LoadController) ChangeStatus(ctx *gin.Context) {
+    id := ctx.Param("id")
+
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid load ID format"))
+        return
+    }
+
+    var req dto.ChangeStatusRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    loadResp, err := c.loadService.ChangeStatus(ctx, id, &req)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, loadResp)
+}
+
+func (c *LoadController) GetStatusHistory(ctx *gin.Context) {
+    id := ctx.Param("id")
+
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid load ID format"))
+        return
+    }
+
+    history, err := c.loadService.GetStatusHistory(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,1083 @@
+package controllers_test
+
+import (
+    "bytes"
+    "context"
+    "encoding/csv"
+    "encoding/json"
+    "fmt"
+    "io"
+    "mime/multipart"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "strings"
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/controllers"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/faketurvo"
+    "freight-broker/backend/internal/middleware"
+    "freight-broker/backend/internal/migrations"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+    "freight-broker/backend/internal/services"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+    _ "github.com/lib/pq"
+)
+
+// The suite runs against a real Postgres database named by
+// TEST_DATABASE_URL (e.g. "host=localhost user=postgres dbname=freight_test
+// sslmode=disable") and a fake Turvo. The tables are truncated between tests.
+
+type loadAPI struct {
+    t           *testing.T
+    db          *gorm.DB
+    fake        *faketurvo.Server
+    router      *gin.Engine
+    authService *services.AuthService
+    tenantID    string
+    token       string
+}
+
+func newLoadAPI(t *testing.T, tmsTimeout time.Duration) *loadAPI {
+    t.Helper()
+
+    dbURL := os.Getenv("TEST_DATABASE_URL")
+    if dbURL == "" {
+        t.Skip("TEST_DATABASE_URL is not set")
+    }
+
+    db, err := gorm.Open("postgres", dbURL)
+    if err != nil {
+        t.Fatalf("failed to connect to test database: %v", err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    migrateTestDatabase(t, db)
+    if err := db.Exec("TRUNCATE loads, stops, load_status_history, outbox_messages, sync_checkpoints, audit_log, idempotency_keys, load_imports, load_import_rows").Error; err != nil {
+        t.Fatalf("failed to reset test database: %v", err)
+    }
+
+    fake := faketurvo.New(faketurvo.Config{})
+    t.Cleanup(fake.Close)
+
+    registry := services.NewTMSRegistry(services.TMSProviderTurvo, nil)
+    registry.Register(services.TMSProviderTurvo, services.NewTurvoService(services.TMSServiceConfig{
+        BaseURL: fake.BaseURL(),
+        AuthURL: fake.AuthURL(),
+        Timeout: tmsTimeout,
+    }))
+
+    ctx, cancel := context.WithCancel(context.Background())
+    t.Cleanup(cancel)
+    // Two workers, as with several API instances, so a load's messages
+    // must be delivered in order whichever worker claims them.
+    for i := 0; i < 2; i++ {
+        worker := services.NewOutboxWorker(db, registry, services.OutboxWorkerConfig{
+            PollInterval: 20 * time.Millisecond,
+            BaseBackoff:  10 * time.Millisecond,
+            MaxAttempts:  5,
+        })
+        go worker.Run(ctx)
+    }
+
+    authService, err := services.NewAuthService(db, services.AuthServiceConfig{
+        Keys: []services.SigningKey{services.NewHMACKey("test", "test-secret")},
+    })
+    if err != nil {
+        t.Fatalf("failed to create auth service: %v", err)
+    }
+    tenantID := uuid.New().String()
+    token, err := authService.GenerateToken(tenantID, "user123", "admin", "broker")
+    if err != nil {
+        t.Fatalf("failed to generate token: %v", err)
+    }
+
+    loadService := services.NewLoadService(db, registry, services.LoadServiceConfig{})
+    loadController := controllers.NewLoadController(loadService)
+    importController := controllers.NewLoadImportController(services.NewLoadImportService(db, loadService))
+    importWorker := services.NewLoadImportWorker(db, loadService, services.LoadImportWorkerConfig{
+        PollInterval: 20 * time.Millisecond,
+    })
+    go importWorker.Run(ctx)
+    auditController := controllers.NewAuditController(services.NewAuditService(db))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.ContextWithFallback = true
+    router.Use(middleware.RequestID())
+    router.Use(middleware.ErrorHandler())
+    loads := router.Group("/api/loads")
+    loads.Use(middleware.JWTAuthMiddleware(authService))
+    {
+        loads.POST("/", loadController.CreateLoad)
+        loads.GET("/", loadController.ListLoads)
+        loads.GET("/export", loadController.ExportLoads)
+        loads.POST("/import", importController.ImportLoads)
+        loads.GET("/import/:id", importController.GetImport)
+        loads.GET("/import/:id/result", importController.GetResult)
+        loads.GET("/:id", loadController.GetLoad)
+        loads.PUT("/:id", loadController.UpdateLoad)
+        loads.PATCH("/:id", loadController.UpdateLoad)
+        loads.DELETE("/:id", loadController.CancelLoad)
+        loads.GET("/:id/stops", loadController.GetStops)
+        loads.PUT("/:id/stops", loadController.ReplaceStops)
+        loads.POST("/:id/status", loadController.ChangeStatus)
+        loads.GET("/:id/status/history", loadController.GetStatusHistory)
+        loads.GET("/:id/audit", auditController.GetLoadAudit)
+    }
+    audit := router.Group("/api/admin/audit")
+    audit.Use(middleware.JWTAuthMiddleware(authService))
+    {
+        audit.GET("", auditController.SearchAudit)
+        audit.GET("/verify", auditController.VerifyAuditLog)
+    }
+
+    return &loadAPI{t: t, db: db, fake: fake, router: router, authService: authService, tenantID: tenantID, token: token}
+}
+
+// migrateTestDatabase brings the test database to the latest schema.
+func migrateTestDatabase(t *testing.T, db *gorm.DB) {
+    t.Helper()
+
+    migrator, err := migrations.New(db)
+    if err != nil {
+        t.Fatalf("failed to load migrations: %v", err)
+    }
+    if err := migrator.Up(); err != nil {
+        t.Fatalf("failed to migrate test database: %v", err)
+    }
+}
+
+func (a *loadAPI) do(method, path string, body interface{}, out interface{}) int {
+    a.t.Helper()
+
+    rec := a.send(method, path, body, nil)
+    if out != nil && rec.Body.Len() > 0 {
+        if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+            a.t.Fatalf("failed to decode %s %s response %q: %v", method, path, rec.Body.String(), err)
+        }
+    }
+    return rec.Code
+}
+
+// send makes a request with extra headers and returns the raw response.
+// A body that is an io.Reader is sent as it is, anything else as JSON.
+func (a *loadAPI) send(method, path string, body interface{}, header http.Header) *httptest.ResponseRecorder {
+    a.t.Helper()
+
+    var reader io.Reader
+    if raw, ok := body.(io.Reader); ok {
+        reader = raw
+    } else if body != nil {
+        raw, err := json.Marshal(body)
+        if err != nil {
+            a.t.Fatalf("failed to marshal request: %v", err)
+        }
+        reader = bytes.NewReader(raw)
+    } else {
+        reader = bytes.NewReader(nil)
+    }
+
+    req := httptest.NewRequest(method, path, reader)
+    req.Header.Set("Content-Type", "application/json")
+    if a.token != "" {
+        req.Header.Set("Authorization", "Bearer "+a.token)
+    }
+    for name, values := range header {
+        req.Header[name] = values
+    }
+
+    rec := httptest.NewRecorder()
+    a.router.ServeHTTP(rec, req)
+    return rec
+}
+
+// waitForSync polls the load until the outbox worker has pushed it.
+func (a *loadAPI) waitForSync(id string) dto.LoadResponse {
+    a.t.Helper()
+
+    deadline := time.Now().Add(5 * time.Second)
+    for {
+        var load dto.LoadResponse
+        if code := a.do("GET", "/api/loads/"+id, nil, &load); code != http.StatusOK {
+            a.t.Fatalf("GET load returned %d", code)
+        }
+        if load.TMSSync.Status == models.TMSSyncSynced {
+            return load
+        }
+        if time.Now().After(deadline) {
+            a.t.Fatalf("load %s did not sync, last state %+v", id, load.TMSSync)
+        }
+        time.Sleep(20 * time.Millisecond)
+    }
+}
+
+func (a *loadAPI) createLoad(freightLoadID string) dto.LoadResponse {
+    a.t.Helper()
+
+    var load dto.LoadResponse
+    if code := a.do("POST", "/api/loads/", newCreateLoadRequest(freightLoadID), &load); code != http.StatusCreated {
+        a.t.Fatalf("POST load returned %d", code)
+    }
+    return load
+}
+
+// userToken creates a user of the API's tenant and returns a token for
+// them, for requests that are checked against the user as stored.
+func (a *loadAPI) userToken(role string, active bool) string {
+    a.t.Helper()
+
+    user := models.User{
+        ID:       uuid.New(),
+        TenantID: uuid.MustParse(a.tenantID),
+        Username: "user-" + uuid.New().String(),
+        Role:     role,
+        Active:   active,
+    }
+    if err := a.db.Create(&user).Error; err != nil {
+        a.t.Fatalf("failed to create user: %v", err)
+    }
+    token, err := a.authService.GenerateToken(a.tenantID, user.ID.String(), user.Username, role)
+    if err != nil {
+        a.t.Fatalf("failed to generate token: %v", err)
+    }
+    return token
+}
+
+// partyToken signs in a new customer or carrier user of the API's tenant
+// who belongs to partyID.
+func (a *loadAPI) partyToken(role, partyID string) string {
+    a.t.Helper()
+
+    user := models.User{
+        ID:       uuid.New(),
+        TenantID: uuid.MustParse(a.tenantID),
+        Username: "user-" + uuid.New().String(),
+        Role:     role,
+        PartyID:  partyID,
+        Active:   true,
+    }
+    if err := a.db.Create(&user).Error; err != nil {
+        a.t.Fatalf("failed to create user: %v", err)
+    }
+    tokens, err := a.authService.IssueTokens(context.Background(), &user)
+    if err != nil {
+        a.t.Fatalf("failed to issue tokens: %v", err)
+    }
+    return tokens.Token
+}
+
+func newCreateLoadRequest(freightLoadID string) dto.CreateLoadRequest {
+    return dto.CreateLoadRequest{
+        FreightLoadID: freightLoadID,
+        Status: dto.StatusDTO{
+            Code: dto.StatusCodeDTO{Key: "2101", Value: "Tendered"},
+        },
+        Customer: &models.Party{Name: "Acme"},
+        Pickup: &models.Location{
+            ScheduledTime: "2026-03-01T15:00:00Z",
+            Address:       models.Address{City: "Chicago", State: "IL"},
+        },
+        Consignee: &models.Location{
+            ScheduledTime: "2026-03-03T15:00:00Z",
+            Address:       models.Address{City: "Dallas", State: "TX"},
+        },
+    }
+}
+
+func TestLoadLifecycleAgainstFakeTurvo(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    created := api.createLoad("FL-100")
+    if created.TMSSync.Status != models.TMSSyncPending {
+        t.Errorf("expected a new load to be pending, got %q", created.TMSSync.Status)
+    }
+
+    synced := api.waitForSync(created.ID)
+    shipment, ok := api.fake.Shipment(synced.ExternalTMSLoadID)
+    if !ok {
+        t.Fatalf("expected shipment %q in fake Turvo", synced.ExternalTMSLoadID)
+    }
+    if shipment.Lane.Start != "Chicago, IL" || shipment.CustomerOrder[0].CustomerOrderSourceId != "FL-100" {
+        t.Errorf("unexpected shipment in fake Turvo: %+v", shipment)
+    }
+    if synced.TMSSync.CustomID != shipment.CustomID {
+        t.Errorf("expected custom ID %q, got %q", shipment.CustomID, synced.TMSSync.CustomID)
+    }
+
+    update := map[string]interface{}{
+        "consignee": map[string]interface{}{
+            "scheduledTime": "2026-03-04T15:00:00Z",
+            "address":       map[string]interface{}{"city": "Austin", "state": "TX"},
+        },
+    }
+    if code := api.do("PATCH", "/api/loads/"+created.ID, update, nil); code != http.StatusOK {
+        t.Fatalf("PATCH load returned %d", code)
+    }
+    api.waitForSync(created.ID)
+    if shipment, _ := api.fake.Shipment(synced.ExternalTMSLoadID); shipment.Lane.End != "Austin, TX" {
+        t.Errorf("expected the update to reach Turvo, lane end is %q", shipment.Lane.End)
+    }
+
+    var cancelled dto.LoadResponse
+    if code := api.do("DELETE", "/api/loads/"+created.ID, dto.CancelLoadRequest{Reason: "customer request"}, &cancelled); code != http.StatusOK {
+        t.Fatalf("DELETE load returned %d", code)
+    }
+    if cancelled.CancelledAt == "" {
+        t.Error("expected cancelledAt to be set")
+    }
+    api.waitForSync(created.ID)
+    if _, ok := api.fake.Shipment(synced.ExternalTMSLoadID); ok {
+        t.Error("expected the shipment to be deleted from Turvo")
+    }
+
+    if code := api.do("PATCH", "/api/loads/"+created.ID, update, nil); code != http.StatusConflict {
+        t.Errorf("expected 409 updating a cancelled load, got %d", code)
+    }
+
+    var list dto.ListLoadsResponse
+    if code := api.do("GET", "/api/loads/?page=1&size=10", nil, &list); code != http.StatusOK {
+        t.Fatalf("GET loads returned %d", code)
+    }
+    if list.Total == nil || *list.Total != 1 || len(list.Loads) != 1 {
+        t.Errorf("expected one load in the list, got total %v", list.Total)
+    }
+}
+
+// TestChangesDuringShipmentCreateReachTurvo edits and cancels loads while
+// their shipments are being created, before they have a shipment ID.
+func TestChangesDuringShipmentCreateReachTurvo(t *testing.T) {
+    api := newLoadAPI(t, 0)
+    whileCreating := func(freightLoadID string, change func(id string)) dto.LoadResponse {
+        api.fake.InjectFault(faketurvo.RouteCreate, faketurvo.Fault{Delay: 300 * time.Millisecond, Times: 1})
+        requests := api.fake.Requests(faketurvo.RouteCreate)
+        created := api.createLoad(freightLoadID)
+        for api.fake.Requests(faketurvo.RouteCreate) == requests {
+            time.Sleep(5 * time.Millisecond)
+        }
+        change(created.ID)
+        return api.waitForSync(created.ID)
+    }
+
+    edited := whileCreating("FL-150", func(id string) {
+        update := map[string]interface{}{"consignee": map[string]interface{}{
+            "scheduledTime": "2026-03-04T15:00:00Z",
+            "address":       map[string]interface{}{"city": "Austin", "state": "TX"},
+        }}
+        if code := api.do("PATCH", "/api/loads/"+id, update, nil); code != http.StatusOK {
+            t.Fatalf("PATCH load returned %d", code)
+        }
+    })
+    if shipment, _ := api.fake.Shipment(edited.ExternalTMSLoadID); shipment.Lane.End != "Austin, TX" {
+        t.Errorf("expected the edit to reach Turvo, lane end is %q", shipment.Lane.End)
+    }
+    if got := len(api.fake.Shipments()); got != 1 {
+        t.Errorf("expected the edit to wait for the create, got %d shipments", got)
+    }
+
+    cancelled := whileCreating("FL-151", func(id string) {
+        if code := api.do("DELETE", "/api/loads/"+id, nil, nil); code != http.StatusOK {
+            t.Fatalf("DELETE load returned %d", code)
+        }
+    })
+    if _, ok := api.fake.Shipment(cancelled.ExternalTMSLoadID); cancelled.ExternalTMSLoadID == "" || ok {
+        t.Errorf("expected the cancelled load's new shipment to be deleted, load %+v", cancelled.TMSSync)
+    }
+}
+
+func TestMultiStopLoadReachesTurvo(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    req := newCreateLoadRequest("FL-150")
+    req.Pickup, req.Consignee = nil, nil
+    req.Stops = []dto.StopDTO{
+        {Type: models.StopTypePickup, AppointmentStart: "2026-03-01T15:00:00Z", Address: models.Address{City: "Chicago", State: "IL"}},
+        {Type: models.StopTypeDrop, AppointmentStart: "2026-03-02T15:00:00Z", Address: models.Address{City: "Memphis", State: "TN"},
+            ReferenceNumbers: []models.ReferenceNumber{{Type: "po", Value: "PO-7"}}},
+        {Type: models.StopTypeDrop, AppointmentStart: "2026-03-03T15:00:00Z", Address: models.Address{City: "Dallas", State: "TX"}},
+    }
+
+    var created dto.LoadResponse
+    if code := api.do("POST", "/api/loads/", req, &created); code != http.StatusCreated {
+        t.Fatalf("POST multi-stop load returned %d", code)
+    }
+    if len(created.Stops) != 3 || created.Consignee.Address.City != "Dallas" {
+        t.Fatalf("expected 3 stops ending in Dallas, got %+v", created.Stops)
+    }
+
+    synced := api.waitForSync(created.ID)
+    shipment, _ := api.fake.Shipment(synced.ExternalTMSLoadID)
+    if len(shipment.GlobalRoute) != 3 || shipment.GlobalRoute[1].PONumbers[0] != "PO-7" {
+        t.Errorf("expected the full route in Turvo, got %+v", shipment.GlobalRoute)
+    }
+    if shipment.Lane.End != "Dallas, TX" {
+        t.Errorf("expected the lane to end at the last stop, got %q", shipment.Lane.End)
+    }
+
+    replace := dto.ReplaceStopsRequest{Stops: req.Stops[:2]}
+    var stops dto.StopsResponse
+    if code := api.do("PUT", "/api/loads/"+created.ID+"/stops", replace, &stops); code != http.StatusOK {
+        t.Fatalf("PUT stops returned %d", code)
+    }
+    if len(stops.Stops) != 2 {
+        t.Errorf("expected 2 stops after replacing the route, got %d", len(stops.Stops))
+    }
+    api.waitForSync(created.ID)
+    if shipment, _ := api.fake.Shipment(synced.ExternalTMSLoadID); len(shipment.GlobalRoute) != 2 {
+        t.Errorf("expected the shortened route in Turvo, got %d stops", len(shipment.GlobalRoute))
+    }
+
+    bad := dto.ReplaceStopsRequest{Stops: req.Stops[1:]}
+    if code := api.do("PUT", "/api/loads/"+created.ID+"/stops", bad, nil); code != http.StatusBadRequest {
+        t.Errorf("expected 400 for a route starting with a drop, got %d", code)
+    }
+}
+
+func TestLoadsAreIsolatedByTenant(t *testing.T) {
+    api := newLoadAPI(t, 0)
+    created := api.createLoad("FL-TENANT")
+
+    other, err := api.authService.GenerateToken(uuid.New().String(), "user456", "other", "broker")
+    if err != nil {
+        t.Fatalf("failed to generate token: %v", err)
+    }
+    own := api.token
+    api.token = other
+
+    if code := api.do("GET", "/api/loads/"+created.ID, nil, nil); code != http.StatusNotFound {
+        t.Errorf("expected another tenant's load to be not found, got %d", code)
+    }
+    if code := api.do("PATCH", "/api/loads/"+created.ID, map[string]string{"poNums": "PO-X"}, nil); code != http.StatusNotFound {
+        t.Errorf("expected another tenant's load not to be updated, got %d", code)
+    }
+    var list dto.ListLoadsResponse
+    api.do("GET", "/api/loads/", nil, &list)
+    if len(list.Loads) != 0 || list.Total == nil || *list.Total != 0 {
+        t.Errorf("expected another tenant to list no loads, got %+v", list)
+    }
+
+    api.token = own
+    if code := api.do("GET", "/api/loads/"+created.ID, nil, nil); code != http.StatusOK {
+        t.Errorf("expected the owning tenant to see its load, got %d", code)
+    }
+}
+
+func TestCustomersAndCarriersSeeOnlyTheirLoads(t *testing.T) {
+    api := newLoadAPI(t, 0)
+    ids := make(map[string]string)
+    for _, party := range []struct{ freightLoadID, account, scac string }{
+        {"FL-460", "ACME-1", "ABCD"},
+        {"FL-461", "GLOBEX-1", "WXYZ"},
+    } {
+        req := newCreateLoadRequest(party.freightLoadID)
+        req.Customer.AccountNumber = party.account
+        req.Carrier = &models.Carrier{Name: party.scac + " Freight", SCAC: party.scac}
+        var load dto.LoadResponse
+        if code := api.do("POST", "/api/loads/", req, &load); code != http.StatusCreated {
+            t.Fatalf("POST load returned %d", code)
+        }
+        ids[party.freightLoadID] = load.ID
+    }
+
+    own := api.token
+    defer func() { api.token = own }()
+    for _, tt := range []struct {
+        role, partyID, visible, hidden string
+    }{
+        {models.RoleCustomer, "ACME-1", "FL-460", "FL-461"},
+        {models.RoleCarrier, "WXYZ", "FL-461", "FL-460"},
+    } {
+        api.token = api.partyToken(tt.role, tt.partyID)
+
+        var list dto.ListLoadsResponse
+        if code := api.do("GET", "/api/loads/", nil, &list); code != http.StatusOK {
+            t.Fatalf("%s: GET loads returned %d", tt.role, code)
+        }
+        if len(list.Loads) != 1 || list.Loads[0].FreightLoadID != tt.visible {
+            t.Errorf("%s: expected only %s listed, got %+v", tt.role, tt.visible, list.Loads)
+        }
+        if code := api.do("GET", "/api/loads/"+ids[tt.visible], nil, nil); code != http.StatusOK {
+            t.Errorf("%s: expected to read %s, got %d", tt.role, tt.visible, code)
+        }
+        hidden := "/api/loads/" + ids[tt.hidden]
+        for _, path := range []string{hidden, hidden + "/status/history", hidden + "/audit"} {
+            if code := api.do("GET", path, nil, nil); code != http.StatusNotFound {
+                t.Errorf("%s: expected 404 for %s, got %d", tt.role, path, code)
+            }
+        }
+
+        rec := api.send("GET", "/api/loads/export", nil, nil)
+        table, err := csv.NewReader(rec.Body).ReadAll()
+        if rec.Code != http.StatusOK || err != nil || len(table) != 2 || !strings.Contains(strings.Join(table[1], ","), tt.visible) {
+            t.Errorf("%s: expected to export only %s, got %d %q (%v)", tt.role, tt.visible, rec.Code, table, err)
+        }
+
+        var audit dto.ListAuditEntriesResponse
+        if code := api.do("GET", "/api/admin/audit", nil, &audit); code != http.StatusOK {
+            t.Fatalf("%s: GET audit returned %d", tt.role, code)
+        }
+        for _, entry := range audit.Entries {
+            if entry.LoadID != ids[tt.visible] {
+                t.Errorf("%s: expected only %s's audit entries, got one for %s", tt.role, tt.visible, entry.LoadID)
+            }
+        }
+    }
+
+    // A customer user without a party sees nothing rather than everything.
+    api.token = api.partyToken(models.RoleCustomer, "")
+    var list dto.ListLoadsResponse
+    if code := api.do("GET", "/api/loads/", nil, &list); code != http.StatusOK || len(list.Loads) != 0 {
+        t.Errorf("expected a customer without a party to see no loads, got %d %+v", code, list.Loads)
+    }
+}
+
+func TestLoadStatusTransitions(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    created := api.createLoad("FL-160")
+    if created.Status.Code.Key != models.StatusTendered {
+        t.Fatalf("expected the Turvo tendered code to map to %q, got %q", models.StatusTendered, created.Status.Code.Key)
+    }
+    synced := api.waitForSync(created.ID)
+
+    var covered dto.LoadResponse
+    change := dto.ChangeStatusRequest{Status: models.StatusCovered, Reason: "carrier booked"}
+    if code := api.do("POST", "/api/loads/"+created.ID+"/status", change, &covered); code != http.StatusOK {
+        t.Fatalf("POST status returned %d", code)
+    }
+    if covered.Status.Code.Key != models.StatusCovered || covered.Status.Code.Value != "Covered" {
+        t.Errorf("expected the load to be covered, got %+v", covered.Status.Code)
+    }
+    api.waitForSync(created.ID)
+    if shipment, _ := api.fake.Shipment(synced.ExternalTMSLoadID); shipment.Status.Code.Key != "2102" {
+        t.Errorf("expected Turvo status 2102, got %q", shipment.Status.Code.Key)
+    }
+
+    skip := dto.ChangeStatusRequest{Status: models.StatusDelivered}
+    if code := api.do("POST", "/api/loads/"+created.ID+"/status", skip, nil); code != http.StatusConflict {
+        t.Errorf("expected 409 moving a covered load to delivered, got %d", code)
+    }
+    unknown := dto.ChangeStatusRequest{Status: "lost"}
+    if code := api.do("POST", "/api/loads/"+created.ID+"/status", unknown, nil); code != http.StatusBadRequest {
+        t.Errorf("expected 400 for an unknown status, got %d", code)
+    }
+
+    var history dto.StatusHistoryResponse
+    if code := api.do("GET", "/api/loads/"+created.ID+"/status/history", nil, &history); code != http.StatusOK {
+        t.Fatalf("GET status history returned %d", code)
+    }
+    if len(history.History) != 2 {
+        t.Fatalf("expected 2 history entries, got %+v", history.History)
+    }
+    last := history.History[1]
+    if last.FromStatus != models.StatusTendered || last.ToStatus != models.StatusCovered ||
+        last.Reason != "carrier booked" || last.ChangedByName != "admin" {
+        t.Errorf("unexpected history entry %+v", last)
+    }
+}
+
+func TestLoadChangesAreAudited(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    created := api.createLoad("FL-AUDIT")
+    synced := api.waitForSync(created.ID)
+    if code := api.do("PATCH", "/api/loads/"+created.ID, map[string]string{"poNums": "PO-9"}, nil); code != http.StatusOK {
+        t.Fatalf("PATCH load returned %d", code)
+    }
+    change := dto.ChangeStatusRequest{Status: models.StatusCovered}
+    if code := api.do("POST", "/api/loads/"+created.ID+"/status", change, nil); code != http.StatusOK {
+        t.Fatalf("POST status returned %d", code)
+    }
+
+    var audit dto.LoadAuditResponse
+    if code := api.do("GET", "/api/loads/"+created.ID+"/audit", nil, &audit); code != http.StatusOK {
+        t.Fatalf("GET audit returned %d", code)
+    }
+    if len(audit.Entries) != 4 {
+        t.Fatalf("expected 4 audit entries, got %+v", audit.Entries)
+    }
+    for i, action := range []string{models.AuditActionCreate, models.AuditActionUpdate, models.AuditActionUpdate, models.AuditActionStatus} {
+        actor := "admin"
+        if i == 1 {
+            actor = requestctx.SystemActor.Username
+        }
+        if entry := audit.Entries[i]; entry.Action != action || entry.ActorName != actor {
+            t.Errorf("unexpected audit entry %d: %+v", i, entry)
+        }
+    }
+    if id, ok := audit.Entries[1].Changes["externalTMSLoadID"]; !ok || id.To != synced.ExternalTMSLoadID {
+        t.Errorf("expected the outbox to record the shipment ID, got %+v", audit.Entries[1].Changes)
+    }
+    if poNums, ok := audit.Entries[2].Changes["poNums"]; !ok || poNums.To != "PO-9" || len(audit.Entries[2].Changes) != 1 {
+        t.Errorf("expected the update to change poNums only, got %+v", audit.Entries[2].Changes)
+    }
+
+    var found dto.ListAuditEntriesResponse
+    if code := api.do("GET", "/api/admin/audit?action=update&requestId="+audit.Entries[2].RequestID, nil, &found); code != http.StatusOK {
+        t.Fatalf("GET audit search returned %d", code)
+    }
+    if found.Total != 1 || found.Entries[0].ID != audit.Entries[2].ID {
+        t.Errorf("expected the search to find the update, got %+v", found)
+    }
+
+    var verify dto.AuditVerifyResponse
+    if code := api.do("GET", "/api/admin/audit/verify", nil, &verify); code != http.StatusOK || !verify.Valid || verify.Entries != 4 {
+        t.Fatalf("expected an intact chain of 4 entries, got %d %+v", code, verify)
+    }
+
+    if err := api.db.Exec("UPDATE audit_log SET actor_name = 'mallory'").Error; err == nil {
+        t.Fatal("expected the audit log to refuse updates")
+    }
+    for _, statement := range []string{
+        "ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only",
+        "UPDATE audit_log SET actor_name = 'mallory' WHERE sequence = 2",
+        "ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only",
+    } {
+        if err := api.db.Exec(statement).Error; err != nil {
+            t.Fatalf("failed to tamper with the audit log: %v", err)
+        }
+    }
+    api.do("GET", "/api/admin/audit/verify", nil, &verify)
+    if verify.Valid || verify.BrokenAtSequence != 2 {
+        t.Errorf("expected the chain to break at entry 2, got %+v", verify)
+    }
+}
+
+func TestListLoadsFiltersSortsAndSearches(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    first := newCreateLoadRequest("FL-300")
+    first.Customer.Name = "Acme Foods"
+    first.PoNums = "PO-555"
+    second := newCreateLoadRequest("FL-301")
+    second.Customer.Name = "Globex"
+    second.Pickup.ScheduledTime = "2026-04-10T15:00:00Z"
+    second.Pickup.Address.State = "WI"
+    for _, req := range []dto.CreateLoadRequest{first, second} {
+        if code := api.do("POST", "/api/loads/", req, nil); code != http.StatusCreated {
+            t.Fatalf("POST load returned %d", code)
+        }
+    }
+
+    cases := map[string][]string{
+        "?customer=acme":                  {"FL-300"},
+        "?originState=wi":                 {"FL-301"},
+        "?po=555":                         {"FL-300"},
+        "?q=globex":                       {"FL-301"},
+        "?pickupFrom=2026-04-01":          {"FL-301"},
+        "?pickupTo=2026-03-01":            {"FL-300"},
+        "?status=tendered&sort=pickupAt":  {"FL-300", "FL-301"},
+        "?sort=-pickupAt":                 {"FL-301", "FL-300"},
+        "?status=delivered":               {},
+    }
+    for params, want := range cases {
+        var list dto.ListLoadsResponse
+        if code := api.do("GET", "/api/loads/"+params, nil, &list); code != http.StatusOK {
+            t.Errorf("GET loads%s returned %d", params, code)
+            continue
+        }
+        var got []string
+        for _, load := range list.Loads {
+            got = append(got, load.FreightLoadID)
+        }
+        if len(got) != len(want) || list.Total == nil || int(*list.Total) != len(want) {
+            t.Errorf("GET loads%s returned %v (total %v), want %v", params, got, list.Total, want)
+            continue
+        }
+        for i := range want {
+            if got[i] != want[i] {
+                t.Errorf("GET loads%s returned %v, want %v", params, got, want)
+                break
+            }
+        }
+    }
+
+    for _, params := range []string{"?sort=rate", "?status=lost", "?pickupFrom=tomorrow"} {
+        if code := api.do("GET", "/api/loads/"+params, nil, nil); code != http.StatusBadRequest {
+            t.Errorf("expected 400 for GET loads%s, got %d", params, code)
+        }
+    }
+}
+
+func TestListLoadsCursorPagination(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    // Two loads share a pickup time, so paging must fall back to the ID.
+    for i, pickup := range []string{"2026-03-01T15:00:00Z", "2026-03-02T15:00:00Z", "2026-03-02T15:00:00Z", "2026-03-04T15:00:00Z", "2026-03-05T15:00:00Z"} {
+        req := newCreateLoadRequest(fmt.Sprintf("FL-40%d", i))
+        req.Pickup.ScheduledTime = pickup
+        if code := api.do("POST", "/api/loads/", req, nil); code != http.StatusCreated {
+            t.Fatalf("POST load returned %d", code)
+        }
+    }
+
+    var all dto.ListLoadsResponse
+    if code := api.do("GET", "/api/loads/?size=100&sort=pickupAt", nil, &all); code != http.StatusOK {
+        t.Fatalf("GET loads returned %d", code)
+    }
+
+    var forward []string
+    var pages []dto.ListLoadsResponse
+    path := "/api/loads/?size=2&sort=pickupAt&cursor="
+    for path != "" {
+        var page dto.ListLoadsResponse
+        if code := api.do("GET", path, nil, &page); code != http.StatusOK {
+            t.Fatalf("GET %s returned %d", path, code)
+        }
+        if page.Total != nil {
+            t.Error("expected no total in cursor mode by default")
+        }
+        for _, load := range page.Loads {
+            forward = append(forward, load.ID)
+        }
+        pages = append(pages, page)
+        path = ""
+        if page.NextCursor != "" {
+            path = "/api/loads/?size=2&sort=pickupAt&cursor=" + page.NextCursor
+        }
+    }
+    if len(pages) != 3 || len(forward) != len(all.Loads) {
+        t.Fatalf("expected 5 loads over 3 pages, got %d loads over %d pages", len(forward), len(pages))
+    }
+    for i, load := range all.Loads {
+        if forward[i] != load.ID {
+            t.Fatalf("cursor order %v differs from page order at %d", forward, i)
+        }
+    }
+    if pages[0].PrevCursor != "" {
+        t.Error("expected no previous cursor on the first page")
+    }
+
+    var back dto.ListLoadsResponse
+    if code := api.do("GET", "/api/loads/?size=2&sort=pickupAt&cursor="+pages[2].PrevCursor, nil, &back); code != http.StatusOK {
+        t.Fatalf("GET previous page returned %d", code)
+    }
+    if len(back.Loads) != 2 || back.Loads[0].ID != forward[2] || back.Loads[1].ID != forward[3] {
+        t.Errorf("expected the previous page to hold loads 3 and 4, got %+v", back.Loads)
+    }
+
+    var estimated dto.ListLoadsResponse
+    if code := api.do("GET", "/api/loads/?size=2&cursor=&total=estimate&customer=acme", nil, &estimated); code != http.StatusOK {
+        t.Fatalf("GET loads with an estimated total returned %d", code)
+    }
+    if estimated.Total == nil {
+        t.Error("expected an estimated total")
+    }
+
+    if code := api.do("GET", "/api/loads/?size=2&sort=-pickupAt&cursor="+pages[0].NextCursor, nil, nil); code != http.StatusBadRequest {
+        t.Errorf("expected 400 reusing a cursor with another sort, got %d", code)
+    }
+    if code := api.do("GET", "/api/loads/?cursor=not-a-cursor", nil, nil); code != http.StatusBadRequest {
+        t.Errorf("expected 400 for a malformed cursor, got %d", code)
+    }
+}
+
+func TestLoadRequestValidation(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    invalid := newCreateLoadRequest("")
+    if code := api.do("POST", "/api/loads/", invalid, nil); code != http.StatusBadRequest {
+        t.Errorf("expected 400 without a freight load ID, got %d", code)
+    }
+    if code := api.do("GET", "/api/loads/not-a-uuid", nil, nil); code != http.StatusBadRequest {
+        t.Errorf("expected 400 for a malformed ID, got %d", code)
+    }
+    if code := api.do("GET", "/api/loads/00000000-0000-0000-0000-000000000000", nil, nil); code != http.StatusNotFound {
+        t.Errorf("expected 404 for an unknown load, got %d", code)
+    }
+    if code := api.do("GET", "/api/loads/?page=0", nil, nil); code != http.StatusBadRequest {
+        t.Errorf("expected 400 for page 0, got %d", code)
+    }
+
+    api.token = ""
+    if code := api.do("GET", "/api/loads/", nil, nil); code != http.StatusUnauthorized {
+        t.Errorf("expected 401 without a token, got %d", code)
+    }
+}
+
+func TestCreateLoadIsIdempotent(t *testing.T) {
+    api := newLoadAPI(t, 0)
+    header := http.Header{controllers.IdempotencyKeyHeader: {"create-fl-500"}}
+
+    var first, replayed dto.LoadResponse
+    rec := api.send("POST", "/api/loads/", newCreateLoadRequest("FL-500"), header)
+    if rec.Code != http.StatusCreated || rec.Header().Get(controllers.IdempotentReplayedHeader) != "" {
+        t.Fatalf("expected a fresh 201, got %d %s", rec.Code, rec.Body.String())
+    }
+    json.Unmarshal(rec.Body.Bytes(), &first)
+
+    rec = api.send("POST", "/api/loads/", newCreateLoadRequest("FL-500"), header)
+    if rec.Code != http.StatusCreated || rec.Header().Get(controllers.IdempotentReplayedHeader) != "true" {
+        t.Fatalf("expected a replayed 201, got %d %s", rec.Code, rec.Body.String())
+    }
+    json.Unmarshal(rec.Body.Bytes(), &replayed)
+    if replayed.ID != first.ID {
+        t.Errorf("expected the replay to return load %s, got %s", first.ID, replayed.ID)
+    }
+
+    if rec := api.send("POST", "/api/loads/", newCreateLoadRequest("FL-501"), header); rec.Code != http.StatusBadRequest {
+        t.Errorf("expected 400 for a reused key with another body, got %d", rec.Code)
+    }
+
+    rec = api.send("POST", "/api/loads/", newCreateLoadRequest("FL-500"), nil)
+    var conflict middleware.ErrorResponse
+    json.Unmarshal(rec.Body.Bytes(), &conflict)
+    if rec.Code != http.StatusConflict || conflict.Location != "/api/loads/"+first.ID || rec.Header().Get("Location") != conflict.Location {
+        t.Fatalf("expected 409 pointing to the existing load, got %d %s", rec.Code, rec.Body.String())
+    }
+
+    var list dto.ListLoadsResponse
+    if api.do("GET", "/api/loads/", nil, &list); len(list.Loads) != 1 {
+        t.Errorf("expected a single load, got %d", len(list.Loads))
+    }
+
+    api.waitForSync(first.ID)
+    if code := api.do("DELETE", "/api/loads/"+first.ID, nil, nil); code != http.StatusOK {
+        t.Fatalf("DELETE load returned %d", code)
+    }
+    api.createLoad("FL-500")
+}
+
+func TestImportLoadsFromSpreadsheet(t *testing.T) {
+    api := newLoadAPI(t, 0)
+    existing := api.createLoad("FL-600")
+    api.token = api.userToken(models.RoleBroker, true)
+
+    sheet := "Order,customer.name,pickup.scheduledTime,consignee.scheduledTime,rateData.totalRate\n" +
+        "FL-601,Acme,2026-03-01T15:00:00Z,2026-03-03T15:00:00Z,900\n" +
+        "FL-600,Acme,2026-03-01T15:00:00Z,2026-03-03T15:00:00Z,900\n" +
+        "FL-601,Acme,2026-03-01T15:00:00Z,2026-03-03T15:00:00Z,900\n" +
+        "FL-602,,2026-03-01T15:00:00Z,2026-03-03T15:00:00Z,lots\n"
+    upload := func(dryRun string) *httptest.ResponseRecorder {
+        var body bytes.Buffer
+        form := multipart.NewWriter(&body)
+        form.WriteField("mapping", `{"freightLoadID": "Order"}`)
+        file, _ := form.CreateFormFile("file", "week12.csv")
+        file.Write([]byte(sheet))
+        form.Close()
+        return api.send("POST", "/api/loads/import?dryRun="+dryRun, &body, http.Header{"Content-Type": {form.FormDataContentType()}})
+    }
+
+    var report dto.ImportValidationResponse
+    rec := upload("true")
+    json.Unmarshal(rec.Body.Bytes(), &report)
+    if rec.Code != http.StatusOK || report.ValidRows != 1 || report.InvalidRows != 3 {
+        t.Fatalf("expected one valid and three invalid rows, got %d %s", rec.Code, rec.Body.String())
+    }
+    if report.Errors[0].Row != 3 || report.Errors[0].Errors[0].Message != "already exists as load "+existing.ID {
+        t.Errorf("expected row 3 to clash with the existing load, got %+v", report.Errors[0])
+    }
+    if report.Errors[1].Row != 4 || report.Errors[1].Errors[0].Message != "repeats row 2" {
+        t.Errorf("expected row 4 to repeat row 2, got %+v", report.Errors[1])
+    }
+
+    var job dto.LoadImportResponse
+    rec = upload("false")
+    json.Unmarshal(rec.Body.Bytes(), &job)
+    if rec.Code != http.StatusAccepted || job.TotalRows != 4 || job.FailedRows != 3 {
+        t.Fatalf("expected a queued import, got %d %s", rec.Code, rec.Body.String())
+    }
+
+    deadline := time.Now().Add(5 * time.Second)
+    for job.Status != models.LoadImportCompleted {
+        if time.Now().After(deadline) {
+            t.Fatalf("import did not complete, last state %+v", job)
+        }
+        time.Sleep(20 * time.Millisecond)
+        api.do("GET", "/api/loads/import/"+job.ID, nil, &job)
+    }
+    if job.CreatedRows != 1 || job.PendingRows != 0 {
+        t.Fatalf("expected one created row, got %+v", job)
+    }
+
+    rec = api.send("GET", "/api/loads/import/"+job.ID+"/result", nil, nil)
+    result, err := csv.NewReader(rec.Body).ReadAll()
+    if err != nil || len(result) != 5 || result[1][2] != models.LoadImportRowCreated || result[4][4] == "" {
+        t.Fatalf("unexpected result file %q (%v)", result, err)
+    }
+    created := api.waitForSync(result[1][3])
+    if created.FreightLoadID != "FL-601" || created.Customer.Name != "Acme" {
+        t.Errorf("unexpected imported load %+v", created)
+    }
+
+    // A user deactivated after uploading an import gets none of its rows.
+    api.token = api.userToken(models.RoleBroker, false)
+    sheet = "Order,customer.name,pickup.scheduledTime,consignee.scheduledTime\n" +
+        "FL-603,Acme,2026-03-01T15:00:00Z,2026-03-03T15:00:00Z\n"
+    rec = upload("false")
+    json.Unmarshal(rec.Body.Bytes(), &job)
+    if rec.Code != http.StatusAccepted {
+        t.Fatalf("expected a queued import, got %d %s", rec.Code, rec.Body.String())
+    }
+    deadline = time.Now().Add(5 * time.Second)
+    for job.Status != models.LoadImportCompleted {
+        if time.Now().After(deadline) {
+            t.Fatalf("import did not complete, last state %+v", job)
+        }
+        time.Sleep(20 * time.Millisecond)
+        api.do("GET", "/api/loads/import/"+job.ID, nil, &job)
+    }
+    if job.CreatedRows != 0 || job.FailedRows != 1 {
+        t.Errorf("expected the inactive user's row to fail, got %+v", job)
+    }
+}
+
+func TestExportLoads(t *testing.T) {
+    api := newLoadAPI(t, 0)
+    for i, customer := range []string{"Acme", "Globex", "Acme"} {
+        req := newCreateLoadRequest(fmt.Sprintf("FL-70%d", i))
+        req.Customer.Name = customer
+        req.Pickup.ScheduledTime = fmt.Sprintf("2026-03-0%dT15:00:00Z", 3-i)
+        req.RateData = &models.RateData{TotalRate: 1250.5, Currency: "USD", CarrierRate: &models.Rate{TotalRate: 900}}
+        if code := api.do("POST", "/api/loads/", req, nil); code != http.StatusCreated {
+            t.Fatalf("POST load returned %d", code)
+        }
+    }
+
+    rec := api.send("GET", "/api/loads/export?customer=acme&sort=pickupAt", nil, nil)
+    table, err := csv.NewReader(rec.Body).ReadAll()
+    if rec.Code != http.StatusOK || err != nil || len(table) != 3 {
+        t.Fatalf("expected a header and two loads, got %d %q (%v)", rec.Code, table, err)
+    }
+    column := make(map[string]int)
+    for i, name := range table[0] {
+        column[name] = i
+    }
+    if table[1][column["freightLoadID"]] != "FL-702" || table[2][column["freightLoadID"]] != "FL-700" {
+        t.Errorf("expected the Acme loads by pickup time, got %q", table[1:])
+    }
+    row := table[1]
+    if row[column["pickupCity"]] != "Chicago" || row[column["pickupAt"]] != "2026-03-01T15:00:00Z" ||
+        row[column["totalRate"]] != "1250.5" || row[column["carrierTotalRate"]] != "900" {
+        t.Errorf("unexpected flattened row %q", row)
+    }
+
+    dispatcher, err := api.authService.GenerateToken(api.tenantID, "user789", "dispatch", models.RoleDispatcher)
+    if err != nil {
+        t.Fatalf("failed to generate token: %v", err)
+    }
+    own := api.token
+    api.token = dispatcher
+    rec = api.send("GET", "/api/loads/export?format=ndjson&customer=globex", nil, nil)
+    var loads []map[string]interface{}
+    decoder := json.NewDecoder(rec.Body)
+    for decoder.More() {
+        var load map[string]interface{}
+        if err := decoder.Decode(&load); err != nil {
+            t.Fatalf("invalid NDJSON: %v", err)
+        }
+        loads = append(loads, load)
+    }
+    if rec.Header().Get("Content-Type") != "application/x-ndjson" || len(loads) != 1 || loads[0]["customerName"] != "Globex" {
+        t.Fatalf("expected the Globex load as NDJSON, got %+v", loads)
+    }
+    if _, ok := loads[0]["totalRate"]; ok || loads[0]["carrierTotalRate"] != 900.0 {
+        t.Errorf("expected a dispatcher to see only carrier rates, got %+v", loads[0])
+    }
+
+    other, err := api.authService.GenerateToken(uuid.New().String(), "user456", "other", "broker")
+    if err != nil {
+        t.Fatalf("failed to generate token: %v", err)
+    }
+    api.token = other
+    rec = api.send("GET", "/api/loads/export", nil, nil)
+    if table, _ := csv.NewReader(rec.Body).ReadAll(); len(table) != 1 {
+        t.Errorf("expected another tenant to export no loads, got %q", table)
+    }
+
+    api.token = own
+    if code := api.do("GET", "/api/loads/export?format=pdf", nil, nil); code != http.StatusBadRequest {
+        t.Errorf("expected 400 for an unknown format, got %d", code)
+    }
+}
+
+func TestLoadSyncRetriesTurvoFaults(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    api.fake.InjectFault(faketurvo.RouteCreate, faketurvo.Fault{Status: http.StatusInternalServerError, Times: 2})
+    created := api.createLoad("FL-200")
+
+    synced := api.waitForSync(created.ID)
+    if synced.ExternalTMSLoadID == "" {
+        t.Fatal("expected the load to be linked after retries")
+    }
+    if got := api.fake.Requests(faketurvo.RouteCreate); got != 3 {
+        t.Errorf("expected 3 create attempts, got %d", got)
+    }
+    if got := len(api.fake.Shipments()); got != 1 {
+        t.Errorf("expected exactly one shipment, got %d", got)
+    }
+}
+
+func TestFailedLoadSyncLeavesLoadUnedited(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    api.fake.InjectFault(faketurvo.RouteCreate, faketurvo.Fault{Status: http.StatusInternalServerError, Times: 5})
+    created := api.createLoad("FL-250")
+
+    deadline := time.Now().Add(5 * time.Second)
+    var load dto.LoadResponse
+    for load.TMSSync.Status != models.TMSSyncFailed {
+        if time.Now().After(deadline) {
+            t.Fatalf("load did not fail to sync, last state %+v", load.TMSSync)
+        }
+        time.Sleep(20 * time.Millisecond)
+        if code := api.do("GET", "/api/loads/"+created.ID, nil, &load); code != http.StatusOK {
+            t.Fatalf("GET load returned %d", code)
+        }
+    }
+    if load.TMSSync.Error == "" {
+        t.Error("expected the sync error to be recorded")
+    }
+    if load.UpdatedAt != created.UpdatedAt {
+        t.Errorf("expected failed deliveries to leave updatedAt alone, %s became %s", created.UpdatedAt, load.UpdatedAt)
+    }
+}
+
+func TestLoadSyncRecoversFromUnauthorized(t *testing.T) {
+    api := newLoadAPI(t, 0)
+
+    api.fake.InjectFault(faketurvo.RouteCreate, faketurvo.Fault{Status: http.StatusUnauthorized, Times: 1})
+    created := api.createLoad("FL-300")
+
+    api.waitForSync(created.ID)
+    if got := len(api.fake.Shipments()); got != 1 {
+        t.Errorf("expected exactly one shipment, got %d", got)
+    }
+}
+
+func TestCancelLoadDoesNotWaitForTurvo(t *testing.T) {
+    api := newLoadAPI(t, 200*time.Millisecond)
+
+    created := api.createLoad("FL-400")
+    synced := api.waitForSync(created.ID)
+
+    api.fake.InjectFault(faketurvo.RouteDelete, faketurvo.Fault{Delay: time.Second, Times: 1})
+    var cancelled dto.LoadResponse
+    if code := api.do("DELETE", "/api/loads/"+created.ID, nil, &cancelled); code != http.StatusOK {
+        t.Fatalf("expected the cancel to succeed while Turvo is slow, got %d", code)
+    }
+    if cancelled.CancelledAt == "" || cancelled.TMSSync.Status != models.TMSSyncPending {
+        t.Errorf("expected a cancelled load waiting on the TMS, got %+v", cancelled.TMSSync)
+    }
+
+    api.waitForSync(created.ID)
+    if _, ok := api.fake.Shipment(synced.ExternalTMSLoadID); ok {
+        t.Error("expected the shipment to be deleted once Turvo recovered")
+    }
+
+    // A shipment already gone from Turvo counts as deleted.
+    other := api.createLoad("FL-401")
+    api.waitForSync(other.ID)
+    api.fake.InjectFault(faketurvo.RouteDelete, faketurvo.Fault{Status: http.StatusNotFound})
+    if code := api.do("DELETE", "/api/loads/"+other.ID, nil, nil); code != http.StatusOK {
+        t.Fatalf("DELETE load returned %d", code)
+    }
+    api.waitForSync(other.ID)
+}
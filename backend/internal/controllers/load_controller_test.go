@@ -0,0 +1,101 @@
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/middleware"
+
+    "github.com/gin-gonic/gin"
+)
+
+// postLoad sends body to CreateLoad. Requests failing validation never reach
+// the load service, so none is configured.
+func postLoad(t *testing.T, body string) (int, middleware.ErrorResponse) {
+    t.Helper()
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.ContextWithFallback = true
+    router.Use(middleware.ErrorHandler())
+    router.POST("/api/loads/", NewLoadController(nil).CreateLoad)
+
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, httptest.NewRequest("POST", "/api/loads/", bytes.NewBufferString(body)))
+
+    var resp middleware.ErrorResponse
+    json.Unmarshal(rec.Body.Bytes(), &resp)
+    return rec.Code, resp
+}
+
+func hasField(fields []apperrors.FieldError, name string) bool {
+    for _, field := range fields {
+        if field.Field == name {
+            return true
+        }
+    }
+    return false
+}
+
+func TestCreateLoadReportsFieldErrors(t *testing.T) {
+    status, resp := postLoad(t, `{
+        "customer": {"name": "Acme", "contact": {"email": "not-an-email"}},
+        "pickup": {"address": {"city": "Chicago"}},
+        "consignee": {"scheduledTime": "next tuesday"},
+        "rateData": {"totalRate": -5, "currency": "DOLLARS"}
+    }`)
+
+    if status != http.StatusBadRequest || resp.Code != apperrors.CodeValidation {
+        t.Fatalf("expected a 400 validation error, got %d %+v", status, resp)
+    }
+    for _, field := range []string{
+        "freightLoadID",
+        "customer.contact.email",
+        "pickup.scheduledTime",
+        "consignee.scheduledTime",
+        "rateData.totalRate",
+        "rateData.currency",
+    } {
+        if !hasField(resp.Fields, field) {
+            t.Errorf("expected a field error for %s, got %+v", field, resp.Fields)
+        }
+    }
+}
+
+func TestCreateLoadRejectsMalformedShapes(t *testing.T) {
+    status, resp := postLoad(t, `{
+        "freightLoadID": "FL-1",
+        "customer": {"name": "Acme"},
+        "pickup": {"scheduledTime": "2026-03-01T15:00:00Z", "address": "Chicago, IL"},
+        "consignee": {"scheduledTime": "2026-03-03T15:00:00Z"}
+    }`)
+
+    if status != http.StatusBadRequest || !hasField(resp.Fields, "pickup.address") {
+        t.Fatalf("expected a 400 for pickup.address, got %d %+v", status, resp)
+    }
+}
+
+func TestCreateLoadRequestAcceptsLegacyJSON(t *testing.T) {
+    var req dto.CreateLoadRequest
+    body := `{
+        "freightLoadID": "FL-1",
+        "customer": {"name": "Acme", "accountNumber": "A-1"},
+        "pickup": {"scheduledTime": "2026-03-01T15:00Z", "address": {"city": "Chicago", "state": "IL"}},
+        "consignee": {"scheduledTime": "2026-03-03T15:00:00Z"},
+        "carrier": {"name": "Fast Freight", "equipment": {"type": "DryVan", "length": 53}}
+    }`
+    if err := json.Unmarshal([]byte(body), &req); err != nil {
+        t.Fatalf("failed to decode legacy request: %v", err)
+    }
+    if req.Carrier.Equipment.Length != "53" {
+        t.Errorf("expected a numeric equipment length to decode as \"53\", got %q", req.Carrier.Equipment.Length)
+    }
+    if _, err := req.Pickup.ScheduledAt(); err != nil {
+        t.Errorf("expected a datetime-local scheduledTime to parse: %v", err)
+    }
+}
@@ -0,0 +1,146 @@
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/spreadsheet"
+
+    "github.com/gin-gonic/gin"
+)
+
+// exportFormatNDJSON exports one JSON object per line.
+const exportFormatNDJSON = "ndjson"
+
+// exportWriteTimeout is how long the client has to take each part of an
+// export. Exports run past the server's write timeout, so the deadline is
+// pushed back as the export makes progress instead.
+const exportWriteTimeout = 30 * time.Second
+
+// ExportLoads streams every load matching the list filters and sort as CSV,
+// XLSX or NDJSON, with the JSONB fields flattened into columns.
+func (c *LoadController) ExportLoads(ctx *gin.Context) {
+    formatName := strings.ToLower(strings.TrimSpace(ctx.DefaultQuery("format", string(spreadsheet.FormatCSV))))
+    var format spreadsheet.Format
+    switch formatName {
+    case string(spreadsheet.FormatCSV), string(spreadsheet.FormatXLSX):
+        format = spreadsheet.Format(formatName)
+    case exportFormatNDJSON:
+    default:
+        ctx.Error(apperrors.Validation("Invalid format parameter",
+            apperrors.FieldError{Field: "format", Message: "must be csv, xlsx or ndjson"}))
+        return
+    }
+
+    query, err := parseListLoadsQuery(ctx)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    name := "loads-" + time.Now().UTC().Format("20060102")
+    deadline := &exportDeadline{rc: http.NewResponseController(ctx.Writer)}
+    deadline.extend()
+
+    if format != "" {
+        writeSpreadsheet(ctx, name, format, func(w spreadsheet.Writer) error {
+            return c.loadService.ExportLoads(ctx, query, &spreadsheetExportWriter{w: w, deadline: deadline})
+        })
+        return
+    }
+
+    ctx.Header("Content-Type", "application/x-ndjson")
+    ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, exportFormatNDJSON))
+    ctx.Status(http.StatusOK)
+
+    err = c.loadService.ExportLoads(ctx, query, &ndjsonExportWriter{w: ctx.Writer, deadline: deadline})
+    if err != nil && !ctx.Writer.Written() {
+        ctx.Writer.Header().Del("Content-Type")
+        ctx.Writer.Header().Del("Content-Disposition")
+        ctx.Error(err)
+        return
+    }
+    if err != nil {
+        log.Printf("Failed to write %s.%s: %v", name, exportFormatNDJSON, err)
+    }
+}
+
+// exportDeadline pushes back the response's write deadline, at most once a
+// second. Servers and recorders that have no deadline are left alone.
+type exportDeadline struct {
+    rc       *http.ResponseController
+    extended time.Time
+}
+
+func (d *exportDeadline) extend() {
+    now := time.Now()
+    if now.Sub(d.extended) < time.Second {
+        return
+    }
+    d.extended = now
+    d.rc.SetWriteDeadline(now.Add(exportWriteTimeout))
+}
+
+// spreadsheetExportWriter writes an export as a table with a header row.
+type spreadsheetExportWriter struct {
+    w        spreadsheet.Writer
+    deadline *exportDeadline
+}
+
+func (e *spreadsheetExportWriter) WriteHeader(columns []string) error {
+    return e.w.WriteRow(columns)
+}
+
+func (e *spreadsheetExportWriter) WriteLoad(values []interface{}) error {
+    e.deadline.extend()
+    return e.w.WriteValues(values)
+}
+
+// ndjsonExportWriter writes each load as a JSON object on a line of its own,
+// keyed by column name in column order.
+type ndjsonExportWriter struct {
+    w        io.Writer
+    deadline *exportDeadline
+    keys     [][]byte
+}
+
+func (e *ndjsonExportWriter) WriteHeader(columns []string) error {
+    e.keys = make([][]byte, len(columns))
+    for i, column := range columns {
+        key, err := json.Marshal(column)
+        if err != nil {
+            return err
+        }
+        e.keys[i] = key
+    }
+    return nil
+}
+
+func (e *ndjsonExportWriter) WriteLoad(values []interface{}) error {
+    e.deadline.extend()
+
+    var b bytes.Buffer
+    b.WriteByte('{')
+    for i, value := range values {
+        raw, err := json.Marshal(value)
+        if err != nil {
+            return err
+        }
+        if i > 0 {
+            b.WriteByte(',')
+        }
+        b.Write(e.keys[i])
+        b.WriteByte(':')
+        b.Write(raw)
+    }
+    b.WriteString("}\n")
+    _, err := e.w.Write(b.Bytes())
+    return err
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "freight-broker/backend/internal/middleware"
+
+    "github.com/gin-gonic/gin"
+)
+
+func TestNDJSONExportKeepsColumnOrder(t *testing.T) {
+    var buf bytes.Buffer
+    w := &ndjsonExportWriter{w: &buf, deadline: &exportDeadline{rc: http.NewResponseController(httptest.NewRecorder())}}
+    w.WriteHeader([]string{"freightLoadID", "totalRate", "pickupAt"})
+    w.WriteLoad([]interface{}{"FL-1", 1250.5, nil})
+    w.WriteLoad([]interface{}{"FL-\"2\"", 0.0, "2026-03-01T15:00:00Z"})
+
+    want := `{"freightLoadID":"FL-1","totalRate":1250.5,"pickupAt":null}` + "\n" +
+        `{"freightLoadID":"FL-\"2\"","totalRate":0,"pickupAt":"2026-03-01T15:00:00Z"}` + "\n"
+    if buf.String() != want {
+        t.Errorf("expected %q, got %q", want, buf.String())
+    }
+}
+
+func TestExportLoadsRefusesUnknownFormats(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(middleware.ErrorHandler())
+    router.GET("/api/loads/export", NewLoadController(nil).ExportLoads)
+
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, httptest.NewRequest("GET", "/api/loads/export?format=xlsx.csv", nil))
+
+    var resp middleware.ErrorResponse
+    json.Unmarshal(rec.Body.Bytes(), &resp)
+    if rec.Code != http.StatusBadRequest || !hasField(resp.Fields, "format") {
+        t.Errorf("expected 400 on the format, got %d %+v", rec.Code, resp)
+    }
+}
@@ -0,0 +1,272 @@
+package controllers
+
+import (
+    "encoding/json"
+    "fmt"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+
+    "github.com/gin-gonic/gin/binding"
+)
+
+// maxImportRows is the most loads one file may hold.
+const maxImportRows = 5000
+
+type importKind int
+
+const (
+    importText importKind = iota
+    importNumber
+    importInteger
+    // importTime is a scheduled time, which Excel may store as a date
+    // serial number instead of text.
+    importTime
+)
+
+// importColumn is a load field a spreadsheet column can fill, named by its
+// path in a create request. Unless mapped otherwise, a column whose header
+// is the path fills it.
+type importColumn struct {
+    path string
+    kind importKind
+}
+
+var importColumns = []importColumn{
+    {"freightLoadID", importText},
+    {"externalTMSLoadID", importText},
+    {"status.code.key", importText},
+    {"status.notes", importText},
+    {"customer.name", importText},
+    {"customer.accountNumber", importText},
+    {"customer.address.street", importText},
+    {"customer.address.city", importText},
+    {"customer.address.state", importText},
+    {"customer.address.zipCode", importText},
+    {"customer.contact.name", importText},
+    {"customer.contact.email", importText},
+    {"customer.contact.phone", importText},
+    {"billTo.name", importText},
+    {"billTo.accountNumber", importText},
+    {"pickup.facilityName", importText},
+    {"pickup.scheduledTime", importTime},
+    {"pickup.address.street", importText},
+    {"pickup.address.city", importText},
+    {"pickup.address.state", importText},
+    {"pickup.address.zipCode", importText},
+    {"pickup.contact.name", importText},
+    {"pickup.contact.phone", importText},
+    {"consignee.facilityName", importText},
+    {"consignee.scheduledTime", importTime},
+    {"consignee.address.street", importText},
+    {"consignee.address.city", importText},
+    {"consignee.address.state", importText},
+    {"consignee.address.zipCode", importText},
+    {"consignee.contact.name", importText},
+    {"consignee.contact.phone", importText},
+    {"carrier.name", importText},
+    {"carrier.scac", importText},
+    {"carrier.equipment.type", importText},
+    {"carrier.equipment.length", importText},
+    {"rateData.baseRate", importNumber},
+    {"rateData.fuelSurcharge", importNumber},
+    {"rateData.totalRate", importNumber},
+    {"rateData.currency", importText},
+    {"rateData.carrierRate.baseRate", importNumber},
+    {"rateData.carrierRate.fuelSurcharge", importNumber},
+    {"rateData.carrierRate.totalRate", importNumber},
+    {"specifications.serviceLevel", importText},
+    {"specifications.specialInstructions", importText},
+    {"specifications.temperature.min", importNumber},
+    {"specifications.temperature.max", importNumber},
+    {"specifications.temperature.unit", importText},
+    {"inPalletCount", importInteger},
+    {"outPalletCount", importInteger},
+    {"numCommodities", importInteger},
+    {"totalWeight", importNumber},
+    {"billableWeight", importNumber},
+    {"poNums", importText},
+    {"operator", importText},
+    {"routeMiles", importNumber},
+}
+
+// importTemplate is the header row of a file every column is read from.
+func importTemplate() []string {
+    headers := make([]string, len(importColumns))
+    for i, column := range importColumns {
+        headers[i] = column.path
+    }
+    return headers
+}
+
+// parseImportRows reads a spreadsheet's rows, after its header row, as
+// create requests checked as CreateLoad checks a request body. mapping
+// names the column to read a field from by its header, for headers that
+// differ from the field's path. Blank rows are skipped.
+func parseImportRows(table [][]string, mapping map[string]string) ([]dto.ImportRow, error) {
+    if len(table) == 0 {
+        return nil, apperrors.Validation("Invalid import file",
+            apperrors.FieldError{Field: "file", Message: "is empty"})
+    }
+
+    columns, err := importColumnIndexes(table[0], mapping)
+    if err != nil {
+        return nil, err
+    }
+
+    var rows []dto.ImportRow
+    for i, values := range table[1:] {
+        if isBlankRow(values) {
+            continue
+        }
+        if len(rows) == maxImportRows {
+            return nil, apperrors.Validation("Invalid import file",
+                apperrors.FieldError{Field: "file", Message: fmt.Sprintf("must have at most %d rows", maxImportRows)})
+        }
+        rows = append(rows, parseImportRow(i+2, values, columns))
+    }
+    if len(rows) == 0 {
+        return nil, apperrors.Validation("Invalid import file",
+            apperrors.FieldError{Field: "file", Message: "has no rows"})
+    }
+    return rows, nil
+}
+
+// importColumnIndexes finds the column each field is read from.
+func importColumnIndexes(header []string, mapping map[string]string) (map[int]importColumn, error) {
+    byHeader := make(map[string]int, len(header))
+    for i, name := range header {
+        name = strings.ToLower(strings.TrimSpace(name))
+        if _, ok := byHeader[name]; !ok && name != "" {
+            byHeader[name] = i
+        }
+    }
+
+    known := make(map[string]bool, len(importColumns))
+    for _, column := range importColumns {
+        known[column.path] = true
+    }
+    var fields []apperrors.FieldError
+    for path, name := range mapping {
+        if !known[path] {
+            fields = append(fields, apperrors.FieldError{Field: "mapping." + path, Message: "is not a field that can be imported"})
+        } else if _, ok := byHeader[strings.ToLower(strings.TrimSpace(name))]; !ok {
+            fields = append(fields, apperrors.FieldError{Field: "mapping." + path, Message: fmt.Sprintf("column %q is not in the file", name)})
+        }
+    }
+    if len(fields) > 0 {
+        return nil, apperrors.Validation("Invalid column mapping", fields...)
+    }
+
+    columns := make(map[int]importColumn)
+    for _, column := range importColumns {
+        name := column.path
+        if mapped, ok := mapping[column.path]; ok {
+            name = mapped
+        }
+        if i, ok := byHeader[strings.ToLower(strings.TrimSpace(name))]; ok {
+            columns[i] = column
+        }
+    }
+    if len(columns) == 0 {
+        return nil, apperrors.Validation("Invalid import file",
+            apperrors.FieldError{Field: "file", Message: "no column header matches a load field; see the import template"})
+    }
+    return columns, nil
+}
+
+func isBlankRow(values []string) bool {
+    for _, value := range values {
+        if strings.TrimSpace(value) != "" {
+            return false
+        }
+    }
+    return true
+}
+
+// parseImportRow builds the create request body a row describes, so that it
+// is decoded and validated exactly as a posted one would be.
+func parseImportRow(line int, values []string, columns map[int]importColumn) dto.ImportRow {
+    row := dto.ImportRow{Line: line}
+    body := make(map[string]interface{})
+    for i, raw := range values {
+        column, ok := columns[i]
+        raw = strings.TrimSpace(raw)
+        if !ok || raw == "" {
+            continue
+        }
+        value, err := importValue(column.kind, raw)
+        if err != nil {
+            row.Errors = append(row.Errors, apperrors.FieldError{Field: column.path, Message: err.Error()})
+            continue
+        }
+        setImportValue(body, column.path, value)
+    }
+    if len(row.Errors) > 0 {
+        return row
+    }
+
+    raw, err := json.Marshal(body)
+    if err == nil {
+        row.Request = &dto.CreateLoadRequest{}
+        err = json.Unmarshal(raw, row.Request)
+    }
+    if err == nil {
+        err = binding.Validator.ValidateStruct(row.Request)
+    }
+    if err != nil {
+        row.Errors = bindingFieldErrors(err)
+        if len(row.Errors) == 0 {
+            row.Errors = []apperrors.FieldError{{Field: "row", Message: err.Error()}}
+        }
+    }
+    return row
+}
+
+func importValue(kind importKind, raw string) (interface{}, error) {
+    switch kind {
+    case importNumber:
+        value, err := strconv.ParseFloat(raw, 64)
+        if err != nil || math.IsInf(value, 0) || math.IsNaN(value) {
+            return nil, fmt.Errorf("must be a number")
+        }
+        return value, nil
+    case importInteger:
+        value, err := strconv.Atoi(raw)
+        if err != nil {
+            return nil, fmt.Errorf("must be a whole number")
+        }
+        return value, nil
+    case importTime:
+        if serial, err := strconv.ParseFloat(raw, 64); err == nil {
+            return excelTime(serial).Format(time.RFC3339), nil
+        }
+    }
+    return raw, nil
+}
+
+// excelTime converts an Excel date serial number, days since the end of
+// 1899, to a time. Excel dates have no time zone; they are taken as UTC.
+func excelTime(serial float64) time.Time {
+    epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+    return epoch.Add(time.Duration(math.Round(serial * 24 * 60 * 60)) * time.Second)
+}
+
+// setImportValue sets the value at a dot path in a JSON body, adding the
+// objects on the way.
+func setImportValue(body map[string]interface{}, path string, value interface{}) {
+    parts := strings.Split(path, ".")
+    for _, part := range parts[:len(parts)-1] {
+        next, ok := body[part].(map[string]interface{})
+        if !ok {
+            next = make(map[string]interface{})
+            body[part] = next
+        }
+        body = next
+    }
+    body[parts[len(parts)-1]] = value
+}
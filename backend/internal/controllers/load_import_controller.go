@@ -0,0 +1,204 @@
+package controllers
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/spreadsheet"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+// maxImportFileSize is the largest spreadsheet an import accepts.
+const maxImportFileSize = 10 << 20
+
+// LoadImportController creates loads from CSV and XLSX spreadsheets.
+type LoadImportController struct {
+    importService interfaces.LoadImportService
+}
+
+func NewLoadImportController(importService interfaces.LoadImportService) *LoadImportController {
+    return &LoadImportController{
+        importService: importService,
+    }
+}
+
+// ImportLoads reads the uploaded spreadsheet in the multipart field "file".
+// With dryRun=true it only reports the rows that would fail; otherwise it
+// queues the rows to be created in the background and returns the import.
+func (c *LoadImportController) ImportLoads(ctx *gin.Context) {
+    dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dryRun", "false"))
+    if err != nil {
+        ctx.Error(apperrors.Validation("Invalid dryRun parameter",
+            apperrors.FieldError{Field: "dryRun", Message: "must be true or false"}))
+        return
+    }
+
+    ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize+1<<20)
+    file, header, err := ctx.Request.FormFile("file")
+    if err != nil {
+        message := "is required"
+        if strings.Contains(err.Error(), "too large") {
+            message = fmt.Sprintf("must be at most %d MB", maxImportFileSize>>20)
+        }
+        ctx.Error(apperrors.Validation("Invalid import file", apperrors.FieldError{Field: "file", Message: message}))
+        return
+    }
+    defer file.Close()
+    if header.Size > maxImportFileSize {
+        ctx.Error(apperrors.Validation("Invalid import file",
+            apperrors.FieldError{Field: "file", Message: fmt.Sprintf("must be at most %d MB", maxImportFileSize>>20)}))
+        return
+    }
+
+    formatName := ctx.PostForm("format")
+    if formatName == "" {
+        formatName = header.Filename
+    }
+    format, err := spreadsheet.ParseFormat(formatName)
+    if err != nil {
+        ctx.Error(apperrors.Validation("Invalid import file", apperrors.FieldError{Field: "format", Message: err.Error()}))
+        return
+    }
+
+    var mapping map[string]string
+    if raw := ctx.PostForm("mapping"); raw != "" {
+        if err := json.Unmarshal([]byte(raw), &mapping); err != nil {
+            ctx.Error(apperrors.Validation("Invalid column mapping",
+                apperrors.FieldError{Field: "mapping", Message: "must be a JSON object of field paths to column headers"}))
+            return
+        }
+    }
+
+    table, err := spreadsheet.Read(file, format)
+    if err != nil {
+        ctx.Error(apperrors.Validation("Invalid import file", apperrors.FieldError{Field: "file", Message: err.Error()}))
+        return
+    }
+    rows, err := parseImportRows(table, mapping)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    if dryRun {
+        report, err := c.importService.ValidateImport(ctx, rows)
+        if err != nil {
+            ctx.Error(err)
+            return
+        }
+        ctx.JSON(http.StatusOK, report)
+        return
+    }
+
+    job, err := c.importService.StartImport(ctx, header.Filename, rows)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+    ctx.Header("Location", "/api/loads/import/"+job.ID)
+    ctx.JSON(http.StatusAccepted, job)
+}
+
+// GetTemplate returns a spreadsheet with the header of every column an
+// import reads.
+func (c *LoadImportController) GetTemplate(ctx *gin.Context) {
+    format, ok := parseSpreadsheetFormat(ctx)
+    if !ok {
+        return
+    }
+
+    writeSpreadsheet(ctx, "load-import-template", format, func(w spreadsheet.Writer) error {
+        return w.WriteRow(importTemplate())
+    })
+}
+
+func (c *LoadImportController) GetImport(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid import ID format"))
+        return
+    }
+
+    job, err := c.importService.GetImport(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+    ctx.JSON(http.StatusOK, job)
+}
+
+// GetResult returns a spreadsheet of each row's outcome: the load it
+// created, or why it failed.
+func (c *LoadImportController) GetResult(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid import ID format"))
+        return
+    }
+    format, ok := parseSpreadsheetFormat(ctx)
+    if !ok {
+        return
+    }
+    if _, err := c.importService.GetImport(ctx, id); err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    writeSpreadsheet(ctx, "load-import-"+id, format, func(w spreadsheet.Writer) error {
+        if err := w.WriteRow([]string{"row", "freightLoadID", "status", "loadId", "errors"}); err != nil {
+            return err
+        }
+        return c.importService.EachImportRow(ctx, id, func(result dto.ImportRowResult) error {
+            errors := make([]string, len(result.Errors))
+            for i, fieldErr := range result.Errors {
+                errors[i] = fieldErr.Field + ": " + fieldErr.Message
+            }
+            return w.WriteRow([]string{
+                strconv.Itoa(result.Row), result.FreightLoadID, result.Status, result.LoadID, strings.Join(errors, "; "),
+            })
+        })
+    })
+}
+
+// parseSpreadsheetFormat reads the format parameter, csv by default.
+func parseSpreadsheetFormat(ctx *gin.Context) (spreadsheet.Format, bool) {
+    format, err := spreadsheet.ParseFormat(ctx.DefaultQuery("format", string(spreadsheet.FormatCSV)))
+    if err != nil {
+        ctx.Error(apperrors.Validation("Invalid format parameter",
+            apperrors.FieldError{Field: "format", Message: "must be csv or xlsx"}))
+        return "", false
+    }
+    return format, true
+}
+
+// writeSpreadsheet streams a file download written by write. A failure
+// after the download has started can only cut it short, so it is logged.
+func writeSpreadsheet(ctx *gin.Context, name string, format spreadsheet.Format, write func(spreadsheet.Writer) error) {
+    ctx.Header("Content-Type", format.ContentType())
+    ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+    ctx.Status(http.StatusOK)
+
+    w := spreadsheet.NewWriter(ctx.Writer, format)
+    err := write(w)
+    if err != nil && !ctx.Writer.Written() {
+        ctx.Writer.Header().Del("Content-Type")
+        ctx.Writer.Header().Del("Content-Disposition")
+        ctx.Error(err)
+        return
+    }
+    if err == nil {
+        err = w.Close()
+    }
+    if err != nil {
+        log.Printf("Failed to write %s.%s: %v", name, format, err)
+    }
+}
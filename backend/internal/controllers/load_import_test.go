@@ -0,0 +1,91 @@
+package controllers
+
+import (
+    "bytes"
+    "encoding/json"
+    "mime/multipart"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/middleware"
+
+    "github.com/gin-gonic/gin"
+)
+
+func TestParseImportRows(t *testing.T) {
+    table := [][]string{
+        {"FreightLoadID", "Customer", "pickup.scheduledTime", "Pickup City", "consignee.scheduledTime", "rateData.totalRate", "Ignored"},
+        {"FL-1", "Acme", "2026-03-01T15:00:00Z", "Chicago", "46084.5", "1250.50", "x"},
+        {"", " ", "", "", "", "", ""},
+        {"FL-2", "", "next tuesday", "", "2026-03-03T15:00:00Z", "lots", ""},
+        {"FL-3", "Globex", "2026-03-01T15:00:00Z", "", "", "", ""},
+    }
+    mapping := map[string]string{"customer.name": "customer", "pickup.address.city": "pickup city"}
+
+    rows, err := parseImportRows(table, mapping)
+    if err != nil {
+        t.Fatalf("failed to parse rows: %v", err)
+    }
+    if len(rows) != 3 {
+        t.Fatalf("expected the blank row to be skipped, got %d rows", len(rows))
+    }
+
+    first := rows[0]
+    if first.Line != 2 || len(first.Errors) != 0 {
+        t.Fatalf("expected row 2 to be valid, got %+v", first)
+    }
+    req := first.Request
+    if req.FreightLoadID != "FL-1" || req.Customer.Name != "Acme" || req.Pickup.Address.City != "Chicago" || req.RateData.TotalRate != 1250.5 {
+        t.Errorf("unexpected request %+v", req)
+    }
+    if req.Consignee.ScheduledTime != "2026-03-03T12:00:00Z" {
+        t.Errorf("expected the Excel date to be converted, got %q", req.Consignee.ScheduledTime)
+    }
+
+    if rows[1].Line != 4 || !hasField(rows[1].Errors, "rateData.totalRate") {
+        t.Errorf("expected row 4 to fail on its rate, got %+v", rows[1])
+    }
+    if rows[2].Line != 5 || !hasField(rows[2].Errors, "consignee") {
+        t.Errorf("expected row 5 to fail binding on its consignee, got %+v", rows[2])
+    }
+}
+
+func TestParseImportRowsRefusesBadMappings(t *testing.T) {
+    table := [][]string{{"freightLoadID"}, {"FL-1"}}
+
+    _, err := parseImportRows(table, map[string]string{"customer.name": "Client", "id": "freightLoadID"})
+    appErr, ok := apperrors.As(err)
+    if !ok || !hasField(appErr.Fields, "mapping.customer.name") || !hasField(appErr.Fields, "mapping.id") {
+        t.Errorf("expected the missing column and unknown field to be reported, got %v", err)
+    }
+
+    if _, err := parseImportRows([][]string{{"Order", "Client"}, {"FL-1", "Acme"}}, nil); !apperrors.Is(err, apperrors.CodeValidation) {
+        t.Errorf("expected a file without known columns to be refused, got %v", err)
+    }
+}
+
+func TestImportLoadsRefusesUnknownFormats(t *testing.T) {
+    var body bytes.Buffer
+    form := multipart.NewWriter(&body)
+    file, _ := form.CreateFormFile("file", "loads.xls")
+    file.Write([]byte("not a workbook"))
+    form.Close()
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(middleware.ErrorHandler())
+    router.POST("/api/loads/import", NewLoadImportController(nil).ImportLoads)
+
+    req := httptest.NewRequest("POST", "/api/loads/import", &body)
+    req.Header.Set("Content-Type", form.FormDataContentType())
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, req)
+
+    var resp middleware.ErrorResponse
+    json.Unmarshal(rec.Body.Bytes(), &resp)
+    if rec.Code != http.StatusBadRequest || !hasField(resp.Fields, "format") {
+        t.Errorf("expected 400 on the format, got %d %+v", rec.Code, resp)
+    }
+}
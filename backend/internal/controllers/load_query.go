@@ -0,0 +1,81 @@
+package controllers
+
+import (
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+
+    "github.com/gin-gonic/gin"
+)
+
+// parseListLoadsQuery reads the filter, search, sort and pagination mode
+// parameters of GET /api/loads. Sort fields, statuses and cursors are checked
+// by the service.
+func parseListLoadsQuery(ctx *gin.Context) (*dto.ListLoadsQuery, error) {
+    query := &dto.ListLoadsQuery{
+        Customer:         strings.TrimSpace(ctx.Query("customer")),
+        Carrier:          strings.TrimSpace(ctx.Query("carrier")),
+        Operator:         strings.TrimSpace(ctx.Query("operator")),
+        OriginState:      strings.TrimSpace(ctx.Query("originState")),
+        DestinationState: strings.TrimSpace(ctx.Query("destinationState")),
+        PONumber:         strings.TrimSpace(ctx.Query("po")),
+        Search:           strings.TrimSpace(ctx.Query("q")),
+        Sort:             strings.TrimSpace(ctx.Query("sort")),
+    }
+
+    // status may be repeated or comma separated.
+    for _, value := range ctx.QueryArray("status") {
+        for _, status := range strings.Split(value, ",") {
+            if status = strings.TrimSpace(status); status != "" {
+                query.Status = append(query.Status, status)
+            }
+        }
+    }
+
+    var fields []apperrors.FieldError
+
+    // A cursor parameter, even an empty one, selects cursor mode.
+    query.Cursor, query.UseCursor = ctx.GetQuery("cursor")
+    query.Cursor = strings.TrimSpace(query.Cursor)
+
+    switch query.TotalMode = ctx.Query("total"); query.TotalMode {
+    case "", dto.TotalExact, dto.TotalEstimate, dto.TotalNone:
+    default:
+        fields = append(fields, apperrors.FieldError{Field: "total", Message: "must be one of exact, estimate, none"})
+    }
+    dateParam := func(name string, endOfRange bool) *time.Time {
+        value := strings.TrimSpace(ctx.Query(name))
+        if value == "" {
+            return nil
+        }
+        t, err := parseDateParam(value, endOfRange)
+        if err != nil {
+            fields = append(fields, apperrors.FieldError{Field: name, Message: "must be a date (YYYY-MM-DD) or an RFC3339 timestamp"})
+            return nil
+        }
+        return &t
+    }
+    query.PickupFrom = dateParam("pickupFrom", false)
+    query.PickupTo = dateParam("pickupTo", true)
+    query.DeliveryFrom = dateParam("deliveryFrom", false)
+    query.DeliveryTo = dateParam("deliveryTo", true)
+
+    if len(fields) > 0 {
+        return nil, apperrors.Validation("Invalid query parameters", fields...)
+    }
+    return query, nil
+}
+
+// parseDateParam parses a date range bound. Ranges are half-open, so a bare
+// date ending a range covers that whole day.
+func parseDateParam(value string, endOfRange bool) (time.Time, error) {
+    if t, err := time.Parse("2006-01-02", value); err == nil {
+        if endOfRange {
+            t = t.AddDate(0, 0, 1)
+        }
+        return t, nil
+    }
+    return time.Parse(time.RFC3339, value)
+}
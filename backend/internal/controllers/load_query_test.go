@@ -0,0 +1,43 @@
+package controllers
+
+import (
+    "net/http/httptest"
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+
+    "github.com/gin-gonic/gin"
+)
+
+func queryContext(target string) *gin.Context {
+    ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+    ctx.Request = httptest.NewRequest("GET", target, nil)
+    return ctx
+}
+
+func TestParseListLoadsQuery(t *testing.T) {
+    query, err := parseListLoadsQuery(queryContext(
+        "/api/loads?status=covered,dispatched&status=in_transit&customer=%20Acme%20&pickupFrom=2026-03-01&pickupTo=2026-03-01&sort=-pickupAt"))
+    if err != nil {
+        t.Fatalf("parseListLoadsQuery: %v", err)
+    }
+    if len(query.Status) != 3 || query.Status[2] != "in_transit" {
+        t.Errorf("expected three statuses, got %v", query.Status)
+    }
+    if query.Customer != "Acme" || query.Sort != "-pickupAt" {
+        t.Errorf("unexpected query %+v", query)
+    }
+    day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+    if !query.PickupFrom.Equal(day) || !query.PickupTo.Equal(day.AddDate(0, 0, 1)) {
+        t.Errorf("expected pickup range to cover 2026-03-01, got %v to %v", query.PickupFrom, query.PickupTo)
+    }
+}
+
+func TestParseListLoadsQueryRejectsBadDates(t *testing.T) {
+    _, err := parseListLoadsQuery(queryContext("/api/loads?deliveryFrom=soon&deliveryTo=2026-13-01"))
+    appErr, ok := apperrors.As(err)
+    if !ok || appErr.Code != apperrors.CodeValidation || len(appErr.Fields) != 2 {
+        t.Fatalf("expected two field errors, got %v", err)
+    }
+}
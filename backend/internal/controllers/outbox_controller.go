@@ -0,0 +1,74 @@
+package controllers
+
+import (
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+type OutboxController struct {
+    outboxService interfaces.OutboxService
+}
+
+func NewOutboxController(outboxService interfaces.OutboxService) *OutboxController {
+    return &OutboxController{
+        outboxService: outboxService,
+    }
+}
+
+// ListMessages lists outbox deliveries, dead-lettered ones by default.
+func (c *OutboxController) ListMessages(ctx *gin.Context) {
+    status := ctx.DefaultQuery("status", models.OutboxDead)
+    if status == "all" {
+        status = ""
+    } else if status != models.OutboxPending && status != models.OutboxDelivered && status != models.OutboxDead {
+        ctx.Error(apperrors.Validation("Invalid status parameter",
+            apperrors.FieldError{Field: "status", Message: "must be one of pending, delivered, dead or all"}))
+        return
+    }
+
+    page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+    if err != nil || page < 1 {
+        ctx.Error(invalidPageError())
+        return
+    }
+
+    pageSize, err := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+    if err != nil || pageSize < 1 || pageSize > 100 {
+        ctx.Error(invalidPageSizeError())
+        return
+    }
+
+    resp, err := c.outboxService.ListMessages(ctx, status, page, pageSize)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    resp.Page = page
+    resp.Size = pageSize
+
+    ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *OutboxController) ReplayMessage(ctx *gin.Context) {
+    id := ctx.Param("id")
+
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid message ID format"))
+        return
+    }
+
+    resp, err := c.outboxService.ReplayMessage(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, resp)
+}
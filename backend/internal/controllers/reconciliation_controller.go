@@ -0,0 +1,52 @@
+package controllers
+
+import (
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+type ReconciliationController struct {
+    reconciliationService interfaces.ReconciliationService
+}
+
+func NewReconciliationController(reconciliationService interfaces.ReconciliationService) *ReconciliationController {
+    return &ReconciliationController{
+        reconciliationService: reconciliationService,
+    }
+}
+
+// GetReport compares local loads with TMS shipments without changing either.
+func (c *ReconciliationController) GetReport(ctx *gin.Context) {
+    c.reconcile(ctx, &dto.ReconciliationRequest{})
+}
+
+// Repair reconciles and then repairs in the requested direction.
+func (c *ReconciliationController) Repair(ctx *gin.Context) {
+    var req dto.ReconciliationRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    if req.Repair != dto.ReconcileRepairLocal && req.Repair != dto.ReconcileRepairTMS {
+        ctx.Error(apperrors.Validation("Invalid repair direction",
+            apperrors.FieldError{Field: "repair", Message: "must be either local or tms"}))
+        return
+    }
+
+    c.reconcile(ctx, &req)
+}
+
+func (c *ReconciliationController) reconcile(ctx *gin.Context, req *dto.ReconciliationRequest) {
+    report, err := c.reconciliationService.Reconcile(ctx, req)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+// TenantController serves the platform admin endpoints that manage tenants.
+type TenantController struct {
+    tenantService interfaces.TenantService
+}
+
+func NewTenantController(tenantService interfaces.TenantService) *TenantController {
+    return &TenantController{
+        tenantService: tenantService,
+    }
+}
+
+func (c *TenantController) CreateTenant(ctx *gin.Context) {
+    var req dto.CreateTenantRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    tenant, err := c.tenantService.CreateTenant(ctx, &req)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusCreated, tenant)
+}
+
+func (c *TenantController) ListTenants(ctx *gin.Context) {
+    resp, err := c.tenantService.ListTenants(ctx)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *TenantController) GetTenant(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid tenant ID format"))
+        return
+    }
+
+    tenant, err := c.tenantService.GetTenant(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, tenant)
+}
+
+func (c *TenantController) UpdateTenant(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid tenant ID format"))
+        return
+    }
+
+    var req dto.UpdateTenantRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    tenant, err := c.tenantService.UpdateTenant(ctx, id, &req)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, tenant)
+}
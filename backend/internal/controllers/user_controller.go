@@ -0,0 +1,133 @@
+package controllers
+
+import (
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+// UserController serves the admin endpoints that manage user accounts.
+type UserController struct {
+    userService interfaces.UserService
+}
+
+func NewUserController(userService interfaces.UserService) *UserController {
+    return &UserController{
+        userService: userService,
+    }
+}
+
+func (c *UserController) CreateUser(ctx *gin.Context) {
+    var req dto.CreateUserRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    user, err := c.userService.CreateUser(ctx, &req)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusCreated, user)
+}
+
+func (c *UserController) ListUsers(ctx *gin.Context) {
+    page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+    if err != nil || page < 1 {
+        ctx.Error(invalidPageError())
+        return
+    }
+
+    pageSize, err := strconv.Atoi(ctx.DefaultQuery("size", "10"))
+    if err != nil || pageSize < 1 || pageSize > 100 {
+        ctx.Error(invalidPageSizeError())
+        return
+    }
+
+    resp, err := c.userService.ListUsers(ctx, page, pageSize)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    resp.Page = page
+    resp.Size = pageSize
+
+    ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *UserController) GetUser(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid user ID format"))
+        return
+    }
+
+    user, err := c.userService.GetUser(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, user)
+}
+
+func (c *UserController) UpdateUser(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid user ID format"))
+        return
+    }
+
+    var req dto.UpdateUserRequest
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.Error(invalidBodyError(err))
+        return
+    }
+
+    user, err := c.userService.UpdateUser(ctx, id, &req)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusOK, user)
+}
+
+func (c *UserController) DeleteUser(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid user ID format"))
+        return
+    }
+
+    if err := c.userService.DeleteUser(ctx, id); err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.Status(http.StatusNoContent)
+}
+
+// CreateResetToken issues a one-time password reset token for the user.
+func (c *UserController) CreateResetToken(ctx *gin.Context) {
+    id := ctx.Param("id")
+    if _, err := uuid.Parse(id); err != nil {
+        ctx.Error(invalidIDError("Invalid user ID format"))
+        return
+    }
+
+    token, err := c.userService.CreateResetToken(ctx, id)
+    if err != nil {
+        ctx.Error(err)
+        return
+    }
+
+    ctx.JSON(http.StatusCreated, token)
+}
@@ -0,0 +1,377 @@
+package controllers_test
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/controllers"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/middleware"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/services"
+
+    "github.com/gin-gonic/gin"
+    "github.com/jinzhu/gorm"
+    "golang.org/x/crypto/bcrypt"
+)
+
+type userAPI struct {
+    t           *testing.T
+    router      *gin.Engine
+    authService *services.AuthService
+}
+
+func newUserAPI(t *testing.T) *userAPI {
+    t.Helper()
+
+    dbURL := os.Getenv("TEST_DATABASE_URL")
+    if dbURL == "" {
+        t.Skip("TEST_DATABASE_URL is not set")
+    }
+
+    db, err := gorm.Open("postgres", dbURL)
+    if err != nil {
+        t.Fatalf("failed to connect to test database: %v", err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    migrateTestDatabase(t, db)
+    if err := db.Exec("TRUNCATE tenants, users, password_reset_tokens, refresh_tokens, revoked_tokens, api_keys").Error; err != nil {
+        t.Fatalf("failed to reset test database: %v", err)
+    }
+
+    userService := services.NewUserService(db, services.UserServiceConfig{
+        MaxFailedLogins: 3,
+        LockoutDuration: time.Minute,
+        BcryptCost:      bcrypt.MinCost,
+    })
+    tenantService := services.NewTenantService(db, userService)
+    defaultTenant, err := tenantService.EnsureDefaultTenant(context.Background())
+    if err != nil {
+        t.Fatalf("failed to create default tenant: %v", err)
+    }
+    if _, err := userService.EnsureAdmin(context.Background(), defaultTenant.ID, "admin", "admin-password"); err != nil {
+        t.Fatalf("failed to create admin: %v", err)
+    }
+
+    authService, err := services.NewAuthService(db, services.AuthServiceConfig{
+        Keys: []services.SigningKey{services.NewHMACKey("test", "test-secret")},
+    })
+    if err != nil {
+        t.Fatalf("failed to create auth service: %v", err)
+    }
+    authController := controllers.NewAuthController(authService, userService)
+    userController := controllers.NewUserController(userService)
+    tenantController := controllers.NewTenantController(tenantService)
+    apiKeyController := controllers.NewAPIKeyController(services.NewAPIKeyService(db, services.APIKeyServiceConfig{}))
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.ContextWithFallback = true
+    router.Use(middleware.ErrorHandler())
+    api := router.Group("/api")
+    api.POST("/auth/login", authController.Login)
+    api.POST("/auth/password-reset", authController.ResetPassword)
+    api.POST("/auth/refresh", authController.Refresh)
+    protected := api.Group("")
+    protected.Use(middleware.AuthMiddleware(authService, services.NewAPIKeyService(db, services.APIKeyServiceConfig{})))
+    protected.POST("/auth/password", middleware.RequireUser(), authController.ChangePassword)
+    protected.POST("/auth/logout", middleware.RequireUser(), authController.Logout)
+    protected.GET("/whoami", middleware.RequirePermission(models.PermLoadsRead), func(c *gin.Context) {
+        c.String(http.StatusOK, c.GetString("tenantID"))
+    })
+    users := protected.Group("/admin/users")
+    users.Use(middleware.RequirePermission(models.PermUsersManage))
+    {
+        users.POST("", userController.CreateUser)
+        users.GET("", userController.ListUsers)
+        users.GET("/:id", userController.GetUser)
+        users.PATCH("/:id", userController.UpdateUser)
+        users.DELETE("/:id", userController.DeleteUser)
+        users.POST("/:id/password-reset", userController.CreateResetToken)
+    }
+    apiKeys := protected.Group("/admin/api-keys")
+    apiKeys.Use(middleware.RequirePermission(models.PermAPIKeysManage))
+    {
+        apiKeys.POST("", apiKeyController.CreateAPIKey)
+        apiKeys.GET("", apiKeyController.ListAPIKeys)
+        apiKeys.POST("/:id/rotate", apiKeyController.RotateAPIKey)
+        apiKeys.DELETE("/:id", apiKeyController.RevokeAPIKey)
+    }
+    tenants := protected.Group("/admin/tenants")
+    tenants.Use(middleware.RequirePermission(models.PermTenantsManage), middleware.RequireTenant(defaultTenant.ID.String()))
+    {
+        tenants.POST("", tenantController.CreateTenant)
+        tenants.GET("", tenantController.ListTenants)
+        tenants.PATCH("/:id", tenantController.UpdateTenant)
+    }
+
+    return &userAPI{t: t, router: router, authService: authService}
+}
+
+func (a *userAPI) do(token, method, path string, body interface{}, out interface{}) int {
+    a.t.Helper()
+    if token == "" {
+        return a.doWithHeader("", "", method, path, body, out)
+    }
+    return a.doWithHeader("Authorization", "Bearer "+token, method, path, body, out)
+}
+
+func (a *userAPI) doWithHeader(header, value, method, path string, body interface{}, out interface{}) int {
+    a.t.Helper()
+
+    raw, err := json.Marshal(body)
+    if err != nil {
+        a.t.Fatalf("failed to marshal request: %v", err)
+    }
+    req := httptest.NewRequest(method, path, bytes.NewReader(raw))
+    req.Header.Set("Content-Type", "application/json")
+    if header != "" {
+        req.Header.Set(header, value)
+    }
+    rec := httptest.NewRecorder()
+    a.router.ServeHTTP(rec, req)
+
+    if out != nil && rec.Body.Len() > 0 {
+        if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+            a.t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+        }
+    }
+    return rec.Code
+}
+
+func (a *userAPI) login(username, password string) (string, int) {
+    a.t.Helper()
+
+    tokens, status := a.loginTokens(username, password)
+    return tokens.Token, status
+}
+
+func (a *userAPI) loginTokens(username, password string) (dto.TokenResponse, int) {
+    a.t.Helper()
+
+    var resp dto.TokenResponse
+    status := a.do("", "POST", "/api/auth/login", map[string]string{"username": username, "password": password}, &resp)
+    return resp, status
+}
+
+func TestUserLoginLockoutAndReset(t *testing.T) {
+    api := newUserAPI(t)
+
+    adminToken, status := api.login("Admin", "admin-password")
+    if status != http.StatusOK {
+        t.Fatalf("expected the admin to sign in, got %d", status)
+    }
+
+    var user dto.UserResponse
+    status = api.do(adminToken, "POST", "/api/admin/users", dto.CreateUserRequest{
+        Username: "broker1", Email: "broker1@example.com", Password: "broker-password", Role: models.RoleBroker,
+    }, &user)
+    if status != http.StatusCreated {
+        t.Fatalf("expected the user to be created, got %d", status)
+    }
+    if status := api.do(adminToken, "POST", "/api/admin/users", dto.CreateUserRequest{
+        Username: "BROKER1", Password: "broker-password", Role: models.RoleBroker,
+    }, nil); status != http.StatusConflict {
+        t.Errorf("expected a duplicate username to conflict, got %d", status)
+    }
+
+    brokerToken, status := api.login("broker1", "broker-password")
+    if status != http.StatusOK {
+        t.Fatalf("expected the broker to sign in, got %d", status)
+    }
+    claims, err := api.authService.ValidateToken(brokerToken)
+    if err != nil || claims.UserID != user.ID || claims.Role != models.RoleBroker {
+        t.Errorf("expected the token to carry the user's ID and role, got %+v (%v)", claims, err)
+    }
+    if status := api.do(brokerToken, "GET", "/api/admin/users", nil, nil); status != http.StatusForbidden {
+        t.Errorf("expected a broker to be refused user management, got %d", status)
+    }
+
+    for i := 0; i < 3; i++ {
+        if _, status := api.login("broker1", "wrong-password"); status != http.StatusUnauthorized {
+            t.Fatalf("expected a bad password to be refused, got %d", status)
+        }
+    }
+    // A locked account is refused like an unknown one, right password or not.
+    if _, status := api.login("broker1", "broker-password"); status != http.StatusUnauthorized {
+        t.Fatalf("expected the locked account to be refused as invalid credentials, got %d", status)
+    }
+
+    var reset dto.PasswordResetTokenResponse
+    if status := api.do(adminToken, "POST", "/api/admin/users/"+user.ID+"/password-reset", nil, &reset); status != http.StatusCreated {
+        t.Fatalf("expected a reset token, got %d", status)
+    }
+    resetReq := dto.ResetPasswordRequest{Token: reset.Token, NewPassword: "new-broker-password"}
+    if status := api.do("", "POST", "/api/auth/password-reset", resetReq, nil); status != http.StatusNoContent {
+        t.Fatalf("expected the password to be reset, got %d", status)
+    }
+    if status := api.do("", "POST", "/api/auth/password-reset", resetReq, nil); status != http.StatusBadRequest {
+        t.Errorf("expected a used reset token to be refused, got %d", status)
+    }
+
+    brokerToken, status = api.login("broker1", "new-broker-password")
+    if status != http.StatusOK {
+        t.Fatalf("expected the reset to lift the lockout, got %d", status)
+    }
+    if status := api.do(brokerToken, "POST", "/api/auth/password", dto.ChangePasswordRequest{
+        CurrentPassword: "wrong-password", NewPassword: "another-password",
+    }, nil); status != http.StatusBadRequest {
+        t.Errorf("expected a wrong current password to be refused, got %d", status)
+    }
+
+    var admin dto.ListUsersResponse
+    api.do(adminToken, "GET", "/api/admin/users", nil, &admin)
+    if admin.Total != 2 {
+        t.Fatalf("expected 2 users, got %+v", admin)
+    }
+    for _, u := range admin.Users {
+        if u.Role == models.RoleAdmin {
+            if status := api.do(adminToken, "DELETE", "/api/admin/users/"+u.ID, nil, nil); status != http.StatusConflict {
+                t.Errorf("expected deleting the last admin to conflict, got %d", status)
+            }
+        }
+    }
+}
+
+func TestRefreshRotationAndLogout(t *testing.T) {
+    api := newUserAPI(t)
+
+    first, status := api.loginTokens("admin", "admin-password")
+    if status != http.StatusOK || first.RefreshToken == "" {
+        t.Fatalf("expected access and refresh tokens, got %d %+v", status, first)
+    }
+
+    var second dto.TokenResponse
+    if status := api.do("", "POST", "/api/auth/refresh", dto.RefreshRequest{RefreshToken: first.RefreshToken}, &second); status != http.StatusOK {
+        t.Fatalf("expected the refresh to succeed, got %d", status)
+    }
+    if second.RefreshToken == first.RefreshToken {
+        t.Fatal("expected the refresh token to be rotated")
+    }
+
+    // Reusing a rotated token revokes the whole family, including the
+    // token it was replaced with.
+    if status := api.do("", "POST", "/api/auth/refresh", dto.RefreshRequest{RefreshToken: first.RefreshToken}, nil); status != http.StatusUnauthorized {
+        t.Fatalf("expected a reused refresh token to be refused, got %d", status)
+    }
+    if status := api.do("", "POST", "/api/auth/refresh", dto.RefreshRequest{RefreshToken: second.RefreshToken}, nil); status != http.StatusUnauthorized {
+        t.Fatalf("expected reuse to revoke the token family, got %d", status)
+    }
+
+    third, _ := api.loginTokens("admin", "admin-password")
+    if status := api.do(third.Token, "GET", "/api/admin/users", nil, nil); status != http.StatusOK {
+        t.Fatalf("expected the access token to work, got %d", status)
+    }
+    if status := api.do(third.Token, "POST", "/api/auth/logout", dto.LogoutRequest{RefreshToken: third.RefreshToken}, nil); status != http.StatusNoContent {
+        t.Fatalf("expected logout to succeed, got %d", status)
+    }
+    if status := api.do(third.Token, "GET", "/api/admin/users", nil, nil); status != http.StatusUnauthorized {
+        t.Errorf("expected the revoked access token to be refused, got %d", status)
+    }
+    if status := api.do("", "POST", "/api/auth/refresh", dto.RefreshRequest{RefreshToken: third.RefreshToken}, nil); status != http.StatusUnauthorized {
+        t.Errorf("expected the logged out refresh token to be refused, got %d", status)
+    }
+}
+
+func TestTenantsHaveSeparateUsers(t *testing.T) {
+    api := newUserAPI(t)
+
+    platformToken, _ := api.login("admin", "admin-password")
+    var tenant dto.TenantResponse
+    status := api.do(platformToken, "POST", "/api/admin/tenants", dto.CreateTenantRequest{
+        Slug: "acme", Name: "Acme Freight", AdminUsername: "acme-admin", AdminPassword: "acme-password",
+    }, &tenant)
+    if status != http.StatusCreated {
+        t.Fatalf("expected the tenant to be created, got %d", status)
+    }
+
+    acmeToken, status := api.login("acme-admin", "acme-password")
+    if status != http.StatusOK {
+        t.Fatalf("expected the tenant admin to sign in, got %d", status)
+    }
+    claims, err := api.authService.ValidateToken(acmeToken)
+    if err != nil || claims.TenantID != tenant.ID {
+        t.Fatalf("expected the token to carry the tenant, got %+v (%v)", claims, err)
+    }
+
+    var acmeUsers dto.ListUsersResponse
+    api.do(acmeToken, "GET", "/api/admin/users", nil, &acmeUsers)
+    if acmeUsers.Total != 1 || acmeUsers.Users[0].Username != "acme-admin" {
+        t.Fatalf("expected the tenant admin to see only their tenant's users, got %+v", acmeUsers)
+    }
+    var platformUsers dto.ListUsersResponse
+    api.do(platformToken, "GET", "/api/admin/users", nil, &platformUsers)
+    if platformUsers.Total != 1 {
+        t.Fatalf("expected the platform admin to see only the default tenant's users, got %+v", platformUsers)
+    }
+    if status := api.do(acmeToken, "GET", "/api/admin/users/"+platformUsers.Users[0].ID, nil, nil); status != http.StatusNotFound {
+        t.Errorf("expected another tenant's user to be not found, got %d", status)
+    }
+    if status := api.do(acmeToken, "GET", "/api/admin/tenants", nil, nil); status != http.StatusForbidden {
+        t.Errorf("expected a tenant admin to be refused tenant management, got %d", status)
+    }
+
+    inactive := false
+    if status := api.do(platformToken, "PATCH", "/api/admin/tenants/"+tenant.ID, dto.UpdateTenantRequest{Active: &inactive}, nil); status != http.StatusOK {
+        t.Fatalf("expected the tenant to be deactivated, got %d", status)
+    }
+    if _, status := api.login("acme-admin", "acme-password"); status != http.StatusUnauthorized {
+        t.Errorf("expected users of a deactivated tenant to be refused, got %d", status)
+    }
+}
+
+func TestAPIKeys(t *testing.T) {
+    api := newUserAPI(t)
+    adminToken, _ := api.login("admin", "admin-password")
+
+    var created dto.APIKeySecretResponse
+    status := api.do(adminToken, "POST", "/api/admin/api-keys", dto.CreateAPIKeyRequest{
+        Name: "customer portal", Scopes: []string{"loads:read"}, RateLimitPerMinute: 3,
+    }, &created)
+    if status != http.StatusCreated || created.Key == "" {
+        t.Fatalf("expected a key to be created, got %d %+v", status, created)
+    }
+    if status := api.do(adminToken, "POST", "/api/admin/api-keys", dto.CreateAPIKeyRequest{
+        Name: "bad", Scopes: []string{"users:manage"},
+    }, nil); status != http.StatusBadRequest {
+        t.Errorf("expected an invalid scope to be refused, got %d", status)
+    }
+
+    if status := api.doWithHeader(middleware.APIKeyHeader, created.Key, "GET", "/api/whoami", nil, nil); status != http.StatusOK {
+        t.Fatalf("expected the key to be accepted, got %d", status)
+    }
+    if status := api.doWithHeader(middleware.APIKeyHeader, created.Key, "GET", "/api/admin/api-keys", nil, nil); status != http.StatusForbidden {
+        t.Errorf("expected the key to be refused outside its scopes, got %d", status)
+    }
+    if status := api.doWithHeader(middleware.APIKeyHeader, created.Key, "GET", "/api/whoami", nil, nil); status != http.StatusTooManyRequests {
+        t.Errorf("expected the key's rate limit to apply, got %d", status)
+    }
+
+    var list dto.ListAPIKeysResponse
+    api.do(adminToken, "GET", "/api/admin/api-keys", nil, &list)
+    if len(list.APIKeys) != 1 || list.APIKeys[0].LastUsedAt == "" || list.APIKeys[0].Prefix == "" {
+        t.Fatalf("expected the key to be listed with its last use, got %+v", list)
+    }
+
+    var rotated dto.APIKeySecretResponse
+    if status := api.do(adminToken, "POST", "/api/admin/api-keys/"+created.ID+"/rotate", nil, &rotated); status != http.StatusOK {
+        t.Fatalf("expected the key to be rotated, got %d", status)
+    }
+    if status := api.doWithHeader(middleware.APIKeyHeader, created.Key, "GET", "/api/whoami", nil, nil); status != http.StatusUnauthorized {
+        t.Errorf("expected the old key to be refused after rotation, got %d", status)
+    }
+
+    if status := api.do(adminToken, "DELETE", "/api/admin/api-keys/"+created.ID, nil, nil); status != http.StatusNoContent {
+        t.Fatalf("expected the key to be revoked, got %d", status)
+    }
+    if status := api.doWithHeader(middleware.APIKeyHeader, rotated.Key, "GET", "/api/whoami", nil, nil); status != http.StatusUnauthorized {
+        t.Errorf("expected a revoked key to be refused, got %d", status)
+    }
+}
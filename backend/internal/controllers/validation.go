@@ -0,0 +1,119 @@
+package controllers
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "strings"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/models"
+
+    "github.com/gin-gonic/gin/binding"
+    "github.com/go-playground/validator/v10"
+)
+
+// init teaches gin's validator the custom tags used on request types and to
+// report fields by their JSON names.
+func init() {
+    v, ok := binding.Validator.Engine().(*validator.Validate)
+    if !ok {
+        return
+    }
+
+    v.RegisterTagNameFunc(func(field reflect.StructField) string {
+        name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+        if name == "-" {
+            return ""
+        }
+        if name == "" {
+            return field.Name
+        }
+        return name
+    })
+
+    v.RegisterValidation("scheduledtime", func(fl validator.FieldLevel) bool {
+        _, err := models.ParseScheduledTime(fl.Field().String())
+        return err == nil
+    })
+}
+
+// bindingFieldErrors converts a failed ShouldBindJSON into per-field errors.
+// It returns nil for errors that are not about a specific field, such as
+// malformed JSON.
+func bindingFieldErrors(err error) []apperrors.FieldError {
+    var validationErrs validator.ValidationErrors
+    if errors.As(err, &validationErrs) {
+        fields := make([]apperrors.FieldError, 0, len(validationErrs))
+        for _, fe := range validationErrs {
+            fields = append(fields, apperrors.FieldError{
+                Field:   fieldPath(fe.Namespace()),
+                Message: validationMessage(fe),
+            })
+        }
+        return fields
+    }
+
+    var typeErr *json.UnmarshalTypeError
+    if errors.As(err, &typeErr) && typeErr.Field != "" {
+        return []apperrors.FieldError{{
+            Field:   typeErr.Field,
+            Message: fmt.Sprintf("must be a %s", jsonTypeName(typeErr.Type)),
+        }}
+    }
+    return nil
+}
+
+// fieldPath drops the request type from a namespace such as
+// "CreateLoadRequest.pickup.scheduledTime".
+func fieldPath(namespace string) string {
+    if i := strings.Index(namespace, "."); i >= 0 {
+        return namespace[i+1:]
+    }
+    return namespace
+}
+
+func validationMessage(fe validator.FieldError) string {
+    switch fe.Tag() {
+    case "required":
+        return "is required"
+    case "scheduledtime":
+        return "must be an RFC3339 timestamp"
+    case "email":
+        return "must be a valid email address"
+    case "oneof":
+        return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(fe.Param()), ", "))
+    case "len":
+        return fmt.Sprintf("must be exactly %s characters", fe.Param())
+    case "gte":
+        return fmt.Sprintf("must be at least %s", fe.Param())
+    case "min":
+        if fe.Kind() == reflect.String {
+            return fmt.Sprintf("must be at least %s characters", fe.Param())
+        }
+        return fmt.Sprintf("must be at least %s", fe.Param())
+    case "max":
+        if fe.Kind() == reflect.String {
+            return fmt.Sprintf("must be at most %s characters", fe.Param())
+        }
+        return fmt.Sprintf("must be at most %s", fe.Param())
+    }
+    return fmt.Sprintf("failed %s validation", fe.Tag())
+}
+
+func jsonTypeName(t reflect.Type) string {
+    switch t.Kind() {
+    case reflect.String:
+        return "string"
+    case reflect.Bool:
+        return "boolean"
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+        reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+        reflect.Float32, reflect.Float64:
+        return "number"
+    case reflect.Slice, reflect.Array:
+        return "list"
+    }
+    return "object"
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+    Name   string   `json:"name" binding:"required,max=100"`
+    Scopes []string `json:"scopes" binding:"required,min=1"`
+    // RateLimitPerMinute overrides the default limit for this key.
+    RateLimitPerMinute int        `json:"rateLimitPerMinute" binding:"omitempty,min=1,max=100000"`
+    ExpiresAt          *time.Time `json:"expiresAt"`
+}
+
+type APIKeyResponse struct {
+    ID                 string   `json:"id"`
+    Name               string   `json:"name"`
+    Prefix             string   `json:"prefix"`
+    Scopes             []string `json:"scopes"`
+    RateLimitPerMinute int      `json:"rateLimitPerMinute"`
+    CreatedBy          string   `json:"createdBy"`
+    CreatedAt          string   `json:"createdAt"`
+    RotatedAt          string   `json:"rotatedAt,omitempty"`
+    LastUsedAt         string   `json:"lastUsedAt,omitempty"`
+    ExpiresAt          string   `json:"expiresAt,omitempty"`
+    RevokedAt          string   `json:"revokedAt,omitempty"`
+}
+
+// APIKeySecretResponse carries a new or rotated key. The key is not shown
+// again.
+type APIKeySecretResponse struct {
+    APIKeyResponse
+    Key string `json:"key"`
+}
+
+type ListAPIKeysResponse struct {
+    APIKeys []APIKeyResponse `json:"apiKeys"`
+}
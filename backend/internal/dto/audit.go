@@ -0,0 +1,57 @@
+package dto
+
+import "time"
+
+// AuditChange is a field's value before and after a change.
+type AuditChange struct {
+    From interface{} `json:"from"`
+    To   interface{} `json:"to"`
+}
+
+type AuditEntryResponse struct {
+    ID        string                 `json:"id"`
+    Sequence  int64                  `json:"sequence"`
+    LoadID    string                 `json:"loadId"`
+    Action    string                 `json:"action"`
+    ActorID   string                 `json:"actorId"`
+    ActorName string                 `json:"actorName"`
+    RequestID string                 `json:"requestId,omitempty"`
+    Changes   map[string]AuditChange `json:"changes"`
+    Hash      string                 `json:"hash"`
+    CreatedAt string                 `json:"createdAt"`
+}
+
+type LoadAuditResponse struct {
+    LoadID  string               `json:"loadId"`
+    Entries []AuditEntryResponse `json:"entries"`
+}
+
+// AuditSearchQuery filters GET /api/admin/audit. Empty fields match every
+// entry; the time range is half-open.
+type AuditSearchQuery struct {
+    LoadID    string
+    ActorID   string
+    Action    string
+    RequestID string
+    From      *time.Time
+    To        *time.Time
+    Page      int
+    Size      int
+}
+
+type ListAuditEntriesResponse struct {
+    Entries []AuditEntryResponse `json:"entries"`
+    Total   int64                `json:"total"`
+    Page    int                  `json:"page"`
+    Size    int                  `json:"size"`
+}
+
+// AuditVerifyResponse reports whether a tenant's audit chain is intact.
+// BrokenAtSequence is the first entry that does not match its hash or its
+// predecessor.
+type AuditVerifyResponse struct {
+    Valid            bool   `json:"valid"`
+    Entries          int64  `json:"entries"`
+    BrokenAtSequence int64  `json:"brokenAtSequence,omitempty"`
+    Reason           string `json:"reason,omitempty"`
+}
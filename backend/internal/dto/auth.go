@@ -0,0 +1,19 @@
+package dto
+
+type TokenResponse struct {
+    // Token is the access token, sent as a Bearer token.
+    Token            string `json:"token"`
+    ExpiresAt        string `json:"expiresAt"`
+    RefreshToken     string `json:"refreshToken"`
+    RefreshExpiresAt string `json:"refreshExpiresAt"`
+}
+
+type RefreshRequest struct {
+    RefreshToken string `json:"refreshToken" binding:"required"`
+}
+
+// LogoutRequest optionally names the refresh token to revoke along with the
+// access token the request is made with.
+type LogoutRequest struct {
+    RefreshToken string `json:"refreshToken"`
+}
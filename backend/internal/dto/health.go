@@ -0,0 +1,14 @@
+package dto
+
+type HealthResponse struct {
+    Status string               `json:"status"`
+    TMS    map[string]TMSHealth `json:"tms,omitempty"`
+}
+
+// TMSHealth reports the circuit breaker state of one TMS provider.
+type TMSHealth struct {
+    Circuit             string `json:"circuit"`
+    ConsecutiveFailures int    `json:"consecutiveFailures"`
+    OpenedAt            string `json:"openedAt,omitempty"`
+    RetryAt             string `json:"retryAt,omitempty"`
+}
@@ -1,16 +1,25 @@
 package dto
 
+import (
+    "time"
+
+    "freight-broker/backend/internal/models"
+)
+
 type CreateLoadRequest struct {
-    ExternalTMSLoadID string                 `json:"externalTMSLoadID"`
-    FreightLoadID     string                 `json:"freightLoadID"`
+    ExternalTMSLoadID string                 `json:"externalTMSLoadID" binding:"max=100"`
+    FreightLoadID     string                 `json:"freightLoadID" binding:"required,max=100"`
     Status           StatusDTO              `json:"status"`
-    Customer         map[string]interface{} `json:"customer"`
-    BillTo          map[string]interface{} `json:"billTo"`
-    Pickup          map[string]interface{} `json:"pickup"`
-    Consignee       map[string]interface{} `json:"consignee"`
-    Carrier         map[string]interface{} `json:"carrier"`
-    RateData        map[string]interface{} `json:"rateData"`
-    Specifications  map[string]interface{} `json:"specifications"`
+    Customer         *models.Party          `json:"customer" binding:"required"`
+    BillTo          *models.Party          `json:"billTo"`
+    // Pickup and Consignee describe a two-stop load; Stops replaces them
+    // for multi-stop loads.
+    Pickup          *models.Location       `json:"pickup" binding:"required_without=Stops"`
+    Consignee       *models.Location       `json:"consignee" binding:"required_without=Stops"`
+    Stops           []StopDTO              `json:"stops,omitempty" binding:"omitempty,max=20,dive"`
+    Carrier         *models.Carrier        `json:"carrier"`
+    RateData        *models.RateData       `json:"rateData"`
+    Specifications  *models.Specifications `json:"specifications"`
     InPalletCount   int                   `json:"inPalletCount"`
     OutPalletCount  int                   `json:"outPalletCount"`
     NumCommodities  int                   `json:"numCommodities"`
@@ -21,6 +30,58 @@ type CreateLoadRequest struct {
     RouteMiles      float64               `json:"routeMiles"`
 }
 
+// UpdateLoadRequest carries a partial load update. Nil fields are left untouched.
+type UpdateLoadRequest struct {
+    FreightLoadID     *string                `json:"freightLoadID" binding:"omitempty,min=1,max=100"`
+    Status           *StatusDTO             `json:"status"`
+    Customer         *models.Party          `json:"customer"`
+    BillTo          *models.Party          `json:"billTo"`
+    Pickup          *models.Location       `json:"pickup"`
+    Consignee       *models.Location       `json:"consignee"`
+    // Stops, when present, replaces the whole route.
+    Stops           []StopDTO              `json:"stops" binding:"omitempty,max=20,dive"`
+    Carrier         *models.Carrier        `json:"carrier"`
+    RateData        *models.RateData       `json:"rateData"`
+    Specifications  *models.Specifications `json:"specifications"`
+    InPalletCount   *int                  `json:"inPalletCount"`
+    OutPalletCount  *int                  `json:"outPalletCount"`
+    NumCommodities  *int                  `json:"numCommodities"`
+    TotalWeight     *float64              `json:"totalWeight"`
+    BillableWeight  *float64              `json:"billableWeight"`
+    PoNums          *string               `json:"poNums"`
+    Operator        *string               `json:"operator"`
+    RouteMiles      *float64              `json:"routeMiles"`
+}
+
+// StopDTO is one stop on a load's route. Stops are ordered by sequence when
+// every stop sets one, and by their position in the list otherwise.
+type StopDTO struct {
+    ID               string                   `json:"id,omitempty"`
+    Sequence         int                      `json:"sequence" binding:"gte=0"`
+    Type             string                   `json:"type" binding:"required,oneof=pickup drop"`
+    FacilityName     string                   `json:"facilityName" binding:"max=255"`
+    Address          models.Address           `json:"address"`
+    Contact          models.Contact           `json:"contact"`
+    AppointmentStart string                   `json:"appointmentStart" binding:"required,scheduledtime"`
+    AppointmentEnd   string                   `json:"appointmentEnd,omitempty" binding:"omitempty,scheduledtime"`
+    TimeZone         string                   `json:"timeZone,omitempty" binding:"max=50"`
+    Commodities      []models.Commodity       `json:"commodities" binding:"omitempty,max=50,dive"`
+    ReferenceNumbers []models.ReferenceNumber `json:"referenceNumbers" binding:"omitempty,max=50,dive"`
+    Notes            string                   `json:"notes"`
+}
+
+type ReplaceStopsRequest struct {
+    Stops []StopDTO `json:"stops" binding:"required,dive"`
+}
+
+type StopsResponse struct {
+    Stops []StopDTO `json:"stops"`
+}
+
+type CancelLoadRequest struct {
+    Reason string `json:"reason"`
+}
+
 type StatusDTO struct {
     Code        StatusCodeDTO `json:"code"`
     Notes       string        `json:"notes"`
@@ -37,13 +98,14 @@ type LoadResponse struct {
     ExternalTMSLoadID string                 `json:"externalTMSLoadID"`
     FreightLoadID     string                 `json:"freightLoadID"`
     Status           StatusDTO              `json:"status"`
-    Customer         map[string]interface{} `json:"customer"`
-    BillTo          map[string]interface{} `json:"billTo"`
-    Pickup          map[string]interface{} `json:"pickup"`
-    Consignee       map[string]interface{} `json:"consignee"`
-    Carrier         map[string]interface{} `json:"carrier"`
-    RateData        map[string]interface{} `json:"rateData"`
-    Specifications  map[string]interface{} `json:"specifications"`
+    Customer         models.Party          `json:"customer"`
+    BillTo          models.Party          `json:"billTo"`
+    Pickup          models.Location       `json:"pickup"`
+    Consignee       models.Location       `json:"consignee"`
+    Stops           []StopDTO             `json:"stops"`
+    Carrier         models.Carrier        `json:"carrier"`
+    RateData        RateDataResponse      `json:"rateData"`
+    Specifications  models.Specifications `json:"specifications"`
     InPalletCount   int                   `json:"inPalletCount"`
     OutPalletCount  int                   `json:"outPalletCount"`
     NumCommodities  int                   `json:"numCommodities"`
@@ -52,14 +114,97 @@ type LoadResponse struct {
     PoNums          string                `json:"poNums"`
     Operator        string                `json:"operator"`
     RouteMiles      float64               `json:"routeMiles"`
+    CancelledAt     string                `json:"cancelledAt,omitempty"`
+    TMSSync         TMSSyncDTO            `json:"tmsSync"`
     CreatedAt       string                `json:"createdAt"`
     UpdatedAt       string                `json:"updatedAt"`
 }
 
+// RateDataResponse is a load's rates as far as the caller may see them: the
+// customer rate fields or CarrierRate are left out for roles that may not
+// read them.
+type RateDataResponse struct {
+    BaseRate      *float64     `json:"baseRate,omitempty"`
+    FuelSurcharge *float64     `json:"fuelSurcharge,omitempty"`
+    TotalRate     *float64     `json:"totalRate,omitempty"`
+    Currency      string       `json:"currency"`
+    CarrierRate   *models.Rate `json:"carrierRate,omitempty"`
+}
+
+type TMSSyncDTO struct {
+    Status   string `json:"status"`
+    CustomID string `json:"customId,omitempty"`
+    Error    string `json:"error,omitempty"`
+    SyncedAt string `json:"syncedAt,omitempty"`
+}
+
+// ListLoadsQuery holds the filters, search and sort order of a load list
+// request. Empty fields do not filter.
+type ListLoadsQuery struct {
+    Page             int
+    PageSize         int
+    Status           []string
+    Customer         string
+    Carrier          string
+    Operator         string
+    OriginState      string
+    DestinationState string
+    PONumber         string
+    Search           string
+    PickupFrom       *time.Time
+    PickupTo         *time.Time
+    DeliveryFrom     *time.Time
+    DeliveryTo       *time.Time
+    // Sort is a sortable field, prefixed with "-" for descending order.
+    Sort             string
+    // UseCursor switches from page numbers to keyset pagination, starting
+    // after Cursor or at the first page when Cursor is empty.
+    UseCursor        bool
+    Cursor           string
+    // TotalMode is one of the Total* modes; empty picks the default for the
+    // pagination mode.
+    TotalMode        string
+}
+
+// How a load list reports its total: counted exactly, estimated from the
+// query planner, or not at all.
+const (
+    TotalExact    = "exact"
+    TotalEstimate = "estimate"
+    TotalNone     = "none"
+)
+
 type ListLoadsResponse struct {
-    Loads []LoadResponse `json:"loads"`
-    Total int64         `json:"total"`
-    Page  int           `json:"page"`
-    Size  int           `json:"size"`
+    Loads          []LoadResponse `json:"loads"`
+    Total          *int64         `json:"total,omitempty"`
+    TotalEstimated bool           `json:"totalEstimated,omitempty"`
+    Page           int            `json:"page,omitempty"`
+    Size           int            `json:"size"`
+    // NextCursor and PrevCursor are set in cursor mode when there are
+    // loads after or before this page.
+    NextCursor     string         `json:"nextCursor,omitempty"`
+    PrevCursor     string         `json:"prevCursor,omitempty"`
 }
 
+
+// ChangeStatusRequest moves a load to another status. Status is a status
+// key such as "covered"; Reason is kept in the status history.
+type ChangeStatusRequest struct {
+    Status string `json:"status" binding:"required"`
+    Reason string `json:"reason" binding:"max=1000"`
+}
+
+type StatusHistoryEntry struct {
+    FromStatus    string `json:"fromStatus,omitempty"`
+    ToStatus      string `json:"toStatus"`
+    ChangedBy     string `json:"changedBy"`
+    ChangedByName string `json:"changedByName"`
+    Source        string `json:"source"`
+    Reason        string `json:"reason,omitempty"`
+    ChangedAt     string `json:"changedAt"`
+}
+
+type StatusHistoryResponse struct {
+    LoadID  string               `json:"loadId"`
+    History []StatusHistoryEntry `json:"history"`
+}
@@ -0,0 +1,45 @@
+package dto
+
+import "freight-broker/backend/internal/apperrors"
+
+// ImportRow is a spreadsheet row read as a create request. Line is its row
+// number in the file, counting the header as 1. Rows with Errors are not
+// created.
+type ImportRow struct {
+    Line    int
+    Request *CreateLoadRequest
+    Errors  []apperrors.FieldError
+}
+
+// ImportRowResult is the outcome of a row: created, failed, or still
+// pending while the import runs.
+type ImportRowResult struct {
+    Row           int                    `json:"row"`
+    FreightLoadID string                 `json:"freightLoadID,omitempty"`
+    Status        string                 `json:"status"`
+    LoadID        string                 `json:"loadId,omitempty"`
+    Errors        []apperrors.FieldError `json:"errors,omitempty"`
+}
+
+// ImportValidationResponse reports a dry run: the rows that would fail and
+// why. Nothing is created.
+type ImportValidationResponse struct {
+    TotalRows   int               `json:"totalRows"`
+    ValidRows   int               `json:"validRows"`
+    InvalidRows int               `json:"invalidRows"`
+    Errors      []ImportRowResult `json:"errors"`
+}
+
+type LoadImportResponse struct {
+    ID          string `json:"id"`
+    FileName    string `json:"fileName"`
+    Status      string `json:"status"`
+    TotalRows   int    `json:"totalRows"`
+    PendingRows int    `json:"pendingRows"`
+    CreatedRows int    `json:"createdRows"`
+    FailedRows  int    `json:"failedRows"`
+    CreatedBy   string `json:"createdBy"`
+    CreatedAt   string `json:"createdAt"`
+    StartedAt   string `json:"startedAt,omitempty"`
+    FinishedAt  string `json:"finishedAt,omitempty"`
+}
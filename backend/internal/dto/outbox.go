@@ -0,0 +1,21 @@
+package dto
+
+type OutboxMessageResponse struct {
+    ID            string `json:"id"`
+    LoadID        string `json:"loadId"`
+    Operation     string `json:"operation"`
+    Status        string `json:"status"`
+    Attempts      int    `json:"attempts"`
+    NextAttemptAt string `json:"nextAttemptAt"`
+    LastError     string `json:"lastError,omitempty"`
+    DeliveredAt   string `json:"deliveredAt,omitempty"`
+    CreatedAt     string `json:"createdAt"`
+    UpdatedAt     string `json:"updatedAt"`
+}
+
+type ListOutboxMessagesResponse struct {
+    Messages []OutboxMessageResponse `json:"messages"`
+    Total    int64                   `json:"total"`
+    Page     int                     `json:"page"`
+    Size     int                     `json:"size"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+// Repair directions for a reconciliation run.
+const (
+    // ReconcileRepairLocal overwrites local loads with the TMS copy.
+    ReconcileRepairLocal = "local"
+    // ReconcileRepairTMS queues outbox deliveries that push local loads to the TMS.
+    ReconcileRepairTMS = "tms"
+)
+
+type ReconciliationRequest struct {
+    Repair string `json:"repair"`
+}
+
+type ReconciliationReport struct {
+    GeneratedAt    string                `json:"generatedAt"`
+    LocalLoads     int                   `json:"localLoads"`
+    TMSShipments   int                   `json:"tmsShipments"`
+    Matched        int                   `json:"matched"`
+    // InFlight counts local loads whose TMS create is still queued.
+    InFlight       int                   `json:"inFlight"`
+    MissingInTMS   []ReconciliationLoad  `json:"missingInTMS"`
+    MissingLocally []ReconciliationLoad  `json:"missingLocally"`
+    Mismatches     []LoadMismatch        `json:"mismatches"`
+    Repair         *ReconciliationRepair `json:"repair,omitempty"`
+}
+
+// ReconciliationLoad identifies a record that exists on only one side.
+type ReconciliationLoad struct {
+    Provider      string `json:"provider"`
+    LoadID        string `json:"loadId,omitempty"`
+    ShipmentID    string `json:"shipmentId,omitempty"`
+    FreightLoadID string `json:"freightLoadID,omitempty"`
+}
+
+type LoadMismatch struct {
+    Provider   string          `json:"provider"`
+    LoadID     string          `json:"loadId"`
+    ShipmentID string          `json:"shipmentId"`
+    MatchedBy  string          `json:"matchedBy"`
+    Fields     []FieldMismatch `json:"fields"`
+}
+
+type FieldMismatch struct {
+    Field string `json:"field"`
+    Local string `json:"local"`
+    TMS   string `json:"tms"`
+}
+
+type ReconciliationRepair struct {
+    Direction string   `json:"direction"`
+    Repaired  int      `json:"repaired"`
+    Skipped   int      `json:"skipped"`
+    Errors    []string `json:"errors,omitempty"`
+}
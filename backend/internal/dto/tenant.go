@@ -0,0 +1,29 @@
+package dto
+
+// CreateTenantRequest creates a tenant together with its first admin.
+type CreateTenantRequest struct {
+    Slug          string `json:"slug" binding:"required,min=2,max=50"`
+    Name          string `json:"name" binding:"required,max=255"`
+    AdminUsername string `json:"adminUsername" binding:"required,min=3,max=100"`
+    AdminEmail    string `json:"adminEmail" binding:"omitempty,email,max=255"`
+    AdminPassword string `json:"adminPassword" binding:"required,min=10,max=72"`
+}
+
+// UpdateTenantRequest changes a tenant. Nil fields are left untouched.
+type UpdateTenantRequest struct {
+    Name   *string `json:"name" binding:"omitempty,min=1,max=255"`
+    Active *bool   `json:"active"`
+}
+
+type TenantResponse struct {
+    ID        string `json:"id"`
+    Slug      string `json:"slug"`
+    Name      string `json:"name"`
+    Active    bool   `json:"active"`
+    CreatedAt string `json:"createdAt"`
+    UpdatedAt string `json:"updatedAt"`
+}
+
+type ListTenantsResponse struct {
+    Tenants []TenantResponse `json:"tenants"`
+}
@@ -0,0 +1,79 @@
+package dto
+
+import "time"
+
+// Shipment is the provider-neutral shipment model exchanged with TMS
+// adapters. Each adapter translates it to and from its own wire format.
+type Shipment struct {
+    ID            string           `json:"id"`
+    CustomID      string           `json:"customId"`
+    // SourceID is our freight load ID as recorded on the TMS customer order.
+    SourceID      string           `json:"sourceId"`
+    LTL           bool             `json:"ltl"`
+    Status        ShipmentStatus   `json:"status"`
+    Origin        ShipmentLocation `json:"origin"`
+    Destination   ShipmentLocation `json:"destination"`
+    CustomerName  string           `json:"customerName"`
+    CarrierName   string           `json:"carrierName"`
+    // Stops is the full route in sequence order. Origin and Destination
+    // mirror its first and last stop.
+    Stops         []ShipmentStop   `json:"stops,omitempty"`
+    LastUpdatedOn time.Time        `json:"lastUpdatedOn"`
+}
+
+type ShipmentStatus struct {
+    Code  string `json:"code"`
+    Label string `json:"label"`
+}
+
+type ShipmentLocation struct {
+    City     string    `json:"city"`
+    State    string    `json:"state"`
+    Date     time.Time `json:"date"`
+    TimeZone string    `json:"timeZone"`
+}
+
+const (
+    ShipmentStopPickup = "pickup"
+    ShipmentStopDrop   = "drop"
+)
+
+type ShipmentStop struct {
+    Sequence         int                 `json:"sequence"`
+    Type             string              `json:"type"`
+    Name             string              `json:"name"`
+    Street           string              `json:"street"`
+    City             string              `json:"city"`
+    State            string              `json:"state"`
+    Zip              string              `json:"zip"`
+    AppointmentStart time.Time           `json:"appointmentStart"`
+    // AppointmentEnd is zero for a fixed appointment.
+    AppointmentEnd   time.Time           `json:"appointmentEnd"`
+    TimeZone         string              `json:"timeZone"`
+    Commodities      []ShipmentCommodity `json:"commodities,omitempty"`
+    References       []ShipmentReference `json:"references,omitempty"`
+    Notes            string              `json:"notes,omitempty"`
+}
+
+type ShipmentCommodity struct {
+    Description string  `json:"description"`
+    Quantity    int     `json:"quantity"`
+    PackageType string  `json:"packageType"`
+    Weight      float64 `json:"weight"`
+    WeightUnit  string  `json:"weightUnit"`
+}
+
+type ShipmentReference struct {
+    Type  string `json:"type"`
+    Value string `json:"value"`
+}
+
+type ShipmentPage struct {
+    Shipments     []Shipment `json:"shipments"`
+    MoreAvailable bool       `json:"moreAvailable"`
+}
+
+// ListShipmentsFilter narrows a shipment listing. Zero values are ignored.
+type ListShipmentsFilter struct {
+    UpdatedSince time.Time
+}
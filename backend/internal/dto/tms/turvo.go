@@ -6,10 +6,11 @@ type TurvoAuthRequest struct {
     GrantType    string `json:"grant_type"`
     ClientID     string `json:"client_id"`
     ClientSecret string `json:"client_secret"`
-    Username     string `json:"username"`
-    Password     string `json:"password"`
-    Scope        string `json:"scope"`
-    Type         string `json:"type"`
+    Username     string `json:"username,omitempty"`
+    Password     string `json:"password,omitempty"`
+    RefreshToken string `json:"refresh_token,omitempty"`
+    Scope        string `json:"scope,omitempty"`
+    Type         string `json:"type,omitempty"`
 }
 
 type TurvoAuthResponse struct {
@@ -49,12 +50,58 @@ type CustomerOrder struct {
     Customer             CustomerInfo `json:"customer"`
 }
 
+// RouteStop is one stop on a Turvo shipment's globalRoute.
+type RouteStop struct {
+    Sequence        int          `json:"sequence"`
+    SegmentSequence int          `json:"segmentSequence"`
+    StopType        StatusCode   `json:"stopType"`
+    Name            string       `json:"name"`
+    Timezone        string       `json:"timezone"`
+    Address         RouteAddress `json:"address"`
+    Appointment     Appointment  `json:"appointment"`
+    PONumbers       []string     `json:"poNumbers,omitempty"`
+    ExternalIDs     []ExternalID `json:"externalIds,omitempty"`
+    Items           []RouteItem  `json:"items,omitempty"`
+    Notes           string       `json:"notes,omitempty"`
+}
+
+type RouteAddress struct {
+    Line1 string `json:"line1"`
+    City  string `json:"city"`
+    State string `json:"state"`
+    Zip   string `json:"zip"`
+}
+
+type Appointment struct {
+    Start    time.Time  `json:"start"`
+    End      *time.Time `json:"end,omitempty"`
+    Timezone string     `json:"timezone"`
+}
+
+type ExternalID struct {
+    Type  StatusCode `json:"type"`
+    Value string     `json:"value"`
+}
+
+type RouteItem struct {
+    Name        string      `json:"name"`
+    Qty         int         `json:"qty"`
+    Unit        StatusCode  `json:"unit"`
+    GrossWeight GrossWeight `json:"grossWeight"`
+}
+
+type GrossWeight struct {
+    Weight float64 `json:"weight"`
+    Unit   string  `json:"unit"`
+}
+
 type CreateShipmentRequest struct {
     LTLShipment   bool            `json:"ltlShipment"`
     StartDate     DateInfo        `json:"startDate"`
     EndDate       DateInfo        `json:"endDate"`
     Status        Status          `json:"status"`
     Lane          Lane           `json:"lane"`
+    GlobalRoute   []RouteStop     `json:"globalRoute,omitempty"`
     CustomerOrder []CustomerOrder `json:"customerOrder"`
 }
 
@@ -62,8 +109,13 @@ type ShipmentResponse struct {
     ID           int       `json:"id"`
     CustomID     string    `json:"customId"`
     Status       Status    `json:"status"`
+    Lane         Lane      `json:"lane"`
+    StartDate    DateInfo  `json:"startDate"`
+    EndDate      DateInfo  `json:"endDate"`
+    GlobalRoute  []RouteStop `json:"globalRoute"`
     CustomerOrder []struct {
         ID       int `json:"id"`
+        CustomerOrderSourceId string `json:"customerOrderSourceId"`
         Customer struct {
             ID   int    `json:"id"`
             Name string `json:"name"`
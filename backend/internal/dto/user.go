@@ -0,0 +1,61 @@
+package dto
+
+// Passwords are limited to 72 bytes, the most bcrypt hashes.
+
+type CreateUserRequest struct {
+    Username string `json:"username" binding:"required,min=3,max=100"`
+    Email    string `json:"email" binding:"omitempty,email,max=255"`
+    Password string `json:"password" binding:"required,min=10,max=72"`
+    Role     string `json:"role" binding:"required"`
+    // PartyID is required for customer and carrier users; see
+    // models.User.PartyID.
+    PartyID  string `json:"partyId" binding:"max=100"`
+}
+
+// UpdateUserRequest changes an account. Nil fields are left untouched.
+type UpdateUserRequest struct {
+    Email   *string `json:"email" binding:"omitempty,email,max=255"`
+    Role    *string `json:"role"`
+    PartyID *string `json:"partyId" binding:"omitempty,max=100"`
+    Active  *bool   `json:"active"`
+    // Unlock clears a lockout from failed sign-ins.
+    Unlock  bool    `json:"unlock"`
+}
+
+type UserResponse struct {
+    ID                string `json:"id"`
+    Username          string `json:"username"`
+    Email             string `json:"email,omitempty"`
+    Role              string `json:"role"`
+    PartyID           string `json:"partyId,omitempty"`
+    Active            bool   `json:"active"`
+    LockedUntil       string `json:"lockedUntil,omitempty"`
+    LastLoginAt       string `json:"lastLoginAt,omitempty"`
+    PasswordChangedAt string `json:"passwordChangedAt,omitempty"`
+    CreatedAt         string `json:"createdAt"`
+    UpdatedAt         string `json:"updatedAt"`
+}
+
+type ListUsersResponse struct {
+    Users []UserResponse `json:"users"`
+    Total int64          `json:"total"`
+    Page  int            `json:"page"`
+    Size  int            `json:"size"`
+}
+
+type ChangePasswordRequest struct {
+    CurrentPassword string `json:"currentPassword" binding:"required"`
+    NewPassword     string `json:"newPassword" binding:"required,min=10,max=72"`
+}
+
+// PasswordResetTokenResponse carries a one-time reset token for an admin to
+// hand to the user. It is not shown again.
+type PasswordResetTokenResponse struct {
+    Token     string `json:"token"`
+    ExpiresAt string `json:"expiresAt"`
+}
+
+type ResetPasswordRequest struct {
+    Token       string `json:"token" binding:"required"`
+    NewPassword string `json:"newPassword" binding:"required,min=10,max=72"`
+}
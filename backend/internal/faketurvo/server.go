@@ -0,0 +1,459 @@
+// Package faketurvo is an in-memory stand-in for the Turvo public API, used
+// by tests and for running the backend without real Turvo credentials.
+package faketurvo
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+
+    "freight-broker/backend/internal/dto/tms"
+
+    "github.com/google/uuid"
+)
+
+// Route identifies one fake endpoint for fault injection and request counts.
+type Route string
+
+const (
+    RouteToken  Route = "token"
+    RouteCreate Route = "create"
+    RouteList   Route = "list"
+    RouteGet    Route = "get"
+    RouteUpdate Route = "update"
+    RouteDelete Route = "delete"
+)
+
+// Fault makes a route misbehave. A non-zero Status is returned instead of
+// handling the request, with RetryAfter as its Retry-After header if set;
+// Delay is applied first. Times limits the fault to that many requests, zero
+// means until cleared.
+type Fault struct {
+    Status     int
+    RetryAfter string
+    Delay      time.Duration
+    Times      int
+}
+
+// Config holds the credentials the token endpoint accepts. Empty fields are
+// not checked.
+type Config struct {
+    APIKey       string
+    ClientID     string
+    ClientSecret string
+    Username     string
+    Password     string
+    TokenTTL     time.Duration
+}
+
+// Server is a stateful fake Turvo API backed by httptest.
+type Server struct {
+    URL string
+
+    config     Config
+    httpServer *httptest.Server
+
+    mu        sync.Mutex
+    nextID    int
+    shipments map[int]*dto.ShipmentResponse
+    tokens    map[string]time.Time
+    refresh   map[string]bool
+    grants    map[string]int
+    faults    map[Route]*Fault
+    requests  map[Route]int
+}
+
+// New starts a fake Turvo server. Callers must Close it.
+func New(config Config) *Server {
+    if config.TokenTTL <= 0 {
+        config.TokenTTL = time.Hour
+    }
+
+    s := &Server{
+        config:    config,
+        nextID:    1000,
+        shipments: make(map[int]*dto.ShipmentResponse),
+        tokens:    make(map[string]time.Time),
+        refresh:   make(map[string]bool),
+        grants:    make(map[string]int),
+        faults:    make(map[Route]*Fault),
+        requests:  make(map[Route]int),
+    }
+
+    mux := http.NewServeMux()
+    mux.HandleFunc("POST /v1/oauth/token", s.route(RouteToken, false, s.handleToken))
+    mux.HandleFunc("POST /v1/shipments", s.route(RouteCreate, true, s.handleCreate))
+    mux.HandleFunc("GET /v1/shipments/list", s.route(RouteList, true, s.handleList))
+    mux.HandleFunc("GET /v1/shipments/{id}", s.route(RouteGet, true, s.handleGet))
+    mux.HandleFunc("PUT /v1/shipments/{id}", s.route(RouteUpdate, true, s.handleUpdate))
+    mux.HandleFunc("DELETE /v1/shipments/{id}", s.route(RouteDelete, true, s.handleDelete))
+
+    s.httpServer = httptest.NewServer(mux)
+    s.URL = s.httpServer.URL
+    return s
+}
+
+func (s *Server) Close() {
+    s.httpServer.Close()
+}
+
+// BaseURL is the value for TMSServiceConfig.BaseURL.
+func (s *Server) BaseURL() string {
+    return s.URL + "/v1"
+}
+
+// AuthURL is the value for TMSServiceConfig.AuthURL.
+func (s *Server) AuthURL() string {
+    return s.URL + "/v1/oauth/token"
+}
+
+func (s *Server) InjectFault(route Route, fault Fault) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.faults[route] = &fault
+}
+
+func (s *Server) ClearFaults() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.faults = make(map[Route]*Fault)
+}
+
+// ExpireTokens invalidates every issued access token, as if they had all
+// reached their expiry.
+func (s *Server) ExpireTokens() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.tokens = make(map[string]time.Time)
+}
+
+// RevokeRefreshTokens invalidates every issued refresh token, forcing
+// clients back to the password grant.
+func (s *Server) RevokeRefreshTokens() {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.refresh = make(map[string]bool)
+}
+
+// Grants returns how many tokens were issued with a grant type, e.g.
+// "password" or "refresh_token".
+func (s *Server) Grants(grantType string) int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.grants[grantType]
+}
+
+// Requests returns how many requests reached a route, including faulted ones.
+func (s *Server) Requests(route Route) int {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.requests[route]
+}
+
+// Shipment returns a copy of a stored shipment.
+func (s *Server) Shipment(id string) (dto.ShipmentResponse, bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    shipmentID, err := strconv.Atoi(id)
+    if err != nil {
+        return dto.ShipmentResponse{}, false
+    }
+    shipment, ok := s.shipments[shipmentID]
+    if !ok {
+        return dto.ShipmentResponse{}, false
+    }
+    return *shipment, true
+}
+
+// Shipments returns copies of all stored shipments ordered by ID.
+func (s *Server) Shipments() []dto.ShipmentResponse {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.sortedShipments()
+}
+
+// AddShipment stores a shipment as if it had been created in Turvo directly
+// and returns its ID.
+func (s *Server) AddShipment(req dto.CreateShipmentRequest) string {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return strconv.Itoa(s.store(0, req).ID)
+}
+
+// route wraps a handler with request counting, fault injection and, for the
+// shipment endpoints, bearer token checks.
+func (s *Server) route(route Route, requireToken bool, handler http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        s.mu.Lock()
+        s.requests[route]++
+        var fault Fault
+        if f, ok := s.faults[route]; ok {
+            fault = *f
+            if f.Times > 0 {
+                f.Times--
+                if f.Times == 0 {
+                    delete(s.faults, route)
+                }
+            }
+        }
+        s.mu.Unlock()
+
+        if fault.Delay > 0 {
+            select {
+            case <-time.After(fault.Delay):
+            case <-r.Context().Done():
+                return
+            }
+        }
+        if fault.Status != 0 {
+            if fault.RetryAfter != "" {
+                w.Header().Set("Retry-After", fault.RetryAfter)
+            }
+            writeError(w, fault.Status, "injected fault")
+            return
+        }
+
+        if requireToken && !s.validToken(r) {
+            writeError(w, http.StatusUnauthorized, "invalid or expired access token")
+            return
+        }
+
+        handler(w, r)
+    }
+}
+
+func (s *Server) validToken(r *http.Request) bool {
+    token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+    if s.config.APIKey != "" && r.Header.Get("x-api-key") != s.config.APIKey {
+        return false
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    expiry, ok := s.tokens[token]
+    return ok && time.Now().Before(expiry)
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+    var req dto.TurvoAuthRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        writeError(w, http.StatusBadRequest, "invalid token request")
+        return
+    }
+
+    if !matches(s.config.APIKey, r.Header.Get("x-api-key")) ||
+        !matches(s.config.ClientID, req.ClientID) ||
+        !matches(s.config.ClientSecret, req.ClientSecret) {
+        writeError(w, http.StatusUnauthorized, "bad client credentials")
+        return
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    switch req.GrantType {
+    case "password":
+        if !matches(s.config.Username, req.Username) || !matches(s.config.Password, req.Password) {
+            writeError(w, http.StatusUnauthorized, "bad credentials")
+            return
+        }
+    case "refresh_token":
+        if !s.refresh[req.RefreshToken] {
+            writeError(w, http.StatusUnauthorized, "invalid refresh token")
+            return
+        }
+        // Refresh tokens are single use.
+        delete(s.refresh, req.RefreshToken)
+    default:
+        writeError(w, http.StatusBadRequest, "unsupported grant_type")
+        return
+    }
+
+    token, refreshToken := uuid.NewString(), uuid.NewString()
+    s.tokens[token] = time.Now().Add(s.config.TokenTTL)
+    s.refresh[refreshToken] = true
+    s.grants[req.GrantType]++
+
+    writeJSON(w, http.StatusOK, dto.TurvoAuthResponse{
+        AccessToken:  token,
+        TokenType:    "bearer",
+        ExpiresIn:    int(s.config.TokenTTL / time.Second),
+        Scope:        req.Scope,
+        RefreshToken: refreshToken,
+    })
+}
+
+func (s *Server) handleCreate(w http.ResponseWriter, r *http.Request) {
+    var req dto.CreateShipmentRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        writeError(w, http.StatusBadRequest, "invalid shipment payload")
+        return
+    }
+
+    s.mu.Lock()
+    shipment := *s.store(0, req)
+    s.mu.Unlock()
+
+    writeJSON(w, http.StatusOK, envelope(shipment))
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+    query := r.URL.Query()
+    start, _ := strconv.Atoi(query.Get("start"))
+    pageSize, err := strconv.Atoi(query.Get("pageSize"))
+    if err != nil || pageSize <= 0 {
+        pageSize = 24
+    }
+
+    var updatedSince time.Time
+    if raw := query.Get("lastUpdatedOn[gte]"); raw != "" {
+        if updatedSince, err = time.Parse(time.RFC3339, raw); err != nil {
+            writeError(w, http.StatusBadRequest, "invalid lastUpdatedOn[gte]")
+            return
+        }
+    }
+
+    s.mu.Lock()
+    var matched []dto.ShipmentResponse
+    for _, shipment := range s.sortedShipments() {
+        if shipment.LastUpdatedOn.Before(updatedSince) {
+            continue
+        }
+        matched = append(matched, shipment)
+    }
+    s.mu.Unlock()
+
+    var resp dto.ListShipmentsResponse
+    resp.Status = "SUCCESS"
+    resp.Details.Pagination.Start = start
+    resp.Details.Pagination.PageSize = pageSize
+    resp.Details.Shipments = []dto.ShipmentResponse{}
+    if start < len(matched) {
+        end := start + pageSize
+        if end > len(matched) {
+            end = len(matched)
+        }
+        resp.Details.Shipments = matched[start:end]
+        resp.Details.Pagination.MoreAvailable = end < len(matched)
+    }
+    resp.Details.Pagination.TotalRecordsInPage = len(resp.Details.Shipments)
+
+    writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+    shipment, ok := s.Shipment(r.PathValue("id"))
+    if !ok {
+        writeError(w, http.StatusNotFound, "shipment not found")
+        return
+    }
+    writeJSON(w, http.StatusOK, envelope(shipment))
+}
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request) {
+    var req dto.CreateShipmentRequest
+    if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+        writeError(w, http.StatusBadRequest, "invalid shipment payload")
+        return
+    }
+
+    id, _ := strconv.Atoi(r.PathValue("id"))
+
+    s.mu.Lock()
+    if _, ok := s.shipments[id]; !ok {
+        s.mu.Unlock()
+        writeError(w, http.StatusNotFound, "shipment not found")
+        return
+    }
+    shipment := *s.store(id, req)
+    s.mu.Unlock()
+
+    writeJSON(w, http.StatusOK, envelope(shipment))
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
+    id, _ := strconv.Atoi(r.PathValue("id"))
+
+    s.mu.Lock()
+    _, ok := s.shipments[id]
+    delete(s.shipments, id)
+    s.mu.Unlock()
+
+    if !ok {
+        writeError(w, http.StatusNotFound, "shipment not found")
+        return
+    }
+    writeJSON(w, http.StatusOK, map[string]string{"Status": "SUCCESS"})
+}
+
+// store saves req under id, allocating a new ID when id is zero. The caller
+// must hold s.mu.
+func (s *Server) store(id int, req dto.CreateShipmentRequest) *dto.ShipmentResponse {
+    // The request and response share their JSON field names, so a round trip
+    // copies the payload into the richer response shape.
+    var shipment dto.ShipmentResponse
+    raw, _ := json.Marshal(req)
+    json.Unmarshal(raw, &shipment)
+
+    now := time.Now().UTC()
+    if existing, ok := s.shipments[id]; ok {
+        shipment.Created = existing.Created
+        shipment.CreatedDate = existing.CreatedDate
+        shipment.CustomID = existing.CustomID
+    } else {
+        s.nextID++
+        id = s.nextID
+        shipment.Created = now
+        shipment.CreatedDate = now
+        shipment.CustomID = fmt.Sprintf("FAKE-%d", id)
+    }
+    shipment.ID = id
+    shipment.Updated = now
+    shipment.LastUpdatedOn = now
+
+    s.shipments[id] = &shipment
+    return &shipment
+}
+
+// sortedShipments returns copies ordered by ID. The caller must hold s.mu.
+func (s *Server) sortedShipments() []dto.ShipmentResponse {
+    shipments := make([]dto.ShipmentResponse, 0, len(s.shipments))
+    for _, shipment := range s.shipments {
+        shipments = append(shipments, *shipment)
+    }
+    sort.Slice(shipments, func(i, j int) bool {
+        return shipments[i].ID < shipments[j].ID
+    })
+    return shipments
+}
+
+func matches(expected, actual string) bool {
+    return expected == "" || expected == actual
+}
+
+func envelope(details interface{}) map[string]interface{} {
+    return map[string]interface{}{
+        "Status":  "SUCCESS",
+        "details": details,
+    }
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+    writeJSON(w, status, map[string]string{
+        "message": message,
+        "details": http.StatusText(status),
+    })
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(body)
+}
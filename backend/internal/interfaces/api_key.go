@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+    "context"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+)
+
+type APIKeyService interface {
+    // Authenticate checks a key presented with a request and counts the
+    // request against the key's rate limit.
+    Authenticate(ctx context.Context, key string) (*models.APIKey, error)
+    CreateAPIKey(ctx context.Context, req *dto.CreateAPIKeyRequest) (*dto.APIKeySecretResponse, error)
+    ListAPIKeys(ctx context.Context) (*dto.ListAPIKeysResponse, error)
+    GetAPIKey(ctx context.Context, id string) (*dto.APIKeyResponse, error)
+    RotateAPIKey(ctx context.Context, id string) (*dto.APIKeySecretResponse, error)
+    RevokeAPIKey(ctx context.Context, id string) error
+}
@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+    "context"
+    "freight-broker/backend/internal/dto"
+)
+
+type AuditService interface {
+    // GetLoadAudit lists a load's audit entries, oldest first.
+    GetLoadAudit(ctx context.Context, loadID string) (*dto.LoadAuditResponse, error)
+    SearchAudit(ctx context.Context, query *dto.AuditSearchQuery) (*dto.ListAuditEntriesResponse, error)
+    // VerifyAuditLog recomputes the hash chain of the actor's tenant.
+    VerifyAuditLog(ctx context.Context) (*dto.AuditVerifyResponse, error)
+}
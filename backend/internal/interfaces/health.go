@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+    "context"
+    "freight-broker/backend/internal/dto"
+)
+
+type HealthService interface {
+    Check(ctx context.Context) *dto.HealthResponse
+}
+
+// TMSHealthReporter is implemented by TMS adapters that expose the state of
+// their circuit breaker.
+type TMSHealthReporter interface {
+    TMSHealth() dto.TMSHealth
+}
@@ -7,6 +7,23 @@ import (
 
 type LoadService interface {
     CreateLoad(ctx context.Context, req *dto.CreateLoadRequest) (*dto.LoadResponse, error)
+    // CreateLoadIdempotent creates a load once per idempotency key and
+    // reports whether the response is a replay of an earlier request.
+    CreateLoadIdempotent(ctx context.Context, key string, req *dto.CreateLoadRequest) (*dto.LoadResponse, bool, error)
     GetLoad(ctx context.Context, id string) (*dto.LoadResponse, error)
-    ListLoads(ctx context.Context, page, pageSize int) (*dto.ListLoadsResponse, error)
-}
\ No newline at end of file
+    ListLoads(ctx context.Context, query *dto.ListLoadsQuery) (*dto.ListLoadsResponse, error)
+    // ExportLoads writes every load matching the query's filters to w.
+    ExportLoads(ctx context.Context, query *dto.ListLoadsQuery, w LoadExportWriter) error
+    UpdateLoad(ctx context.Context, id string, req *dto.UpdateLoadRequest) (*dto.LoadResponse, error)
+    CancelLoad(ctx context.Context, id string, req *dto.CancelLoadRequest) (*dto.LoadResponse, error)
+    ChangeStatus(ctx context.Context, id string, req *dto.ChangeStatusRequest) (*dto.LoadResponse, error)
+    GetStatusHistory(ctx context.Context, id string) (*dto.StatusHistoryResponse, error)
+}
+
+// LoadExportWriter receives a load export: the names of its columns, then
+// each load's values in column order. Values are strings, float64s, ints,
+// or nil where a load has none.
+type LoadExportWriter interface {
+    WriteHeader(columns []string) error
+    WriteLoad(values []interface{}) error
+}
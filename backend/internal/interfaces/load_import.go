@@ -0,0 +1,14 @@
+package interfaces
+
+import (
+    "context"
+    "freight-broker/backend/internal/dto"
+)
+
+type LoadImportService interface {
+    ValidateImport(ctx context.Context, rows []dto.ImportRow) (*dto.ImportValidationResponse, error)
+    StartImport(ctx context.Context, fileName string, rows []dto.ImportRow) (*dto.LoadImportResponse, error)
+    GetImport(ctx context.Context, id string) (*dto.LoadImportResponse, error)
+    // EachImportRow calls fn with the outcome of each row, in file order.
+    EachImportRow(ctx context.Context, id string, fn func(dto.ImportRowResult) error) error
+}
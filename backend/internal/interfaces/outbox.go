@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+    "context"
+    "freight-broker/backend/internal/dto"
+)
+
+type OutboxService interface {
+    ListMessages(ctx context.Context, status string, page, pageSize int) (*dto.ListOutboxMessagesResponse, error)
+    ReplayMessage(ctx context.Context, id string) (*dto.OutboxMessageResponse, error)
+}
@@ -0,0 +1,10 @@
+package interfaces
+
+import (
+    "context"
+    "freight-broker/backend/internal/dto"
+)
+
+type ReconciliationService interface {
+    Reconcile(ctx context.Context, req *dto.ReconciliationRequest) (*dto.ReconciliationReport, error)
+}
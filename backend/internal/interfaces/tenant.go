@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+    "context"
+    "freight-broker/backend/internal/dto"
+)
+
+type TenantService interface {
+    CreateTenant(ctx context.Context, req *dto.CreateTenantRequest) (*dto.TenantResponse, error)
+    ListTenants(ctx context.Context) (*dto.ListTenantsResponse, error)
+    GetTenant(ctx context.Context, id string) (*dto.TenantResponse, error)
+    UpdateTenant(ctx context.Context, id string, req *dto.UpdateTenantRequest) (*dto.TenantResponse, error)
+}
@@ -10,9 +10,15 @@ type TMSService interface {
     IsTokenValid() bool
     RefreshToken(ctx context.Context) error
     
-    CreateShipment(ctx context.Context, req dto.CreateShipmentRequest) (*dto.ShipmentResponse, error)
-    GetShipment(ctx context.Context, id string) (*dto.ShipmentResponse, error)
-    ListShipments(ctx context.Context, page, pageSize int) (*dto.ListShipmentsResponse, error)
-    UpdateShipment(ctx context.Context, id string, req dto.CreateShipmentRequest) (*dto.ShipmentResponse, error)
+    CreateShipment(ctx context.Context, shipment dto.Shipment) (*dto.Shipment, error)
+    GetShipment(ctx context.Context, id string) (*dto.Shipment, error)
+    ListShipments(ctx context.Context, page, pageSize int, filter dto.ListShipmentsFilter) (*dto.ShipmentPage, error)
+    UpdateShipment(ctx context.Context, id string, shipment dto.Shipment) (*dto.Shipment, error)
     DeleteShipment(ctx context.Context, id string) error
-}
\ No newline at end of file
+}
+
+// TokenRefresher is implemented by TMS adapters that renew their credentials
+// in the background.
+type TokenRefresher interface {
+    RunTokenRefresh(ctx context.Context)
+}
@@ -0,0 +1,15 @@
+package interfaces
+
+// TMSProviderRegistry resolves which TMS adapter handles a load.
+type TMSProviderRegistry interface {
+    Provider(name string) (TMSService, error)
+    ProviderNames() []string
+    // ResolveProvider picks the provider for a customer, falling back to the
+    // configured default.
+    ResolveProvider(customerName string) string
+    // ForTenant returns the registry for a tenant's loads: the tenant's own
+    // when it has TMS settings, or the shared one.
+    ForTenant(tenantID string) TMSProviderRegistry
+    // TenantIDs lists the tenants with their own TMS settings.
+    TenantIDs() []string
+}
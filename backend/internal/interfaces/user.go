@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+    "context"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+)
+
+type UserService interface {
+    Authenticate(ctx context.Context, username, password string) (*models.User, error)
+    CreateUser(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error)
+    GetUser(ctx context.Context, id string) (*dto.UserResponse, error)
+    ListUsers(ctx context.Context, page, pageSize int) (*dto.ListUsersResponse, error)
+    UpdateUser(ctx context.Context, id string, req *dto.UpdateUserRequest) (*dto.UserResponse, error)
+    DeleteUser(ctx context.Context, id string) error
+    ChangePassword(ctx context.Context, userID string, req *dto.ChangePasswordRequest) error
+    CreateResetToken(ctx context.Context, userID string) (*dto.PasswordResetTokenResponse, error)
+    ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+}
@@ -1,15 +1,59 @@
 package middleware
 
 import (
+    "log"
     "net/http"
+    "strconv"
     "strings"
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
     "freight-broker/backend/internal/services"
     "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
 )
 
+// ErrorResponse is the JSON body of every error response.
 type ErrorResponse struct {
-    Code    int    `json:"code"`
-    Message string `json:"message"`
+    Status   int                    `json:"status"`
+    Code     apperrors.Code         `json:"code"`
+    Message  string                 `json:"error"`
+    Details  string                 `json:"details,omitempty"`
+    Fields   []apperrors.FieldError `json:"fields,omitempty"`
+    // Location points to the existing resource of a CONFLICT, if any.
+    Location string                 `json:"location,omitempty"`
+}
+
+// RequestIDHeader carries the ID a request is logged and audited under.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID keeps the caller's X-Request-ID when it is reasonable, or
+// generates one, and echoes it in the response.
+func RequestID() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        requestID := c.GetHeader(RequestIDHeader)
+        if !validRequestID(requestID) {
+            requestID = uuid.New().String()
+        }
+
+        c.Set("requestID", requestID)
+        c.Header(RequestIDHeader, requestID)
+        c.Request = c.Request.WithContext(requestctx.WithRequestID(c.Request.Context(), requestID))
+        c.Next()
+    }
+}
+
+func validRequestID(requestID string) bool {
+    if requestID == "" || len(requestID) > 64 {
+        return false
+    }
+    for _, r := range requestID {
+        if r < '!' || r > '~' {
+            return false
+        }
+    }
+    return true
 }
 
 func JWTAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
@@ -20,62 +64,218 @@ func JWTAuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
         }
         authHeader := c.GetHeader("Authorization")
         if authHeader == "" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
+            c.Error(apperrors.Unauthorized("authorization header required"))
             c.Abort()
             return
         }
 
         bearerToken := strings.Split(authHeader, " ")
         if len(bearerToken) != 2 || bearerToken[0] != "Bearer" {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token format"})
+            c.Error(apperrors.Unauthorized("invalid token format"))
             c.Abort()
             return
         }
 
         claims, err := authService.ValidateToken(bearerToken[1])
         if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+            c.Error(err)
+            c.Abort()
+            return
+        }
+        // Everything a request touches is scoped to its tenant, so a token
+        // without one cannot be used.
+        if claims.TenantID == "" {
+            c.Error(apperrors.Unauthorized("token has no tenant; sign in again"))
             c.Abort()
             return
         }
 
+        c.Set("claims", claims)
+        c.Set("tenantID", claims.TenantID)
         c.Set("userID", claims.UserID)
         c.Set("username", claims.Username)
         c.Set("role", claims.Role)
+        c.Request = c.Request.WithContext(requestctx.WithActor(c.Request.Context(), requestctx.Actor{
+            TenantID: claims.TenantID,
+            UserID:   claims.UserID,
+            Username: claims.Username,
+            Role:     claims.Role,
+            PartyID:  claims.PartyID,
+        }))
+
+        c.Next()
+    }
+}
+
+// APIKeyHeader carries an API key in place of a bearer token.
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware accepts either an API key in the X-API-Key header or a
+// bearer token, which is checked as by JWTAuthMiddleware.
+func AuthMiddleware(authService *services.AuthService, apiKeyService interfaces.APIKeyService) gin.HandlerFunc {
+    jwtAuth := JWTAuthMiddleware(authService)
+    return func(c *gin.Context) {
+        key := c.GetHeader(APIKeyHeader)
+        if key == "" || c.Request.Method == "OPTIONS" {
+            jwtAuth(c)
+            return
+        }
+
+        apiKey, err := apiKeyService.Authenticate(c.Request.Context(), key)
+        if err != nil {
+            c.Error(err)
+            c.Abort()
+            return
+        }
+
+        actor := requestctx.Actor{
+            TenantID: apiKey.TenantID.String(),
+            UserID:   "apikey:" + apiKey.ID.String(),
+            Username: apiKey.Name,
+            APIKeyID: apiKey.ID.String(),
+            Scopes:   apiKey.Scopes,
+        }
+        c.Set("tenantID", actor.TenantID)
+        c.Set("apiKeyID", actor.APIKeyID)
+        c.Set("scopes", actor.Scopes)
+        c.Request = c.Request.WithContext(requestctx.WithActor(c.Request.Context(), actor))
+
+        c.Next()
+    }
+}
+
+// RequireUser refuses requests made with an API key, for endpoints that act
+// on the signed-in user.
+func RequireUser() gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if c.GetString("apiKeyID") != "" {
+            c.Error(apperrors.Forbidden("not available to API keys"))
+            c.Abort()
+            return
+        }
+        c.Next()
+    }
+}
 
+// RequirePermission lets through only requests whose token carries a role
+// allowed permission, or whose API key has it as a scope. It must run after
+// JWTAuthMiddleware or AuthMiddleware.
+func RequirePermission(permission models.Permission) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        allowed := models.HasPermission(c.GetString("role"), permission)
+        if scopes, ok := c.Get("scopes"); ok {
+            allowed = models.ScopesAllow(scopes.([]string), permission)
+        }
+        if !allowed {
+            c.Error(apperrors.Forbidden("missing permission " + string(permission)))
+            c.Abort()
+            return
+        }
         c.Next()
     }
 }
 
+// RequireTenant lets through only requests from users of one tenant. It
+// keeps deployment-wide endpoints to the default tenant's admins and must
+// run after JWTAuthMiddleware.
+func RequireTenant(tenantID string) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        if c.GetString("tenantID") != tenantID {
+            c.Error(apperrors.Forbidden("only available to the platform tenant"))
+            c.Abort()
+            return
+        }
+        c.Next()
+    }
+}
+
+// ErrorHandler renders the last error a handler attached with c.Error.
+// Typed errors keep their code; anything else is logged and reported as an
+// internal error without leaking its message.
 func ErrorHandler() gin.HandlerFunc {
     return func(c *gin.Context) {
         c.Next()
 
-        if len(c.Errors) > 0 {
-            err := c.Errors.Last()
-            
-            switch e := err.Err.(type) {
-            case *CustomError:
-                c.JSON(e.StatusCode, ErrorResponse{
-                    Code:    e.StatusCode,
-                    Message: e.Message,
-                })
-            default:
-                // Default error response
-                c.JSON(http.StatusInternalServerError, ErrorResponse{
-                    Code:    http.StatusInternalServerError,
-                    Message: "An internal server error occurred",
-                })
+        if len(c.Errors) == 0 || c.Writer.Written() {
+            return
+        }
+        err := c.Errors.Last().Err
+
+        if e, ok := err.(*CustomError); ok {
+            code := e.Code
+            if code == "" {
+                code = codeForStatus(e.StatusCode)
             }
+            c.JSON(e.StatusCode, ErrorResponse{
+                Status:  e.StatusCode,
+                Code:    code,
+                Message: e.Message,
+            })
+            return
+        }
+
+        appErr, ok := apperrors.As(err)
+        if !ok {
+            log.Printf("Unhandled error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+            c.JSON(http.StatusInternalServerError, ErrorResponse{
+                Status:  http.StatusInternalServerError,
+                Code:    apperrors.CodeInternal,
+                Message: "An internal server error occurred",
+            })
+            return
+        }
+
+        status := apperrors.HTTPStatus(appErr.Code)
+        if status >= http.StatusInternalServerError {
+            log.Printf("Error on %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+        }
+        if appErr.Code == apperrors.CodeRateLimited && appErr.RetryAfter > 0 {
+            c.Header("Retry-After", strconv.Itoa(int(appErr.RetryAfter.Seconds()+0.5)))
+        }
+
+        // Wrapped causes can name internal hosts and upstream responses, so
+        // only the error's own details are shown.
+        resp := ErrorResponse{
+            Status:  status,
+            Code:    appErr.Code,
+            Message: appErr.Message,
+            Details: appErr.Details,
+            Fields:  appErr.Fields,
+        }
+        if appErr.Location != "" {
+            c.Header("Location", appErr.Location)
+            resp.Location = appErr.Location
         }
+        c.JSON(status, resp)
     }
 }
 
+func codeForStatus(status int) apperrors.Code {
+    switch status {
+    case http.StatusBadRequest:
+        return apperrors.CodeValidation
+    case http.StatusUnauthorized:
+        return apperrors.CodeUnauthorized
+    case http.StatusForbidden:
+        return apperrors.CodeForbidden
+    case http.StatusNotFound:
+        return apperrors.CodeNotFound
+    case http.StatusConflict:
+        return apperrors.CodeConflict
+    case http.StatusTooManyRequests:
+        return apperrors.CodeRateLimited
+    case http.StatusServiceUnavailable:
+        return apperrors.CodeUpstreamUnavailable
+    }
+    return apperrors.CodeInternal
+}
+
 type CustomError struct {
     StatusCode int
+    Code       apperrors.Code
     Message    string
 }
 
 func (e *CustomError) Error() string {
     return e.Message
-}
\ No newline at end of file
+}
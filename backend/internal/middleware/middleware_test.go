@@ -0,0 +1,260 @@
+package middleware
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+    "freight-broker/backend/internal/services"
+    "github.com/gin-gonic/gin"
+    "github.com/google/uuid"
+)
+
+func serveError(t *testing.T, err error) (*httptest.ResponseRecorder, ErrorResponse) {
+    t.Helper()
+
+    gin.SetMode(gin.TestMode)
+    router := gin.New()
+    router.Use(ErrorHandler())
+    router.GET("/", func(c *gin.Context) {
+        c.Error(err)
+    })
+
+    rec := httptest.NewRecorder()
+    router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+    var body ErrorResponse
+    if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+        t.Fatalf("failed to decode error response %q: %v", rec.Body.String(), err)
+    }
+    return rec, body
+}
+
+func TestErrorHandlerRendersTypedErrors(t *testing.T) {
+    tests := []struct {
+        err    error
+        status int
+        code   apperrors.Code
+    }{
+        {apperrors.Validation("bad input"), http.StatusBadRequest, apperrors.CodeValidation},
+        {apperrors.Unauthorized("no token"), http.StatusUnauthorized, apperrors.CodeUnauthorized},
+        {apperrors.Forbidden("nope"), http.StatusForbidden, apperrors.CodeForbidden},
+        {apperrors.NotFound("load not found"), http.StatusNotFound, apperrors.CodeNotFound},
+        {apperrors.Conflict("load is cancelled"), http.StatusConflict, apperrors.CodeConflict},
+        {apperrors.UpstreamUnavailable("TMS down", errors.New("timeout")), http.StatusServiceUnavailable, apperrors.CodeUpstreamUnavailable},
+        {apperrors.UpstreamRejected(http.StatusBadRequest), http.StatusBadGateway, apperrors.CodeUpstreamRejected},
+        {&CustomError{StatusCode: http.StatusNotFound, Message: "missing"}, http.StatusNotFound, apperrors.CodeNotFound},
+        {errors.New("boom"), http.StatusInternalServerError, apperrors.CodeInternal},
+    }
+
+    for _, tt := range tests {
+        rec, body := serveError(t, tt.err)
+        if rec.Code != tt.status || body.Status != tt.status || body.Code != tt.code {
+            t.Errorf("%v: expected %d %s, got %d %+v", tt.err, tt.status, tt.code, rec.Code, body)
+        }
+    }
+}
+
+func TestErrorHandlerIncludesFieldsAndRetryAfter(t *testing.T) {
+    _, body := serveError(t, apperrors.Validation("Validation failed",
+        apperrors.FieldError{Field: "customer", Message: "is required"}))
+    if len(body.Fields) != 1 || body.Fields[0].Field != "customer" {
+        t.Errorf("expected the customer field error, got %+v", body.Fields)
+    }
+
+    rec, _ := serveError(t, apperrors.RateLimited("slow down", 3*time.Second))
+    if got := rec.Header().Get("Retry-After"); got != "3" {
+        t.Errorf("expected Retry-After 3, got %q", got)
+    }
+
+    rec, body = serveError(t, apperrors.Duplicate("already exists", "/api/loads/1"))
+    if rec.Code != http.StatusConflict || body.Location != "/api/loads/1" || rec.Header().Get("Location") != "/api/loads/1" {
+        t.Errorf("expected a 409 with the existing location, got %d %+v", rec.Code, body)
+    }
+}
+
+func TestErrorHandlerHidesUntypedErrors(t *testing.T) {
+    _, body := serveError(t, errors.New("pq: connection refused"))
+    if body.Message != "An internal server error occurred" || body.Details != "" {
+        t.Errorf("expected a generic message without details, got %+v", body)
+    }
+}
+
+func TestErrorHandlerHidesWrappedCauses(t *testing.T) {
+    err := fmt.Errorf("failed to update shipment in TMS: %w",
+        apperrors.UpstreamUnavailable("TMS unavailable", errors.New("dial tcp 10.0.0.7:443: connection refused")))
+    _, body := serveError(t, err)
+    if body.Message != "TMS unavailable" || body.Details != "" {
+        t.Errorf("expected the cause to stay out of the response, got %+v", body)
+    }
+
+    _, body = serveError(t, apperrors.UpstreamRejected(http.StatusForbidden))
+    if body.Details != "TMS responded with status 403" {
+        t.Errorf("expected the upstream status in the details, got %+v", body)
+    }
+}
+
+func TestRequirePermission(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+
+    for _, tt := range []struct {
+        role   string
+        status int
+    }{
+        {"admin", http.StatusNoContent},
+        {"broker", http.StatusNoContent},
+        {"dispatcher", http.StatusForbidden},
+        {"customer", http.StatusForbidden},
+        {"", http.StatusForbidden},
+    } {
+        router := gin.New()
+        router.Use(ErrorHandler())
+        router.GET("/", func(c *gin.Context) {
+            c.Set("role", tt.role)
+        }, RequirePermission(models.PermLoadsCancel), func(c *gin.Context) {
+            c.Status(http.StatusNoContent)
+        })
+
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+        if rec.Code != tt.status {
+            t.Errorf("role %q: expected %d, got %d", tt.role, tt.status, rec.Code)
+        }
+    }
+}
+
+func TestRequireTenant(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+
+    for _, tt := range []struct {
+        tenantID string
+        status   int
+    }{
+        {"platform", http.StatusNoContent},
+        {"other", http.StatusForbidden},
+        {"", http.StatusForbidden},
+    } {
+        router := gin.New()
+        router.Use(ErrorHandler())
+        router.GET("/", func(c *gin.Context) {
+            c.Set("tenantID", tt.tenantID)
+        }, RequireTenant("platform"), func(c *gin.Context) {
+            c.Status(http.StatusNoContent)
+        })
+
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+        if rec.Code != tt.status {
+            t.Errorf("tenant %q: expected %d, got %d", tt.tenantID, tt.status, rec.Code)
+        }
+    }
+}
+
+func TestRequestID(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+
+    router := gin.New()
+    router.Use(RequestID())
+    router.GET("/", func(c *gin.Context) {
+        c.String(http.StatusOK, requestctx.RequestIDFrom(c.Request.Context()))
+    })
+
+    for _, tt := range []struct {
+        header string
+        kept   bool
+    }{
+        {"req-123", true},
+        {"", false},
+        {"has space", false},
+        {strings.Repeat("x", 65), false},
+    } {
+        req := httptest.NewRequest("GET", "/", nil)
+        req.Header.Set(RequestIDHeader, tt.header)
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, req)
+
+        got := rec.Header().Get(RequestIDHeader)
+        if got == "" || got != rec.Body.String() {
+            t.Errorf("header %q: expected the echoed ID to match the context, got %q and %q", tt.header, got, rec.Body.String())
+        }
+        if (got == tt.header) != tt.kept {
+            t.Errorf("header %q: expected kept=%v, got %q", tt.header, tt.kept, got)
+        }
+    }
+}
+
+type fakeAPIKeyService struct {
+    interfaces.APIKeyService
+    keys map[string]*models.APIKey
+}
+
+func (f *fakeAPIKeyService) Authenticate(ctx context.Context, key string) (*models.APIKey, error) {
+    if apiKey, ok := f.keys[key]; ok {
+        return apiKey, nil
+    }
+    return nil, apperrors.Unauthorized("invalid API key")
+}
+
+func TestAuthMiddlewareAcceptsAPIKeysAndTokens(t *testing.T) {
+    gin.SetMode(gin.TestMode)
+
+    authService, err := services.NewAuthService(nil, services.AuthServiceConfig{
+        Keys: []services.SigningKey{services.NewHMACKey("test", "test-secret")},
+    })
+    if err != nil {
+        t.Fatalf("failed to create auth service: %v", err)
+    }
+    token, _ := authService.GenerateToken(uuid.New().String(), uuid.New().String(), "broker1", models.RoleBroker)
+    apiKeys := &fakeAPIKeyService{keys: map[string]*models.APIKey{
+        "fbk_reader": {ID: uuid.New(), TenantID: uuid.New(), Name: "reader", Scopes: models.APIKeyScopes{string(models.PermLoadsRead)}},
+    }}
+
+    router := gin.New()
+    router.Use(ErrorHandler(), AuthMiddleware(authService, apiKeys))
+    router.GET("/loads", RequirePermission(models.PermLoadsRead), func(c *gin.Context) {
+        actor, _ := requestctx.ActorFrom(c.Request.Context())
+        c.String(http.StatusOK, actor.Username)
+    })
+    router.POST("/loads", RequirePermission(models.PermLoadsCreate), func(c *gin.Context) {
+        c.Status(http.StatusNoContent)
+    })
+    router.POST("/auth/password", RequireUser(), func(c *gin.Context) {
+        c.Status(http.StatusNoContent)
+    })
+
+    for _, tt := range []struct {
+        method string
+        path   string
+        header string
+        value  string
+        status int
+    }{
+        {"GET", "/loads", APIKeyHeader, "fbk_reader", http.StatusOK},
+        {"POST", "/loads", APIKeyHeader, "fbk_reader", http.StatusForbidden},
+        {"POST", "/auth/password", APIKeyHeader, "fbk_reader", http.StatusForbidden},
+        {"GET", "/loads", APIKeyHeader, "fbk_unknown", http.StatusUnauthorized},
+        {"POST", "/loads", "Authorization", "Bearer " + token, http.StatusNoContent},
+        {"POST", "/auth/password", "Authorization", "Bearer " + token, http.StatusNoContent},
+        {"GET", "/loads", "", "", http.StatusUnauthorized},
+    } {
+        req := httptest.NewRequest(tt.method, tt.path, nil)
+        if tt.header != "" {
+            req.Header.Set(tt.header, tt.value)
+        }
+        rec := httptest.NewRecorder()
+        router.ServeHTTP(rec, req)
+        if rec.Code != tt.status {
+            t.Errorf("%s %s with %s: expected %d, got %d", tt.method, tt.path, tt.header, tt.status, rec.Code)
+        }
+    }
+}
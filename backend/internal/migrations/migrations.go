@@ -0,0 +1,299 @@
+// Package migrations applies the versioned SQL schema changes embedded in
+// the binary. Each change is a pair of files in sql/, named
+// <version>_<name>.up.sql and <version>_<name>.down.sql, and runs in its own
+// transaction. Applied versions are recorded in schema_migrations.
+package migrations
+
+import (
+    "embed"
+    "errors"
+    "fmt"
+    "io/fs"
+    "log"
+    "path"
+    "regexp"
+    "sort"
+    "strconv"
+    "time"
+
+    "github.com/jinzhu/gorm"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// ErrUnknownSchema is returned when the database has a migration applied
+// that this binary does not know, i.e. it was migrated by a newer release.
+var ErrUnknownSchema = errors.New("database schema is newer than this binary")
+
+// Migration is one versioned schema change.
+type Migration struct {
+    Version int64
+    Name    string
+    Up      string
+    Down    string
+}
+
+// Status is a migration and when it was applied, nil if it is pending.
+type Status struct {
+    Version   int64
+    Name      string
+    AppliedAt *time.Time
+    // Unknown is set for versions applied by a newer binary.
+    Unknown bool
+}
+
+type appliedMigration struct {
+    Version   int64  `gorm:"primary_key"`
+    Name      string `gorm:"type:varchar(255)"`
+    AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string {
+    return "schema_migrations"
+}
+
+// Migrator moves a database between schema versions.
+type Migrator struct {
+    db         *gorm.DB
+    migrations []Migration
+}
+
+// New returns a migrator for the embedded migrations.
+func New(db *gorm.DB) (*Migrator, error) {
+    migrations, err := parse(files)
+    if err != nil {
+        return nil, err
+    }
+    return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// parse reads and pairs the migration files in fsys, ordered by version.
+func parse(fsys fs.FS) ([]Migration, error) {
+    names, err := fs.Glob(fsys, "sql/*.sql")
+    if err != nil {
+        return nil, err
+    }
+
+    byVersion := make(map[int64]*Migration)
+    for _, name := range names {
+        match := fileNamePattern.FindStringSubmatch(path.Base(name))
+        if match == nil {
+            return nil, fmt.Errorf("invalid migration file name %s", name)
+        }
+        version, err := strconv.ParseInt(match[1], 10, 64)
+        if err != nil || version <= 0 {
+            return nil, fmt.Errorf("invalid migration version in %s", name)
+        }
+        body, err := fs.ReadFile(fsys, name)
+        if err != nil {
+            return nil, err
+        }
+
+        migration, ok := byVersion[version]
+        if !ok {
+            migration = &Migration{Version: version, Name: match[2]}
+            byVersion[version] = migration
+        } else if migration.Name != match[2] {
+            return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+        }
+        if match[3] == "up" {
+            migration.Up = string(body)
+        } else {
+            migration.Down = string(body)
+        }
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, migration := range byVersion {
+        if migration.Up == "" || migration.Down == "" {
+            return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+        }
+        migrations = append(migrations, *migration)
+    }
+    sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+    return migrations, nil
+}
+
+// Latest returns the version the embedded migrations end at.
+func (m *Migrator) Latest() int64 {
+    if len(m.migrations) == 0 {
+        return 0
+    }
+    return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status lists every known migration, and any unknown applied ones, by
+// version.
+func (m *Migrator) Status() ([]Status, error) {
+    applied, err := m.applied(m.db)
+    if err != nil {
+        return nil, err
+    }
+
+    statuses := make([]Status, 0, len(m.migrations))
+    for _, migration := range m.migrations {
+        status := Status{Version: migration.Version, Name: migration.Name}
+        if row, ok := applied[migration.Version]; ok {
+            status.AppliedAt = &row.AppliedAt
+            delete(applied, migration.Version)
+        }
+        statuses = append(statuses, status)
+    }
+    for _, row := range applied {
+        appliedAt := row.AppliedAt
+        statuses = append(statuses, Status{Version: row.Version, Name: row.Name, AppliedAt: &appliedAt, Unknown: true})
+    }
+    sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+    return statuses, nil
+}
+
+// Check refuses a database with migrations applied that this binary does
+// not know, and reports how many known migrations are pending.
+func (m *Migrator) Check() (pending int, err error) {
+    statuses, err := m.Status()
+    if err != nil {
+        return 0, err
+    }
+    for _, status := range statuses {
+        if status.Unknown {
+            return 0, fmt.Errorf("%w: version %d (%s) is applied", ErrUnknownSchema, status.Version, status.Name)
+        }
+        if status.AppliedAt == nil {
+            pending++
+        }
+    }
+    return pending, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() error {
+    return m.To(m.Latest())
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down() error {
+    statuses, err := m.Status()
+    if err != nil {
+        return err
+    }
+    if _, err := m.Check(); err != nil {
+        return err
+    }
+    for i := len(statuses) - 1; i >= 0; i-- {
+        if statuses[i].AppliedAt != nil {
+            return m.run(*m.find(statuses[i].Version), false)
+        }
+    }
+    return nil
+}
+
+// To applies the pending migrations up to version and rolls back the applied
+// ones after it. Version 0 rolls back everything.
+func (m *Migrator) To(version int64) error {
+    if version != 0 && m.find(version) == nil {
+        return fmt.Errorf("unknown migration version %d", version)
+    }
+    if _, err := m.Check(); err != nil {
+        return err
+    }
+
+    for i := len(m.migrations) - 1; i >= 0; i-- {
+        if migration := m.migrations[i]; migration.Version > version {
+            if err := m.run(migration, false); err != nil {
+                return err
+            }
+        }
+    }
+    for _, migration := range m.migrations {
+        if migration.Version <= version {
+            if err := m.run(migration, true); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// run applies or rolls back a migration unless that was already done. An
+// advisory lock serializes migrations from concurrent starts.
+func (m *Migrator) run(migration Migration, up bool) error {
+    tx := m.db.Begin()
+    if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext('schema_migrations'))").Error; err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to lock schema migrations: %w", err)
+    }
+    applied, err := m.applied(tx)
+    if err != nil {
+        tx.Rollback()
+        return err
+    }
+    if _, ok := applied[migration.Version]; ok == up {
+        tx.Rollback()
+        return nil
+    }
+
+    body, direction := migration.Up, "apply"
+    if !up {
+        body, direction = migration.Down, "roll back"
+    }
+    // The body is run as written; gorm's Exec would treat ? as a parameter.
+    if _, err := tx.CommonDB().Exec(body); err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to %s migration %d_%s: %w", direction, migration.Version, migration.Name, err)
+    }
+
+    if up {
+        err = tx.Create(&appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+    } else {
+        err = tx.Where("version = ?", migration.Version).Delete(&appliedMigration{}).Error
+    }
+    if err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+    }
+    if err := tx.Commit().Error; err != nil {
+        return fmt.Errorf("failed to commit migration %d_%s: %w", migration.Version, migration.Name, err)
+    }
+
+    if up {
+        log.Printf("Applied migration %d_%s", migration.Version, migration.Name)
+    } else {
+        log.Printf("Rolled back migration %d_%s", migration.Version, migration.Name)
+    }
+    return nil
+}
+
+// applied returns the recorded migrations by version, creating the table on
+// first use.
+func (m *Migrator) applied(db *gorm.DB) (map[int64]appliedMigration, error) {
+    err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+        version bigint PRIMARY KEY,
+        name varchar(255),
+        applied_at timestamp with time zone
+    )`).Error
+    if err != nil {
+        return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+    }
+
+    var rows []appliedMigration
+    if err := db.Order("version").Find(&rows).Error; err != nil {
+        return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+    }
+    applied := make(map[int64]appliedMigration, len(rows))
+    for _, row := range rows {
+        applied[row.Version] = row
+    }
+    return applied, nil
+}
+
+func (m *Migrator) find(version int64) *Migration {
+    for i := range m.migrations {
+        if m.migrations[i].Version == version {
+            return &m.migrations[i]
+        }
+    }
+    return nil
+}
@@ -0,0 +1,215 @@
+package migrations
+
+import (
+    "errors"
+    "os"
+    "regexp"
+    "strings"
+    "testing"
+    "testing/fstest"
+    "time"
+
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+    _ "github.com/lib/pq"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+    migrations, err := parse(files)
+    if err != nil {
+        t.Fatalf("failed to parse embedded migrations: %v", err)
+    }
+    for i, migration := range migrations {
+        if migration.Version != int64(i+1) {
+            t.Errorf("expected migration %d to have version %d, got %d_%s", i, i+1, migration.Version, migration.Name)
+        }
+    }
+
+    // Free-text search only uses the index if the query's expression is the
+    // indexed one.
+    search := migrations[2]
+    if !strings.Contains(strings.Join(strings.Fields(search.Up), " "), strings.Join(strings.Fields(models.LoadSearchExpression), " ")) {
+        t.Errorf("expected %d_%s to index models.LoadSearchExpression", search.Version, search.Name)
+    }
+}
+
+func TestParseRejectsBadFiles(t *testing.T) {
+    for name, fsys := range map[string]fstest.MapFS{
+        "missing down": {
+            "sql/0001_init.up.sql": {Data: []byte("SELECT 1")},
+        },
+        "bad name": {
+            "sql/init.up.sql":   {Data: []byte("SELECT 1")},
+            "sql/init.down.sql": {Data: []byte("SELECT 1")},
+        },
+        "mismatched names": {
+            "sql/0001_init.up.sql":    {Data: []byte("SELECT 1")},
+            "sql/0001_other.down.sql": {Data: []byte("SELECT 1")},
+        },
+    } {
+        if _, err := parse(fsys); err == nil {
+            t.Errorf("%s: expected an error", name)
+        }
+    }
+}
+
+// createdObjectPattern finds the tables and indexes a migration creates.
+var createdObjectPattern = regexp.MustCompile(`CREATE (?:UNIQUE )?(?:TABLE|INDEX) IF NOT EXISTS (\w+)`)
+
+func TestMigrateUpDownAndUnknownVersions(t *testing.T) {
+    dbURL := os.Getenv("TEST_DATABASE_URL")
+    if dbURL == "" {
+        t.Skip("TEST_DATABASE_URL is not set")
+    }
+    db, err := gorm.Open("postgres", dbURL)
+    if err != nil {
+        t.Fatalf("failed to connect to test database: %v", err)
+    }
+    defer db.Close()
+
+    migrator, err := New(db)
+    if err != nil {
+        t.Fatalf("failed to load migrations: %v", err)
+    }
+    if err := migrator.Up(); err != nil {
+        t.Fatalf("failed to migrate up: %v", err)
+    }
+    if pending, err := migrator.Check(); err != nil || pending != 0 {
+        t.Fatalf("expected no pending migrations, got %d (%v)", pending, err)
+    }
+
+    latest := migrator.migrations[len(migrator.migrations)-1]
+    if err := migrator.Down(); err != nil {
+        t.Fatalf("failed to migrate down: %v", err)
+    }
+    for _, match := range createdObjectPattern.FindAllStringSubmatch(latest.Up, -1) {
+        var exists bool
+        if err := db.Raw("SELECT to_regclass(?) IS NOT NULL", match[1]).Row().Scan(&exists); err != nil {
+            t.Fatalf("failed to look up %s: %v", match[1], err)
+        }
+        if exists {
+            t.Errorf("expected rolling back %d_%s to drop %s", latest.Version, latest.Name, match[1])
+        }
+    }
+    if err := migrator.To(2); err != nil {
+        t.Fatalf("failed to migrate to version 2: %v", err)
+    }
+    if pending, _ := migrator.Check(); pending != len(migrator.migrations)-2 {
+        t.Errorf("expected %d pending migrations at version 2, got %d", len(migrator.migrations)-2, pending)
+    }
+    if err := migrator.Up(); err != nil {
+        t.Fatalf("failed to migrate up again: %v", err)
+    }
+
+    future := appliedMigration{Version: migrator.Latest() + 1, Name: "from_the_future"}
+    if err := db.Create(&future).Error; err != nil {
+        t.Fatalf("failed to record a newer migration: %v", err)
+    }
+    defer db.Where("version = ?", future.Version).Delete(&appliedMigration{})
+    if _, err := migrator.Check(); !errors.Is(err, ErrUnknownSchema) {
+        t.Errorf("expected ErrUnknownSchema, got %v", err)
+    }
+    if err := migrator.Up(); !errors.Is(err, ErrUnknownSchema) {
+        t.Errorf("expected migrating up to refuse a newer schema, got %v", err)
+    }
+}
+
+// baselineLoad is the loads table as AutoMigrate created it before
+// versioned migrations.
+type baselineLoad struct {
+    ID                uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt         time.Time
+    UpdatedAt         time.Time
+    ExternalTMSLoadID string `gorm:"type:varchar(100)"`
+    FreightLoadID     string `gorm:"type:varchar(100)"`
+    Status            string `gorm:"type:jsonb"`
+    Customer          string `gorm:"type:jsonb"`
+    BillTo            string `gorm:"type:jsonb"`
+    Pickup            string `gorm:"type:jsonb"`
+    Consignee         string `gorm:"type:jsonb"`
+    Carrier           string `gorm:"type:jsonb"`
+    RateData          string `gorm:"type:jsonb"`
+    Specifications    string `gorm:"type:jsonb"`
+    InPalletCount     int
+    OutPalletCount    int
+    NumCommodities    int
+    TotalWeight       float64
+    BillableWeight    float64
+    PoNums            string `gorm:"type:varchar(255)"`
+    Operator          string `gorm:"type:varchar(100)"`
+    RouteMiles        float64
+}
+
+func (baselineLoad) TableName() string {
+    return "loads"
+}
+
+func TestMigrateUpFromAutoMigrateSchema(t *testing.T) {
+    dbURL := os.Getenv("TEST_DATABASE_URL")
+    if dbURL == "" {
+        t.Skip("TEST_DATABASE_URL is not set")
+    }
+    admin, err := gorm.Open("postgres", dbURL)
+    if err != nil {
+        t.Fatalf("failed to connect to test database: %v", err)
+    }
+    defer admin.Close()
+
+    // The baseline gets a schema of its own, so the other suites sharing
+    // the database keep theirs. public stays on the path for extensions.
+    schema := "baseline_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+    if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+        t.Fatalf("failed to create schema: %v", err)
+    }
+    defer admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+    separator := " "
+    if strings.Contains(dbURL, "://") {
+        separator = "?"
+        if strings.Contains(dbURL, "?") {
+            separator = "&"
+        }
+    }
+    db, err := gorm.Open("postgres", dbURL+separator+"search_path="+schema+",public")
+    if err != nil {
+        t.Fatalf("failed to connect to test schema: %v", err)
+    }
+    defer db.Close()
+
+    if err := db.AutoMigrate(&baselineLoad{}).Error; err != nil {
+        t.Fatalf("failed to create the baseline schema: %v", err)
+    }
+    legacy := baselineLoad{
+        ID:                uuid.New(),
+        ExternalTMSLoadID: "12345",
+        FreightLoadID:     "FL-1",
+        Status:            `{"code": {"key": "2101", "value": "Tendered"}}`,
+        Customer:          `{"name": "Acme", "region": "midwest"}`,
+        BillTo:            `{}`,
+        Pickup:            `{"scheduledTime": "2026-03-01T15:00:00Z"}`,
+        Consignee:         `{"scheduledTime": "2026-03-03T15:00:00Z"}`,
+        Carrier:           `{}`,
+        RateData:          `{"totalRate": 900}`,
+        Specifications:    `{}`,
+    }
+    if err := db.Create(&legacy).Error; err != nil {
+        t.Fatalf("failed to store a baseline load: %v", err)
+    }
+
+    migrator, err := New(db)
+    if err != nil {
+        t.Fatalf("failed to load migrations: %v", err)
+    }
+    if err := migrator.Up(); err != nil {
+        t.Fatalf("failed to migrate the baseline schema up: %v", err)
+    }
+
+    var load models.Load
+    if err := db.Where("id = ?", legacy.ID).First(&load).Error; err != nil {
+        t.Fatalf("failed to read the migrated load: %v", err)
+    }
+    if load.TMSSyncStatus != models.TMSSyncSynced || load.PickupAt == nil || load.Customer.Name != "Acme" {
+        t.Errorf("unexpected migrated load %+v", load)
+    }
+}
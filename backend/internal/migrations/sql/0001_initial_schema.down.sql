@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS sync_checkpoints;
+DROP TABLE IF EXISTS outbox_messages;
+DROP TABLE IF EXISTS load_status_history;
+DROP TABLE IF EXISTS stops;
+DROP TABLE IF EXISTS loads;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,197 @@
+-- The schema as gorm's AutoMigrate created it, so databases set up before
+-- versioned migrations are adopted as they are.
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    slug varchar(50),
+    name varchar(255),
+    active boolean
+);
+CREATE UNIQUE INDEX IF NOT EXISTS uix_tenants_slug ON tenants (slug);
+
+CREATE TABLE IF NOT EXISTS loads (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    tenant_id uuid,
+    external_tms_load_id varchar(100),
+    freight_load_id varchar(100),
+    status jsonb,
+    customer jsonb,
+    bill_to jsonb,
+    pickup jsonb,
+    consignee jsonb,
+    pickup_at timestamp with time zone,
+    delivery_at timestamp with time zone,
+    carrier jsonb,
+    rate_data jsonb,
+    specifications jsonb,
+    in_pallet_count integer,
+    out_pallet_count integer,
+    num_commodities integer,
+    total_weight numeric,
+    billable_weight numeric,
+    po_nums varchar(255),
+    operator varchar(100),
+    route_miles numeric,
+    cancelled_at timestamp with time zone,
+    tms_provider varchar(50),
+    tms_custom_id varchar(100),
+    tms_sync_status varchar(20),
+    tms_sync_error text,
+    tms_synced_at timestamp with time zone
+);
+-- A loads table AutoMigrate created before tenants, scheduled times,
+-- cancellation and TMS sync state has none of their columns. Add them.
+-- Tenants are assigned by EnsureDefaultTenant, and pickup_at and
+-- delivery_at are filled in by 0002.
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS tenant_id uuid;
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS pickup_at timestamp with time zone;
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS delivery_at timestamp with time zone;
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS cancelled_at timestamp with time zone;
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS tms_provider varchar(50);
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS tms_custom_id varchar(100);
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS tms_sync_status varchar(20);
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS tms_sync_error text;
+ALTER TABLE loads ADD COLUMN IF NOT EXISTS tms_synced_at timestamp with time zone;
+-- Loads created then were created in the TMS as they were saved, so those
+-- with a shipment ID are synced. The provider is left empty, which matches
+-- any provider.
+UPDATE loads
+SET tms_provider = coalesce(tms_provider, ''),
+    tms_custom_id = coalesce(tms_custom_id, ''),
+    tms_sync_error = CASE WHEN coalesce(external_tms_load_id, '') <> '' THEN '' ELSE 'no TMS shipment was recorded for this load' END,
+    tms_sync_status = CASE WHEN coalesce(external_tms_load_id, '') <> '' THEN 'synced' ELSE 'failed' END,
+    tms_synced_at = CASE WHEN coalesce(external_tms_load_id, '') <> '' THEN updated_at END
+WHERE tms_sync_status IS NULL;
+CREATE INDEX IF NOT EXISTS idx_loads_tenant_id ON loads (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_loads_pickup_at ON loads (pickup_at);
+CREATE INDEX IF NOT EXISTS idx_loads_delivery_at ON loads (delivery_at);
+CREATE INDEX IF NOT EXISTS idx_loads_tms_provider ON loads (tms_provider);
+CREATE INDEX IF NOT EXISTS idx_loads_tms_sync_status ON loads (tms_sync_status);
+
+CREATE TABLE IF NOT EXISTS stops (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    load_id uuid,
+    sequence integer,
+    type varchar(10),
+    facility_name varchar(255),
+    address jsonb,
+    contact jsonb,
+    appointment_start timestamp with time zone,
+    appointment_end timestamp with time zone,
+    time_zone varchar(50),
+    commodities jsonb,
+    reference_numbers jsonb,
+    notes text
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stops_load_sequence ON stops (load_id, sequence);
+
+CREATE TABLE IF NOT EXISTS load_status_history (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    load_id uuid,
+    from_status varchar(20),
+    to_status varchar(20),
+    changed_by varchar(100),
+    changed_by_name varchar(100),
+    source varchar(20),
+    reason text
+);
+CREATE INDEX IF NOT EXISTS idx_load_status_history_created_at ON load_status_history (created_at);
+CREATE INDEX IF NOT EXISTS idx_load_status_history_load_id ON load_status_history (load_id);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    load_id uuid,
+    operation varchar(50),
+    status varchar(20),
+    attempts integer,
+    next_attempt_at timestamp with time zone,
+    last_error text,
+    delivered_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_load_id ON outbox_messages (load_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_status ON outbox_messages (status);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_next_attempt_at ON outbox_messages (next_attempt_at);
+
+CREATE TABLE IF NOT EXISTS sync_checkpoints (
+    name varchar(100) PRIMARY KEY,
+    high_water_mark timestamp with time zone,
+    updated_at timestamp with time zone
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    tenant_id uuid,
+    username varchar(100),
+    email varchar(255),
+    password_hash varchar(100),
+    role varchar(30),
+    active boolean,
+    failed_logins integer,
+    locked_until timestamp with time zone,
+    password_changed_at timestamp with time zone,
+    last_login_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_users_tenant_id ON users (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uix_users_username ON users (username);
+
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    user_id uuid,
+    token_hash varchar(64),
+    expires_at timestamp with time zone,
+    used_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uix_password_reset_tokens_token_hash ON password_reset_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    user_id uuid,
+    family_id uuid,
+    token_hash varchar(64),
+    expires_at timestamp with time zone,
+    used_at timestamp with time zone,
+    revoked_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uix_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    token_id varchar(64) PRIMARY KEY,
+    created_at timestamp with time zone,
+    expires_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    tenant_id uuid,
+    name varchar(100),
+    prefix varchar(20),
+    key_hash varchar(64),
+    scopes jsonb,
+    rate_limit_per_minute integer,
+    created_by varchar(100),
+    rotated_at timestamp with time zone,
+    last_used_at timestamp with time zone,
+    expires_at timestamp with time zone,
+    revoked_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys (tenant_id);
+CREATE UNIQUE INDEX IF NOT EXISTS uix_api_keys_key_hash ON api_keys (key_hash);
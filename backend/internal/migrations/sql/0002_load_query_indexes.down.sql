@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_stops_reference_numbers_gin;
+DROP INDEX IF EXISTS idx_loads_consignee_gin;
+DROP INDEX IF EXISTS idx_loads_pickup_gin;
+DROP INDEX IF EXISTS idx_loads_carrier_gin;
+DROP INDEX IF EXISTS idx_loads_customer_gin;
+DROP INDEX IF EXISTS idx_loads_status_gin;
+DROP INDEX IF EXISTS idx_loads_carrier_name;
+DROP INDEX IF EXISTS idx_loads_customer_name;
+DROP INDEX IF EXISTS idx_loads_status_key;
+DROP INDEX IF EXISTS idx_loads_operator;
+DROP INDEX IF EXISTS idx_loads_freight_load_id;
+DROP INDEX IF EXISTS idx_loads_tenant_updated_at_id;
+DROP INDEX IF EXISTS idx_loads_tenant_created_at_id;
+DROP INDEX IF EXISTS idx_loads_updated_at_id;
+DROP INDEX IF EXISTS idx_loads_created_at_id;
@@ -0,0 +1,26 @@
+-- Indexes behind the load list's filters and sort orders: expression
+-- indexes on JSONB fields and GIN indexes for JSONB containment queries.
+
+CREATE INDEX IF NOT EXISTS idx_loads_created_at_id ON loads (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_loads_updated_at_id ON loads (updated_at, id);
+-- Every request lists a single tenant's loads.
+CREATE INDEX IF NOT EXISTS idx_loads_tenant_created_at_id ON loads (tenant_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_loads_tenant_updated_at_id ON loads (tenant_id, updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_loads_freight_load_id ON loads (freight_load_id);
+CREATE INDEX IF NOT EXISTS idx_loads_operator ON loads (operator);
+CREATE INDEX IF NOT EXISTS idx_loads_status_key ON loads ((status->'code'->>'key'));
+CREATE INDEX IF NOT EXISTS idx_loads_customer_name ON loads (lower(customer->>'name') text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_loads_carrier_name ON loads (lower(carrier->>'name') text_pattern_ops);
+CREATE INDEX IF NOT EXISTS idx_loads_status_gin ON loads USING GIN (status jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_loads_customer_gin ON loads USING GIN (customer jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_loads_carrier_gin ON loads USING GIN (carrier jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_loads_pickup_gin ON loads USING GIN (pickup jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_loads_consignee_gin ON loads USING GIN (consignee jsonb_path_ops);
+CREATE INDEX IF NOT EXISTS idx_stops_reference_numbers_gin ON stops USING GIN (reference_numbers jsonb_path_ops);
+
+-- Fill in pickup_at and delivery_at on rows saved before those columns
+-- existed.
+UPDATE loads SET pickup_at = (pickup->>'scheduledTime')::timestamptz
+    WHERE pickup_at IS NULL AND pickup->>'scheduledTime' ~ '^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2})?(\.\d+)?(Z|[+-]\d{2}:\d{2})$';
+UPDATE loads SET delivery_at = (consignee->>'scheduledTime')::timestamptz
+    WHERE delivery_at IS NULL AND consignee->>'scheduledTime' ~ '^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}(:\d{2})?(\.\d+)?(Z|[+-]\d{2}:\d{2})$';
@@ -0,0 +1,2 @@
+-- pg_trgm is left installed; other database objects may use it.
+DROP INDEX IF EXISTS idx_loads_search_trgm;
@@ -0,0 +1,21 @@
+-- Speeds up free-text search. It needs the pg_trgm extension; without it
+-- search still works, it just cannot use an index. The expression must
+-- match models.LoadSearchExpression.
+
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+EXCEPTION WHEN OTHERS THEN
+    RAISE WARNING 'free-text search index not created: %', SQLERRM;
+END
+$$;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+        CREATE INDEX IF NOT EXISTS idx_loads_search_trgm ON loads USING GIN ((coalesce(freight_load_id, '') || ' ' || coalesce(po_nums, '') || ' ' ||
+    coalesce(customer->>'name', '') || ' ' || coalesce(bill_to->>'name', '') || ' ' ||
+    coalesce(carrier->>'name', '')) gin_trgm_ops);
+    END IF;
+END
+$$;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS audit_log (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    tenant_id uuid,
+    sequence bigint NOT NULL,
+    created_at timestamp with time zone,
+    load_id uuid,
+    action varchar(30),
+    actor_id varchar(100),
+    actor_name varchar(100),
+    request_id varchar(64),
+    changes jsonb,
+    prev_hash varchar(64),
+    hash varchar(64)
+);
+CREATE INDEX IF NOT EXISTS idx_audit_log_tenant_id ON audit_log (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_load_id ON audit_log (load_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log (actor_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_request_id ON audit_log (request_id);
+-- Each tenant's chain is numbered without gaps or repeats.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_log_tenant_sequence ON audit_log (tenant_id, sequence);
+
+-- The audit log is append-only in the database too.
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS idempotency_keys;
+DROP INDEX IF EXISTS idx_loads_tenant_freight_load_id;
@@ -0,0 +1,31 @@
+-- A load's freight load ID is unique within its tenant, so a retried create
+-- cannot add a second load for the same order. Cancelled loads do not
+-- count, so an order can be entered again after cancelling it.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM loads
+        WHERE freight_load_id <> '' AND cancelled_at IS NULL
+        GROUP BY tenant_id, freight_load_id HAVING count(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'loads share a freight_load_id within a tenant; cancel or renumber them before migrating'
+            USING HINT = 'SELECT tenant_id, freight_load_id, count(*) FROM loads WHERE freight_load_id <> '''' AND cancelled_at IS NULL GROUP BY 1, 2 HAVING count(*) > 1';
+    END IF;
+END
+$$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loads_tenant_freight_load_id ON loads (tenant_id, freight_load_id)
+    WHERE freight_load_id <> '' AND cancelled_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    tenant_id uuid,
+    actor_id varchar(100),
+    key varchar(255),
+    created_at timestamp with time zone,
+    expires_at timestamp with time zone,
+    request_hash varchar(64),
+    load_id uuid,
+    response text,
+    PRIMARY KEY (tenant_id, actor_id, key)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
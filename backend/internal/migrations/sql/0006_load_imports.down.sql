@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS load_import_rows;
+DROP TABLE IF EXISTS load_imports;
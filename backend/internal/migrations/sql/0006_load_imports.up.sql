@@ -0,0 +1,32 @@
+-- Spreadsheet imports of loads, run in the background, and their rows.
+CREATE TABLE IF NOT EXISTS load_imports (
+    id uuid DEFAULT gen_random_uuid() PRIMARY KEY,
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    tenant_id uuid,
+    file_name varchar(255),
+    status varchar(20),
+    total_rows integer,
+    actor_id varchar(100),
+    actor_name varchar(100),
+    actor_role varchar(50),
+    api_key_id varchar(100),
+    scopes jsonb,
+    request_id varchar(64),
+    claimed_until timestamp with time zone,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_load_imports_tenant_id ON load_imports (tenant_id);
+CREATE INDEX IF NOT EXISTS idx_load_imports_status ON load_imports (status);
+
+CREATE TABLE IF NOT EXISTS load_import_rows (
+    import_id uuid REFERENCES load_imports (id) ON DELETE CASCADE,
+    line integer,
+    freight_load_id varchar(100),
+    status varchar(20),
+    request text,
+    load_id uuid,
+    errors text,
+    PRIMARY KEY (import_id, line)
+);
@@ -0,0 +1,2 @@
+-- Which loads were cancelled by 0007 is not recorded, so they stay cancelled.
+SELECT 1;
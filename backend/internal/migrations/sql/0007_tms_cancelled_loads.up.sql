@@ -0,0 +1,8 @@
+-- Loads cancelled in the TMS were given the cancelled status by the inbound
+-- sync without being cancelled, so they stayed editable and kept their
+-- freight load IDs. Cancel them as the sync now does.
+UPDATE loads
+SET cancelled_at = updated_at,
+    status = jsonb_set(status, '{code}', '{"key": "cancelled", "value": "Cancelled"}')
+WHERE cancelled_at IS NULL
+  AND status->'code'->>'key' IN ('cancelled', '2113');
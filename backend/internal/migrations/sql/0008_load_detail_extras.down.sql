@@ -0,0 +1,32 @@
+-- Put the moved keys back where they were taken from.
+CREATE FUNCTION pg_temp.load_detail_merge(doc jsonb, extra jsonb) RETURNS jsonb AS $$
+DECLARE
+    entry record;
+BEGIN
+    FOR entry IN SELECT key, value FROM jsonb_each(extra) LOOP
+        IF jsonb_typeof(entry.value) = 'object' AND jsonb_typeof(doc->entry.key) = 'object' THEN
+            doc := doc || jsonb_build_object(entry.key, pg_temp.load_detail_merge(doc->entry.key, entry.value));
+        ELSE
+            doc := doc || jsonb_build_object(entry.key, entry.value);
+        END IF;
+    END LOOP;
+    RETURN doc;
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+DO $$
+DECLARE
+    detail record;
+BEGIN
+    FOR detail IN SELECT DISTINCT field FROM load_detail_extras LOOP
+        EXECUTE format(
+            'UPDATE loads SET %I = pg_temp.load_detail_merge(coalesce(%I, ''{}''), load_detail_extras.extra)
+             FROM load_detail_extras
+             WHERE load_detail_extras.load_id = loads.id AND load_detail_extras.field = %L',
+            detail.field, detail.field, detail.field);
+    END LOOP;
+END
+$$;
+
+DROP FUNCTION pg_temp.load_detail_merge(jsonb, jsonb);
+DROP TABLE IF EXISTS load_detail_extras;
@@ -0,0 +1,93 @@
+-- Loads are read into typed documents, which drop the keys they do not
+-- know, so saving a load stored before the documents were typed would lose
+-- whatever else its JSONB columns held. Those keys are moved out to
+-- load_detail_extras first, by load and column, nested where they were.
+-- Values that cannot be read into their typed field, such as an address
+-- stored as a string, are moved out with them.
+CREATE TABLE IF NOT EXISTS load_detail_extras (
+    load_id uuid NOT NULL,
+    field varchar(50) NOT NULL,
+    extra jsonb NOT NULL,
+    moved_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (load_id, field)
+);
+
+-- load_detail_extra returns the keys of doc that shape, an object of the
+-- known keys with the shapes of nested documents as their values, does not
+-- know.
+CREATE FUNCTION pg_temp.load_detail_extra(doc jsonb, shape jsonb) RETURNS jsonb AS $$
+DECLARE
+    entry record;
+    nested jsonb;
+    extra jsonb := '{}';
+BEGIN
+    FOR entry IN SELECT key, value FROM jsonb_each(doc) LOOP
+        IF NOT shape ? entry.key THEN
+            extra := extra || jsonb_build_object(entry.key, entry.value);
+        ELSIF jsonb_typeof(shape->entry.key) = 'object' AND jsonb_typeof(entry.value) = 'object' THEN
+            nested := pg_temp.load_detail_extra(entry.value, shape->entry.key);
+            IF nested <> '{}' THEN
+                extra := extra || jsonb_build_object(entry.key, nested);
+            END IF;
+        ELSIF jsonb_typeof(shape->entry.key) = 'object' AND jsonb_typeof(entry.value) <> 'null' THEN
+            extra := extra || jsonb_build_object(entry.key, entry.value);
+        END IF;
+    END LOOP;
+    RETURN extra;
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- load_detail_known returns doc without the keys load_detail_extra returns.
+CREATE FUNCTION pg_temp.load_detail_known(doc jsonb, shape jsonb) RETURNS jsonb AS $$
+DECLARE
+    entry record;
+    known jsonb := '{}';
+BEGIN
+    FOR entry IN SELECT key, value FROM jsonb_each(doc) LOOP
+        IF NOT shape ? entry.key THEN
+            CONTINUE;
+        ELSIF jsonb_typeof(shape->entry.key) = 'object' AND jsonb_typeof(entry.value) = 'object' THEN
+            known := known || jsonb_build_object(entry.key, pg_temp.load_detail_known(entry.value, shape->entry.key));
+        ELSIF jsonb_typeof(shape->entry.key) <> 'object' OR jsonb_typeof(entry.value) = 'null' THEN
+            known := known || jsonb_build_object(entry.key, entry.value);
+        END IF;
+    END LOOP;
+    RETURN known;
+END
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- The shapes match the documents in models/load_details.go.
+DO $$
+DECLARE
+    address jsonb := '{"street": true, "city": true, "state": true, "zipCode": true}';
+    contact jsonb := '{"name": true, "email": true, "phone": true}';
+    rate jsonb := '{"baseRate": true, "fuelSurcharge": true, "totalRate": true}';
+    detail record;
+BEGIN
+    FOR detail IN SELECT * FROM (VALUES
+        ('status', '{"code": {"key": true, "value": true}, "notes": true, "description": true}'::jsonb),
+        ('customer', jsonb_build_object('name', true, 'accountNumber', true, 'address', address, 'contact', contact)),
+        ('bill_to', jsonb_build_object('name', true, 'accountNumber', true, 'address', address, 'contact', contact)),
+        ('pickup', jsonb_build_object('facilityName', true, 'scheduledTime', true, 'address', address, 'contact', contact)),
+        ('consignee', jsonb_build_object('facilityName', true, 'scheduledTime', true, 'address', address, 'contact', contact)),
+        ('carrier', jsonb_build_object('name', true, 'scac', true, 'contact', contact,
+            'equipment', '{"type": true, "length": true}'::jsonb)),
+        ('rate_data', rate || jsonb_build_object('currency', true, 'carrierRate', rate)),
+        ('specifications', '{"serviceLevel": true, "specialInstructions": true, "temperature": {"min": true, "max": true, "unit": true}}'::jsonb)
+    ) AS shapes (field, shape) LOOP
+        EXECUTE format(
+            'INSERT INTO load_detail_extras (load_id, field, extra)
+             SELECT id, %L, pg_temp.load_detail_extra(%I, $1) FROM loads
+             WHERE jsonb_typeof(%I) = ''object'' AND pg_temp.load_detail_extra(%I, $1) <> ''{}''',
+            detail.field, detail.field, detail.field, detail.field) USING detail.shape;
+        EXECUTE format(
+            'UPDATE loads SET %I = pg_temp.load_detail_known(%I, $1)
+             FROM load_detail_extras
+             WHERE load_detail_extras.load_id = loads.id AND load_detail_extras.field = %L',
+            detail.field, detail.field, detail.field) USING detail.shape;
+    END LOOP;
+END
+$$;
+
+DROP FUNCTION pg_temp.load_detail_known(jsonb, jsonb);
+DROP FUNCTION pg_temp.load_detail_extra(jsonb, jsonb);
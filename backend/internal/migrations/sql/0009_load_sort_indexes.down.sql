@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_loads_tenant_carrier_name_id;
+DROP INDEX IF EXISTS idx_loads_tenant_customer_name_id;
+DROP INDEX IF EXISTS idx_loads_tenant_status_key_id;
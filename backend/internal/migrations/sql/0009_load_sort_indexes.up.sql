@@ -0,0 +1,7 @@
+-- The list's expression sorts. The name indexes of 0002 use
+-- text_pattern_ops for prefix filters and cannot order by the expression,
+-- so each sort gets a plain btree in the (tenant, value, id) order a
+-- tenant's list is read in. The expressions must match loadSortFields.
+CREATE INDEX IF NOT EXISTS idx_loads_tenant_status_key_id ON loads (tenant_id, (status->'code'->>'key'), id);
+CREATE INDEX IF NOT EXISTS idx_loads_tenant_customer_name_id ON loads (tenant_id, lower(customer->>'name'), id);
+CREATE INDEX IF NOT EXISTS idx_loads_tenant_carrier_name_id ON loads (tenant_id, lower(carrier->>'name'), id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_loads_tenant_carrier_scac;
+DROP INDEX IF EXISTS idx_loads_tenant_customer_account;
+ALTER TABLE users DROP COLUMN IF EXISTS party_id;
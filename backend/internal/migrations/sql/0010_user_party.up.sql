@@ -0,0 +1,7 @@
+-- Customer and carrier users only see their own loads: a customer's by the
+-- load's customer account number, a carrier's by the load's carrier SCAC.
+-- party_id holds that account number or SCAC. Existing customer and carrier
+-- users have none and see no loads until an admin sets it.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS party_id varchar(100) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_loads_tenant_customer_account ON loads (tenant_id, (customer->>'accountNumber'));
+CREATE INDEX IF NOT EXISTS idx_loads_tenant_carrier_scac ON loads (tenant_id, (carrier->>'scac'));
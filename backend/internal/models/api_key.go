@@ -0,0 +1,52 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key, so keys are recognisable in logs and
+// secret scanners.
+const APIKeyPrefix = "fbk_"
+
+// APIKey lets another system call the API without signing in. It belongs
+// to a tenant and is allowed only its scopes. Only a hash of the key is
+// stored; Prefix is the start of the key, kept to tell keys apart.
+type APIKey struct {
+    ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt time.Time
+    UpdatedAt time.Time
+    TenantID  uuid.UUID `gorm:"type:uuid;index"`
+    Name      string    `gorm:"type:varchar(100)"`
+    Prefix    string    `gorm:"type:varchar(20)"`
+    KeyHash   string    `gorm:"type:varchar(64);unique_index"`
+    Scopes    APIKeyScopes `gorm:"type:jsonb"`
+    // RateLimitPerMinute overrides the default request limit when set.
+    RateLimitPerMinute int
+    CreatedBy  string `gorm:"type:varchar(100)"`
+    RotatedAt  *time.Time
+    LastUsedAt *time.Time
+    ExpiresAt  *time.Time
+    RevokedAt  *time.Time
+}
+
+// IsUsable reports whether the key may be used at now.
+func (k *APIKey) IsUsable(now time.Time) bool {
+    return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+type APIKeyScopes []string
+
+func (v APIKeyScopes) Value() (driver.Value, error) {
+    if v == nil {
+        return []byte("[]"), nil
+    }
+    return json.Marshal([]string(v))
+}
+
+func (v *APIKeyScopes) Scan(value interface{}) error {
+    return scanJSONB(value, (*[]string)(v))
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// Audit actions, one per LoadService mutation.
+const (
+    AuditActionCreate = "create"
+    AuditActionUpdate = "update"
+    AuditActionStatus = "status_change"
+    AuditActionCancel = "cancel"
+)
+
+// AuditEntry records one change to a load. Entries are never updated or
+// deleted. Each tenant's entries form a chain: Hash covers the entry and
+// the previous entry's hash, so editing or removing an entry breaks every
+// hash after it.
+type AuditEntry struct {
+    ID        uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    TenantID  uuid.UUID    `gorm:"type:uuid;index"`
+    Sequence  int64        `gorm:"not null"`
+    CreatedAt time.Time    `gorm:"index"`
+    LoadID    uuid.UUID    `gorm:"type:uuid;index"`
+    Action    string       `gorm:"type:varchar(30)"`
+    ActorID   string       `gorm:"type:varchar(100);index"`
+    ActorName string       `gorm:"type:varchar(100)"`
+    RequestID string       `gorm:"type:varchar(64);index"`
+    Changes   AuditChanges `gorm:"type:jsonb"`
+    PrevHash  string       `gorm:"type:varchar(64)"`
+    Hash      string       `gorm:"type:varchar(64)"`
+}
+
+func (AuditEntry) TableName() string {
+    return "audit_log"
+}
+
+// AuditChange is a field's value before and after a change. Fields are
+// named by their dot path in the load's API representation; From is nil
+// for a new load.
+type AuditChange struct {
+    From interface{} `json:"from"`
+    To   interface{} `json:"to"`
+}
+
+type AuditChanges map[string]AuditChange
+
+func (v AuditChanges) Value() (driver.Value, error) {
+    if v == nil {
+        return []byte("{}"), nil
+    }
+    return json.Marshal(map[string]AuditChange(v))
+}
+
+func (v *AuditChanges) Scan(value interface{}) error {
+    return scanJSONB(value, (*map[string]AuditChange)(v))
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// IdempotencyKey records the response to a request sent with an
+// Idempotency-Key header, so that a retry of the request gets the same
+// response instead of repeating it. Keys belong to the actor that sent
+// them and expire after a while.
+type IdempotencyKey struct {
+    TenantID    uuid.UUID `gorm:"type:uuid;primary_key"`
+    ActorID     string    `gorm:"type:varchar(100);primary_key"`
+    Key         string    `gorm:"type:varchar(255);primary_key"`
+    CreatedAt   time.Time
+    ExpiresAt   time.Time `gorm:"index"`
+    // RequestHash tells a retry from a different request reusing the key.
+    RequestHash string    `gorm:"type:varchar(64)"`
+    LoadID      uuid.UUID `gorm:"type:uuid"`
+    Response    string    `gorm:"type:text"`
+}
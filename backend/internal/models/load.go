@@ -12,16 +12,21 @@ type Load struct {
     ID               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
     CreatedAt        time.Time
     UpdatedAt        time.Time
+    TenantID         uuid.UUID      `gorm:"type:uuid;index"`
     ExternalTMSLoadID string        `gorm:"type:varchar(100)"`
     FreightLoadID    string         `gorm:"type:varchar(100)"`
-    Status           JSON           `gorm:"type:jsonb"`
-    Customer         JSON           `gorm:"type:jsonb"`
-    BillTo          JSON           `gorm:"type:jsonb"`
-    Pickup          JSON           `gorm:"type:jsonb"`
-    Consignee       JSON           `gorm:"type:jsonb"`
-    Carrier         JSON           `gorm:"type:jsonb"`
-    RateData        JSON           `gorm:"type:jsonb"`
-    Specifications  JSON           `gorm:"type:jsonb"`
+    Status           LoadStatus     `gorm:"type:jsonb"`
+    Customer         Party          `gorm:"type:jsonb"`
+    BillTo          Party          `gorm:"type:jsonb"`
+    Pickup          Location       `gorm:"type:jsonb"`
+    Consignee       Location       `gorm:"type:jsonb"`
+    // PickupAt and DeliveryAt copy the pickup and consignee scheduled times
+    // so loads can be filtered and sorted on them; see BeforeSave.
+    PickupAt        *time.Time     `gorm:"index"`
+    DeliveryAt      *time.Time     `gorm:"index"`
+    Carrier         Carrier        `gorm:"type:jsonb"`
+    RateData        RateData       `gorm:"type:jsonb"`
+    Specifications  Specifications `gorm:"type:jsonb"`
     InPalletCount   int
     OutPalletCount  int
     NumCommodities  int
@@ -30,8 +35,39 @@ type Load struct {
     PoNums          string         `gorm:"type:varchar(255)"`
     Operator        string         `gorm:"type:varchar(100)"`
     RouteMiles      float64
+    CancelledAt     *time.Time
+    TMSProvider     string         `gorm:"type:varchar(50);index"`
+    TMSCustomID     string         `gorm:"type:varchar(100)"`
+    TMSSyncStatus   string         `gorm:"type:varchar(20);index"`
+    TMSSyncError    string         `gorm:"type:text"`
+    TMSSyncedAt     *time.Time
+    // Stops are stored in their own table and loaded explicitly.
+    Stops           []Stop         `gorm:"-"`
 }
 
+// BeforeSave keeps PickupAt and DeliveryAt in step with the scheduled times.
+func (l *Load) BeforeSave() error {
+    l.PickupAt = scheduledAtOrNil(l.Pickup)
+    l.DeliveryAt = scheduledAtOrNil(l.Consignee)
+    return nil
+}
+
+func scheduledAtOrNil(location Location) *time.Time {
+    t, err := location.ScheduledAt()
+    if err != nil {
+        return nil
+    }
+    t = t.UTC()
+    return &t
+}
+
+// TMS sync states recorded on a load after each push to the TMS.
+const (
+    TMSSyncPending = "pending"
+    TMSSyncSynced  = "synced"
+    TMSSyncFailed  = "failed"
+)
+
 // JSON is a wrapper for handling JSON fields
 type JSON map[string]interface{}
 
@@ -0,0 +1,210 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "strconv"
+    "time"
+)
+
+// Typed JSONB documents stored on a load. Their JSON field names match the
+// free-form objects the API accepted before, so existing clients and rows
+// keep working; the binding tags are checked by gin when a request is bound.
+// Keys a document does not know are dropped when it is read, so keys that
+// older rows held beyond these were moved out to load_detail_extras by
+// migration 0008.
+
+type Address struct {
+    Street  string `json:"street" binding:"max=255"`
+    City    string `json:"city" binding:"max=100"`
+    State   string `json:"state" binding:"max=50"`
+    ZipCode string `json:"zipCode" binding:"max=20"`
+}
+
+type Contact struct {
+    Name  string `json:"name" binding:"max=255"`
+    Email string `json:"email,omitempty" binding:"omitempty,email"`
+    Phone string `json:"phone" binding:"max=50"`
+}
+
+// Party is a customer or bill-to account.
+type Party struct {
+    Name          string  `json:"name" binding:"max=255"`
+    AccountNumber string  `json:"accountNumber" binding:"max=100"`
+    Address       Address `json:"address"`
+    Contact       Contact `json:"contact"`
+}
+
+// Location is a pickup or delivery point.
+type Location struct {
+    FacilityName  string  `json:"facilityName" binding:"max=255"`
+    ScheduledTime string  `json:"scheduledTime" binding:"required,scheduledtime"`
+    Address       Address `json:"address"`
+    Contact       Contact `json:"contact"`
+}
+
+// ScheduledAt parses ScheduledTime.
+func (l Location) ScheduledAt() (time.Time, error) {
+    return ParseScheduledTime(l.ScheduledTime)
+}
+
+// scheduledTimeLayouts are the accepted scheduledTime formats. Besides
+// RFC 3339, browsers' datetime-local inputs omit the seconds.
+var scheduledTimeLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00"}
+
+func ParseScheduledTime(raw string) (time.Time, error) {
+    if raw == "" {
+        return time.Time{}, fmt.Errorf("scheduledTime is required")
+    }
+    for _, layout := range scheduledTimeLayouts {
+        if t, err := time.Parse(layout, raw); err == nil {
+            return t, nil
+        }
+    }
+    return time.Time{}, fmt.Errorf("scheduledTime must be RFC3339: %q", raw)
+}
+
+type Equipment struct {
+    Type   string `json:"type" binding:"max=50"`
+    Length string `json:"length" binding:"max=20"`
+}
+
+// UnmarshalJSON also accepts a numeric length, which older clients sent.
+func (e *Equipment) UnmarshalJSON(data []byte) error {
+    var raw struct {
+        Type   string          `json:"type"`
+        Length json.RawMessage `json:"length"`
+    }
+    if err := json.Unmarshal(data, &raw); err != nil {
+        return err
+    }
+
+    e.Type = raw.Type
+    e.Length = ""
+    if len(raw.Length) == 0 || string(raw.Length) == "null" {
+        return nil
+    }
+    if err := json.Unmarshal(raw.Length, &e.Length); err == nil {
+        return nil
+    }
+    var length float64
+    if err := json.Unmarshal(raw.Length, &length); err != nil {
+        return fmt.Errorf("equipment length must be a string or a number")
+    }
+    e.Length = strconv.FormatFloat(length, 'f', -1, 64)
+    return nil
+}
+
+type Carrier struct {
+    Name      string    `json:"name" binding:"max=255"`
+    SCAC      string    `json:"scac" binding:"omitempty,min=2,max=4"`
+    Contact   Contact   `json:"contact"`
+    Equipment Equipment `json:"equipment"`
+}
+
+// RateData is what the customer is billed for a load and, in CarrierRate,
+// what the carrier is paid.
+type RateData struct {
+    BaseRate      float64 `json:"baseRate" binding:"gte=0"`
+    FuelSurcharge float64 `json:"fuelSurcharge" binding:"gte=0"`
+    TotalRate     float64 `json:"totalRate" binding:"gte=0"`
+    Currency      string  `json:"currency" binding:"omitempty,len=3"`
+    CarrierRate   *Rate   `json:"carrierRate,omitempty"`
+}
+
+type Rate struct {
+    BaseRate      float64 `json:"baseRate" binding:"gte=0"`
+    FuelSurcharge float64 `json:"fuelSurcharge" binding:"gte=0"`
+    TotalRate     float64 `json:"totalRate" binding:"gte=0"`
+}
+
+type Temperature struct {
+    Min  float64 `json:"min"`
+    Max  float64 `json:"max"`
+    Unit string  `json:"unit" binding:"omitempty,oneof=F C"`
+}
+
+type Specifications struct {
+    ServiceLevel        string      `json:"serviceLevel" binding:"max=100"`
+    SpecialInstructions string      `json:"specialInstructions"`
+    Temperature         Temperature `json:"temperature"`
+}
+
+type LoadStatusCode struct {
+    Key   string `json:"key"`
+    Value string `json:"value"`
+}
+
+type LoadStatus struct {
+    Code        LoadStatusCode `json:"code"`
+    Notes       string         `json:"notes"`
+    Description string         `json:"description"`
+}
+
+func (v LoadStatus) Value() (driver.Value, error) {
+    return json.Marshal(v)
+}
+
+func (v *LoadStatus) Scan(value interface{}) error {
+    return scanJSONB(value, v)
+}
+
+func (v Party) Value() (driver.Value, error) {
+    return json.Marshal(v)
+}
+
+func (v *Party) Scan(value interface{}) error {
+    return scanJSONB(value, v)
+}
+
+func (v Location) Value() (driver.Value, error) {
+    return json.Marshal(v)
+}
+
+func (v *Location) Scan(value interface{}) error {
+    return scanJSONB(value, v)
+}
+
+func (v Carrier) Value() (driver.Value, error) {
+    return json.Marshal(v)
+}
+
+func (v *Carrier) Scan(value interface{}) error {
+    return scanJSONB(value, v)
+}
+
+func (v RateData) Value() (driver.Value, error) {
+    return json.Marshal(v)
+}
+
+func (v *RateData) Scan(value interface{}) error {
+    return scanJSONB(value, v)
+}
+
+func (v Specifications) Value() (driver.Value, error) {
+    return json.Marshal(v)
+}
+
+func (v *Specifications) Scan(value interface{}) error {
+    return scanJSONB(value, v)
+}
+
+// scanJSONB decodes a JSONB column into dest. NULL leaves dest zeroed.
+func scanJSONB(value interface{}, dest interface{}) error {
+    var data []byte
+    switch v := value.(type) {
+    case nil:
+        return nil
+    case []byte:
+        data = v
+    case string:
+        data = []byte(v)
+    default:
+        return fmt.Errorf("failed to unmarshal JSONB value: %v", value)
+    }
+    if err := json.Unmarshal(data, dest); err != nil {
+        return fmt.Errorf("failed to unmarshal JSONB value: %w", err)
+    }
+    return nil
+}
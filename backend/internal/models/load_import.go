@@ -0,0 +1,57 @@
+package models
+
+import (
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// LoadImport is a spreadsheet of loads being created in the background.
+// It runs as the actor that uploaded it, whose access is checked again as
+// the import is worked through.
+type LoadImport struct {
+    ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt    time.Time
+    UpdatedAt    time.Time
+    TenantID     uuid.UUID `gorm:"type:uuid;index"`
+    FileName     string    `gorm:"type:varchar(255)"`
+    Status       string    `gorm:"type:varchar(20);index"`
+    TotalRows    int
+    ActorID      string       `gorm:"type:varchar(100)"`
+    ActorName    string       `gorm:"type:varchar(100)"`
+    ActorRole    string       `gorm:"type:varchar(50)"`
+    APIKeyID     string       `gorm:"type:varchar(100)"`
+    Scopes       APIKeyScopes `gorm:"type:jsonb"`
+    RequestID    string       `gorm:"type:varchar(64)"`
+    // ClaimedUntil hides a running import from other workers until it
+    // passes, after which an abandoned import is picked up again.
+    ClaimedUntil *time.Time
+    StartedAt    *time.Time
+    FinishedAt   *time.Time
+}
+
+// LoadImportRow is one row of an import: the create request it was read
+// as, and once processed, the load created or why it was not. Line is the
+// row's number in the spreadsheet, counting the header as 1.
+type LoadImportRow struct {
+    ImportID      uuid.UUID  `gorm:"type:uuid;primary_key"`
+    Line          int        `gorm:"primary_key;auto_increment:false"`
+    FreightLoadID string     `gorm:"type:varchar(100)"`
+    Status        string     `gorm:"type:varchar(20)"`
+    Request       string     `gorm:"type:text"`
+    LoadID        *uuid.UUID `gorm:"type:uuid"`
+    // Errors holds the row's field errors as JSON.
+    Errors        string     `gorm:"type:text"`
+}
+
+const (
+    LoadImportQueued    = "queued"
+    LoadImportRunning   = "running"
+    LoadImportCompleted = "completed"
+)
+
+const (
+    LoadImportRowPending = "pending"
+    LoadImportRowCreated = "created"
+    LoadImportRowFailed  = "failed"
+)
@@ -0,0 +1,8 @@
+package models
+
+// LoadSearchExpression is the text the load list's free-text search matches
+// against. It is built from immutable operators only so that it can be
+// indexed; migration 0003_load_search_index indexes the same expression.
+const LoadSearchExpression = `(coalesce(freight_load_id, '') || ' ' || coalesce(po_nums, '') || ' ' ||
+    coalesce(customer->>'name', '') || ' ' || coalesce(bill_to->>'name', '') || ' ' ||
+    coalesce(carrier->>'name', ''))`
@@ -0,0 +1,111 @@
+package models
+
+import (
+    "strings"
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// Load lifecycle statuses, stored as the load's status code key.
+const (
+    StatusQuoted     = "quoted"
+    StatusTendered   = "tendered"
+    StatusCovered    = "covered"
+    StatusDispatched = "dispatched"
+    StatusAtPickup   = "at_pickup"
+    StatusInTransit  = "in_transit"
+    StatusDelivered  = "delivered"
+    StatusInvoiced   = "invoiced"
+    StatusCancelled  = "cancelled"
+)
+
+var statusLabels = map[string]string{
+    StatusQuoted:     "Quoted",
+    StatusTendered:   "Tendered",
+    StatusCovered:    "Covered",
+    StatusDispatched: "Dispatched",
+    StatusAtPickup:   "At Pickup",
+    StatusInTransit:  "In Transit",
+    StatusDelivered:  "Delivered",
+    StatusInvoiced:   "Invoiced",
+    StatusCancelled:  "Cancelled",
+}
+
+// statusTransitions lists the statuses each status may move to. Besides the
+// forward path, a load can step back when a tender is pulled or a carrier
+// falls off, and can be cancelled until it is picked up.
+var statusTransitions = map[string][]string{
+    StatusQuoted:     {StatusTendered, StatusCancelled},
+    StatusTendered:   {StatusCovered, StatusQuoted, StatusCancelled},
+    StatusCovered:    {StatusDispatched, StatusTendered, StatusCancelled},
+    StatusDispatched: {StatusAtPickup, StatusInTransit, StatusCovered, StatusCancelled},
+    StatusAtPickup:   {StatusInTransit, StatusDispatched, StatusCancelled},
+    StatusInTransit:  {StatusDelivered},
+    StatusDelivered:  {StatusInvoiced},
+    StatusInvoiced:   {},
+    StatusCancelled:  {},
+}
+
+// Statuses returns every status in lifecycle order.
+func Statuses() []string {
+    return []string{
+        StatusQuoted, StatusTendered, StatusCovered, StatusDispatched, StatusAtPickup,
+        StatusInTransit, StatusDelivered, StatusInvoiced, StatusCancelled,
+    }
+}
+
+// ParseStatus accepts a status key or its label, case-insensitively.
+func ParseStatus(value string) (string, bool) {
+    normalized := strings.ToLower(strings.TrimSpace(value))
+    if _, ok := statusLabels[normalized]; ok {
+        return normalized, true
+    }
+    for status, label := range statusLabels {
+        if strings.EqualFold(label, normalized) {
+            return status, true
+        }
+    }
+    return "", false
+}
+
+func StatusLabel(status string) string {
+    return statusLabels[status]
+}
+
+func CanTransition(from, to string) bool {
+    for _, next := range statusTransitions[from] {
+        if next == to {
+            return true
+        }
+    }
+    return false
+}
+
+// NextStatuses returns the statuses a load in status may move to.
+func NextStatuses(status string) []string {
+    return append([]string(nil), statusTransitions[status]...)
+}
+
+// Sources of a status change.
+const (
+    StatusSourceAPI = "api"
+    StatusSourceTMS = "tms"
+)
+
+// LoadStatusHistory records one status change of a load.
+type LoadStatusHistory struct {
+    ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt     time.Time `gorm:"index"`
+    LoadID        uuid.UUID `gorm:"type:uuid;index"`
+    FromStatus    string    `gorm:"type:varchar(20)"`
+    ToStatus      string    `gorm:"type:varchar(20)"`
+    ChangedBy     string    `gorm:"type:varchar(100)"`
+    ChangedByName string    `gorm:"type:varchar(100)"`
+    Source        string    `gorm:"type:varchar(20)"`
+    Reason        string    `gorm:"type:text"`
+}
+
+func (LoadStatusHistory) TableName() string {
+    return "load_status_history"
+}
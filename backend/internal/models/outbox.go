@@ -0,0 +1,34 @@
+package models
+
+import (
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// OutboxMessage is a TMS call queued in the same transaction as the load
+// change that caused it, delivered later by the outbox worker.
+type OutboxMessage struct {
+    ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt     time.Time
+    UpdatedAt     time.Time
+    LoadID        uuid.UUID `gorm:"type:uuid;index"`
+    Operation     string    `gorm:"type:varchar(50)"`
+    Status        string    `gorm:"type:varchar(20);index"`
+    Attempts      int
+    NextAttemptAt time.Time `gorm:"index"`
+    LastError     string    `gorm:"type:text"`
+    DeliveredAt   *time.Time
+}
+
+const (
+    OutboxOpCreateShipment = "create_shipment"
+    OutboxOpUpdateShipment = "update_shipment"
+    OutboxOpDeleteShipment = "delete_shipment"
+)
+
+const (
+    OutboxPending   = "pending"
+    OutboxDelivered = "delivered"
+    OutboxDead      = "dead"
+)
@@ -0,0 +1,145 @@
+package models
+
+// Roles a user can hold.
+const (
+    RoleAdmin      = "admin"
+    RoleBroker     = "broker"
+    RoleDispatcher = "dispatcher"
+    RoleAccounting = "accounting"
+    RoleReadOnly   = "read_only"
+    // RoleCarrier and RoleCustomer are for external users from a carrier
+    // or a customer.
+    RoleCarrier  = "carrier"
+    RoleCustomer = "customer"
+)
+
+var roles = []string{RoleAdmin, RoleBroker, RoleDispatcher, RoleAccounting, RoleReadOnly, RoleCarrier, RoleCustomer}
+
+// Roles returns every role.
+func Roles() []string {
+    return append([]string(nil), roles...)
+}
+
+// IsPartyRole reports whether role only sees the loads of the party its
+// users belong to, named by User.PartyID.
+func IsPartyRole(role string) bool {
+    return role == RoleCarrier || role == RoleCustomer
+}
+
+func IsRole(role string) bool {
+    _, ok := rolePermissions[role]
+    return ok
+}
+
+// Permission is an action a role may be allowed.
+type Permission string
+
+const (
+    PermLoadsRead   Permission = "loads:read"
+    PermLoadsCreate Permission = "loads:create"
+    PermLoadsUpdate Permission = "loads:update"
+    PermLoadsStatus Permission = "loads:status"
+    PermLoadsCancel Permission = "loads:cancel"
+    // PermCustomerRatesRead and PermCarrierRatesRead show the rate billed to
+    // the customer and the rate paid to the carrier; without them the rate
+    // is left out of load responses.
+    PermCustomerRatesRead Permission = "rates:customer:read"
+    PermCarrierRatesRead  Permission = "rates:carrier:read"
+    // PermRatesWrite is needed to set rateData when creating or updating a
+    // load.
+    PermRatesWrite  Permission = "rates:write"
+    PermUsersManage Permission = "users:manage"
+    // PermOperations covers the outbox and reconciliation endpoints.
+    PermOperations Permission = "operations:manage"
+    // PermTenantsManage is only honoured for the default tenant's users.
+    PermTenantsManage Permission = "tenants:manage"
+    PermAPIKeysManage Permission = "api_keys:manage"
+    // PermAuditRead shows a load's audit trail; PermAuditSearch searches
+    // and verifies the whole tenant's audit log.
+    PermAuditRead   Permission = "audit:read"
+    PermAuditSearch Permission = "audit:search"
+)
+
+// apiKeyScopes are the permissions an API key may be given. Managing
+// users, keys, tenants and operations needs a signed-in user.
+var apiKeyScopes = []Permission{
+    PermLoadsRead, PermLoadsCreate, PermLoadsUpdate, PermLoadsStatus, PermLoadsCancel,
+    PermCustomerRatesRead, PermCarrierRatesRead, PermRatesWrite,
+}
+
+var rolePermissions = map[string][]Permission{
+    RoleAdmin: {
+        PermLoadsRead, PermLoadsCreate, PermLoadsUpdate, PermLoadsStatus, PermLoadsCancel,
+        PermCustomerRatesRead, PermCarrierRatesRead, PermRatesWrite,
+        PermUsersManage, PermOperations, PermTenantsManage, PermAPIKeysManage,
+        PermAuditRead, PermAuditSearch,
+    },
+    RoleBroker: {
+        PermLoadsRead, PermLoadsCreate, PermLoadsUpdate, PermLoadsStatus, PermLoadsCancel,
+        PermCustomerRatesRead, PermCarrierRatesRead, PermRatesWrite,
+        PermAuditRead,
+    },
+    RoleDispatcher: {
+        PermLoadsRead, PermLoadsUpdate, PermLoadsStatus,
+        PermCarrierRatesRead,
+        PermAuditRead,
+    },
+    RoleAccounting: {
+        PermLoadsRead, PermLoadsUpdate,
+        PermCustomerRatesRead, PermCarrierRatesRead, PermRatesWrite,
+        PermAuditRead,
+    },
+    RoleReadOnly: {
+        PermLoadsRead,
+        PermCustomerRatesRead, PermCarrierRatesRead,
+        PermAuditRead,
+    },
+    RoleCarrier: {
+        PermLoadsRead,
+        PermCarrierRatesRead,
+    },
+    RoleCustomer: {
+        PermLoadsRead,
+        PermCustomerRatesRead,
+    },
+}
+
+// HasPermission reports whether role is allowed permission. Unknown roles
+// have no permissions.
+func HasPermission(role string, permission Permission) bool {
+    for _, p := range rolePermissions[role] {
+        if p == permission {
+            return true
+        }
+    }
+    return false
+}
+
+// IsAPIKeyScope reports whether an API key may be given scope.
+func IsAPIKeyScope(scope string) bool {
+    for _, p := range apiKeyScopes {
+        if string(p) == scope {
+            return true
+        }
+    }
+    return false
+}
+
+// APIKeyScopeNames returns every scope an API key may be given.
+func APIKeyScopeNames() []string {
+    names := make([]string, len(apiKeyScopes))
+    for i, p := range apiKeyScopes {
+        names[i] = string(p)
+    }
+    return names
+}
+
+// ScopesAllow reports whether an API key's scopes include permission.
+func ScopesAllow(scopes []string, permission Permission) bool {
+    for _, scope := range scopes {
+        if scope == string(permission) {
+            return true
+        }
+    }
+    return false
+}
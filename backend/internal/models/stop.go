@@ -0,0 +1,95 @@
+package models
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// Stop is one pickup or drop on a load's route. Stops are numbered 1..n by
+// Sequence in route order.
+type Stop struct {
+    ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt        time.Time
+    UpdatedAt        time.Time
+    LoadID           uuid.UUID  `gorm:"type:uuid;unique_index:idx_stops_load_sequence"`
+    Sequence         int        `gorm:"unique_index:idx_stops_load_sequence"`
+    Type             string     `gorm:"type:varchar(10)"`
+    FacilityName     string     `gorm:"type:varchar(255)"`
+    Address          Address    `gorm:"type:jsonb"`
+    Contact          Contact    `gorm:"type:jsonb"`
+    AppointmentStart time.Time
+    // AppointmentEnd is nil for a fixed appointment rather than a window.
+    AppointmentEnd   *time.Time
+    TimeZone         string           `gorm:"type:varchar(50)"`
+    Commodities      Commodities      `gorm:"type:jsonb"`
+    ReferenceNumbers ReferenceNumbers `gorm:"type:jsonb"`
+    Notes            string           `gorm:"type:text"`
+}
+
+const (
+    StopTypePickup = "pickup"
+    StopTypeDrop   = "drop"
+)
+
+// Commodity is freight picked up or dropped at a stop.
+type Commodity struct {
+    Description string  `json:"description" binding:"required,max=255"`
+    Quantity    int     `json:"quantity" binding:"gte=0"`
+    PackageType string  `json:"packageType" binding:"max=50"`
+    Weight      float64 `json:"weight" binding:"gte=0"`
+    WeightUnit  string  `json:"weightUnit" binding:"omitempty,oneof=lb kg"`
+}
+
+// ReferenceNumber is a typed reference such as a PO, BOL or appointment
+// number.
+type ReferenceNumber struct {
+    Type  string `json:"type" binding:"required,max=50"`
+    Value string `json:"value" binding:"required,max=100"`
+}
+
+const ReferenceTypePO = "po"
+
+type Commodities []Commodity
+
+type ReferenceNumbers []ReferenceNumber
+
+func (v Address) Value() (driver.Value, error) {
+    return json.Marshal(v)
+}
+
+func (v *Address) Scan(value interface{}) error {
+    return scanJSONB(value, v)
+}
+
+func (v Contact) Value() (driver.Value, error) {
+    return json.Marshal(v)
+}
+
+func (v *Contact) Scan(value interface{}) error {
+    return scanJSONB(value, v)
+}
+
+func (v Commodities) Value() (driver.Value, error) {
+    if v == nil {
+        return []byte("[]"), nil
+    }
+    return json.Marshal([]Commodity(v))
+}
+
+func (v *Commodities) Scan(value interface{}) error {
+    return scanJSONB(value, (*[]Commodity)(v))
+}
+
+func (v ReferenceNumbers) Value() (driver.Value, error) {
+    if v == nil {
+        return []byte("[]"), nil
+    }
+    return json.Marshal([]ReferenceNumber(v))
+}
+
+func (v *ReferenceNumbers) Scan(value interface{}) error {
+    return scanJSONB(value, (*[]ReferenceNumber)(v))
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// SyncCheckpoint records how far an incremental sync job has progressed.
+type SyncCheckpoint struct {
+    Name          string `gorm:"type:varchar(100);primary_key"`
+    HighWaterMark time.Time
+    UpdatedAt     time.Time
+}
+
+const ShipmentSyncCheckpoint = "tms_shipments"
@@ -0,0 +1,23 @@
+package models
+
+import (
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// DefaultTenantSlug names the tenant created on first start. Rows stored
+// before tenants existed belong to it, and its admins operate the whole
+// deployment.
+const DefaultTenantSlug = "default"
+
+// Tenant is a brokerage sharing the deployment. Loads and users belong to
+// exactly one tenant and are never visible to another.
+type Tenant struct {
+    ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt time.Time
+    UpdatedAt time.Time
+    Slug      string    `gorm:"type:varchar(50);unique_index"`
+    Name      string    `gorm:"type:varchar(255)"`
+    Active    bool
+}
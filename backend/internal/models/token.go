@@ -0,0 +1,30 @@
+package models
+
+import (
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// RefreshToken is a long-lived token that is exchanged for a new access
+// token. Each refresh replaces it with a new token in the same family, and
+// presenting a used token again revokes the whole family, since it means
+// the token was stolen. Only a hash of the token is stored.
+type RefreshToken struct {
+    ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt time.Time
+    UserID    uuid.UUID `gorm:"type:uuid;index"`
+    FamilyID  uuid.UUID `gorm:"type:uuid;index"`
+    TokenHash string    `gorm:"type:varchar(64);unique_index"`
+    ExpiresAt time.Time
+    UsedAt    *time.Time
+    RevokedAt *time.Time
+}
+
+// RevokedToken is an access token revoked before it expired, by its jti.
+// It can be forgotten once the token has expired.
+type RevokedToken struct {
+    TokenID   string    `gorm:"type:varchar(64);primary_key"`
+    CreatedAt time.Time
+    ExpiresAt time.Time `gorm:"index"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+    "time"
+
+    "github.com/google/uuid"
+)
+
+// User is an account that can sign in to the API.
+type User struct {
+    ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt         time.Time
+    UpdatedAt         time.Time
+    TenantID          uuid.UUID  `gorm:"type:uuid;index"`
+    // Username is stored lower-cased, so sign-in is case-insensitive.
+    Username          string     `gorm:"type:varchar(100);unique_index"`
+    Email             string     `gorm:"type:varchar(255)"`
+    PasswordHash      string     `gorm:"type:varchar(100)"`
+    Role              string     `gorm:"type:varchar(30)"`
+    // PartyID ties a customer or carrier user to their loads: the
+    // customer's account number or the carrier's SCAC.
+    PartyID           string     `gorm:"type:varchar(100);not null;default:''"`
+    Active            bool
+    // FailedLogins counts consecutive failed sign-ins; reaching the limit
+    // locks the account until LockedUntil.
+    FailedLogins      int
+    LockedUntil       *time.Time
+    PasswordChangedAt *time.Time
+    LastLoginAt       *time.Time
+}
+
+// IsLocked reports whether the account is locked at now.
+func (u *User) IsLocked(now time.Time) bool {
+    return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// PasswordResetToken lets a user set a new password once. Only a hash of the
+// token is stored.
+type PasswordResetToken struct {
+    ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+    CreatedAt time.Time
+    UserID    uuid.UUID `gorm:"type:uuid;index"`
+    TokenHash string    `gorm:"type:varchar(64);unique_index"`
+    ExpiresAt time.Time
+    UsedAt    *time.Time
+}
@@ -0,0 +1,59 @@
+// Package requestctx carries per-request values, such as the authenticated
+// actor, from middleware down to services through context.Context.
+package requestctx
+
+import "context"
+
+// Actor is the user a request is made on behalf of. Requests made with an
+// API key have the key's ID and scopes instead of a role.
+type Actor struct {
+    TenantID string
+    UserID   string
+    Username string
+    Role     string
+    // PartyID is the customer or carrier a user of those roles belongs to.
+    PartyID  string
+    APIKeyID string
+    Scopes   []string
+}
+
+// SystemActor is recorded for changes made by background jobs. It has no
+// tenant and is not limited to one.
+var SystemActor = Actor{UserID: "system", Username: "system"}
+
+// IsSystem reports whether the actor is SystemActor.
+func (a Actor) IsSystem() bool {
+    return a.UserID == SystemActor.UserID && a.APIKeyID == ""
+}
+
+type actorKey struct{}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+    return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// WithSystem marks ctx as a background job's, run as SystemActor.
+func WithSystem(ctx context.Context) context.Context {
+    return WithActor(ctx, SystemActor)
+}
+
+// ActorFrom returns the context's actor. ok is false when none was
+// attached: requests get one from the auth middleware and background jobs
+// must ask for SystemActor with WithSystem, so a context without one is
+// never treated as either.
+func ActorFrom(ctx context.Context) (actor Actor, ok bool) {
+    actor, ok = ctx.Value(actorKey{}).(Actor)
+    return actor, ok
+}
+
+type requestIDKey struct{}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+    return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFrom returns the request's ID, or "" outside a request.
+func RequestIDFrom(ctx context.Context) string {
+    requestID, _ := ctx.Value(requestIDKey{}).(string)
+    return requestID
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/base64"
+    "fmt"
+    "strings"
+    "sync"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+// apiKeyLastUsedInterval limits how often a key's last use is written, so
+// a busy integration does not update its row on every request.
+const apiKeyLastUsedInterval = time.Minute
+
+type APIKeyServiceConfig struct {
+    // RateLimitPerMinute applies to keys without their own limit.
+    RateLimitPerMinute int
+}
+
+// APIKeyService manages the API keys of the signed-in admin's tenant and
+// authenticates requests made with them. Rate limits are counted per
+// process.
+type APIKeyService struct {
+    db     *gorm.DB
+    config APIKeyServiceConfig
+
+    mu       sync.Mutex
+    limiters map[uuid.UUID]*apiKeyLimiter
+}
+
+type apiKeyLimiter struct {
+    ratePerMinute int
+    bucket        *tokenBucket
+}
+
+func NewAPIKeyService(db *gorm.DB, config APIKeyServiceConfig) *APIKeyService {
+    if config.RateLimitPerMinute <= 0 {
+        config.RateLimitPerMinute = 120
+    }
+
+    return &APIKeyService{
+        db:       db,
+        config:   config,
+        limiters: make(map[uuid.UUID]*apiKeyLimiter),
+    }
+}
+
+var errInvalidAPIKey = apperrors.Unauthorized("invalid API key")
+
+// Authenticate returns the key for a request. Revoked and expired keys, and
+// keys of a deactivated tenant, are refused.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*models.APIKey, error) {
+    if !strings.HasPrefix(key, models.APIKeyPrefix) {
+        return nil, errInvalidAPIKey
+    }
+
+    var apiKey models.APIKey
+    err := s.db.Select("api_keys.*").Joins("JOIN tenants ON tenants.id = api_keys.tenant_id AND tenants.active").
+        Where("api_keys.key_hash = ?", hashToken(key)).
+        First(&apiKey).Error
+    if err == gorm.ErrRecordNotFound {
+        return nil, errInvalidAPIKey
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get API key: %w", err)
+    }
+
+    now := time.Now()
+    if !apiKey.IsUsable(now) {
+        return nil, errInvalidAPIKey
+    }
+    if wait := s.limiter(&apiKey).Take(); wait > 0 {
+        return nil, apperrors.RateLimited("API key rate limit exceeded", wait)
+    }
+
+    if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyLastUsedInterval {
+        if err := s.db.Model(&apiKey).UpdateColumn("last_used_at", now).Error; err != nil {
+            return nil, fmt.Errorf("failed to record API key use: %w", err)
+        }
+    }
+    return &apiKey, nil
+}
+
+// limiter returns the key's rate limiter, replacing it when the key's limit
+// has changed.
+func (s *APIKeyService) limiter(apiKey *models.APIKey) *tokenBucket {
+    ratePerMinute := apiKey.RateLimitPerMinute
+    if ratePerMinute <= 0 {
+        ratePerMinute = s.config.RateLimitPerMinute
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    limiter, ok := s.limiters[apiKey.ID]
+    if !ok || limiter.ratePerMinute != ratePerMinute {
+        limiter = &apiKeyLimiter{
+            ratePerMinute: ratePerMinute,
+            bucket:        newTokenBucket(ratePerMinute, ratePerMinute),
+        }
+        s.limiters[apiKey.ID] = limiter
+    }
+    return limiter.bucket
+}
+
+// CreateAPIKey issues a key for the admin's tenant. A key cannot be given a
+// scope its creator does not have.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req *dto.CreateAPIKeyRequest) (*dto.APIKeySecretResponse, error) {
+    actor, err := contextActor(ctx)
+    if err != nil {
+        return nil, err
+    }
+    tenantID, scoped, err := actorTenant(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if !scoped || tenantID == uuid.Nil {
+        return nil, apperrors.Forbidden("a tenant is required to create API keys")
+    }
+    scopes, err := checkAPIKeyScopes(ctx, req.Scopes)
+    if err != nil {
+        return nil, err
+    }
+    if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+        return nil, apperrors.Validation("Invalid expiry",
+            apperrors.FieldError{Field: "expiresAt", Message: "must be in the future"})
+    }
+
+    key, err := generateAPIKey()
+    if err != nil {
+        return nil, err
+    }
+    apiKey := &models.APIKey{
+        ID:                 uuid.New(),
+        TenantID:           tenantID,
+        Name:               strings.TrimSpace(req.Name),
+        Prefix:             key[:len(models.APIKeyPrefix)+8],
+        KeyHash:            hashToken(key),
+        Scopes:             scopes,
+        RateLimitPerMinute: req.RateLimitPerMinute,
+        CreatedBy:          actor.Username,
+        ExpiresAt:          req.ExpiresAt,
+    }
+    if err := s.db.Create(apiKey).Error; err != nil {
+        return nil, fmt.Errorf("failed to create API key: %w", err)
+    }
+
+    return &dto.APIKeySecretResponse{
+        APIKeyResponse: *convertToAPIKeyResponse(apiKey),
+        Key:            key,
+    }, nil
+}
+
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) (*dto.ListAPIKeysResponse, error) {
+    db, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    var apiKeys []models.APIKey
+    if err := db.Order("created_at, id").Find(&apiKeys).Error; err != nil {
+        return nil, fmt.Errorf("failed to list API keys: %w", err)
+    }
+
+    responses := make([]dto.APIKeyResponse, len(apiKeys))
+    for i := range apiKeys {
+        responses[i] = *convertToAPIKeyResponse(&apiKeys[i])
+    }
+    return &dto.ListAPIKeysResponse{APIKeys: responses}, nil
+}
+
+func (s *APIKeyService) GetAPIKey(ctx context.Context, id string) (*dto.APIKeyResponse, error) {
+    db, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    apiKey, err := findAPIKey(db, id)
+    if err != nil {
+        return nil, err
+    }
+    return convertToAPIKeyResponse(apiKey), nil
+}
+
+// RotateAPIKey replaces a key's secret, keeping its scopes and limit. The
+// old secret stops working at once.
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, id string) (*dto.APIKeySecretResponse, error) {
+    key, err := generateAPIKey()
+    if err != nil {
+        return nil, err
+    }
+
+    tx := s.db.Begin()
+    scoped, err := scopeToTenant(ctx, tx)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    apiKey, err := findAPIKey(scoped.Set("gorm:query_option", "FOR UPDATE"), id)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if apiKey.RevokedAt != nil {
+        tx.Rollback()
+        return nil, apperrors.Conflict("API key is revoked")
+    }
+
+    now := time.Now()
+    apiKey.Prefix = key[:len(models.APIKeyPrefix)+8]
+    apiKey.KeyHash = hashToken(key)
+    apiKey.RotatedAt = &now
+    if err := tx.Save(apiKey).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to rotate API key: %w", err)
+    }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit API key rotation: %w", err)
+    }
+
+    return &dto.APIKeySecretResponse{
+        APIKeyResponse: *convertToAPIKeyResponse(apiKey),
+        Key:            key,
+    }, nil
+}
+
+// RevokeAPIKey stops a key from working. The key stays listed as revoked.
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) error {
+    db, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return err
+    }
+    apiKey, err := findAPIKey(db, id)
+    if err != nil {
+        return err
+    }
+    err = s.db.Model(&models.APIKey{}).
+        Where("id = ? AND revoked_at IS NULL", apiKey.ID).
+        Update("revoked_at", time.Now()).Error
+    if err != nil {
+        return fmt.Errorf("failed to revoke API key: %w", err)
+    }
+    return nil
+}
+
+// checkAPIKeyScopes validates and de-duplicates requested scopes.
+func checkAPIKeyScopes(ctx context.Context, requested []string) (models.APIKeyScopes, error) {
+    scopes := make(models.APIKeyScopes, 0, len(requested))
+    seen := make(map[string]bool, len(requested))
+    for _, scope := range requested {
+        scope = strings.TrimSpace(scope)
+        if !models.IsAPIKeyScope(scope) {
+            return nil, apperrors.Validation("Invalid scope",
+                apperrors.FieldError{Field: "scopes", Message: fmt.Sprintf("%q is not one of %s", scope, strings.Join(models.APIKeyScopeNames(), ", "))})
+        }
+        if !actorCan(ctx, models.Permission(scope)) {
+            return nil, apperrors.Forbidden("cannot grant a scope you do not have: " + scope)
+        }
+        if !seen[scope] {
+            seen[scope] = true
+            scopes = append(scopes, scope)
+        }
+    }
+    return scopes, nil
+}
+
+func generateAPIKey() (string, error) {
+    raw := make([]byte, 32)
+    if _, err := rand.Read(raw); err != nil {
+        return "", fmt.Errorf("failed to generate API key: %w", err)
+    }
+    return models.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func findAPIKey(db *gorm.DB, id string) (*models.APIKey, error) {
+    var apiKey models.APIKey
+    if err := db.Where("id = ?", id).First(&apiKey).Error; err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("API key not found")
+        }
+        return nil, fmt.Errorf("failed to get API key: %w", err)
+    }
+    return &apiKey, nil
+}
+
+func convertToAPIKeyResponse(apiKey *models.APIKey) *dto.APIKeyResponse {
+    resp := &dto.APIKeyResponse{
+        ID:                 apiKey.ID.String(),
+        Name:               apiKey.Name,
+        Prefix:             apiKey.Prefix,
+        Scopes:             append([]string{}, apiKey.Scopes...),
+        RateLimitPerMinute: apiKey.RateLimitPerMinute,
+        CreatedBy:          apiKey.CreatedBy,
+        CreatedAt:          apiKey.CreatedAt.Format(time.RFC3339),
+    }
+    if apiKey.RotatedAt != nil {
+        resp.RotatedAt = apiKey.RotatedAt.Format(time.RFC3339)
+    }
+    if apiKey.LastUsedAt != nil {
+        resp.LastUsedAt = apiKey.LastUsedAt.Format(time.RFC3339)
+    }
+    if apiKey.ExpiresAt != nil {
+        resp.ExpiresAt = apiKey.ExpiresAt.Format(time.RFC3339)
+    }
+    if apiKey.RevokedAt != nil {
+        resp.RevokedAt = apiKey.RevokedAt.Format(time.RFC3339)
+    }
+    return resp
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+    "testing"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+)
+
+func TestCheckAPIKeyScopes(t *testing.T) {
+    scopes, err := checkAPIKeyScopes(actorContext(models.RoleAdmin), []string{"loads:read", " loads:create", "loads:read"})
+    if err != nil || len(scopes) != 2 {
+        t.Fatalf("expected two distinct scopes, got %v (%v)", scopes, err)
+    }
+
+    if _, err := checkAPIKeyScopes(actorContext(models.RoleAdmin), []string{string(models.PermUsersManage)}); !apperrors.Is(err, apperrors.CodeValidation) {
+        t.Errorf("expected users:manage to be refused as a key scope, got %v", err)
+    }
+    if _, err := checkAPIKeyScopes(actorContext(models.RoleDispatcher), []string{string(models.PermLoadsCreate)}); !apperrors.Is(err, apperrors.CodeForbidden) {
+        t.Errorf("expected a scope the creator lacks to be refused, got %v", err)
+    }
+}
+
+func TestAPIKeyLimiterFollowsKeyLimit(t *testing.T) {
+    service := NewAPIKeyService(nil, APIKeyServiceConfig{RateLimitPerMinute: 2})
+    apiKey := &models.APIKey{ID: uuid.New()}
+
+    for i := 0; i < 2; i++ {
+        if wait := service.limiter(apiKey).Take(); wait != 0 {
+            t.Fatalf("expected request %d to be allowed, got wait %v", i+1, wait)
+        }
+    }
+    if wait := service.limiter(apiKey).Take(); wait <= 0 {
+        t.Error("expected the third request to be limited")
+    }
+
+    apiKey.RateLimitPerMinute = 10
+    if wait := service.limiter(apiKey).Take(); wait != 0 {
+        t.Errorf("expected a raised limit to take effect, got wait %v", wait)
+    }
+}
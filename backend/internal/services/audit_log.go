@@ -0,0 +1,198 @@
+package services
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "reflect"
+    "time"
+
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+// auditIgnoredFields are left out of audit diffs: they change on every
+// save or track TMS delivery rather than the load itself.
+var auditIgnoredFields = []string{"updatedAt", "tmsSync"}
+
+// auditSnapshot returns a load as audited: its API representation with
+// every rate, as plain JSON values.
+func auditSnapshot(load *models.Load) (map[string]interface{}, error) {
+    resp, err := convertToLoadResponse(requestctx.WithSystem(context.Background()), load)
+    if err != nil {
+        return nil, err
+    }
+    raw, err := json.Marshal(resp)
+    if err != nil {
+        return nil, fmt.Errorf("failed to snapshot load for audit: %w", err)
+    }
+    var snapshot map[string]interface{}
+    if err := json.Unmarshal(raw, &snapshot); err != nil {
+        return nil, fmt.Errorf("failed to snapshot load for audit: %w", err)
+    }
+    for _, field := range auditIgnoredFields {
+        delete(snapshot, field)
+    }
+    return snapshot, nil
+}
+
+// diffSnapshots lists the fields that differ between two snapshots by
+// their dot paths. Objects are compared field by field; arrays, such as
+// stops, as a whole.
+func diffSnapshots(before, after map[string]interface{}) models.AuditChanges {
+    from := make(map[string]interface{})
+    to := make(map[string]interface{})
+    flattenSnapshot("", before, from)
+    flattenSnapshot("", after, to)
+
+    changes := make(models.AuditChanges)
+    for path, value := range from {
+        if other, ok := to[path]; !ok || !reflect.DeepEqual(value, other) {
+            changes[path] = models.AuditChange{From: value, To: to[path]}
+        }
+    }
+    for path, value := range to {
+        if _, ok := from[path]; !ok {
+            changes[path] = models.AuditChange{To: value}
+        }
+    }
+    return changes
+}
+
+func flattenSnapshot(prefix string, object map[string]interface{}, out map[string]interface{}) {
+    for key, value := range object {
+        path := key
+        if prefix != "" {
+            path = prefix + "." + key
+        }
+        if nested, ok := value.(map[string]interface{}); ok && len(nested) > 0 {
+            flattenSnapshot(path, nested, out)
+            continue
+        }
+        out[path] = value
+    }
+}
+
+// recordAudit appends an entry for a change to load to its tenant's chain,
+// in the transaction that makes the change. Updates that change nothing
+// are not recorded. Appends to a chain are serialized by an advisory lock
+// held until the transaction ends.
+func recordAudit(ctx context.Context, tx *gorm.DB, load *models.Load, action string, before, after map[string]interface{}) error {
+    changes := diffSnapshots(before, after)
+    if len(changes) == 0 && action == models.AuditActionUpdate {
+        return nil
+    }
+
+    if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "audit_log:"+load.TenantID.String()).Error; err != nil {
+        return fmt.Errorf("failed to lock audit log: %w", err)
+    }
+    var last models.AuditEntry
+    err := tx.Where("tenant_id = ?", load.TenantID).Order("sequence DESC").First(&last).Error
+    if err != nil && err != gorm.ErrRecordNotFound {
+        return fmt.Errorf("failed to get audit log head: %w", err)
+    }
+
+    actor, err := contextActor(ctx)
+    if err != nil {
+        return err
+    }
+    entry := models.AuditEntry{
+        ID:        uuid.New(),
+        TenantID:  load.TenantID,
+        Sequence:  last.Sequence + 1,
+        // Stored at the database's precision, so the hash can be checked
+        // against the stored row.
+        CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+        LoadID:    load.ID,
+        Action:    action,
+        ActorID:   actor.UserID,
+        ActorName: actor.Username,
+        RequestID: requestctx.RequestIDFrom(ctx),
+        Changes:   changes,
+        PrevHash:  last.Hash,
+    }
+    if entry.Hash, err = auditHash(&entry); err != nil {
+        return err
+    }
+    if err := tx.Create(&entry).Error; err != nil {
+        return fmt.Errorf("failed to record audit entry: %w", err)
+    }
+    return nil
+}
+
+// updateLoadAudited applies updates to a load read in tx, and locked by it,
+// and records the change as made by the context's actor.
+func updateLoadAudited(ctx context.Context, tx *gorm.DB, load *models.Load, updates map[string]interface{}) error {
+    if err := loadStops(tx, load); err != nil {
+        return err
+    }
+    before, err := auditSnapshot(load)
+    if err != nil {
+        return err
+    }
+    if err := tx.Model(load).Updates(updates).Error; err != nil {
+        return fmt.Errorf("failed to update load: %w", err)
+    }
+    after, err := auditSnapshot(load)
+    if err != nil {
+        return err
+    }
+    return recordAudit(ctx, tx, load, models.AuditActionUpdate, before, after)
+}
+
+// auditHash hashes an entry's fields and the previous entry's hash. The
+// changes are hashed in canonical form, since the database does not keep
+// their JSON as written.
+func auditHash(entry *models.AuditEntry) (string, error) {
+    changes, err := canonicalJSON(entry.Changes)
+    if err != nil {
+        return "", fmt.Errorf("failed to hash audit entry: %w", err)
+    }
+    raw, err := json.Marshal(struct {
+        PrevHash  string          `json:"prevHash"`
+        TenantID  string          `json:"tenantId"`
+        Sequence  int64           `json:"sequence"`
+        CreatedAt int64           `json:"createdAt"`
+        LoadID    string          `json:"loadId"`
+        Action    string          `json:"action"`
+        ActorID   string          `json:"actorId"`
+        ActorName string          `json:"actorName"`
+        RequestID string          `json:"requestId"`
+        Changes   json.RawMessage `json:"changes"`
+    }{
+        PrevHash:  entry.PrevHash,
+        TenantID:  entry.TenantID.String(),
+        Sequence:  entry.Sequence,
+        CreatedAt: entry.CreatedAt.UnixMicro(),
+        LoadID:    entry.LoadID.String(),
+        Action:    entry.Action,
+        ActorID:   entry.ActorID,
+        ActorName: entry.ActorName,
+        RequestID: entry.RequestID,
+        Changes:   changes,
+    })
+    if err != nil {
+        return "", fmt.Errorf("failed to hash audit entry: %w", err)
+    }
+    sum := sha256.Sum256(raw)
+    return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalJSON encodes v through plain JSON values, which sorts object
+// keys and normalizes numbers.
+func canonicalJSON(v interface{}) (json.RawMessage, error) {
+    raw, err := json.Marshal(v)
+    if err != nil {
+        return nil, err
+    }
+    var plain interface{}
+    if err := json.Unmarshal(raw, &plain); err != nil {
+        return nil, err
+    }
+    return json.Marshal(plain)
+}
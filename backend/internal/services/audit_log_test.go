@@ -0,0 +1,103 @@
+package services
+
+import (
+    "encoding/json"
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+    before := map[string]interface{}{
+        "poNums":   "PO-1",
+        "customer": map[string]interface{}{"name": "Acme", "id": "c1"},
+        "stops":    []interface{}{"a", "b"},
+        "rateData": map[string]interface{}{"currency": "USD"},
+    }
+    after := map[string]interface{}{
+        "poNums":   "PO-1",
+        "customer": map[string]interface{}{"name": "Globex", "id": "c1"},
+        "stops":    []interface{}{"a"},
+        "rateData": map[string]interface{}{"currency": "USD", "baseRate": 100.0},
+    }
+
+    changes := diffSnapshots(before, after)
+    if len(changes) != 3 {
+        t.Fatalf("expected 3 changes, got %+v", changes)
+    }
+    if change := changes["customer.name"]; change.From != "Acme" || change.To != "Globex" {
+        t.Errorf("unexpected customer.name change %+v", change)
+    }
+    if _, ok := changes["stops"]; !ok {
+        t.Error("expected stops to be compared as a whole")
+    }
+    if change := changes["rateData.baseRate"]; change.From != nil || change.To != 100.0 {
+        t.Errorf("unexpected rateData.baseRate change %+v", change)
+    }
+
+    if created := diffSnapshots(nil, after); len(created) != 6 {
+        t.Errorf("expected every field of a new load, got %+v", created)
+    }
+}
+
+func TestAuditHashSurvivesStorageRoundTrip(t *testing.T) {
+    entry := models.AuditEntry{
+        TenantID:  uuid.New(),
+        Sequence:  1,
+        CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+        LoadID:    uuid.New(),
+        Action:    models.AuditActionUpdate,
+        ActorID:   "u1",
+        ActorName: "alice",
+        Changes: models.AuditChanges{
+            "rateData.baseRate": {From: 100, To: 125.5},
+            "poNums":            {From: "<PO-1>", To: "PO-2"},
+        },
+    }
+    hash, err := auditHash(&entry)
+    if err != nil {
+        t.Fatalf("failed to hash entry: %v", err)
+    }
+    entry.Hash = hash
+
+    // Read back from the database, changes are plain JSON values.
+    raw, err := entry.Changes.Value()
+    if err != nil {
+        t.Fatalf("failed to encode changes: %v", err)
+    }
+    var stored models.AuditEntry
+    stored = entry
+    stored.Changes = nil
+    if err := json.Unmarshal(raw.([]byte), &stored.Changes); err != nil {
+        t.Fatalf("failed to decode changes: %v", err)
+    }
+    if reason := checkAuditEntry(&models.AuditEntry{}, &stored); reason != "" {
+        t.Errorf("expected the stored entry to verify, got %q", reason)
+    }
+
+    stored.ActorName = "mallory"
+    if reason := checkAuditEntry(&models.AuditEntry{}, &stored); reason == "" {
+        t.Error("expected an edited entry to fail verification")
+    }
+    next := models.AuditEntry{Sequence: 3, PrevHash: hash}
+    if reason := checkAuditEntry(&entry, &next); reason == "" {
+        t.Error("expected a gap in the sequence to fail verification")
+    }
+}
+
+func TestAuditPathVisible(t *testing.T) {
+    carrier := actorContext(models.RoleCarrier)
+    if auditPathVisible(carrier, "rateData.baseRate") || !auditPathVisible(carrier, "rateData.carrierRate") {
+        t.Error("expected a carrier to see the carrier rate only")
+    }
+    customer := actorContext(models.RoleCustomer)
+    if auditPathVisible(customer, "rateData.carrierRate") || !auditPathVisible(customer, "rateData.totalRate") {
+        t.Error("expected a customer to see the customer rates only")
+    }
+    if !auditPathVisible(customer, "rateData.currency") || !auditPathVisible(customer, "poNums") {
+        t.Error("expected other fields to be visible")
+    }
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+// auditVerifyBatchSize is how many entries are read at a time when
+// verifying a chain.
+const auditVerifyBatchSize = 500
+
+// AuditService reads the audit log written by LoadService. Entries are
+// limited to the actor's tenant, and rates the actor may not read are left
+// out of the changes.
+type AuditService struct {
+    db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) *AuditService {
+    return &AuditService{
+        db: db,
+    }
+}
+
+func (s *AuditService) GetLoadAudit(ctx context.Context, loadID string) (*dto.LoadAuditResponse, error) {
+    db, err := scopeToVisibleLoads(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    var load models.Load
+    if err := db.Select("id").Where("id = ?", loadID).First(&load).Error; err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("load not found")
+        }
+        return nil, fmt.Errorf("failed to get load: %w", err)
+    }
+
+    var entries []models.AuditEntry
+    if err := s.db.Where("load_id = ?", load.ID).Order("sequence").Find(&entries).Error; err != nil {
+        return nil, fmt.Errorf("failed to get audit log: %w", err)
+    }
+
+    responses := make([]dto.AuditEntryResponse, len(entries))
+    for i := range entries {
+        responses[i] = *convertToAuditEntryResponse(ctx, &entries[i])
+    }
+    return &dto.LoadAuditResponse{
+        LoadID:  load.ID.String(),
+        Entries: responses,
+    }, nil
+}
+
+// SearchAudit lists the tenant's audit entries matching query, newest first.
+// Customer and carrier users only find the entries of loads they can see.
+func (s *AuditService) SearchAudit(ctx context.Context, query *dto.AuditSearchQuery) (*dto.ListAuditEntriesResponse, error) {
+    db, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    party, err := partyConditions(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if where, args := party.where(); where != "" {
+        db = db.Where("load_id IN (SELECT id FROM loads"+where+")", args...)
+    }
+    if query.LoadID != "" {
+        db = db.Where("load_id = ?", query.LoadID)
+    }
+    if query.ActorID != "" {
+        db = db.Where("actor_id = ?", query.ActorID)
+    }
+    if query.Action != "" {
+        db = db.Where("action = ?", query.Action)
+    }
+    if query.RequestID != "" {
+        db = db.Where("request_id = ?", query.RequestID)
+    }
+    if query.From != nil {
+        db = db.Where("created_at >= ?", *query.From)
+    }
+    if query.To != nil {
+        db = db.Where("created_at < ?", *query.To)
+    }
+
+    var total int64
+    if err := db.Model(&models.AuditEntry{}).Count(&total).Error; err != nil {
+        return nil, fmt.Errorf("failed to count audit entries: %w", err)
+    }
+    var entries []models.AuditEntry
+    offset := (query.Page - 1) * query.Size
+    if err := db.Order("created_at DESC, sequence DESC").Offset(offset).Limit(query.Size).Find(&entries).Error; err != nil {
+        return nil, fmt.Errorf("failed to search audit log: %w", err)
+    }
+
+    responses := make([]dto.AuditEntryResponse, len(entries))
+    for i := range entries {
+        responses[i] = *convertToAuditEntryResponse(ctx, &entries[i])
+    }
+    return &dto.ListAuditEntriesResponse{
+        Entries: responses,
+        Total:   total,
+    }, nil
+}
+
+// VerifyAuditLog walks the tenant's chain in order, checking that no entry
+// is missing and that every hash matches its entry.
+func (s *AuditService) VerifyAuditLog(ctx context.Context) (*dto.AuditVerifyResponse, error) {
+    tenantID, scoped, err := actorTenant(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if !scoped || tenantID == uuid.Nil {
+        return nil, apperrors.Forbidden("a tenant is required to verify the audit log")
+    }
+
+    resp := &dto.AuditVerifyResponse{Valid: true}
+    var prev models.AuditEntry
+    for {
+        var entries []models.AuditEntry
+        err := s.db.Where("tenant_id = ? AND sequence > ?", tenantID, prev.Sequence).
+            Order("sequence").Limit(auditVerifyBatchSize).Find(&entries).Error
+        if err != nil {
+            return nil, fmt.Errorf("failed to read audit log: %w", err)
+        }
+
+        for i := range entries {
+            entry := &entries[i]
+            if reason := checkAuditEntry(&prev, entry); reason != "" {
+                resp.Valid = false
+                resp.BrokenAtSequence = entry.Sequence
+                resp.Reason = reason
+                return resp, nil
+            }
+            resp.Entries++
+            prev = *entry
+        }
+        if len(entries) < auditVerifyBatchSize {
+            return resp, nil
+        }
+    }
+}
+
+// checkAuditEntry returns why entry does not follow prev in a chain, or ""
+// if it does. prev is the zero entry for the first in the chain.
+func checkAuditEntry(prev, entry *models.AuditEntry) string {
+    if entry.Sequence != prev.Sequence+1 {
+        return fmt.Sprintf("expected sequence %d", prev.Sequence+1)
+    }
+    if entry.PrevHash != prev.Hash {
+        return "previous hash does not match"
+    }
+    hash, err := auditHash(entry)
+    if err != nil || hash != entry.Hash {
+        return "hash does not match entry"
+    }
+    return ""
+}
+
+// auditPathVisible reports whether the actor may see a change to path.
+// Rates follow the same rules as load responses.
+func auditPathVisible(ctx context.Context, path string) bool {
+    switch {
+    case path == "rateData":
+        return actorCan(ctx, models.PermCustomerRatesRead) && actorCan(ctx, models.PermCarrierRatesRead)
+    case path == "rateData.currency":
+        return true
+    case strings.HasPrefix(path, "rateData.carrierRate"):
+        return actorCan(ctx, models.PermCarrierRatesRead)
+    case strings.HasPrefix(path, "rateData."):
+        return actorCan(ctx, models.PermCustomerRatesRead)
+    }
+    return true
+}
+
+func convertToAuditEntryResponse(ctx context.Context, entry *models.AuditEntry) *dto.AuditEntryResponse {
+    changes := make(map[string]dto.AuditChange, len(entry.Changes))
+    for path, change := range entry.Changes {
+        if auditPathVisible(ctx, path) {
+            changes[path] = dto.AuditChange{From: change.From, To: change.To}
+        }
+    }
+    return &dto.AuditEntryResponse{
+        ID:        entry.ID.String(),
+        Sequence:  entry.Sequence,
+        LoadID:    entry.LoadID.String(),
+        Action:    entry.Action,
+        ActorID:   entry.ActorID,
+        ActorName: entry.ActorName,
+        RequestID: entry.RequestID,
+        Changes:   changes,
+        Hash:      entry.Hash,
+        CreatedAt: entry.CreatedAt.Format(time.RFC3339Nano),
+    }
+}
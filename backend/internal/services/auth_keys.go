@@ -0,0 +1,74 @@
+package services
+
+import (
+    "crypto"
+    "fmt"
+    "os"
+    "strings"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is a key tokens are signed or verified with, identified by
+// the kid header of the tokens it signs. A key loaded from a public key
+// only verifies.
+type SigningKey struct {
+    ID     string
+    Method jwt.SigningMethod
+    // Sign is nil for a verify-only key.
+    Sign   interface{}
+    Verify interface{}
+}
+
+func NewHMACKey(id, secret string) SigningKey {
+    return SigningKey{ID: id, Method: jwt.SigningMethodHS256, Sign: []byte(secret), Verify: []byte(secret)}
+}
+
+// ParseSigningKey reads a key from a spec of the form "HS256:<secret>",
+// "RS256:<PEM file>" or "EdDSA:<PEM file>". A PEM file may hold a private
+// key, or a public key for a key that only verifies.
+func ParseSigningKey(id, spec string) (SigningKey, error) {
+    alg, value, ok := strings.Cut(spec, ":")
+    if !ok || value == "" {
+        return SigningKey{}, fmt.Errorf("key %s: expected ALG:value", id)
+    }
+
+    switch alg {
+    case "HS256":
+        return NewHMACKey(id, value), nil
+    case "RS256":
+        data, err := os.ReadFile(value)
+        if err != nil {
+            return SigningKey{}, fmt.Errorf("key %s: %w", id, err)
+        }
+        key := SigningKey{ID: id, Method: jwt.SigningMethodRS256}
+        if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+            key.Sign, key.Verify = private, &private.PublicKey
+            return key, nil
+        }
+        public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+        if err != nil {
+            return SigningKey{}, fmt.Errorf("key %s: not an RSA key: %w", id, err)
+        }
+        key.Verify = public
+        return key, nil
+    case "EdDSA":
+        data, err := os.ReadFile(value)
+        if err != nil {
+            return SigningKey{}, fmt.Errorf("key %s: %w", id, err)
+        }
+        key := SigningKey{ID: id, Method: jwt.SigningMethodEdDSA}
+        if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+            key.Sign, key.Verify = private, private.(crypto.Signer).Public()
+            return key, nil
+        }
+        public, err := jwt.ParseEdPublicKeyFromPEM(data)
+        if err != nil {
+            return SigningKey{}, fmt.Errorf("key %s: not an Ed25519 key: %w", id, err)
+        }
+        key.Verify = public
+        return key, nil
+    default:
+        return SigningKey{}, fmt.Errorf("key %s: unsupported algorithm %q", id, alg)
+    }
+}
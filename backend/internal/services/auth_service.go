@@ -3,55 +3,313 @@
 package services
 
 import (
-    "time"
+    "context"
+    "crypto/rand"
+    "encoding/base64"
     "errors"
+    "fmt"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
     "github.com/golang-jwt/jwt/v5"
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
 )
 
+type AuthServiceConfig struct {
+    // Keys verify tokens by their kid header. ActiveKeyID signs new tokens
+    // and defaults to the first key; the others stay valid for tokens
+    // signed before a rotation.
+    Keys        []SigningKey
+    ActiveKeyID string
+    // LegacyKeyID verifies tokens without a kid header, which were issued
+    // before keys had IDs.
+    LegacyKeyID     string
+    AccessTokenTTL  time.Duration
+    RefreshTokenTTL time.Duration
+}
+
+// AuthService issues and checks access tokens and the refresh tokens they
+// are renewed with. Without a database it only signs and verifies access
+// tokens.
 type AuthService struct {
-    secretKey []byte
+    db     *gorm.DB
+    config AuthServiceConfig
+    keys   map[string]SigningKey
+    active SigningKey
 }
 
 type Claims struct {
+    TenantID string `json:"tenantId"`
     UserID   string `json:"userId"`
     Username string `json:"username"`
     Role     string `json:"role"`
+    PartyID  string `json:"partyId,omitempty"`
     jwt.RegisteredClaims
 }
 
-func NewAuthService(secretKey string) *AuthService {
-    return &AuthService{
-        secretKey: []byte(secretKey),
+func NewAuthService(db *gorm.DB, config AuthServiceConfig) (*AuthService, error) {
+    if config.AccessTokenTTL <= 0 {
+        config.AccessTokenTTL = 15 * time.Minute
+    }
+    if config.RefreshTokenTTL <= 0 {
+        config.RefreshTokenTTL = 30 * 24 * time.Hour
     }
+    if len(config.Keys) == 0 {
+        return nil, errors.New("no token signing keys configured")
+    }
+    if config.ActiveKeyID == "" {
+        config.ActiveKeyID = config.Keys[0].ID
+    }
+
+    keys := make(map[string]SigningKey, len(config.Keys))
+    for _, key := range config.Keys {
+        if _, ok := keys[key.ID]; ok {
+            return nil, fmt.Errorf("duplicate signing key %s", key.ID)
+        }
+        if secret, ok := key.Verify.([]byte); ok && len(secret) == 0 {
+            return nil, fmt.Errorf("signing key %s has an empty secret", key.ID)
+        }
+        keys[key.ID] = key
+    }
+    active, ok := keys[config.ActiveKeyID]
+    if !ok {
+        return nil, fmt.Errorf("active signing key %s is not configured", config.ActiveKeyID)
+    }
+    if active.Sign == nil {
+        return nil, fmt.Errorf("active signing key %s has no private key", active.ID)
+    }
+
+    return &AuthService{
+        db:     db,
+        config: config,
+        keys:   keys,
+        active: active,
+    }, nil
 }
 
-func (s *AuthService) GenerateToken(userID, username, role string) (string, error) {
+// GenerateToken issues an access token signed with the active key.
+func (s *AuthService) GenerateToken(tenantID, userID, username, role string) (string, error) {
+    token, _, err := s.generateAccessToken(tenantID, userID, username, role, "", time.Now())
+    return token, err
+}
+
+func (s *AuthService) generateAccessToken(tenantID, userID, username, role, partyID string, now time.Time) (string, time.Time, error) {
+    expiresAt := now.Add(s.config.AccessTokenTTL)
     claims := Claims{
+        TenantID: tenantID,
         UserID:   userID,
         Username: username,
         Role:     role,
+        PartyID:  partyID,
         RegisteredClaims: jwt.RegisteredClaims{
-            ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-            IssuedAt:  jwt.NewNumericDate(time.Now()),
+            ID:        uuid.New().String(),
+            ExpiresAt: jwt.NewNumericDate(expiresAt),
+            IssuedAt:  jwt.NewNumericDate(now),
         },
     }
 
-    token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-    return token.SignedString(s.secretKey)
+    token := jwt.NewWithClaims(s.active.Method, claims)
+    token.Header["kid"] = s.active.ID
+    signed, err := token.SignedString(s.active.Sign)
+    if err != nil {
+        return "", time.Time{}, err
+    }
+    return signed, expiresAt, nil
 }
 
+// ValidateToken checks an access token's signature, expiry and that it has
+// not been revoked.
 func (s *AuthService) ValidateToken(tokenString string) (*Claims, error) {
-    token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-        return s.secretKey, nil
-    })
+    claims := &Claims{}
+    token, err := jwt.ParseWithClaims(tokenString, claims, s.keyFunc, jwt.WithExpirationRequired())
+    if err != nil || !token.Valid {
+        return nil, apperrors.Unauthorized("invalid token")
+    }
+
+    if s.db != nil && claims.ID != "" {
+        var revoked int
+        if err := s.db.Model(&models.RevokedToken{}).Where("token_id = ?", claims.ID).Count(&revoked).Error; err != nil {
+            return nil, fmt.Errorf("failed to check token revocation: %w", err)
+        }
+        if revoked > 0 {
+            return nil, apperrors.Unauthorized("token has been revoked")
+        }
+    }
+    return claims, nil
+}
+
+// keyFunc picks the key named by the token's kid header. The token must use
+// that key's algorithm, so an HMAC token cannot be verified with an RSA
+// public key as the secret.
+func (s *AuthService) keyFunc(token *jwt.Token) (interface{}, error) {
+    kid, _ := token.Header["kid"].(string)
+    if kid == "" {
+        kid = s.config.LegacyKeyID
+    }
+    key, ok := s.keys[kid]
+    if !ok {
+        return nil, fmt.Errorf("unknown signing key %q", kid)
+    }
+    if token.Method.Alg() != key.Method.Alg() {
+        return nil, fmt.Errorf("signing key %s does not use %s", kid, token.Method.Alg())
+    }
+    return key.Verify, nil
+}
+
+// IssueTokens signs a user in: an access token and a refresh token that
+// starts a new family.
+func (s *AuthService) IssueTokens(ctx context.Context, user *models.User) (*dto.TokenResponse, error) {
+    return s.issueTokens(s.db, user, uuid.New(), time.Now())
+}
+
+func (s *AuthService) issueTokens(db *gorm.DB, user *models.User, familyID uuid.UUID, now time.Time) (*dto.TokenResponse, error) {
+    access, accessExpiresAt, err := s.generateAccessToken(user.TenantID.String(), user.ID.String(), user.Username, user.Role, user.PartyID, now)
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate token: %w", err)
+    }
+
+    raw := make([]byte, 32)
+    if _, err := rand.Read(raw); err != nil {
+        return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+    }
+    refresh := base64.RawURLEncoding.EncodeToString(raw)
+    refreshToken := models.RefreshToken{
+        ID:        uuid.New(),
+        UserID:    user.ID,
+        FamilyID:  familyID,
+        TokenHash: hashToken(refresh),
+        ExpiresAt: now.Add(s.config.RefreshTokenTTL),
+    }
+    if err := db.Create(&refreshToken).Error; err != nil {
+        return nil, fmt.Errorf("failed to store refresh token: %w", err)
+    }
+
+    return &dto.TokenResponse{
+        Token:            access,
+        ExpiresAt:        accessExpiresAt.Format(time.RFC3339),
+        RefreshToken:     refresh,
+        RefreshExpiresAt: refreshToken.ExpiresAt.Format(time.RFC3339),
+    }, nil
+}
+
+var errInvalidRefreshToken = apperrors.Unauthorized("invalid refresh token")
+
+// Refresh exchanges a refresh token for a new access token and a new
+// refresh token. A token that was already used revokes its whole family,
+// as does a user or tenant that has been deactivated. The new access token
+// carries the user's current username and role.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*dto.TokenResponse, error) {
+    now := time.Now()
+
+    var token models.RefreshToken
+    tx := s.db.Begin()
+    err := tx.Set("gorm:query_option", "FOR UPDATE").
+        Where("token_hash = ?", hashToken(refreshToken)).First(&token).Error
+    if err == gorm.ErrRecordNotFound {
+        tx.Rollback()
+        return nil, errInvalidRefreshToken
+    }
+    if err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to get refresh token: %w", err)
+    }
+
+    if token.RevokedAt != nil || now.After(token.ExpiresAt) {
+        tx.Rollback()
+        return nil, errInvalidRefreshToken
+    }
 
+    var user models.User
+    err = tx.Where("id = ?", token.UserID).First(&user).Error
+    if err != nil && err != gorm.ErrRecordNotFound {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+    active := err == nil && user.Active
+    if active {
+        var tenant models.Tenant
+        err := tx.Where("id = ?", user.TenantID).First(&tenant).Error
+        if err != nil && err != gorm.ErrRecordNotFound {
+            tx.Rollback()
+            return nil, fmt.Errorf("failed to get tenant: %w", err)
+        }
+        active = err == nil && tenant.Active
+    }
+    if token.UsedAt != nil || !active {
+        if err := revokeRefreshTokens(tx, "family_id = ?", token.FamilyID, now); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+        if err := tx.Commit().Error; err != nil {
+            return nil, fmt.Errorf("failed to commit refresh token revocation: %w", err)
+        }
+        return nil, errInvalidRefreshToken
+    }
+
+    if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to use refresh token: %w", err)
+    }
+    resp, err := s.issueTokens(tx, &user, token.FamilyID, now)
     if err != nil {
+        tx.Rollback()
         return nil, err
     }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit refresh: %w", err)
+    }
+    return resp, nil
+}
+
+// Logout revokes the access token the request was made with and, when
+// given, the family of the user's refresh token.
+func (s *AuthService) Logout(ctx context.Context, claims *Claims, refreshToken string) error {
+    now := time.Now()
+
+    tx := s.db.Begin()
+    if claims.ID != "" && claims.ExpiresAt != nil {
+        revoked := models.RevokedToken{TokenID: claims.ID, ExpiresAt: claims.ExpiresAt.Time}
+        if err := tx.Where(models.RevokedToken{TokenID: claims.ID}).FirstOrCreate(&revoked).Error; err != nil {
+            tx.Rollback()
+            return fmt.Errorf("failed to revoke token: %w", err)
+        }
+    }
+
+    if refreshToken != "" {
+        var token models.RefreshToken
+        err := tx.Where("token_hash = ? AND user_id = ?", hashToken(refreshToken), claims.UserID).First(&token).Error
+        if err != nil && err != gorm.ErrRecordNotFound {
+            tx.Rollback()
+            return fmt.Errorf("failed to get refresh token: %w", err)
+        }
+        if err == nil {
+            if err := revokeRefreshTokens(tx, "family_id = ?", token.FamilyID, now); err != nil {
+                tx.Rollback()
+                return err
+            }
+        }
+    }
 
-    if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-        return claims, nil
+    // Revoked tokens past their expiry would be refused anyway.
+    if err := tx.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to prune revoked tokens: %w", err)
     }
+    return tx.Commit().Error
+}
 
-    return nil, errors.New("invalid token")
-}
\ No newline at end of file
+// revokeRefreshTokens revokes the unrevoked refresh tokens matching where.
+func revokeRefreshTokens(db *gorm.DB, where string, arg interface{}, now time.Time) error {
+    err := db.Model(&models.RefreshToken{}).
+        Where(where, arg).
+        Where("revoked_at IS NULL").
+        Update("revoked_at", now).Error
+    if err != nil {
+        return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+    "crypto/ed25519"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/x509"
+    "encoding/pem"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+
+    "github.com/golang-jwt/jwt/v5"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+    t.Helper()
+
+    path := filepath.Join(t.TempDir(), "key.pem")
+    if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+        t.Fatalf("failed to write key: %v", err)
+    }
+    return path
+}
+
+func newTestAuthService(t *testing.T, config AuthServiceConfig) *AuthService {
+    t.Helper()
+
+    service, err := NewAuthService(nil, config)
+    if err != nil {
+        t.Fatalf("failed to create auth service: %v", err)
+    }
+    return service
+}
+
+func TestAuthServiceKeyRotation(t *testing.T) {
+    oldKey := NewHMACKey("2024", "old-secret")
+    newKey := NewHMACKey("2025", "new-secret")
+
+    before := newTestAuthService(t, AuthServiceConfig{Keys: []SigningKey{oldKey}})
+    oldToken, err := before.GenerateToken("t1", "u1", "alice", "broker")
+    if err != nil {
+        t.Fatalf("failed to generate token: %v", err)
+    }
+
+    after := newTestAuthService(t, AuthServiceConfig{Keys: []SigningKey{oldKey, newKey}, ActiveKeyID: "2025"})
+    newToken, _ := after.GenerateToken("t1", "u1", "alice", "broker")
+    parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+    if parsed.Header["kid"] != "2025" {
+        t.Errorf("expected new tokens to be signed with the active key, got kid %v", parsed.Header["kid"])
+    }
+
+    for _, token := range []string{oldToken, newToken} {
+        claims, err := after.ValidateToken(token)
+        if err != nil || claims.Username != "alice" || claims.ID == "" {
+            t.Errorf("expected the token to verify, got %+v (%v)", claims, err)
+        }
+    }
+
+    retired := newTestAuthService(t, AuthServiceConfig{Keys: []SigningKey{newKey}})
+    if _, err := retired.ValidateToken(oldToken); !apperrors.Is(err, apperrors.CodeUnauthorized) {
+        t.Errorf("expected a token from a retired key to be refused, got %v", err)
+    }
+}
+
+func TestAuthServiceLegacyTokens(t *testing.T) {
+    service := newTestAuthService(t, AuthServiceConfig{
+        Keys:        []SigningKey{NewHMACKey("default", "secret")},
+        LegacyKeyID: "default",
+    })
+
+    legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+        UserID: "u1",
+        RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+    })
+    token, _ := legacy.SignedString([]byte("secret"))
+    if _, err := service.ValidateToken(token); err != nil {
+        t.Errorf("expected a token without kid to verify with the legacy key, got %v", err)
+    }
+
+    expired := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+        RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+    })
+    token, _ = expired.SignedString([]byte("secret"))
+    if _, err := service.ValidateToken(token); err == nil {
+        t.Error("expected an expired token to be refused")
+    }
+}
+
+func TestAuthServiceAsymmetricKeys(t *testing.T) {
+    rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+    if err != nil {
+        t.Fatalf("failed to generate RSA key: %v", err)
+    }
+    _, edKey, _ := ed25519.GenerateKey(rand.Reader)
+    edDER, _ := x509.MarshalPKCS8PrivateKey(edKey)
+
+    for _, spec := range []string{
+        "RS256:" + writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey)),
+        "EdDSA:" + writePEM(t, "PRIVATE KEY", edDER),
+    } {
+        key, err := ParseSigningKey("k1", spec)
+        if err != nil {
+            t.Fatalf("failed to parse %s: %v", spec, err)
+        }
+        service := newTestAuthService(t, AuthServiceConfig{Keys: []SigningKey{key}})
+        token, err := service.GenerateToken("t1", "u1", "alice", "broker")
+        if err != nil {
+            t.Fatalf("%s: failed to sign: %v", key.Method.Alg(), err)
+        }
+        if _, err := service.ValidateToken(token); err != nil {
+            t.Errorf("%s: expected the token to verify, got %v", key.Method.Alg(), err)
+        }
+    }
+
+    // A public key verifies but cannot be the active key.
+    publicDER, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+    public, err := ParseSigningKey("pub", "RS256:"+writePEM(t, "PUBLIC KEY", publicDER))
+    if err != nil {
+        t.Fatalf("failed to parse public key: %v", err)
+    }
+    if _, err := NewAuthService(nil, AuthServiceConfig{Keys: []SigningKey{public}}); err == nil {
+        t.Error("expected a verify-only active key to be refused")
+    }
+}
+
+func TestAuthServiceRejectsAlgorithmMismatch(t *testing.T) {
+    service := newTestAuthService(t, AuthServiceConfig{Keys: []SigningKey{NewHMACKey("k1", "secret")}})
+
+    token := jwt.NewWithClaims(jwt.SigningMethodHS512, Claims{
+        RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+    })
+    token.Header["kid"] = "k1"
+    signed, _ := token.SignedString([]byte("secret"))
+    if _, err := service.ValidateToken(signed); err == nil {
+        t.Error("expected a token signed with another algorithm to be refused")
+    }
+
+    if _, err := NewAuthService(nil, AuthServiceConfig{Keys: []SigningKey{NewHMACKey("k1", "")}}); err == nil {
+        t.Error("expected an empty secret to be refused")
+    }
+}
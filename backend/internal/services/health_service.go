@@ -0,0 +1,64 @@
+package services
+
+import (
+    "context"
+
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
+)
+
+const (
+    HealthStatusHealthy  = "healthy"
+    HealthStatusDegraded = "degraded"
+)
+
+type HealthService struct {
+    tmsRegistry interfaces.TMSProviderRegistry
+}
+
+func NewHealthService(tmsRegistry interfaces.TMSProviderRegistry) *HealthService {
+    return &HealthService{
+        tmsRegistry: tmsRegistry,
+    }
+}
+
+// Check reports the API as degraded, not down, while a TMS circuit is open:
+// loads can still be written and the outbox delivers them once it closes.
+// Tenants' own providers count towards the status but are not listed, as
+// the endpoint is public.
+func (s *HealthService) Check(ctx context.Context) *dto.HealthResponse {
+    resp := &dto.HealthResponse{
+        Status: HealthStatusHealthy,
+        TMS:    make(map[string]dto.TMSHealth),
+    }
+
+    for name, health := range providerHealth(s.tmsRegistry) {
+        if health.Circuit != CircuitClosed {
+            resp.Status = HealthStatusDegraded
+        }
+        resp.TMS[name] = health
+    }
+    for _, tenantID := range s.tmsRegistry.TenantIDs() {
+        for _, health := range providerHealth(s.tmsRegistry.ForTenant(tenantID)) {
+            if health.Circuit != CircuitClosed {
+                resp.Status = HealthStatusDegraded
+            }
+        }
+    }
+
+    return resp
+}
+
+func providerHealth(registry interfaces.TMSProviderRegistry) map[string]dto.TMSHealth {
+    healths := make(map[string]dto.TMSHealth)
+    for _, name := range registry.ProviderNames() {
+        provider, err := registry.Provider(name)
+        if err != nil {
+            continue
+        }
+        if reporter, ok := provider.(interfaces.TMSHealthReporter); ok {
+            healths[name] = reporter.TMSHealth()
+        }
+    }
+    return healths
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+    "context"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/jinzhu/gorm"
+)
+
+// actorCan reports whether the request's actor is allowed permission: by
+// its role, or by its scopes for an API key. Background jobs run as the
+// system actor, which is allowed everything; a context without an actor is
+// allowed nothing.
+func actorCan(ctx context.Context, permission models.Permission) bool {
+    actor, ok := requestctx.ActorFrom(ctx)
+    if !ok {
+        return false
+    }
+    if actor.IsSystem() {
+        return true
+    }
+    if actor.APIKeyID != "" {
+        return models.ScopesAllow(actor.Scopes, permission)
+    }
+    return models.HasPermission(actor.Role, permission)
+}
+
+// partyConditions limits customer and carrier users to their own loads,
+// matched on the customer's account number or the carrier's SCAC. A user of
+// those roles without a party sees no loads. Other actors, API keys among
+// them, are not limited.
+func partyConditions(ctx context.Context) (loadConditions, error) {
+    actor, err := contextActor(ctx)
+    if err != nil {
+        return nil, err
+    }
+    var conditions loadConditions
+    if actor.APIKeyID != "" || !models.IsPartyRole(actor.Role) {
+        return conditions, nil
+    }
+    switch {
+    case actor.PartyID == "":
+        conditions.add("FALSE")
+    case actor.Role == models.RoleCustomer:
+        conditions.add("customer->>'accountNumber' = ?", actor.PartyID)
+    default:
+        conditions.add("carrier->>'scac' = ?", actor.PartyID)
+    }
+    return conditions, nil
+}
+
+// visibleLoadConditions limits a query on loads to those the actor may
+// read: the tenant's, within partyConditions.
+func visibleLoadConditions(ctx context.Context) (loadConditions, error) {
+    tenantID, scoped, err := actorTenant(ctx)
+    if err != nil {
+        return nil, err
+    }
+    var conditions loadConditions
+    if scoped {
+        conditions.add("tenant_id = ?", tenantID)
+    }
+    party, err := partyConditions(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return append(conditions, party...), nil
+}
+
+// scopeToVisibleLoads limits a query on loads as visibleLoadConditions
+// does.
+func scopeToVisibleLoads(ctx context.Context, db *gorm.DB) (*gorm.DB, error) {
+    conditions, err := visibleLoadConditions(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return conditions.apply(db), nil
+}
+
+// checkRatesWrite refuses a request that sets rates from a role that may
+// not, rather than silently dropping them.
+func checkRatesWrite(ctx context.Context, rates *models.RateData) error {
+    if rates == nil || actorCan(ctx, models.PermRatesWrite) {
+        return nil
+    }
+    return apperrors.Forbidden("role may not set rateData")
+}
+
+// checkCancel refuses cancelling a load through the status endpoint to an
+// actor who could not cancel it through DELETE.
+func checkCancel(ctx context.Context) error {
+    if actorCan(ctx, models.PermLoadsCancel) {
+        return nil
+    }
+    return apperrors.Forbidden("role may not cancel loads")
+}
+
+// checkStatusWrite refuses an update that moves a load's status from an
+// actor who could not move it through the status endpoint.
+func checkStatusWrite(ctx context.Context, from, to string) error {
+    if from == to || actorCan(ctx, models.PermLoadsStatus) {
+        return nil
+    }
+    return apperrors.Forbidden("role may not change status")
+}
+
+// rateDataResponse returns the parts of rates the actor may read: customers
+// never see what the carrier is paid, and carriers never see what the
+// customer is billed.
+func rateDataResponse(ctx context.Context, rates models.RateData) dto.RateDataResponse {
+    response := dto.RateDataResponse{Currency: rates.Currency}
+    if actorCan(ctx, models.PermCustomerRatesRead) {
+        response.BaseRate = &rates.BaseRate
+        response.FuelSurcharge = &rates.FuelSurcharge
+        response.TotalRate = &rates.TotalRate
+    }
+    if actorCan(ctx, models.PermCarrierRatesRead) {
+        response.CarrierRate = rates.CarrierRate
+    }
+    return response
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "strings"
+    "testing"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/google/uuid"
+)
+
+func actorContext(role string) context.Context {
+    return requestctx.WithActor(context.Background(), requestctx.Actor{UserID: "u1", Username: "u1", Role: role})
+}
+
+func TestRateDataResponseHidesRatesByRole(t *testing.T) {
+    rates := models.RateData{
+        BaseRate:    1000,
+        TotalRate:   1100,
+        Currency:    "USD",
+        CarrierRate: &models.Rate{BaseRate: 800, TotalRate: 850},
+    }
+
+    tests := []struct {
+        ctx          context.Context
+        customerRate bool
+        carrierRate  bool
+    }{
+        {actorContext(models.RoleBroker), true, true},
+        {actorContext(models.RoleDispatcher), false, true},
+        {actorContext(models.RoleCustomer), true, false},
+        {actorContext(models.RoleCarrier), false, true},
+        {actorContext("unknown"), false, false},
+        {requestctx.WithSystem(context.Background()), true, true},
+        {context.Background(), false, false},
+    }
+    for _, tt := range tests {
+        actor, _ := requestctx.ActorFrom(tt.ctx)
+        role := actor.Role
+        body, _ := json.Marshal(rateDataResponse(tt.ctx, rates))
+        if got := strings.Contains(string(body), `"totalRate":1100`); got != tt.customerRate {
+            t.Errorf("role %q: customer rate shown = %v in %s", role, got, body)
+        }
+        if got := strings.Contains(string(body), `"carrierRate"`); got != tt.carrierRate {
+            t.Errorf("role %q: carrier rate shown = %v in %s", role, got, body)
+        }
+    }
+}
+
+func TestCheckRatesWrite(t *testing.T) {
+    rates := &models.RateData{TotalRate: 100}
+
+    if err := checkRatesWrite(actorContext(models.RoleAccounting), rates); err != nil {
+        t.Errorf("expected accounting to set rates, got %v", err)
+    }
+    if err := checkRatesWrite(actorContext(models.RoleDispatcher), nil); err != nil {
+        t.Errorf("expected a request without rates to pass, got %v", err)
+    }
+    err := checkRatesWrite(actorContext(models.RoleDispatcher), rates)
+    if !apperrors.Is(err, apperrors.CodeForbidden) {
+        t.Errorf("expected dispatchers to be refused, got %v", err)
+    }
+}
+
+func TestCheckCancel(t *testing.T) {
+    if err := checkCancel(actorContext(models.RoleBroker)); err != nil {
+        t.Errorf("expected brokers to cancel loads, got %v", err)
+    }
+    err := checkCancel(actorContext(models.RoleDispatcher))
+    if !apperrors.Is(err, apperrors.CodeForbidden) {
+        t.Errorf("expected dispatchers to be refused, got %v", err)
+    }
+}
+
+func TestCheckStatusWrite(t *testing.T) {
+    if err := checkStatusWrite(actorContext(models.RoleAccounting), models.StatusDelivered, models.StatusDelivered); err != nil {
+        t.Errorf("expected an unchanged status to pass, got %v", err)
+    }
+    if err := checkStatusWrite(actorContext(models.RoleDispatcher), models.StatusCovered, models.StatusDispatched); err != nil {
+        t.Errorf("expected dispatchers to change status, got %v", err)
+    }
+    err := checkStatusWrite(actorContext(models.RoleAccounting), models.StatusDelivered, models.StatusInvoiced)
+    if !apperrors.Is(err, apperrors.CodeForbidden) {
+        t.Errorf("expected accounting to be refused, got %v", err)
+    }
+    scoped := requestctx.WithActor(context.Background(), requestctx.Actor{APIKeyID: "k1", Scopes: []string{string(models.PermLoadsUpdate)}})
+    if err := checkStatusWrite(scoped, models.StatusCovered, models.StatusDispatched); !apperrors.Is(err, apperrors.CodeForbidden) {
+        t.Errorf("expected a key scoped to loads:update to be refused, got %v", err)
+    }
+}
+
+func TestActorTenantRequiresAnActor(t *testing.T) {
+    if _, _, err := actorTenant(context.Background()); err != errNoActor {
+        t.Errorf("expected a context without an actor to be refused, got %v", err)
+    }
+    if _, scoped, err := actorTenant(requestctx.WithSystem(context.Background())); err != nil || scoped {
+        t.Errorf("expected the system actor to be unscoped, got scoped=%v err=%v", scoped, err)
+    }
+    tenantID, scoped, err := actorTenant(actorContext(models.RoleBroker))
+    if err != nil || !scoped || tenantID != uuid.Nil {
+        t.Errorf("expected an actor without a tenant to match no tenant, got %v scoped=%v err=%v", tenantID, scoped, err)
+    }
+    if checkCancel(context.Background()) == nil {
+        t.Error("expected a context without an actor to be refused a cancel")
+    }
+}
+
+func TestPartyConditionsLimitCustomersAndCarriers(t *testing.T) {
+    party := func(role, partyID string) context.Context {
+        return requestctx.WithActor(context.Background(), requestctx.Actor{UserID: "u1", Role: role, PartyID: partyID})
+    }
+    tests := []struct {
+        ctx  context.Context
+        want string
+    }{
+        {party(models.RoleCustomer, "ACME-1"), " WHERE (customer->>'accountNumber' = ?)"},
+        {party(models.RoleCarrier, "ABCD"), " WHERE (carrier->>'scac' = ?)"},
+        {party(models.RoleCarrier, ""), " WHERE (FALSE)"},
+        {party(models.RoleBroker, "ACME-1"), ""},
+        {requestctx.WithActor(context.Background(), requestctx.Actor{APIKeyID: "k1"}), ""},
+        {requestctx.WithSystem(context.Background()), ""},
+    }
+    for _, tt := range tests {
+        conditions, err := partyConditions(tt.ctx)
+        if err != nil {
+            t.Fatalf("unexpected error: %v", err)
+        }
+        if where, _ := conditions.where(); where != tt.want {
+            t.Errorf("expected %q, got %q", tt.want, where)
+        }
+    }
+    if _, err := partyConditions(context.Background()); err != errNoActor {
+        t.Errorf("expected a context without an actor to be refused, got %v", err)
+    }
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+)
+
+// loadCursor marks a position in a sorted load list: the sort value and ID
+// of the load at the edge of a page. It is handed to clients as an opaque
+// string and only valid for the sort and filters it was issued for.
+type loadCursor struct {
+    Sort   string  `json:"s"`
+    Filter string  `json:"f"`
+    Value  *string `json:"v"`
+    ID     string  `json:"id"`
+    // Before selects the loads before the position instead of after it.
+    Before bool    `json:"b,omitempty"`
+}
+
+func newLoadCursor(order loadSort, filter string, load *models.Load, before bool) string {
+    cursor := loadCursor{
+        Sort:   order.String(),
+        Filter: filter,
+        Value:  order.field.value(load),
+        ID:     load.ID.String(),
+        Before: before,
+    }
+    data, _ := json.Marshal(cursor)
+    return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func parseLoadCursor(raw string, order loadSort, filter string) (*loadCursor, error) {
+    invalid := func(message string) error {
+        return apperrors.Validation("Invalid cursor", apperrors.FieldError{Field: "cursor", Message: message})
+    }
+
+    data, err := base64.RawURLEncoding.DecodeString(raw)
+    if err != nil {
+        return nil, invalid("is malformed")
+    }
+    var cursor loadCursor
+    if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" {
+        return nil, invalid("is malformed")
+    }
+    if cursor.Sort != order.String() || cursor.Filter != filter {
+        return nil, invalid("was issued for a different sort or filter; start again without a cursor")
+    }
+    return &cursor, nil
+}
+
+// filterFingerprint identifies the filters of a query, so a cursor is not
+// reused with different ones.
+func filterFingerprint(query *dto.ListLoadsQuery) string {
+    filters := *query
+    filters.Page, filters.PageSize = 0, 0
+    filters.Sort, filters.Cursor, filters.UseCursor, filters.TotalMode = "", "", false, ""
+    data, _ := json.Marshal(filters)
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:8])
+}
+
+// keysetCondition selects the loads after the cursor in the list order, or
+// before it for a Before cursor. The list sorts by the sort column with
+// NULLs last and then by ID, both in the sort's direction.
+func keysetCondition(order loadSort, cursor *loadCursor) sqlCondition {
+    column := order.field.column
+
+    // Comparison finding the loads after the cursor in list order.
+    after := ">"
+    if order.desc {
+        after = "<"
+    }
+    if cursor.Before {
+        if after == ">" {
+            after = "<"
+        } else {
+            after = ">"
+        }
+    }
+
+    switch {
+    case cursor.Value == nil && !cursor.Before:
+        return sqlCondition{
+            sql:  fmt.Sprintf("%s IS NULL AND id %s ?", column, after),
+            args: []interface{}{cursor.ID},
+        }
+    case cursor.Value == nil:
+        return sqlCondition{
+            sql:  fmt.Sprintf("%s IS NOT NULL OR id %s ?", column, after),
+            args: []interface{}{cursor.ID},
+        }
+    case !cursor.Before:
+        return sqlCondition{
+            sql:  fmt.Sprintf("%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?) OR %[1]s IS NULL", column, after),
+            args: []interface{}{*cursor.Value, *cursor.Value, cursor.ID},
+        }
+    default:
+        return sqlCondition{
+            sql:  fmt.Sprintf("%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?)", column, after),
+            args: []interface{}{*cursor.Value, *cursor.Value, cursor.ID},
+        }
+    }
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+)
+
+func TestLoadCursorRoundTrip(t *testing.T) {
+    order, _ := parseLoadSort("-pickupAt")
+    pickup := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+    load := &models.Load{ID: uuid.New(), PickupAt: &pickup}
+    query := &dto.ListLoadsQuery{Customer: "acme"}
+    filter := filterFingerprint(query)
+
+    cursor, err := parseLoadCursor(newLoadCursor(order, filter, load, true), order, filter)
+    if err != nil {
+        t.Fatalf("parseLoadCursor: %v", err)
+    }
+    if cursor.ID != load.ID.String() || cursor.Value == nil || *cursor.Value != "2026-03-01T15:00:00Z" || !cursor.Before {
+        t.Errorf("unexpected cursor %+v", cursor)
+    }
+
+    // Page and cursor settings do not change the fingerprint; filters do.
+    paged := *query
+    paged.PageSize, paged.Cursor, paged.UseCursor = 50, "abc", true
+    if filterFingerprint(&paged) != filter {
+        t.Error("expected pagination settings to keep the filter fingerprint")
+    }
+    other := &dto.ListLoadsQuery{Customer: "globex"}
+    raw := newLoadCursor(order, filter, load, false)
+    if _, err := parseLoadCursor(raw, order, filterFingerprint(other)); !apperrors.Is(err, apperrors.CodeValidation) {
+        t.Errorf("expected a cursor for other filters to be rejected, got %v", err)
+    }
+    ascending, _ := parseLoadSort("pickupAt")
+    if _, err := parseLoadCursor(raw, ascending, filter); !apperrors.Is(err, apperrors.CodeValidation) {
+        t.Errorf("expected a cursor for another sort to be rejected, got %v", err)
+    }
+    if _, err := parseLoadCursor("%%%", order, filter); !apperrors.Is(err, apperrors.CodeValidation) {
+        t.Errorf("expected a malformed cursor to be rejected, got %v", err)
+    }
+}
+
+func TestKeysetCondition(t *testing.T) {
+    value := "2026-03-01T15:00:00Z"
+    id := "00000000-0000-0000-0000-000000000001"
+    ascending, _ := parseLoadSort("pickupAt")
+    descending, _ := parseLoadSort("-pickupAt")
+
+    cases := []struct {
+        order  loadSort
+        cursor loadCursor
+        want   string
+    }{
+        {ascending, loadCursor{Value: &value, ID: id},
+            "pickup_at > ? OR (pickup_at = ? AND id > ?) OR pickup_at IS NULL"},
+        {descending, loadCursor{Value: &value, ID: id},
+            "pickup_at < ? OR (pickup_at = ? AND id < ?) OR pickup_at IS NULL"},
+        {ascending, loadCursor{Value: &value, ID: id, Before: true},
+            "pickup_at < ? OR (pickup_at = ? AND id < ?)"},
+        {ascending, loadCursor{ID: id},
+            "pickup_at IS NULL AND id > ?"},
+        {descending, loadCursor{ID: id, Before: true},
+            "pickup_at IS NOT NULL OR id > ?"},
+    }
+    for _, tc := range cases {
+        if got := keysetCondition(tc.order, &tc.cursor).sql; got != tc.want {
+            t.Errorf("keysetCondition(%s, %+v) = %q, want %q", tc.order, tc.cursor, got, tc.want)
+        }
+    }
+}
+
+func TestLoadConditionsWhere(t *testing.T) {
+    conditions, err := filterConditions(&dto.ListLoadsQuery{Operator: "kim", Search: "acme"})
+    if err != nil {
+        t.Fatalf("filterConditions: %v", err)
+    }
+    where, args := conditions.where()
+    if where != " WHERE (operator = ?) AND ("+models.LoadSearchExpression+" ILIKE ?)" || len(args) != 2 {
+        t.Errorf("unexpected where clause %q with %v", where, args)
+    }
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+)
+
+// exportBatchSize is how many loads an export reads per query.
+const exportBatchSize = 500
+
+// exportColumn is one column of a load export, flattened out of the load's
+// JSONB fields. A column with a permission is left out of the exports of
+// actors without it.
+type exportColumn struct {
+    name       string
+    permission models.Permission
+    value      func(load *models.Load) interface{}
+}
+
+var exportColumns = []exportColumn{
+    {"id", "", func(l *models.Load) interface{} { return l.ID.String() }},
+    {"freightLoadID", "", func(l *models.Load) interface{} { return l.FreightLoadID }},
+    {"externalTMSLoadID", "", func(l *models.Load) interface{} { return l.ExternalTMSLoadID }},
+    {"status", "", func(l *models.Load) interface{} { return l.Status.Code.Key }},
+    {"customerName", "", func(l *models.Load) interface{} { return l.Customer.Name }},
+    {"customerAccountNumber", "", func(l *models.Load) interface{} { return l.Customer.AccountNumber }},
+    {"billToName", "", func(l *models.Load) interface{} { return l.BillTo.Name }},
+    {"billToAccountNumber", "", func(l *models.Load) interface{} { return l.BillTo.AccountNumber }},
+    {"pickupFacility", "", func(l *models.Load) interface{} { return l.Pickup.FacilityName }},
+    {"pickupCity", "", func(l *models.Load) interface{} { return l.Pickup.Address.City }},
+    {"pickupState", "", func(l *models.Load) interface{} { return l.Pickup.Address.State }},
+    {"pickupZipCode", "", func(l *models.Load) interface{} { return l.Pickup.Address.ZipCode }},
+    {"pickupAt", "", func(l *models.Load) interface{} { return exportTime(l.PickupAt) }},
+    {"consigneeFacility", "", func(l *models.Load) interface{} { return l.Consignee.FacilityName }},
+    {"consigneeCity", "", func(l *models.Load) interface{} { return l.Consignee.Address.City }},
+    {"consigneeState", "", func(l *models.Load) interface{} { return l.Consignee.Address.State }},
+    {"consigneeZipCode", "", func(l *models.Load) interface{} { return l.Consignee.Address.ZipCode }},
+    {"deliveryAt", "", func(l *models.Load) interface{} { return exportTime(l.DeliveryAt) }},
+    {"carrierName", "", func(l *models.Load) interface{} { return l.Carrier.Name }},
+    {"carrierSCAC", "", func(l *models.Load) interface{} { return l.Carrier.SCAC }},
+    {"equipmentType", "", func(l *models.Load) interface{} { return l.Carrier.Equipment.Type }},
+    {"equipmentLength", "", func(l *models.Load) interface{} { return l.Carrier.Equipment.Length }},
+    {"currency", "", func(l *models.Load) interface{} { return l.RateData.Currency }},
+    {"baseRate", models.PermCustomerRatesRead, func(l *models.Load) interface{} { return l.RateData.BaseRate }},
+    {"fuelSurcharge", models.PermCustomerRatesRead, func(l *models.Load) interface{} { return l.RateData.FuelSurcharge }},
+    {"totalRate", models.PermCustomerRatesRead, func(l *models.Load) interface{} { return l.RateData.TotalRate }},
+    {"carrierBaseRate", models.PermCarrierRatesRead, func(l *models.Load) interface{} { return carrierRateValue(l, func(r *models.Rate) float64 { return r.BaseRate }) }},
+    {"carrierFuelSurcharge", models.PermCarrierRatesRead, func(l *models.Load) interface{} { return carrierRateValue(l, func(r *models.Rate) float64 { return r.FuelSurcharge }) }},
+    {"carrierTotalRate", models.PermCarrierRatesRead, func(l *models.Load) interface{} { return carrierRateValue(l, func(r *models.Rate) float64 { return r.TotalRate }) }},
+    {"totalWeight", "", func(l *models.Load) interface{} { return l.TotalWeight }},
+    {"billableWeight", "", func(l *models.Load) interface{} { return l.BillableWeight }},
+    {"routeMiles", "", func(l *models.Load) interface{} { return l.RouteMiles }},
+    {"inPalletCount", "", func(l *models.Load) interface{} { return l.InPalletCount }},
+    {"outPalletCount", "", func(l *models.Load) interface{} { return l.OutPalletCount }},
+    {"numCommodities", "", func(l *models.Load) interface{} { return l.NumCommodities }},
+    {"poNums", "", func(l *models.Load) interface{} { return l.PoNums }},
+    {"operator", "", func(l *models.Load) interface{} { return l.Operator }},
+    {"tmsSyncStatus", "", func(l *models.Load) interface{} { return l.TMSSyncStatus }},
+    {"cancelledAt", "", func(l *models.Load) interface{} { return exportTime(l.CancelledAt) }},
+    {"createdAt", "", func(l *models.Load) interface{} { return exportTime(&l.CreatedAt) }},
+    {"updatedAt", "", func(l *models.Load) interface{} { return exportTime(&l.UpdatedAt) }},
+}
+
+func exportTime(t *time.Time) interface{} {
+    if t == nil {
+        return nil
+    }
+    return t.UTC().Format(time.RFC3339)
+}
+
+func carrierRateValue(load *models.Load, value func(rate *models.Rate) float64) interface{} {
+    if load.RateData.CarrierRate == nil {
+        return nil
+    }
+    return value(load.RateData.CarrierRate)
+}
+
+// visibleExportColumns returns the columns the actor may see.
+func visibleExportColumns(ctx context.Context) []exportColumn {
+    var columns []exportColumn
+    for _, column := range exportColumns {
+        if column.permission == "" || actorCan(ctx, column.permission) {
+            columns = append(columns, column)
+        }
+    }
+    return columns
+}
+
+// ExportLoads writes every load matching the query's filters to w, in list
+// order. Loads are read a batch at a time, each batch after the last load
+// of the one before as a list cursor would, so an export of any size holds
+// only one batch in memory and needs no long-running transaction.
+func (s *LoadService) ExportLoads(ctx context.Context, query *dto.ListLoadsQuery, w interfaces.LoadExportWriter) error {
+    order, err := parseLoadSort(query.Sort)
+    if err != nil {
+        return err
+    }
+    conditions, err := filterConditions(query)
+    if err != nil {
+        return err
+    }
+    visible, err := visibleLoadConditions(ctx)
+    if err != nil {
+        return err
+    }
+    conditions = append(conditions, visible...)
+
+    columns := visibleExportColumns(ctx)
+    names := make([]string, len(columns))
+    for i, column := range columns {
+        names[i] = column.name
+    }
+    if err := w.WriteHeader(names); err != nil {
+        return err
+    }
+
+    var cursor *loadCursor
+    for {
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+
+        db := conditions.apply(s.db)
+        if cursor != nil {
+            keyset := keysetCondition(order, cursor)
+            db = db.Where(keyset.sql, keyset.args...)
+        }
+        var loads []models.Load
+        if err := db.Order(order.orderClause()).Limit(exportBatchSize).Find(&loads).Error; err != nil {
+            return fmt.Errorf("failed to export loads: %w", err)
+        }
+
+        for i := range loads {
+            values := make([]interface{}, len(columns))
+            for j, column := range columns {
+                values[j] = column.value(&loads[i])
+            }
+            if err := w.WriteLoad(values); err != nil {
+                return err
+            }
+        }
+        if len(loads) < exportBatchSize {
+            return nil
+        }
+        last := &loads[len(loads)-1]
+        cursor = &loadCursor{Value: order.field.value(last), ID: last.ID.String()}
+    }
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+    "context"
+    "testing"
+
+    "freight-broker/backend/internal/models"
+)
+
+func TestExportColumnsFollowRateVisibility(t *testing.T) {
+    tests := []struct {
+        ctx          context.Context
+        customerRate bool
+        carrierRate  bool
+    }{
+        {actorContext(models.RoleBroker), true, true},
+        {actorContext(models.RoleDispatcher), false, true},
+        {actorContext(models.RoleCustomer), true, false},
+        {actorContext("unknown"), false, false},
+    }
+    for _, tt := range tests {
+        names := make(map[string]bool)
+        for _, column := range visibleExportColumns(tt.ctx) {
+            names[column.name] = true
+        }
+        if !names["currency"] || !names["customerName"] {
+            t.Errorf("expected every actor to see the plain columns, got %v", names)
+        }
+        if names["totalRate"] != tt.customerRate || names["carrierTotalRate"] != tt.carrierRate {
+            t.Errorf("unexpected rate columns %v", names)
+        }
+    }
+}
+
+func TestExportColumnValues(t *testing.T) {
+    load := &models.Load{
+        Pickup:   models.Location{Address: models.Address{City: "Chicago", State: "IL"}},
+        RateData: models.RateData{TotalRate: 1250.5},
+    }
+    load.BeforeSave()
+
+    values := make(map[string]interface{})
+    for _, column := range exportColumns {
+        values[column.name] = column.value(load)
+    }
+    if values["pickupCity"] != "Chicago" || values["totalRate"] != 1250.5 {
+        t.Errorf("unexpected values %v", values)
+    }
+    if values["carrierTotalRate"] != nil || values["pickupAt"] != nil || values["cancelledAt"] != nil {
+        t.Errorf("expected missing values to be nil, got %v", values)
+    }
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+    "github.com/lib/pq"
+)
+
+// freightLoadIDIndex is the unique index on a tenant's live freight load
+// IDs; see migration 0005_load_idempotency.
+const freightLoadIDIndex = "idx_loads_tenant_freight_load_id"
+
+// findLoadByFreightID returns the tenant's live load, other than except,
+// with the freight load ID, or nil if there is none.
+func findLoadByFreightID(db *gorm.DB, tenantID uuid.UUID, freightLoadID string, except uuid.UUID) (*models.Load, error) {
+    var load models.Load
+    err := db.Select("id").
+        Where("tenant_id = ? AND freight_load_id = ? AND cancelled_at IS NULL AND id <> ?", tenantID, freightLoadID, except).
+        First(&load).Error
+    if err == gorm.ErrRecordNotFound {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to check freight load ID: %w", err)
+    }
+    return &load, nil
+}
+
+// checkFreightLoadID refuses a freight load ID another live load of the
+// tenant already has, pointing to that load.
+func checkFreightLoadID(db *gorm.DB, tenantID uuid.UUID, freightLoadID string, except uuid.UUID) error {
+    if freightLoadID == "" {
+        return nil
+    }
+    existing, err := findLoadByFreightID(db, tenantID, freightLoadID, except)
+    if err != nil {
+        return err
+    }
+    if existing != nil {
+        return apperrors.Duplicate(fmt.Sprintf("a load with freightLoadID %q already exists", freightLoadID), "/api/loads/"+existing.ID.String())
+    }
+    return nil
+}
+
+// isDuplicateFreightLoadID reports whether err is the unique index refusing
+// a freight load ID, which happens when two requests race past
+// checkFreightLoadID.
+func isDuplicateFreightLoadID(err error) bool {
+    pqErr, ok := err.(*pq.Error)
+    return ok && pqErr.Code == "23505" && pqErr.Constraint == freightLoadIDIndex
+}
+
+// idempotencyRequestHash identifies a create request, so that a key reused
+// for a different request is caught.
+func idempotencyRequestHash(req *dto.CreateLoadRequest) (string, error) {
+    raw, err := json.Marshal(req)
+    if err != nil {
+        return "", fmt.Errorf("failed to hash request: %w", err)
+    }
+    sum := sha256.Sum256(raw)
+    return hex.EncodeToString(sum[:]), nil
+}
+
+// replayIdempotencyKey returns the response recorded for the actor's key,
+// or nil if the key has not been used or has expired. The caller holds the
+// key's lock.
+func replayIdempotencyKey(tx *gorm.DB, tenantID uuid.UUID, actorID, key, requestHash string) (*dto.LoadResponse, error) {
+    var record models.IdempotencyKey
+    err := tx.Where("tenant_id = ? AND actor_id = ? AND key = ? AND expires_at > ?", tenantID, actorID, key, time.Now()).
+        First(&record).Error
+    if err == gorm.ErrRecordNotFound {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+    }
+
+    if record.RequestHash != requestHash {
+        return nil, apperrors.Validation("Idempotency key reused",
+            apperrors.FieldError{Field: "Idempotency-Key", Message: "was already used for a different request"})
+    }
+    var resp dto.LoadResponse
+    if err := json.Unmarshal([]byte(record.Response), &resp); err != nil {
+        return nil, fmt.Errorf("failed to replay idempotency key: %w", err)
+    }
+    return &resp, nil
+}
+
+// recordIdempotencyKey stores the response to the request made with key,
+// replacing the key's expired record and purging other expired ones.
+func recordIdempotencyKey(tx *gorm.DB, tenantID uuid.UUID, actorID, key, requestHash string, ttl time.Duration, resp *dto.LoadResponse) error {
+    raw, err := json.Marshal(resp)
+    if err != nil {
+        return fmt.Errorf("failed to record idempotency key: %w", err)
+    }
+
+    now := time.Now()
+    if err := tx.Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{}).Error; err != nil {
+        return fmt.Errorf("failed to purge idempotency keys: %w", err)
+    }
+    loadID, _ := uuid.Parse(resp.ID)
+    record := &models.IdempotencyKey{
+        TenantID:    tenantID,
+        ActorID:     actorID,
+        Key:         key,
+        CreatedAt:   now,
+        ExpiresAt:   now.Add(ttl),
+        RequestHash: requestHash,
+        LoadID:      loadID,
+        Response:    string(raw),
+    }
+    if err := tx.Create(record).Error; err != nil {
+        return fmt.Errorf("failed to record idempotency key: %w", err)
+    }
+    return nil
+}
+
+// lockIdempotencyKey makes a retry sent while the first request is still
+// running wait for it, and then replay its response.
+func lockIdempotencyKey(tx *gorm.DB, tenantID uuid.UUID, actorID, key string) error {
+    if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "idempotency:"+tenantID.String()+":"+actorID+":"+key).Error; err != nil {
+        return fmt.Errorf("failed to lock idempotency key: %w", err)
+    }
+    return nil
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+    "testing"
+
+    "freight-broker/backend/internal/dto"
+
+    "github.com/lib/pq"
+)
+
+func TestIdempotencyRequestHash(t *testing.T) {
+    first, _ := idempotencyRequestHash(&dto.CreateLoadRequest{FreightLoadID: "FL-1", PoNums: "PO-1"})
+    same, _ := idempotencyRequestHash(&dto.CreateLoadRequest{FreightLoadID: "FL-1", PoNums: "PO-1"})
+    other, _ := idempotencyRequestHash(&dto.CreateLoadRequest{FreightLoadID: "FL-1", PoNums: "PO-2"})
+    if first != same || len(first) != 64 {
+        t.Errorf("expected equal requests to hash alike, got %q and %q", first, same)
+    }
+    if first == other {
+        t.Error("expected different requests to hash differently")
+    }
+}
+
+func TestIsDuplicateFreightLoadID(t *testing.T) {
+    if !isDuplicateFreightLoadID(&pq.Error{Code: "23505", Constraint: freightLoadIDIndex}) {
+        t.Error("expected the freight load ID index violation to be recognized")
+    }
+    if isDuplicateFreightLoadID(&pq.Error{Code: "23505", Constraint: "loads_pkey"}) {
+        t.Error("expected other unique violations to be ignored")
+    }
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+// importRowBatchSize is how many rows are written or read per statement.
+const importRowBatchSize = 500
+
+// LoadImportService checks spreadsheet rows and queues them as an import,
+// which LoadImportWorker runs.
+type LoadImportService struct {
+    db    *gorm.DB
+    loads *LoadService
+}
+
+func NewLoadImportService(db *gorm.DB, loads *LoadService) *LoadImportService {
+    return &LoadImportService{
+        db:    db,
+        loads: loads,
+    }
+}
+
+// ValidateImport reports the rows an import would refuse, without creating
+// anything.
+func (s *LoadImportService) ValidateImport(ctx context.Context, rows []dto.ImportRow) (*dto.ImportValidationResponse, error) {
+    if err := s.checkRows(ctx, rows); err != nil {
+        return nil, err
+    }
+
+    resp := &dto.ImportValidationResponse{TotalRows: len(rows), Errors: []dto.ImportRowResult{}}
+    for _, row := range rows {
+        if len(row.Errors) == 0 {
+            resp.ValidRows++
+            continue
+        }
+        resp.InvalidRows++
+        result := dto.ImportRowResult{Row: row.Line, Status: models.LoadImportRowFailed, Errors: row.Errors}
+        if row.Request != nil {
+            result.FreightLoadID = row.Request.FreightLoadID
+        }
+        resp.Errors = append(resp.Errors, result)
+    }
+    return resp, nil
+}
+
+// StartImport queues rows to be created in the background as the request's
+// actor. Rows that fail the checks are recorded as failed straight away.
+func (s *LoadImportService) StartImport(ctx context.Context, fileName string, rows []dto.ImportRow) (*dto.LoadImportResponse, error) {
+    actor, err := contextActor(ctx)
+    if err != nil {
+        return nil, err
+    }
+    tenantID, scoped, err := actorTenant(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if !scoped || tenantID == uuid.Nil {
+        return nil, apperrors.Forbidden("imports can only be started on behalf of a tenant")
+    }
+    if err := s.checkRows(ctx, rows); err != nil {
+        return nil, err
+    }
+
+    job := &models.LoadImport{
+        ID:        uuid.New(),
+        TenantID:  tenantID,
+        FileName:  fileName,
+        Status:    models.LoadImportQueued,
+        TotalRows: len(rows),
+        ActorID:   actor.UserID,
+        ActorName: actor.Username,
+        ActorRole: actor.Role,
+        APIKeyID:  actor.APIKeyID,
+        Scopes:    actor.Scopes,
+        RequestID: requestctx.RequestIDFrom(ctx),
+    }
+
+    tx := s.db.Begin()
+    if err := tx.Create(job).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to create import: %w", err)
+    }
+    for start := 0; start < len(rows); start += importRowBatchSize {
+        end := start + importRowBatchSize
+        if end > len(rows) {
+            end = len(rows)
+        }
+        if err := insertImportRows(tx, job.ID, rows[start:end]); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit import: %w", err)
+    }
+
+    return s.convertToLoadImportResponse(job)
+}
+
+func (s *LoadImportService) GetImport(ctx context.Context, id string) (*dto.LoadImportResponse, error) {
+    var job models.LoadImport
+    db, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    if err := db.Where("id = ?", id).First(&job).Error; err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("import not found")
+        }
+        return nil, fmt.Errorf("failed to get import: %w", err)
+    }
+    return s.convertToLoadImportResponse(&job)
+}
+
+// EachImportRow calls fn with the outcome of each of an import's rows, in
+// file order, reading them a batch at a time.
+func (s *LoadImportService) EachImportRow(ctx context.Context, id string, fn func(dto.ImportRowResult) error) error {
+    if _, err := s.GetImport(ctx, id); err != nil {
+        return err
+    }
+
+    after := 0
+    for {
+        var rows []models.LoadImportRow
+        if err := s.db.Where("import_id = ? AND line > ?", id, after).
+            Order("line").
+            Limit(importRowBatchSize).
+            Find(&rows).Error; err != nil {
+            return fmt.Errorf("failed to get import rows: %w", err)
+        }
+
+        for i := range rows {
+            if err := fn(convertToImportRowResult(&rows[i])); err != nil {
+                return err
+            }
+        }
+        if len(rows) < importRowBatchSize {
+            return nil
+        }
+        after = rows[len(rows)-1].Line
+    }
+}
+
+// checkRows adds to the rows' errors what creating them would fail on: the
+// checks CreateLoad makes, and freight load IDs repeated within the file or
+// held by a live load. Rows that failed binding are not checked further.
+func (s *LoadImportService) checkRows(ctx context.Context, rows []dto.ImportRow) error {
+    tenantID, scoped, err := actorTenant(ctx)
+    if err != nil {
+        return err
+    }
+    if !scoped || tenantID == uuid.Nil {
+        return apperrors.Forbidden("imports can only be checked on behalf of a tenant")
+    }
+
+    seen := make(map[string]int)
+    var freightLoadIDs []string
+    for i := range rows {
+        row := &rows[i]
+        if len(row.Errors) > 0 {
+            continue
+        }
+        if _, _, _, err := s.loads.newLoad(ctx, row.Request); err != nil {
+            fields, err := importRowErrors(err)
+            if err != nil {
+                return err
+            }
+            row.Errors = fields
+            continue
+        }
+
+        id := row.Request.FreightLoadID
+        if line, ok := seen[id]; ok {
+            row.Errors = []apperrors.FieldError{{Field: "freightLoadID", Message: fmt.Sprintf("repeats row %d", line)}}
+            continue
+        }
+        seen[id] = row.Line
+        freightLoadIDs = append(freightLoadIDs, id)
+    }
+
+    existing, err := liveFreightLoadIDs(s.db, tenantID, freightLoadIDs)
+    if err != nil {
+        return err
+    }
+    for i := range rows {
+        row := &rows[i]
+        if len(row.Errors) > 0 {
+            continue
+        }
+        if loadID, ok := existing[row.Request.FreightLoadID]; ok {
+            row.Errors = []apperrors.FieldError{{Field: "freightLoadID", Message: "already exists as load " + loadID.String()}}
+        }
+    }
+    return nil
+}
+
+// liveFreightLoadIDs returns which of the freight load IDs the tenant's
+// live loads have, and the loads that have them.
+func liveFreightLoadIDs(db *gorm.DB, tenantID uuid.UUID, freightLoadIDs []string) (map[string]uuid.UUID, error) {
+    existing := make(map[string]uuid.UUID)
+    for start := 0; start < len(freightLoadIDs); start += importRowBatchSize {
+        end := start + importRowBatchSize
+        if end > len(freightLoadIDs) {
+            end = len(freightLoadIDs)
+        }
+
+        var loads []models.Load
+        if err := db.Select("id, freight_load_id").
+            Where("tenant_id = ? AND freight_load_id IN (?) AND cancelled_at IS NULL", tenantID, freightLoadIDs[start:end]).
+            Find(&loads).Error; err != nil {
+            return nil, fmt.Errorf("failed to check freight load IDs: %w", err)
+        }
+        for _, load := range loads {
+            existing[load.FreightLoadID] = load.ID
+        }
+    }
+    return existing, nil
+}
+
+// importRowErrors turns why a row cannot be created into field errors.
+// Errors that are not about the row are returned as they are.
+func importRowErrors(err error) ([]apperrors.FieldError, error) {
+    appErr, ok := apperrors.As(err)
+    if !ok {
+        return nil, err
+    }
+    if len(appErr.Fields) > 0 {
+        return appErr.Fields, nil
+    }
+    return []apperrors.FieldError{{Field: "row", Message: appErr.Message}}, nil
+}
+
+// insertImportRows writes rows in one statement; gorm has no batch insert.
+func insertImportRows(tx *gorm.DB, importID uuid.UUID, rows []dto.ImportRow) error {
+    placeholders := make([]string, 0, len(rows))
+    args := make([]interface{}, 0, len(rows)*6)
+    for _, row := range rows {
+        status, freightLoadID, request, errors := models.LoadImportRowPending, "", "", ""
+        if row.Request != nil {
+            raw, err := json.Marshal(row.Request)
+            if err != nil {
+                return fmt.Errorf("failed to store import row %d: %w", row.Line, err)
+            }
+            freightLoadID, request = row.Request.FreightLoadID, string(raw)
+        }
+        if len(row.Errors) > 0 {
+            raw, err := json.Marshal(row.Errors)
+            if err != nil {
+                return fmt.Errorf("failed to store import row %d: %w", row.Line, err)
+            }
+            status, errors = models.LoadImportRowFailed, string(raw)
+        }
+        placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?)")
+        args = append(args, importID, row.Line, freightLoadID, status, request, errors)
+    }
+
+    sql := "INSERT INTO load_import_rows (import_id, line, freight_load_id, status, request, errors) VALUES " +
+        strings.Join(placeholders, ", ")
+    if err := tx.Exec(sql, args...).Error; err != nil {
+        return fmt.Errorf("failed to store import rows: %w", err)
+    }
+    return nil
+}
+
+func (s *LoadImportService) convertToLoadImportResponse(job *models.LoadImport) (*dto.LoadImportResponse, error) {
+    var counts []struct {
+        Status string
+        Count  int
+    }
+    if err := s.db.Model(&models.LoadImportRow{}).
+        Select("status, count(*) AS count").
+        Where("import_id = ?", job.ID).
+        Group("status").
+        Scan(&counts).Error; err != nil {
+        return nil, fmt.Errorf("failed to count import rows: %w", err)
+    }
+
+    resp := &dto.LoadImportResponse{
+        ID:        job.ID.String(),
+        FileName:  job.FileName,
+        Status:    job.Status,
+        TotalRows: job.TotalRows,
+        CreatedBy: job.ActorName,
+        CreatedAt: job.CreatedAt.Format(time.RFC3339),
+    }
+    for _, count := range counts {
+        switch count.Status {
+        case models.LoadImportRowPending:
+            resp.PendingRows = count.Count
+        case models.LoadImportRowCreated:
+            resp.CreatedRows = count.Count
+        case models.LoadImportRowFailed:
+            resp.FailedRows = count.Count
+        }
+    }
+    if job.StartedAt != nil {
+        resp.StartedAt = job.StartedAt.Format(time.RFC3339)
+    }
+    if job.FinishedAt != nil {
+        resp.FinishedAt = job.FinishedAt.Format(time.RFC3339)
+    }
+    return resp, nil
+}
+
+func convertToImportRowResult(row *models.LoadImportRow) dto.ImportRowResult {
+    result := dto.ImportRowResult{
+        Row:           row.Line,
+        FreightLoadID: row.FreightLoadID,
+        Status:        row.Status,
+    }
+    if row.LoadID != nil {
+        result.LoadID = row.LoadID.String()
+    }
+    if row.Errors != "" {
+        if err := json.Unmarshal([]byte(row.Errors), &result.Errors); err != nil {
+            log.Printf("Warning: import %s row %d has unreadable errors: %v", row.ImportID, row.Line, err)
+        }
+    }
+    return result
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "log"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+type LoadImportWorkerConfig struct {
+    PollInterval time.Duration
+    // BatchSize is how many rows are created between progress updates.
+    BatchSize    int
+    // ClaimTimeout is how long a running import stays hidden from other
+    // workers without progress before it is considered abandoned.
+    ClaimTimeout time.Duration
+}
+
+// LoadImportWorker creates the loads of queued imports, a batch of rows at
+// a time. Each row is created as by CreateLoad, which also queues its TMS
+// shipment, under an idempotency key of its own, so a row is never
+// created twice when an abandoned import is picked up again.
+type LoadImportWorker struct {
+    db     *gorm.DB
+    loads  *LoadService
+    config LoadImportWorkerConfig
+}
+
+func NewLoadImportWorker(db *gorm.DB, loads *LoadService, config LoadImportWorkerConfig) *LoadImportWorker {
+    if config.PollInterval <= 0 {
+        config.PollInterval = 5 * time.Second
+    }
+    if config.BatchSize <= 0 {
+        config.BatchSize = 50
+    }
+    if config.ClaimTimeout <= 0 {
+        config.ClaimTimeout = 5 * time.Minute
+    }
+
+    return &LoadImportWorker{
+        db:     db,
+        loads:  loads,
+        config: config,
+    }
+}
+
+// Run works through queued imports until ctx is cancelled.
+func (w *LoadImportWorker) Run(ctx context.Context) {
+    ticker := time.NewTicker(w.config.PollInterval)
+    defer ticker.Stop()
+
+    for {
+        for ctx.Err() == nil {
+            job, err := w.claim()
+            if err != nil {
+                log.Printf("Load import worker: %v", err)
+                break
+            }
+            if job == nil {
+                break
+            }
+            if err := w.run(ctx, job); err != nil {
+                log.Printf("Load import worker: import %s: %v", job.ID, err)
+            }
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// claim takes the oldest queued import, or a running one whose worker has
+// stopped making progress.
+func (w *LoadImportWorker) claim() (*models.LoadImport, error) {
+    var job models.LoadImport
+    now := time.Now()
+
+    tx := w.db.Begin()
+    err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+        Where("status = ? OR (status = ? AND claimed_until < ?)", models.LoadImportQueued, models.LoadImportRunning, now).
+        Order("created_at").
+        First(&job).Error
+    if err == gorm.ErrRecordNotFound {
+        tx.Rollback()
+        return nil, nil
+    }
+    if err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to claim import: %w", err)
+    }
+
+    claimedUntil := now.Add(w.config.ClaimTimeout)
+    job.Status = models.LoadImportRunning
+    job.ClaimedUntil = &claimedUntil
+    if job.StartedAt == nil {
+        job.StartedAt = &now
+    }
+    if err := tx.Save(&job).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to claim import: %w", err)
+    }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit import claim: %w", err)
+    }
+    return &job, nil
+}
+
+// run creates the import's pending rows as the actor that started it,
+// renewing the claim after each batch, and completes the import. The actor
+// is checked again before each batch, so an import cannot outlive the
+// access of whoever started it; once they lose it, the rows left are failed.
+func (w *LoadImportWorker) run(ctx context.Context, job *models.LoadImport) error {
+    for {
+        actor, ok, err := w.resolveActor(job)
+        if err != nil {
+            return err
+        }
+        if !ok {
+            log.Printf("Load import %s: %s may no longer create loads; failing its remaining rows", job.ID, job.ActorID)
+            if err := w.failPendingRows(job); err != nil {
+                return err
+            }
+            break
+        }
+        actorCtx := requestctx.WithRequestID(requestctx.WithActor(ctx, actor), job.RequestID)
+
+        var rows []models.LoadImportRow
+        if err := w.db.Where("import_id = ? AND status = ?", job.ID, models.LoadImportRowPending).
+            Order("line").
+            Limit(w.config.BatchSize).
+            Find(&rows).Error; err != nil {
+            return fmt.Errorf("failed to get import rows: %w", err)
+        }
+        if len(rows) == 0 {
+            break
+        }
+
+        for i := range rows {
+            if ctx.Err() != nil {
+                return nil
+            }
+            if err := w.createRow(actorCtx, job, &rows[i]); err != nil {
+                return err
+            }
+        }
+
+        if err := w.db.Model(job).Update("claimed_until", time.Now().Add(w.config.ClaimTimeout)).Error; err != nil {
+            return fmt.Errorf("failed to renew import claim: %w", err)
+        }
+    }
+
+    now := time.Now()
+    if err := w.db.Model(job).Updates(map[string]interface{}{
+        "status":        models.LoadImportCompleted,
+        "claimed_until": nil,
+        "finished_at":   now,
+    }).Error; err != nil {
+        return fmt.Errorf("failed to complete import: %w", err)
+    }
+    log.Printf("Load import %s completed: %d rows", job.ID, job.TotalRows)
+    return nil
+}
+
+// resolveActor returns the actor that started the import as they are now,
+// rather than as they were when it was uploaded: their API key must still
+// be usable and belong to an active tenant, or they must still be an active
+// user of the import's tenant, and either way still be allowed to create
+// loads. ok is false when they are not.
+func (w *LoadImportWorker) resolveActor(job *models.LoadImport) (actor requestctx.Actor, ok bool, err error) {
+    actor = requestctx.Actor{
+        TenantID: job.TenantID.String(),
+        UserID:   job.ActorID,
+        Username: job.ActorName,
+        APIKeyID: job.APIKeyID,
+    }
+
+    if job.APIKeyID != "" {
+        var key models.APIKey
+        err := w.db.Select("api_keys.*").Joins("JOIN tenants ON tenants.id = api_keys.tenant_id AND tenants.active").
+            Where("api_keys.id = ? AND api_keys.tenant_id = ?", job.APIKeyID, job.TenantID).
+            First(&key).Error
+        if err == gorm.ErrRecordNotFound {
+            return actor, false, nil
+        }
+        if err != nil {
+            return actor, false, fmt.Errorf("failed to get import API key: %w", err)
+        }
+        if !key.IsUsable(time.Now()) || !models.ScopesAllow(key.Scopes, models.PermLoadsCreate) {
+            return actor, false, nil
+        }
+        actor.Scopes = key.Scopes
+        return actor, true, nil
+    }
+
+    userID, err := uuid.Parse(job.ActorID)
+    if err != nil {
+        return actor, false, nil
+    }
+    var user models.User
+    err = w.db.Where("id = ? AND tenant_id = ? AND active", userID, job.TenantID).First(&user).Error
+    if err == gorm.ErrRecordNotFound {
+        return actor, false, nil
+    }
+    if err != nil {
+        return actor, false, fmt.Errorf("failed to get import user: %w", err)
+    }
+    if !models.HasPermission(user.Role, models.PermLoadsCreate) {
+        return actor, false, nil
+    }
+    actor.Username = user.Username
+    actor.Role = user.Role
+    return actor, true, nil
+}
+
+// failPendingRows fails the rows of an import that have not been created,
+// when the actor that started it may no longer create loads.
+func (w *LoadImportWorker) failPendingRows(job *models.LoadImport) error {
+    raw, err := json.Marshal([]apperrors.FieldError{{Field: "row", Message: "not created: the import's user or API key may no longer create loads"}})
+    if err != nil {
+        return fmt.Errorf("failed to fail import rows: %w", err)
+    }
+    if err := w.db.Model(&models.LoadImportRow{}).
+        Where("import_id = ? AND status = ?", job.ID, models.LoadImportRowPending).
+        Updates(map[string]interface{}{"status": models.LoadImportRowFailed, "errors": string(raw)}).Error; err != nil {
+        return fmt.Errorf("failed to fail import rows: %w", err)
+    }
+    return nil
+}
+
+// createRow creates a row's load and records the outcome. A row that cannot
+// be created is failed rather than retried, so one bad row cannot hold up
+// the import; the reasons of unexpected failures are only logged.
+func (w *LoadImportWorker) createRow(ctx context.Context, job *models.LoadImport, row *models.LoadImportRow) error {
+    updates := map[string]interface{}{"status": models.LoadImportRowCreated}
+
+    var req dto.CreateLoadRequest
+    err := json.Unmarshal([]byte(row.Request), &req)
+    var load *dto.LoadResponse
+    if err == nil {
+        key := fmt.Sprintf("import:%s:%d", job.ID, row.Line)
+        load, _, err = w.loads.CreateLoadIdempotent(ctx, key, &req)
+    }
+    if err == nil {
+        loadID, _ := uuid.Parse(load.ID)
+        updates["load_id"] = loadID
+    } else {
+        fields, typedErr := importRowErrors(err)
+        if typedErr != nil {
+            log.Printf("Load import %s: row %d failed: %v", job.ID, row.Line, typedErr)
+            fields = []apperrors.FieldError{{Field: "row", Message: "could not be created"}}
+        }
+        raw, err := json.Marshal(fields)
+        if err != nil {
+            return fmt.Errorf("failed to record import row %d: %w", row.Line, err)
+        }
+        updates["status"] = models.LoadImportRowFailed
+        updates["errors"] = string(raw)
+    }
+
+    if err := w.db.Model(&models.LoadImportRow{}).
+        Where("import_id = ? AND line = ?", row.ImportID, row.Line).
+        Updates(updates).Error; err != nil {
+        return fmt.Errorf("failed to record import row %d: %w", row.Line, err)
+    }
+    return nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/jinzhu/gorm"
+)
+
+const defaultLoadSort = "-createdAt"
+
+// loadSortField is a field the load list can be sorted on: the indexed
+// column or expression it orders by, and how to read that value from a
+// load for a pagination cursor. Expressions are indexed per tenant by
+// migration 0009_load_sort_indexes.
+type loadSortField struct {
+    column string
+    value  func(load *models.Load) *string
+}
+
+var loadSortFields = map[string]loadSortField{
+    "createdAt":     {"created_at", func(l *models.Load) *string { return timeSortValue(&l.CreatedAt) }},
+    "updatedAt":     {"updated_at", func(l *models.Load) *string { return timeSortValue(&l.UpdatedAt) }},
+    "freightLoadID": {"freight_load_id", func(l *models.Load) *string { return &l.FreightLoadID }},
+    "pickupAt":      {"pickup_at", func(l *models.Load) *string { return timeSortValue(l.PickupAt) }},
+    "deliveryAt":    {"delivery_at", func(l *models.Load) *string { return timeSortValue(l.DeliveryAt) }},
+    "operator":      {"operator", func(l *models.Load) *string { return &l.Operator }},
+    "status":        {"(status->'code'->>'key')", func(l *models.Load) *string { return &l.Status.Code.Key }},
+    "customer":      {"lower(customer->>'name')", func(l *models.Load) *string { return lowerSortValue(l.Customer.Name) }},
+    "carrier":       {"lower(carrier->>'name')", func(l *models.Load) *string { return lowerSortValue(l.Carrier.Name) }},
+}
+
+func timeSortValue(t *time.Time) *string {
+    if t == nil {
+        return nil
+    }
+    value := t.UTC().Format(time.RFC3339Nano)
+    return &value
+}
+
+func lowerSortValue(value string) *string {
+    value = strings.ToLower(value)
+    return &value
+}
+
+type loadSort struct {
+    name  string
+    field loadSortField
+    desc  bool
+}
+
+func parseLoadSort(value string) (loadSort, error) {
+    if value == "" {
+        value = defaultLoadSort
+    }
+    desc := strings.HasPrefix(value, "-")
+    name := strings.TrimPrefix(value, "-")
+    field, ok := loadSortFields[name]
+    if !ok {
+        names := make([]string, 0, len(loadSortFields))
+        for name := range loadSortFields {
+            names = append(names, name)
+        }
+        sort.Strings(names)
+        return loadSort{}, apperrors.Validation("Invalid sort parameter",
+            apperrors.FieldError{Field: "sort", Message: "must be one of " + strings.Join(names, ", ") + ", optionally prefixed with -"})
+    }
+    return loadSort{name: name, field: field, desc: desc}, nil
+}
+
+// String returns the sort in its query parameter form.
+func (s loadSort) String() string {
+    if s.desc {
+        return "-" + s.name
+    }
+    return s.name
+}
+
+// orderClause orders by the sort column and then by ID, so loads with equal
+// sort values keep a stable order. Loads without a value sort last.
+func (s loadSort) orderClause() string {
+    direction := "ASC"
+    if s.desc {
+        direction = "DESC"
+    }
+    return fmt.Sprintf("%s %s NULLS LAST, id %s", s.field.column, direction, direction)
+}
+
+// reverseOrderClause is orderClause backwards, for reading the page before
+// a cursor.
+func (s loadSort) reverseOrderClause() string {
+    direction := "DESC"
+    if s.desc {
+        direction = "ASC"
+    }
+    return fmt.Sprintf("%s %s NULLS FIRST, id %s", s.field.column, direction, direction)
+}
+
+// sqlCondition is one WHERE condition with its arguments.
+type sqlCondition struct {
+    sql  string
+    args []interface{}
+}
+
+type loadConditions []sqlCondition
+
+func (c *loadConditions) add(sql string, args ...interface{}) {
+    *c = append(*c, sqlCondition{sql: sql, args: args})
+}
+
+// apply adds the conditions to a gorm query.
+func (c loadConditions) apply(db *gorm.DB) *gorm.DB {
+    for _, condition := range c {
+        db = db.Where(condition.sql, condition.args...)
+    }
+    return db
+}
+
+// where renders the conditions as a single WHERE clause for raw queries.
+func (c loadConditions) where() (string, []interface{}) {
+    if len(c) == 0 {
+        return "", nil
+    }
+    clauses := make([]string, len(c))
+    var args []interface{}
+    for i, condition := range c {
+        clauses[i] = "(" + condition.sql + ")"
+        args = append(args, condition.args...)
+    }
+    return " WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// filterConditions returns the conditions selecting the loads matching query.
+func filterConditions(query *dto.ListLoadsQuery) (loadConditions, error) {
+    var conditions loadConditions
+
+    if len(query.Status) > 0 {
+        var keys []string
+        for _, value := range query.Status {
+            status, ok := resolveStatus(value)
+            if !ok {
+                return nil, apperrors.Validation("Invalid status filter",
+                    apperrors.FieldError{Field: "status", Message: "must be one of " + strings.Join(models.Statuses(), ", ")})
+            }
+            keys = append(keys, statusKeys(status)...)
+        }
+        conditions.add("(status->'code'->>'key') IN (?)", keys)
+    }
+
+    if query.Customer != "" {
+        conditions.add("lower(customer->>'name') LIKE ?", prefixPattern(query.Customer))
+    }
+    if query.Carrier != "" {
+        conditions.add("lower(carrier->>'name') LIKE ?", prefixPattern(query.Carrier))
+    }
+    if query.Operator != "" {
+        conditions.add("operator = ?", query.Operator)
+    }
+
+    if query.OriginState != "" {
+        conditions.add("pickup @> ?::jsonb", addressStateDocument(query.OriginState))
+    }
+    if query.DestinationState != "" {
+        conditions.add("consignee @> ?::jsonb", addressStateDocument(query.DestinationState))
+    }
+
+    if query.PONumber != "" {
+        reference, _ := json.Marshal([]map[string]string{{"value": query.PONumber}})
+        conditions.add("(po_nums ILIKE ? OR EXISTS (SELECT 1 FROM stops WHERE stops.load_id = loads.id AND stops.reference_numbers @> ?::jsonb))",
+            containsPattern(query.PONumber), string(reference))
+    }
+
+    if query.Search != "" {
+        conditions.add(models.LoadSearchExpression+" ILIKE ?", containsPattern(query.Search))
+    }
+
+    if query.PickupFrom != nil {
+        conditions.add("pickup_at >= ?", *query.PickupFrom)
+    }
+    if query.PickupTo != nil {
+        conditions.add("pickup_at < ?", *query.PickupTo)
+    }
+    if query.DeliveryFrom != nil {
+        conditions.add("delivery_at >= ?", *query.DeliveryFrom)
+    }
+    if query.DeliveryTo != nil {
+        conditions.add("delivery_at < ?", *query.DeliveryTo)
+    }
+
+    return conditions, nil
+}
+
+// statusKeys returns the stored status keys that mean status: our key and
+// the Turvo codes loads stored before statuses were normalized may hold.
+func statusKeys(status string) []string {
+    keys := []string{status}
+    for code, mapped := range turvoStatusKeys {
+        if mapped == status {
+            keys = append(keys, code)
+        }
+    }
+    return keys
+}
+
+func addressStateDocument(state string) string {
+    document, _ := json.Marshal(map[string]interface{}{
+        "address": map[string]string{"state": strings.ToUpper(strings.TrimSpace(state))},
+    })
+    return string(document)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func prefixPattern(value string) string {
+    return likeEscaper.Replace(strings.ToLower(strings.TrimSpace(value))) + "%"
+}
+
+func containsPattern(value string) string {
+    return "%" + likeEscaper.Replace(strings.TrimSpace(value)) + "%"
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+    "testing"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/models"
+)
+
+func TestParseLoadSort(t *testing.T) {
+    order, err := parseLoadSort("")
+    if err != nil || order.orderClause() != "created_at DESC NULLS LAST, id DESC" {
+        t.Errorf("unexpected default order %q, %v", order.orderClause(), err)
+    }
+    order, err = parseLoadSort("customer")
+    if err != nil || order.orderClause() != "lower(customer->>'name') ASC NULLS LAST, id ASC" {
+        t.Errorf("unexpected customer order %q, %v", order.orderClause(), err)
+    }
+    if _, err := parseLoadSort("-rate"); !apperrors.Is(err, apperrors.CodeValidation) {
+        t.Errorf("expected an unknown sort field to be rejected, got %v", err)
+    }
+}
+
+func TestStatusKeysIncludeLegacyTurvoCodes(t *testing.T) {
+    keys := statusKeys(models.StatusInTransit)
+    want := map[string]bool{models.StatusInTransit: true, "2105": true, "2106": true, "2114": true}
+    if len(keys) != len(want) {
+        t.Fatalf("expected %d keys, got %v", len(want), keys)
+    }
+    for _, key := range keys {
+        if !want[key] {
+            t.Errorf("unexpected key %q", key)
+        }
+    }
+}
+
+func TestLikePatternsEscapeWildcards(t *testing.T) {
+    if got := prefixPattern(" Acme_100% "); got != `acme\_100\%%` {
+        t.Errorf("prefixPattern = %q", got)
+    }
+    if got := containsPattern("PO-5"); got != "%PO-5%" {
+        t.Errorf("containsPattern = %q", got)
+    }
+}
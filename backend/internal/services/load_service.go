@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"freight-broker/backend/internal/apperrors"
 	"freight-broker/backend/internal/dto"
 	"freight-broker/backend/internal/interfaces"
 	"freight-broker/backend/internal/models"
@@ -12,38 +14,164 @@ import (
 	"github.com/jinzhu/gorm"
 )
 
+type LoadServiceConfig struct {
+    // IdempotencyKeyTTL is how long the response to a create sent with an
+    // Idempotency-Key is replayed.
+    IdempotencyKeyTTL time.Duration
+}
+
 type LoadService struct {
     db         *gorm.DB
-    tmsService interfaces.TMSService
+    tmsRegistry interfaces.TMSProviderRegistry
+    config      LoadServiceConfig
 }
 
-func NewLoadService(db *gorm.DB, tmsService interfaces.TMSService) *LoadService {
+func NewLoadService(db *gorm.DB, tmsRegistry interfaces.TMSProviderRegistry, config LoadServiceConfig) *LoadService {
+    if config.IdempotencyKeyTTL <= 0 {
+        config.IdempotencyKeyTTL = 24 * time.Hour
+    }
+
     return &LoadService{
-        db:         db,
-        tmsService: tmsService,
+        db:          db,
+        tmsRegistry: tmsRegistry,
+        config:      config,
     }
 }
 
 func (s *LoadService) CreateLoad(ctx context.Context, req *dto.CreateLoadRequest) (*dto.LoadResponse, error) {
+    resp, _, err := s.createLoad(ctx, req, "")
+    return resp, err
+}
+
+// CreateLoadIdempotent creates a load once per idempotency key: repeating
+// the request with the key returns the first response, with replayed set,
+// until the key expires.
+func (s *LoadService) CreateLoadIdempotent(ctx context.Context, key string, req *dto.CreateLoadRequest) (*dto.LoadResponse, bool, error) {
+    return s.createLoad(ctx, req, key)
+}
+
+func (s *LoadService) createLoad(ctx context.Context, req *dto.CreateLoadRequest, idempotencyKey string) (*dto.LoadResponse, bool, error) {
+    load, stops, status, err := s.newLoad(ctx, req)
+    if err != nil {
+        return nil, false, err
+    }
+    tenantID := load.TenantID
+    actor, err := contextActor(ctx)
+    if err != nil {
+        return nil, false, err
+    }
+    actorID := actor.UserID
+    var requestHash string
+    tx := s.db.Begin()
+    if idempotencyKey != "" {
+        if requestHash, err = idempotencyRequestHash(req); err != nil {
+            tx.Rollback()
+            return nil, false, err
+        }
+        if err := lockIdempotencyKey(tx, tenantID, actorID, idempotencyKey); err != nil {
+            tx.Rollback()
+            return nil, false, err
+        }
+        replay, err := replayIdempotencyKey(tx, tenantID, actorID, idempotencyKey, requestHash)
+        if err != nil || replay != nil {
+            tx.Rollback()
+            return replay, replay != nil, err
+        }
+    }
+
+    if err := checkFreightLoadID(tx, tenantID, load.FreightLoadID, uuid.Nil); err != nil {
+        tx.Rollback()
+        return nil, false, err
+    }
+    if err := tx.Create(load).Error; err != nil {
+        tx.Rollback()
+        if isDuplicateFreightLoadID(err) {
+            return nil, false, checkFreightLoadID(s.db, tenantID, load.FreightLoadID, uuid.Nil)
+        }
+        return nil, false, fmt.Errorf("failed to create load: %w", err)
+    }
+
+    if err := replaceStops(tx, load, stops); err != nil {
+        tx.Rollback()
+        return nil, false, err
+    }
+
+    if err := recordStatusChange(ctx, tx, load.ID, "", status, req.Status.Notes, models.StatusSourceAPI); err != nil {
+        tx.Rollback()
+        return nil, false, err
+    }
+
+    after, err := auditSnapshot(load)
+    if err != nil {
+        tx.Rollback()
+        return nil, false, err
+    }
+    if err := recordAudit(ctx, tx, load, models.AuditActionCreate, nil, after); err != nil {
+        tx.Rollback()
+        return nil, false, err
+    }
+
+    if err := enqueueOutboxMessage(tx, load.ID, models.OutboxOpCreateShipment); err != nil {
+        tx.Rollback()
+        return nil, false, err
+    }
+
+    resp, err := convertToLoadResponse(ctx, load)
+    if err != nil {
+        tx.Rollback()
+        return nil, false, err
+    }
+    if idempotencyKey != "" {
+        if err := recordIdempotencyKey(tx, tenantID, actorID, idempotencyKey, requestHash, s.config.IdempotencyKeyTTL, resp); err != nil {
+            tx.Rollback()
+            return nil, false, err
+        }
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        return nil, false, fmt.Errorf("failed to commit load: %w", err)
+    }
+    return resp, false, nil
+}
+
+// newLoad checks a create request beyond what binding does and builds the
+// load it asks for, with its stops and initial status. Nothing is stored.
+func (s *LoadService) newLoad(ctx context.Context, req *dto.CreateLoadRequest) (*models.Load, []models.Stop, string, error) {
+    if err := checkRatesWrite(ctx, req.RateData); err != nil {
+        return nil, nil, "", err
+    }
+
+    var stops []models.Stop
+    var err error
+    if len(req.Stops) > 0 {
+        stops, err = buildStops(req.Stops)
+    } else {
+        stops, err = stopsFromLocations(*req.Pickup, *req.Consignee)
+    }
+    if err != nil {
+        return nil, nil, "", err
+    }
+
+    status, err := initialStatus(req.Status)
+    if err != nil {
+        return nil, nil, "", err
+    }
+
+    tenantID, scoped, err := actorTenant(ctx)
+    if err != nil {
+        return nil, nil, "", err
+    }
+    if !scoped || tenantID == uuid.Nil {
+        return nil, nil, "", apperrors.Forbidden("loads can only be created on behalf of a tenant")
+    }
+
     load := &models.Load{
         ID:               uuid.New(),
+        TenantID:         tenantID,
         ExternalTMSLoadID: req.ExternalTMSLoadID,
         FreightLoadID:     req.FreightLoadID,
-        Status:           models.JSON(map[string]interface{}{
-            "code": map[string]interface{}{
-                "key":   req.Status.Code.Key,
-                "value": req.Status.Code.Value,
-            },
-            "notes":       req.Status.Notes,
-            "description": req.Status.Description,
-        }),
-        Customer:         models.JSON(req.Customer),
-        BillTo:          models.JSON(req.BillTo),
-        Pickup:          models.JSON(req.Pickup),
-        Consignee:       models.JSON(req.Consignee),
-        Carrier:         models.JSON(req.Carrier),
-        RateData:        models.JSON(req.RateData),
-        Specifications:  models.JSON(req.Specifications),
+        Status:           statusFromDTO(req.Status, status),
+        Customer:         *req.Customer,
         InPalletCount:   req.InPalletCount,
         OutPalletCount:  req.OutPalletCount,
         NumCommodities:  req.NumCommodities,
@@ -52,79 +180,584 @@ func (s *LoadService) CreateLoad(ctx context.Context, req *dto.CreateLoadRequest
         PoNums:          req.PoNums,
         Operator:        req.Operator,
         RouteMiles:      req.RouteMiles,
+        TMSProvider:     s.tmsRegistry.ForTenant(tenantID.String()).ResolveProvider(req.Customer.Name),
+        TMSSyncStatus:   models.TMSSyncPending,
+    }
+    if req.BillTo != nil {
+        load.BillTo = *req.BillTo
+    }
+    if len(req.Stops) > 0 {
+        load.Stops = stops
+        syncLocationsFromStops(load)
+    } else {
+        load.Pickup = *req.Pickup
+        load.Consignee = *req.Consignee
     }
+    if req.Carrier != nil {
+        load.Carrier = *req.Carrier
+    }
+    if req.RateData != nil {
+        load.RateData = *req.RateData
+    }
+    if req.Specifications != nil {
+        load.Specifications = *req.Specifications
+    }
+    return load, stops, status, nil
+}
 
-    if err := s.db.Create(load).Error; err != nil {
-        return nil, fmt.Errorf("failed to create load: %w", err)
+// initialStatus resolves the status of a new load. Loads start as quoted
+// unless the client says otherwise, and cannot start out cancelled.
+func initialStatus(status dto.StatusDTO) (string, error) {
+    if status.Code.Key == "" && status.Code.Value == "" {
+        return models.StatusQuoted, nil
     }
+    key, err := statusKeyFromDTO(status)
+    if err != nil {
+        return "", err
+    }
+    if key == models.StatusCancelled {
+        return "", apperrors.Validation("Invalid status",
+            apperrors.FieldError{Field: "status.code.key", Message: "a new load cannot be cancelled"})
+    }
+    return key, nil
+}
 
-    return s.convertToLoadResponse(load)
+func markTMSSynced(load *models.Load) {
+    now := time.Now()
+    load.TMSSyncStatus = models.TMSSyncSynced
+    load.TMSSyncError = ""
+    load.TMSSyncedAt = &now
 }
 
 func (s *LoadService) GetLoad(ctx context.Context, id string) (*dto.LoadResponse, error) {
     var load models.Load
     
-    if err := s.db.Where("id = ?", id).First(&load).Error; err != nil {
+    db, err := scopeToVisibleLoads(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    if err := db.Where("id = ?", id).First(&load).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
-            return nil, fmt.Errorf("load not found")
+            return nil, apperrors.NotFound("load not found")
         }
         return nil, fmt.Errorf("failed to get load: %w", err)
     }
 
-    return s.convertToLoadResponse(&load)
+    if err := loadStops(s.db, &load); err != nil {
+        return nil, err
+    }
+
+    return convertToLoadResponse(ctx, &load)
 }
 
-func (s *LoadService) ListLoads(ctx context.Context, page, pageSize int) (*dto.ListLoadsResponse, error) {
+// ListLoads returns a page of loads, by page number or, in cursor mode, by
+// keyset pagination from a cursor.
+func (s *LoadService) ListLoads(ctx context.Context, query *dto.ListLoadsQuery) (*dto.ListLoadsResponse, error) {
+    order, err := parseLoadSort(query.Sort)
+    if err != nil {
+        return nil, err
+    }
+    conditions, err := filterConditions(query)
+    if err != nil {
+        return nil, err
+    }
+    visible, err := visibleLoadConditions(ctx)
+    if err != nil {
+        return nil, err
+    }
+    conditions = append(conditions, visible...)
+
+    totalMode := query.TotalMode
+    if totalMode == "" {
+        // Counting is what makes deep lists slow, so cursor mode skips it
+        // unless asked.
+        totalMode = dto.TotalExact
+        if query.UseCursor {
+            totalMode = dto.TotalNone
+        }
+    }
+
     var loads []models.Load
-    var total int64
+    response := &dto.ListLoadsResponse{Size: query.PageSize}
+    if query.UseCursor {
+        loads, err = s.listLoadsByCursor(query, order, conditions, response)
+    } else {
+        offset := (query.Page - 1) * query.PageSize
+        response.Page = query.Page
+        err = conditions.apply(s.db).Order(order.orderClause()).Offset(offset).Limit(query.PageSize).Find(&loads).Error
+        if err != nil {
+            err = fmt.Errorf("failed to list loads: %w", err)
+        }
+    }
+    if err != nil {
+        return nil, err
+    }
 
-    offset := (page - 1) * pageSize
+    if totalMode != dto.TotalNone {
+        total, estimated, err := s.countLoads(conditions, totalMode == dto.TotalEstimate)
+        if err != nil {
+            return nil, err
+        }
+        response.Total = &total
+        response.TotalEstimated = estimated
+    }
 
-    if err := s.db.Model(&models.Load{}).Count(&total).Error; err != nil {
-        return nil, fmt.Errorf("failed to count loads: %w", err)
+    loadPtrs := make([]*models.Load, len(loads))
+    for i := range loads {
+        loadPtrs[i] = &loads[i]
+    }
+    if err := loadStops(s.db, loadPtrs...); err != nil {
+        return nil, err
     }
 
-    if err := s.db.Offset(offset).Limit(pageSize).Find(&loads).Error; err != nil {
+    response.Loads = make([]dto.LoadResponse, len(loads))
+    for i, load := range loads {
+        loadResponse, err := convertToLoadResponse(ctx, &load)
+        if err != nil {
+            return nil, err
+        }
+        response.Loads[i] = *loadResponse
+    }
+
+    return response, nil
+}
+
+// listLoadsByCursor reads the page after (or before) query.Cursor and sets
+// the cursors of the neighbouring pages on response.
+func (s *LoadService) listLoadsByCursor(query *dto.ListLoadsQuery, order loadSort, conditions loadConditions, response *dto.ListLoadsResponse) ([]models.Load, error) {
+    filter := filterFingerprint(query)
+
+    var cursor *loadCursor
+    if query.Cursor != "" {
+        var err error
+        if cursor, err = parseLoadCursor(query.Cursor, order, filter); err != nil {
+            return nil, err
+        }
+        conditions = append(append(loadConditions(nil), conditions...), keysetCondition(order, cursor))
+    }
+    backward := cursor != nil && cursor.Before
+
+    orderClause := order.orderClause()
+    if backward {
+        orderClause = order.reverseOrderClause()
+    }
+
+    // One extra row tells whether another page follows.
+    var loads []models.Load
+    if err := conditions.apply(s.db).Order(orderClause).Limit(query.PageSize + 1).Find(&loads).Error; err != nil {
         return nil, fmt.Errorf("failed to list loads: %w", err)
     }
+    more := len(loads) > query.PageSize
+    if more {
+        loads = loads[:query.PageSize]
+    }
+    if backward {
+        for i, j := 0, len(loads)-1; i < j; i, j = i+1, j-1 {
+            loads[i], loads[j] = loads[j], loads[i]
+        }
+    }
+    if len(loads) == 0 {
+        return loads, nil
+    }
 
-    loadResponses := make([]dto.LoadResponse, len(loads))
-    for i, load := range loads {
-        response, err := s.convertToLoadResponse(&load)
+    first, last := &loads[0], &loads[len(loads)-1]
+    if (backward && more) || (!backward && cursor != nil) {
+        response.PrevCursor = newLoadCursor(order, filter, first, true)
+    }
+    if (!backward && more) || backward {
+        response.NextCursor = newLoadCursor(order, filter, last, false)
+    }
+    return loads, nil
+}
+
+// countLoads counts the loads matching conditions. An estimate comes from
+// the table statistics or the query planner and avoids scanning the table.
+func (s *LoadService) countLoads(conditions loadConditions, estimate bool) (int64, bool, error) {
+    if estimate {
+        total, err := s.estimateLoads(conditions)
+        if err != nil {
+            return 0, false, err
+        }
+        if total >= 0 {
+            return total, true, nil
+        }
+    }
+
+    var total int64
+    if err := conditions.apply(s.db.Model(&models.Load{})).Count(&total).Error; err != nil {
+        return 0, false, fmt.Errorf("failed to count loads: %w", err)
+    }
+    return total, false, nil
+}
+
+// estimateLoads returns -1 when no statistics are available yet.
+func (s *LoadService) estimateLoads(conditions loadConditions) (int64, error) {
+    if len(conditions) == 0 {
+        var rows float64
+        err := s.db.Raw("SELECT reltuples FROM pg_class WHERE oid = 'loads'::regclass").Row().Scan(&rows)
         if err != nil {
+            return 0, fmt.Errorf("failed to estimate loads: %w", err)
+        }
+        return int64(rows), nil
+    }
+
+    where, args := conditions.where()
+    var plan []byte
+    err := s.db.Raw("EXPLAIN (FORMAT JSON) SELECT 1 FROM loads"+where, args...).Row().Scan(&plan)
+    if err != nil {
+        return 0, fmt.Errorf("failed to estimate loads: %w", err)
+    }
+    var explained []struct {
+        Plan struct {
+            Rows float64 `json:"Plan Rows"`
+        } `json:"Plan"`
+    }
+    if err := json.Unmarshal(plan, &explained); err != nil || len(explained) == 0 {
+        return 0, fmt.Errorf("failed to parse query plan: %v", err)
+    }
+    return int64(explained[0].Plan.Rows), nil
+}
+
+func (s *LoadService) UpdateLoad(ctx context.Context, id string, req *dto.UpdateLoadRequest) (*dto.LoadResponse, error) {
+    if err := checkRatesWrite(ctx, req.RateData); err != nil {
+        return nil, err
+    }
+
+    var load models.Load
+
+    tx := s.db.Begin()
+    scoped, err := scopeToTenant(ctx, tx)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if err := scoped.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).First(&load).Error; err != nil {
+        tx.Rollback()
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("load not found")
+        }
+        return nil, fmt.Errorf("failed to get load: %w", err)
+    }
+
+    if load.CancelledAt != nil {
+        tx.Rollback()
+        return nil, apperrors.Conflict("load is cancelled")
+    }
+    if req.FreightLoadID != nil && *req.FreightLoadID != load.FreightLoadID {
+        if err := checkFreightLoadID(tx, load.TenantID, *req.FreightLoadID, load.ID); err != nil {
+            tx.Rollback()
             return nil, err
         }
-        loadResponses[i] = *response
     }
 
-    return &dto.ListLoadsResponse{
-        Loads: loadResponses,
-        Total: total,
-    }, nil
+    if err := loadStops(tx, &load); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    before, err := auditSnapshot(&load)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    // Status changes follow the same rules as POST /status; cancelling
+    // has its own endpoint because it also removes the TMS shipment.
+    from := currentStatus(&load)
+    to := from
+    if req.Status != nil {
+        var err error
+        if to, err = statusKeyFromDTO(*req.Status); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+        if to == models.StatusCancelled {
+            tx.Rollback()
+            return nil, apperrors.Conflict("use DELETE /api/loads/:id or POST /api/loads/:id/status to cancel a load")
+        }
+        if err := checkStatusWrite(ctx, from, to); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+        if to != from {
+            if err := checkTransition(from, to); err != nil {
+                tx.Rollback()
+                return nil, err
+            }
+        }
+    }
+
+    var stops []models.Stop
+    routeChanged := req.Stops != nil || req.Pickup != nil || req.Consignee != nil
+    if routeChanged {
+        var err error
+        if stops, err = updatedStops(&load, req); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+
+    applyLoadUpdate(&load, req)
+    if req.Status != nil {
+        load.Status = statusFromDTO(*req.Status, to)
+    }
+    if routeChanged {
+        load.Stops = stops
+        syncLocationsFromStops(&load)
+    }
+
+    // Loads without a shipment yet are covered by their pending create,
+    // which always sends the latest state.
+    linked := load.ExternalTMSLoadID != ""
+    if linked {
+        load.TMSSyncStatus = models.TMSSyncPending
+    }
+
+    if err := tx.Save(&load).Error; err != nil {
+        tx.Rollback()
+        if isDuplicateFreightLoadID(err) {
+            return nil, checkFreightLoadID(s.db, load.TenantID, load.FreightLoadID, load.ID)
+        }
+        return nil, fmt.Errorf("failed to update load: %w", err)
+    }
+
+    if routeChanged {
+        if err := replaceStops(tx, &load, stops); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+
+    if to != from {
+        if err := recordStatusChange(ctx, tx, load.ID, from, to, req.Status.Notes, models.StatusSourceAPI); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+
+    after, err := auditSnapshot(&load)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if err := recordAudit(ctx, tx, &load, models.AuditActionUpdate, before, after); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    if linked {
+        if err := enqueueOutboxMessage(tx, load.ID, models.OutboxOpUpdateShipment); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit load update: %w", err)
+    }
+
+    return convertToLoadResponse(ctx, &load)
+}
+
+// CancelLoad soft-cancels a load: the row is kept, marked cancelled and the
+// matching TMS shipment is queued for removal.
+func (s *LoadService) CancelLoad(ctx context.Context, id string, req *dto.CancelLoadRequest) (*dto.LoadResponse, error) {
+    var load models.Load
+
+    tx := s.db.Begin()
+    scoped, err := scopeToTenant(ctx, tx)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if err := scoped.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).First(&load).Error; err != nil {
+        tx.Rollback()
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("load not found")
+        }
+        return nil, fmt.Errorf("failed to get load: %w", err)
+    }
+
+    if load.CancelledAt != nil {
+        tx.Rollback()
+        return nil, apperrors.Conflict("load is cancelled")
+    }
+
+    from := currentStatus(&load)
+    if err := checkTransition(from, models.StatusCancelled); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    if err := loadStops(tx, &load); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    before, err := auditSnapshot(&load)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    now := time.Now()
+    load.CancelledAt = &now
+    load.Status = models.LoadStatus{
+        Code: models.LoadStatusCode{
+            Key:   models.StatusCancelled,
+            Value: models.StatusLabel(models.StatusCancelled),
+        },
+        Notes:       req.Reason,
+        Description: "Load cancelled",
+    }
+    linked := load.ExternalTMSLoadID != ""
+    if linked {
+        load.TMSSyncStatus = models.TMSSyncPending
+    }
+
+    if err := tx.Save(&load).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to cancel load: %w", err)
+    }
+
+    if err := recordStatusChange(ctx, tx, load.ID, from, models.StatusCancelled, req.Reason, models.StatusSourceAPI); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    after, err := auditSnapshot(&load)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if err := recordAudit(ctx, tx, &load, models.AuditActionCancel, before, after); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    if linked {
+        if err := enqueueOutboxMessage(tx, load.ID, models.OutboxOpDeleteShipment); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit load cancellation: %w", err)
+    }
+
+    return convertToLoadResponse(ctx, &load)
+}
+
+// updatedStops returns the load's route after req. A full stops list
+// replaces the route; a pickup or consignee alone edits its first or last
+// stop, so clients that predate stops keep working on multi-stop loads.
+func updatedStops(load *models.Load, req *dto.UpdateLoadRequest) ([]models.Stop, error) {
+    if req.Stops != nil {
+        return buildStops(req.Stops)
+    }
+
+    stops := load.Stops
+    if len(stops) == 0 {
+        var err error
+        if stops, err = stopsFromLocations(load.Pickup, load.Consignee); err != nil {
+            return nil, err
+        }
+    }
+    stops = append([]models.Stop(nil), stops...)
+
+    if req.Pickup != nil {
+        if err := applyLocationToStop(&stops[0], *req.Pickup); err != nil {
+            return nil, apperrors.Validation("Invalid pickup",
+                apperrors.FieldError{Field: "pickup.scheduledTime", Message: "must be an RFC3339 timestamp"})
+        }
+    }
+    if req.Consignee != nil {
+        if err := applyLocationToStop(&stops[len(stops)-1], *req.Consignee); err != nil {
+            return nil, apperrors.Validation("Invalid consignee",
+                apperrors.FieldError{Field: "consignee.scheduledTime", Message: "must be an RFC3339 timestamp"})
+        }
+    }
+    return stops, nil
+}
+
+func applyLoadUpdate(load *models.Load, req *dto.UpdateLoadRequest) {
+    if req.FreightLoadID != nil {
+        load.FreightLoadID = *req.FreightLoadID
+    }
+    if req.Customer != nil {
+        load.Customer = *req.Customer
+    }
+    if req.BillTo != nil {
+        load.BillTo = *req.BillTo
+    }
+    if req.Pickup != nil {
+        load.Pickup = *req.Pickup
+    }
+    if req.Consignee != nil {
+        load.Consignee = *req.Consignee
+    }
+    if req.Carrier != nil {
+        load.Carrier = *req.Carrier
+    }
+    if req.RateData != nil {
+        load.RateData = *req.RateData
+    }
+    if req.Specifications != nil {
+        load.Specifications = *req.Specifications
+    }
+    if req.InPalletCount != nil {
+        load.InPalletCount = *req.InPalletCount
+    }
+    if req.OutPalletCount != nil {
+        load.OutPalletCount = *req.OutPalletCount
+    }
+    if req.NumCommodities != nil {
+        load.NumCommodities = *req.NumCommodities
+    }
+    if req.TotalWeight != nil {
+        load.TotalWeight = *req.TotalWeight
+    }
+    if req.BillableWeight != nil {
+        load.BillableWeight = *req.BillableWeight
+    }
+    if req.PoNums != nil {
+        load.PoNums = *req.PoNums
+    }
+    if req.Operator != nil {
+        load.Operator = *req.Operator
+    }
+    if req.RouteMiles != nil {
+        load.RouteMiles = *req.RouteMiles
+    }
 }
 
 // Helper function to convert model to DTO
-func (s *LoadService) convertToLoadResponse(load *models.Load) (*dto.LoadResponse, error) {
-    statusCode := load.Status["code"].(map[string]interface{})
-    
+func convertToLoadResponse(ctx context.Context, load *models.Load) (*dto.LoadResponse, error) {
+    var cancelledAt string
+    if load.CancelledAt != nil {
+        cancelledAt = load.CancelledAt.Format(time.RFC3339)
+    }
+
+    tmsSync := dto.TMSSyncDTO{
+        Status:   load.TMSSyncStatus,
+        CustomID: load.TMSCustomID,
+        Error:    load.TMSSyncError,
+    }
+    if load.TMSSyncedAt != nil {
+        tmsSync.SyncedAt = load.TMSSyncedAt.Format(time.RFC3339)
+    }
+
     return &dto.LoadResponse{
         ID:               load.ID.String(),
         ExternalTMSLoadID: load.ExternalTMSLoadID,
         FreightLoadID:     load.FreightLoadID,
-        Status: dto.StatusDTO{
-            Code: dto.StatusCodeDTO{
-                Key:   statusCode["key"].(string), 
-                Value: statusCode["value"].(string),
-            },
-            Notes:       load.Status["notes"].(string),
-            Description: load.Status["description"].(string),
-        },
+        Status:           toStatusDTO(load.Status),
         Customer:         load.Customer,
         BillTo:          load.BillTo,
         Pickup:          load.Pickup,
         Consignee:       load.Consignee,
+        Stops:           responseStops(load),
         Carrier:         load.Carrier,
-        RateData:        load.RateData,
+        RateData:        rateDataResponse(ctx, load.RateData),
         Specifications:  load.Specifications,
         InPalletCount:   load.InPalletCount,
         OutPalletCount:  load.OutPalletCount,
@@ -134,7 +767,31 @@ func (s *LoadService) convertToLoadResponse(load *models.Load) (*dto.LoadRespons
         PoNums:          load.PoNums,
         Operator:        load.Operator,
         RouteMiles:      load.RouteMiles,
+        CancelledAt:     cancelledAt,
+        TMSSync:         tmsSync,
         CreatedAt:       load.CreatedAt.Format(time.RFC3339),
         UpdatedAt:       load.UpdatedAt.Format(time.RFC3339),
     }, nil
-}
\ No newline at end of file
+}
+
+func toLoadStatus(status dto.StatusDTO) models.LoadStatus {
+    return models.LoadStatus{
+        Code: models.LoadStatusCode{
+            Key:   status.Code.Key,
+            Value: status.Code.Value,
+        },
+        Notes:       status.Notes,
+        Description: status.Description,
+    }
+}
+
+func toStatusDTO(status models.LoadStatus) dto.StatusDTO {
+    return dto.StatusDTO{
+        Code: dto.StatusCodeDTO{
+            Key:   status.Code.Key,
+            Value: status.Code.Value,
+        },
+        Notes:       status.Notes,
+        Description: status.Description,
+    }
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+// resolveStatus maps a requested status to one of ours. Besides our keys
+// and labels it accepts the Turvo codes clients sent before statuses were
+// normalized.
+func resolveStatus(value string) (string, bool) {
+    if status, ok := models.ParseStatus(value); ok {
+        return status, true
+    }
+    status, ok := turvoStatusKeys[strings.TrimSpace(value)]
+    return status, ok
+}
+
+// currentStatus returns the load's status, or "" for loads stored with a
+// status we do not recognise.
+func currentStatus(load *models.Load) string {
+    status, _ := resolveStatus(load.Status.Code.Key)
+    return status
+}
+
+// checkTransition rejects moving from one status to another unless the
+// lifecycle allows it. Loads whose stored status is unknown may move to any
+// status, which is how they get back onto the lifecycle.
+func checkTransition(from, to string) error {
+    if from == "" || models.CanTransition(from, to) {
+        return nil
+    }
+    allowed := models.NextStatuses(from)
+    if len(allowed) == 0 {
+        return apperrors.Conflict(fmt.Sprintf("load is %s and its status can no longer change", from))
+    }
+    return apperrors.Conflict(fmt.Sprintf("cannot change status from %s to %s; allowed: %s",
+        from, to, strings.Join(allowed, ", ")))
+}
+
+// statusKeyFromDTO resolves the status a load payload asks for from its code
+// key, falling back to the label.
+func statusKeyFromDTO(status dto.StatusDTO) (string, error) {
+    if key, ok := resolveStatus(status.Code.Key); ok {
+        return key, nil
+    }
+    if key, ok := resolveStatus(status.Code.Value); ok {
+        return key, nil
+    }
+    return "", apperrors.Validation("Invalid status",
+        apperrors.FieldError{Field: "status.code.key", Message: "must be one of " + strings.Join(models.Statuses(), ", ")})
+}
+
+func statusFromDTO(status dto.StatusDTO, key string) models.LoadStatus {
+    loadStatus := toLoadStatus(status)
+    loadStatus.Code = models.LoadStatusCode{Key: key, Value: models.StatusLabel(key)}
+    return loadStatus
+}
+
+// recordStatusChange appends a status history row for load, changed by the
+// context's actor.
+func recordStatusChange(ctx context.Context, tx *gorm.DB, loadID uuid.UUID, from, to, reason, source string) error {
+    actor, err := contextActor(ctx)
+    if err != nil {
+        return err
+    }
+    entry := models.LoadStatusHistory{
+        ID:            uuid.New(),
+        LoadID:        loadID,
+        FromStatus:    from,
+        ToStatus:      to,
+        ChangedBy:     actor.UserID,
+        ChangedByName: actor.Username,
+        Source:        source,
+        Reason:        reason,
+    }
+    if err := tx.Create(&entry).Error; err != nil {
+        return fmt.Errorf("failed to record status change: %w", err)
+    }
+    return nil
+}
+
+// ChangeStatus moves a load along its lifecycle, recording who changed it
+// and why. Cancelling goes through CancelLoad so the TMS shipment is removed.
+func (s *LoadService) ChangeStatus(ctx context.Context, id string, req *dto.ChangeStatusRequest) (*dto.LoadResponse, error) {
+    to, ok := resolveStatus(req.Status)
+    if !ok {
+        return nil, apperrors.Validation("Invalid status",
+            apperrors.FieldError{Field: "status", Message: "must be one of " + strings.Join(models.Statuses(), ", ")})
+    }
+    if to == models.StatusCancelled {
+        if err := checkCancel(ctx); err != nil {
+            return nil, err
+        }
+        return s.CancelLoad(ctx, id, &dto.CancelLoadRequest{Reason: req.Reason})
+    }
+
+    var load models.Load
+
+    tx := s.db.Begin()
+    scoped, err := scopeToTenant(ctx, tx)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if err := scoped.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).First(&load).Error; err != nil {
+        tx.Rollback()
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("load not found")
+        }
+        return nil, fmt.Errorf("failed to get load: %w", err)
+    }
+
+    if load.CancelledAt != nil {
+        tx.Rollback()
+        return nil, apperrors.Conflict("load is cancelled")
+    }
+
+    from := currentStatus(&load)
+    if err := checkTransition(from, to); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    if err := loadStops(tx, &load); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    before, err := auditSnapshot(&load)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    load.Status.Code = models.LoadStatusCode{Key: to, Value: models.StatusLabel(to)}
+    load.Status.Notes = req.Reason
+
+    linked := load.ExternalTMSLoadID != ""
+    if linked {
+        load.TMSSyncStatus = models.TMSSyncPending
+    }
+
+    if err := tx.Save(&load).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to update load status: %w", err)
+    }
+
+    if err := recordStatusChange(ctx, tx, load.ID, from, to, req.Reason, models.StatusSourceAPI); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    after, err := auditSnapshot(&load)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if err := recordAudit(ctx, tx, &load, models.AuditActionStatus, before, after); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    if linked {
+        if err := enqueueOutboxMessage(tx, load.ID, models.OutboxOpUpdateShipment); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit load status: %w", err)
+    }
+
+    return convertToLoadResponse(ctx, &load)
+}
+
+// GetStatusHistory lists a load's status changes, oldest first.
+func (s *LoadService) GetStatusHistory(ctx context.Context, id string) (*dto.StatusHistoryResponse, error) {
+    var load models.Load
+    db, err := scopeToVisibleLoads(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    if err := db.Select("id").Where("id = ?", id).First(&load).Error; err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("load not found")
+        }
+        return nil, fmt.Errorf("failed to get load: %w", err)
+    }
+
+    var entries []models.LoadStatusHistory
+    if err := s.db.Where("load_id = ?", load.ID).Order("created_at, id").Find(&entries).Error; err != nil {
+        return nil, fmt.Errorf("failed to get status history: %w", err)
+    }
+
+    history := make([]dto.StatusHistoryEntry, len(entries))
+    for i, entry := range entries {
+        history[i] = dto.StatusHistoryEntry{
+            FromStatus:    entry.FromStatus,
+            ToStatus:      entry.ToStatus,
+            ChangedBy:     entry.ChangedBy,
+            ChangedByName: entry.ChangedByName,
+            Source:        entry.Source,
+            Reason:        entry.Reason,
+            ChangedAt:     entry.CreatedAt.Format(time.RFC3339),
+        }
+    }
+    return &dto.StatusHistoryResponse{
+        LoadID:  load.ID.String(),
+        History: history,
+    }, nil
+}
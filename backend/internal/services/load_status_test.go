@@ -0,0 +1,87 @@
+package services
+
+import (
+    "testing"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    tmsDTO "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/models"
+)
+
+func TestCheckTransitionFollowsLifecycle(t *testing.T) {
+    allowed := [][2]string{
+        {models.StatusQuoted, models.StatusTendered},
+        {models.StatusTendered, models.StatusCovered},
+        {models.StatusCovered, models.StatusTendered},
+        {models.StatusDispatched, models.StatusInTransit},
+        {models.StatusInTransit, models.StatusDelivered},
+        {models.StatusDelivered, models.StatusInvoiced},
+        {"", models.StatusDelivered},
+    }
+    for _, pair := range allowed {
+        if err := checkTransition(pair[0], pair[1]); err != nil {
+            t.Errorf("expected %s -> %s to be allowed, got %v", pair[0], pair[1], err)
+        }
+    }
+
+    rejected := [][2]string{
+        {models.StatusQuoted, models.StatusDelivered},
+        {models.StatusInTransit, models.StatusCancelled},
+        {models.StatusDelivered, models.StatusInTransit},
+        {models.StatusInvoiced, models.StatusDelivered},
+        {models.StatusCancelled, models.StatusQuoted},
+    }
+    for _, pair := range rejected {
+        err := checkTransition(pair[0], pair[1])
+        if !apperrors.Is(err, apperrors.CodeConflict) {
+            t.Errorf("expected %s -> %s to conflict, got %v", pair[0], pair[1], err)
+        }
+    }
+}
+
+func TestResolveStatusAcceptsKeysLabelsAndTurvoCodes(t *testing.T) {
+    cases := map[string]string{
+        "covered":    models.StatusCovered,
+        "In Transit": models.StatusInTransit,
+        "2101":       models.StatusTendered,
+        "2106":       models.StatusInTransit,
+    }
+    for input, want := range cases {
+        if got, ok := resolveStatus(input); !ok || got != want {
+            t.Errorf("resolveStatus(%q) = %q, %v; want %q", input, got, ok, want)
+        }
+    }
+    if _, ok := resolveStatus("lost"); ok {
+        t.Error("expected an unknown status to be rejected")
+    }
+}
+
+func TestInitialStatus(t *testing.T) {
+    if status, err := initialStatus(dto.StatusDTO{}); err != nil || status != models.StatusQuoted {
+        t.Errorf("expected new loads to default to quoted, got %q, %v", status, err)
+    }
+    legacy := dto.StatusDTO{Code: dto.StatusCodeDTO{Key: "2102", Value: "Covered"}}
+    if status, err := initialStatus(legacy); err != nil || status != models.StatusCovered {
+        t.Errorf("expected a Turvo code to resolve to covered, got %q, %v", status, err)
+    }
+    cancelled := dto.StatusDTO{Code: dto.StatusCodeDTO{Key: models.StatusCancelled}}
+    if _, err := initialStatus(cancelled); !apperrors.Is(err, apperrors.CodeValidation) {
+        t.Errorf("expected a cancelled new load to be rejected, got %v", err)
+    }
+}
+
+func TestTurvoStatusMapping(t *testing.T) {
+    for _, status := range models.Statuses() {
+        code := toTurvoStatus(tmsDTO.ShipmentStatus{Code: status})
+        if back := fromTurvoStatus(code); back.Code != status {
+            t.Errorf("status %q went to Turvo as %q and came back as %q", status, code.Key, back.Code)
+        }
+    }
+
+    // Codes we do not know about are kept rather than dropped.
+    unknown := fromTurvoStatus(tmsDTO.StatusCode{Key: "9999", Value: "Custom"})
+    if unknown.Code != "9999" || unknown.Label != "Custom" {
+        t.Errorf("expected an unknown Turvo status to pass through, got %+v", unknown)
+    }
+}
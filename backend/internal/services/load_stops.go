@@ -0,0 +1,260 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+// buildStops validates a requested route and numbers it 1..n. A route starts
+// with a pickup, ends with a drop and has every appointment window ending
+// after it starts.
+func buildStops(reqStops []dto.StopDTO) ([]models.Stop, error) {
+    if len(reqStops) < 2 {
+        return nil, apperrors.Validation("Invalid stops",
+            apperrors.FieldError{Field: "stops", Message: "must contain at least a pickup and a drop"})
+    }
+
+    ordered, err := orderStops(reqStops)
+    if err != nil {
+        return nil, err
+    }
+
+    var fields []apperrors.FieldError
+    stops := make([]models.Stop, len(ordered))
+    for i, reqStop := range ordered {
+        field := fmt.Sprintf("stops[%d]", reqStop.index)
+
+        start, err := models.ParseScheduledTime(reqStop.AppointmentStart)
+        if err != nil {
+            fields = append(fields, apperrors.FieldError{Field: field + ".appointmentStart", Message: "must be an RFC3339 timestamp"})
+            continue
+        }
+
+        stop := models.Stop{
+            Sequence:         i + 1,
+            Type:             reqStop.Type,
+            FacilityName:     reqStop.FacilityName,
+            Address:          reqStop.Address,
+            Contact:          reqStop.Contact,
+            AppointmentStart: start,
+            TimeZone:         reqStop.TimeZone,
+            Commodities:      reqStop.Commodities,
+            ReferenceNumbers: reqStop.ReferenceNumbers,
+            Notes:            reqStop.Notes,
+        }
+        if stop.TimeZone == "" {
+            stop.TimeZone = defaultShipmentTimeZone
+        }
+        if reqStop.AppointmentEnd != "" {
+            end, err := models.ParseScheduledTime(reqStop.AppointmentEnd)
+            if err != nil || end.Before(start) {
+                fields = append(fields, apperrors.FieldError{Field: field + ".appointmentEnd", Message: "must not be before appointmentStart"})
+                continue
+            }
+            stop.AppointmentEnd = &end
+        }
+        stops[i] = stop
+    }
+
+    if len(fields) == 0 {
+        if stops[0].Type != models.StopTypePickup {
+            fields = append(fields, apperrors.FieldError{Field: "stops", Message: "the first stop must be a pickup"})
+        }
+        if stops[len(stops)-1].Type != models.StopTypeDrop {
+            fields = append(fields, apperrors.FieldError{Field: "stops", Message: "the last stop must be a drop"})
+        }
+    }
+    if len(fields) > 0 {
+        return nil, apperrors.Validation("Invalid stops", fields...)
+    }
+    return stops, nil
+}
+
+type indexedStop struct {
+    dto.StopDTO
+    index int
+}
+
+// orderStops sorts stops by sequence when every stop has one, and keeps the
+// request order when none do.
+func orderStops(reqStops []dto.StopDTO) ([]indexedStop, error) {
+    ordered := make([]indexedStop, len(reqStops))
+    sequenced := 0
+    seen := make(map[int]bool)
+    for i, stop := range reqStops {
+        ordered[i] = indexedStop{StopDTO: stop, index: i}
+        if stop.Sequence == 0 {
+            continue
+        }
+        if seen[stop.Sequence] {
+            return nil, apperrors.Validation("Invalid stops",
+                apperrors.FieldError{Field: fmt.Sprintf("stops[%d].sequence", i), Message: "must be unique"})
+        }
+        seen[stop.Sequence] = true
+        sequenced++
+    }
+
+    switch sequenced {
+    case 0:
+        return ordered, nil
+    case len(reqStops):
+        sort.SliceStable(ordered, func(i, j int) bool {
+            return ordered[i].Sequence < ordered[j].Sequence
+        })
+        return ordered, nil
+    }
+    return nil, apperrors.Validation("Invalid stops",
+        apperrors.FieldError{Field: "stops", Message: "sequence must be set on every stop or on none"})
+}
+
+// stopsFromLocations builds the two-stop route of a load described only by
+// its pickup and consignee.
+func stopsFromLocations(pickup, consignee models.Location) ([]models.Stop, error) {
+    pickupStop, err := stopFromLocation(models.StopTypePickup, pickup)
+    if err != nil {
+        return nil, apperrors.Validation("Invalid pickup",
+            apperrors.FieldError{Field: "pickup.scheduledTime", Message: "must be an RFC3339 timestamp"})
+    }
+    dropStop, err := stopFromLocation(models.StopTypeDrop, consignee)
+    if err != nil {
+        return nil, apperrors.Validation("Invalid consignee",
+            apperrors.FieldError{Field: "consignee.scheduledTime", Message: "must be an RFC3339 timestamp"})
+    }
+    pickupStop.Sequence, dropStop.Sequence = 1, 2
+    return []models.Stop{pickupStop, dropStop}, nil
+}
+
+func stopFromLocation(stopType string, location models.Location) (models.Stop, error) {
+    start, err := location.ScheduledAt()
+    if err != nil {
+        return models.Stop{}, err
+    }
+    return models.Stop{
+        Type:             stopType,
+        FacilityName:     location.FacilityName,
+        Address:          location.Address,
+        Contact:          location.Contact,
+        AppointmentStart: start,
+        TimeZone:         defaultShipmentTimeZone,
+    }, nil
+}
+
+// applyLocationToStop copies a pickup or consignee edit from a client that
+// does not know about stops onto the matching end of the route.
+func applyLocationToStop(stop *models.Stop, location models.Location) error {
+    start, err := location.ScheduledAt()
+    if err != nil {
+        return err
+    }
+    stop.FacilityName = location.FacilityName
+    stop.Address = location.Address
+    stop.Contact = location.Contact
+    stop.AppointmentStart = start
+    return nil
+}
+
+func locationFromStop(stop models.Stop) models.Location {
+    return models.Location{
+        FacilityName:  stop.FacilityName,
+        ScheduledTime: stop.AppointmentStart.Format(time.RFC3339),
+        Address:       stop.Address,
+        Contact:       stop.Contact,
+    }
+}
+
+// syncLocationsFromStops keeps a load's pickup and consignee in step with
+// the first and last stop, so lane views and older clients stay accurate.
+func syncLocationsFromStops(load *models.Load) {
+    if len(load.Stops) == 0 {
+        return
+    }
+    load.Pickup = locationFromStop(load.Stops[0])
+    load.Consignee = locationFromStop(load.Stops[len(load.Stops)-1])
+}
+
+// replaceStops swaps a load's stored route for stops, assigning IDs.
+func replaceStops(tx *gorm.DB, load *models.Load, stops []models.Stop) error {
+    if err := tx.Where("load_id = ?", load.ID).Delete(&models.Stop{}).Error; err != nil {
+        return fmt.Errorf("failed to delete stops: %w", err)
+    }
+    for i := range stops {
+        stops[i].ID = uuid.New()
+        stops[i].LoadID = load.ID
+        if err := tx.Create(&stops[i]).Error; err != nil {
+            return fmt.Errorf("failed to create stop: %w", err)
+        }
+    }
+    load.Stops = stops
+    return nil
+}
+
+// loadStops fills in the stops of each load with a single query.
+func loadStops(db *gorm.DB, loads ...*models.Load) error {
+    if len(loads) == 0 {
+        return nil
+    }
+
+    ids := make([]uuid.UUID, len(loads))
+    byID := make(map[uuid.UUID]*models.Load, len(loads))
+    for i, load := range loads {
+        ids[i] = load.ID
+        byID[load.ID] = load
+        load.Stops = nil
+    }
+
+    var stops []models.Stop
+    if err := db.Where("load_id IN (?)", ids).Order("load_id, sequence").Find(&stops).Error; err != nil {
+        return fmt.Errorf("failed to get stops: %w", err)
+    }
+    for _, stop := range stops {
+        load := byID[stop.LoadID]
+        load.Stops = append(load.Stops, stop)
+    }
+    return nil
+}
+
+// responseStops returns the load's route, deriving one from the pickup and
+// consignee for loads stored before stops existed.
+func responseStops(load *models.Load) []dto.StopDTO {
+    stops := load.Stops
+    if len(stops) == 0 {
+        stops, _ = stopsFromLocations(load.Pickup, load.Consignee)
+    }
+
+    result := make([]dto.StopDTO, len(stops))
+    for i, stop := range stops {
+        result[i] = dto.StopDTO{
+            Sequence:         stop.Sequence,
+            Type:             stop.Type,
+            FacilityName:     stop.FacilityName,
+            Address:          stop.Address,
+            Contact:          stop.Contact,
+            AppointmentStart: stop.AppointmentStart.Format(time.RFC3339),
+            TimeZone:         stop.TimeZone,
+            Commodities:      stop.Commodities,
+            ReferenceNumbers: stop.ReferenceNumbers,
+            Notes:            stop.Notes,
+        }
+        if stop.ID != uuid.Nil {
+            result[i].ID = stop.ID.String()
+        }
+        if stop.AppointmentEnd != nil {
+            result[i].AppointmentEnd = stop.AppointmentEnd.Format(time.RFC3339)
+        }
+        if result[i].Commodities == nil {
+            result[i].Commodities = []models.Commodity{}
+        }
+        if result[i].ReferenceNumbers == nil {
+            result[i].ReferenceNumbers = []models.ReferenceNumber{}
+        }
+    }
+    return result
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+    "testing"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+)
+
+func stopDTO(sequence int, stopType, start string) dto.StopDTO {
+    return dto.StopDTO{Sequence: sequence, Type: stopType, AppointmentStart: start}
+}
+
+func TestBuildStopsOrdersBySequence(t *testing.T) {
+    stops, err := buildStops([]dto.StopDTO{
+        stopDTO(30, models.StopTypeDrop, "2026-03-03T15:00:00Z"),
+        stopDTO(10, models.StopTypePickup, "2026-03-01T15:00:00Z"),
+        stopDTO(20, models.StopTypeDrop, "2026-03-02T15:00:00Z"),
+    })
+    if err != nil {
+        t.Fatalf("buildStops: %v", err)
+    }
+
+    for i, stop := range stops {
+        if stop.Sequence != i+1 {
+            t.Errorf("expected stop %d to be renumbered %d, got %d", i, i+1, stop.Sequence)
+        }
+    }
+    if stops[0].Type != models.StopTypePickup || stops[1].AppointmentStart.Day() != 2 {
+        t.Errorf("stops were not ordered by sequence: %+v", stops)
+    }
+    if stops[0].TimeZone != defaultShipmentTimeZone {
+        t.Errorf("expected the default time zone, got %q", stops[0].TimeZone)
+    }
+}
+
+func TestBuildStopsRejectsInvalidRoutes(t *testing.T) {
+    tests := map[string][]dto.StopDTO{
+        "single stop": {
+            stopDTO(0, models.StopTypePickup, "2026-03-01T15:00:00Z"),
+        },
+        "starts with a drop": {
+            stopDTO(0, models.StopTypeDrop, "2026-03-01T15:00:00Z"),
+            stopDTO(0, models.StopTypeDrop, "2026-03-02T15:00:00Z"),
+        },
+        "ends with a pickup": {
+            stopDTO(0, models.StopTypePickup, "2026-03-01T15:00:00Z"),
+            stopDTO(0, models.StopTypePickup, "2026-03-02T15:00:00Z"),
+        },
+        "duplicate sequence": {
+            stopDTO(1, models.StopTypePickup, "2026-03-01T15:00:00Z"),
+            stopDTO(1, models.StopTypeDrop, "2026-03-02T15:00:00Z"),
+        },
+        "partial sequence": {
+            stopDTO(1, models.StopTypePickup, "2026-03-01T15:00:00Z"),
+            stopDTO(0, models.StopTypeDrop, "2026-03-02T15:00:00Z"),
+        },
+        "window ends before it starts": {
+            stopDTO(0, models.StopTypePickup, "2026-03-01T15:00:00Z"),
+            {Type: models.StopTypeDrop, AppointmentStart: "2026-03-02T15:00:00Z", AppointmentEnd: "2026-03-02T14:00:00Z"},
+        },
+    }
+
+    for name, reqStops := range tests {
+        if _, err := buildStops(reqStops); !apperrors.Is(err, apperrors.CodeValidation) {
+            t.Errorf("%s: expected a validation error, got %v", name, err)
+        }
+    }
+}
+
+func TestUpdatedStopsAppliesLegacyLocationsToRouteEnds(t *testing.T) {
+    load := &models.Load{}
+    load.Stops, _ = buildStops([]dto.StopDTO{
+        stopDTO(0, models.StopTypePickup, "2026-03-01T15:00:00Z"),
+        stopDTO(0, models.StopTypeDrop, "2026-03-02T15:00:00Z"),
+        stopDTO(0, models.StopTypeDrop, "2026-03-03T15:00:00Z"),
+    })
+
+    consignee := models.Location{
+        ScheduledTime: "2026-03-04T15:00:00Z",
+        Address:       models.Address{City: "Austin", State: "TX"},
+    }
+    stops, err := updatedStops(load, &dto.UpdateLoadRequest{Consignee: &consignee})
+    if err != nil {
+        t.Fatalf("updatedStops: %v", err)
+    }
+    if len(stops) != 3 || stops[2].Address.City != "Austin" || stops[1].Address.City != "" {
+        t.Errorf("expected only the last stop to change, got %+v", stops)
+    }
+    if load.Stops[2].Address.City != "" {
+        t.Error("expected the load's stops to be left untouched")
+    }
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+type OutboxService struct {
+    db *gorm.DB
+}
+
+func NewOutboxService(db *gorm.DB) *OutboxService {
+    return &OutboxService{
+        db: db,
+    }
+}
+
+func (s *OutboxService) ListMessages(ctx context.Context, status string, page, pageSize int) (*dto.ListOutboxMessagesResponse, error) {
+    var messages []models.OutboxMessage
+    var total int64
+
+    query := s.db.Model(&models.OutboxMessage{})
+    if status != "" {
+        query = query.Where("status = ?", status)
+    }
+
+    if err := query.Count(&total).Error; err != nil {
+        return nil, fmt.Errorf("failed to count outbox messages: %w", err)
+    }
+
+    offset := (page - 1) * pageSize
+    if err := query.Order("created_at desc").Offset(offset).Limit(pageSize).Find(&messages).Error; err != nil {
+        return nil, fmt.Errorf("failed to list outbox messages: %w", err)
+    }
+
+    responses := make([]dto.OutboxMessageResponse, len(messages))
+    for i := range messages {
+        responses[i] = convertToOutboxMessageResponse(&messages[i])
+    }
+
+    return &dto.ListOutboxMessagesResponse{
+        Messages: responses,
+        Total:    total,
+    }, nil
+}
+
+// ReplayMessage puts a dead-lettered message back in the queue with a fresh
+// attempt budget.
+func (s *OutboxService) ReplayMessage(ctx context.Context, id string) (*dto.OutboxMessageResponse, error) {
+    var message models.OutboxMessage
+
+    tx := s.db.Begin()
+    if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", id).First(&message).Error; err != nil {
+        tx.Rollback()
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("outbox message not found")
+        }
+        return nil, fmt.Errorf("failed to get outbox message: %w", err)
+    }
+
+    if message.Status != models.OutboxDead {
+        tx.Rollback()
+        return nil, apperrors.Conflict("outbox message is not dead-lettered")
+    }
+
+    message.Status = models.OutboxPending
+    message.Attempts = 0
+    message.NextAttemptAt = time.Now()
+
+    if err := tx.Save(&message).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to replay outbox message: %w", err)
+    }
+
+    if err := tx.Model(&models.Load{}).Where("id = ?", message.LoadID).
+        Update("tms_sync_status", models.TMSSyncPending).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to reset load sync state: %w", err)
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit outbox replay: %w", err)
+    }
+
+    response := convertToOutboxMessageResponse(&message)
+    return &response, nil
+}
+
+// enqueueOutboxMessage must be called with the transaction that writes the
+// load so the message and the change commit or roll back together.
+func enqueueOutboxMessage(tx *gorm.DB, loadID uuid.UUID, operation string) error {
+    message := &models.OutboxMessage{
+        ID:            uuid.New(),
+        LoadID:        loadID,
+        Operation:     operation,
+        Status:        models.OutboxPending,
+        NextAttemptAt: time.Now(),
+    }
+
+    if err := tx.Create(message).Error; err != nil {
+        return fmt.Errorf("failed to enqueue %s: %w", operation, err)
+    }
+    return nil
+}
+
+func convertToOutboxMessageResponse(message *models.OutboxMessage) dto.OutboxMessageResponse {
+    var deliveredAt string
+    if message.DeliveredAt != nil {
+        deliveredAt = message.DeliveredAt.Format(time.RFC3339)
+    }
+
+    return dto.OutboxMessageResponse{
+        ID:            message.ID.String(),
+        LoadID:        message.LoadID.String(),
+        Operation:     message.Operation,
+        Status:        message.Status,
+        Attempts:      message.Attempts,
+        NextAttemptAt: message.NextAttemptAt.Format(time.RFC3339),
+        LastError:     message.LastError,
+        DeliveredAt:   deliveredAt,
+        CreatedAt:     message.CreatedAt.Format(time.RFC3339),
+        UpdatedAt:     message.UpdatedAt.Format(time.RFC3339),
+    }
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/jinzhu/gorm"
+)
+
+type OutboxWorkerConfig struct {
+    PollInterval time.Duration
+    BatchSize    int
+    MaxAttempts  int
+    BaseBackoff  time.Duration
+    MaxBackoff   time.Duration
+    // ClaimTimeout is how long a claimed message stays hidden from other
+    // workers before it is considered abandoned and picked up again.
+    ClaimTimeout time.Duration
+}
+
+// OutboxWorker delivers queued TMS calls with exponential backoff and moves
+// messages that exhaust their attempts to the dead letter state.
+type OutboxWorker struct {
+    db          *gorm.DB
+    tmsRegistry interfaces.TMSProviderRegistry
+    config      OutboxWorkerConfig
+}
+
+func NewOutboxWorker(db *gorm.DB, tmsRegistry interfaces.TMSProviderRegistry, config OutboxWorkerConfig) *OutboxWorker {
+    if config.PollInterval <= 0 {
+        config.PollInterval = 5 * time.Second
+    }
+    if config.BatchSize <= 0 {
+        config.BatchSize = 20
+    }
+    if config.MaxAttempts <= 0 {
+        config.MaxAttempts = 10
+    }
+    if config.BaseBackoff <= 0 {
+        config.BaseBackoff = 5 * time.Second
+    }
+    if config.MaxBackoff <= 0 {
+        config.MaxBackoff = 30 * time.Minute
+    }
+    if config.ClaimTimeout <= 0 {
+        config.ClaimTimeout = 5 * time.Minute
+    }
+
+    return &OutboxWorker{
+        db:          db,
+        tmsRegistry: tmsRegistry,
+        config:      config,
+    }
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (w *OutboxWorker) Run(ctx context.Context) {
+    ticker := time.NewTicker(w.config.PollInterval)
+    defer ticker.Stop()
+
+    for {
+        if err := w.processBatch(ctx); err != nil {
+            log.Printf("Outbox worker: %v", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (w *OutboxWorker) processBatch(ctx context.Context) error {
+    messages, err := w.claimBatch()
+    if err != nil {
+        return err
+    }
+
+    for i := range messages {
+        if ctx.Err() != nil {
+            return nil
+        }
+
+        message := &messages[i]
+        if err := w.deliver(ctx, message); err != nil {
+            w.recordFailure(message, err)
+            continue
+        }
+        w.recordSuccess(message)
+    }
+
+    return nil
+}
+
+// claimBatch locks due messages, pushes their next attempt past the claim
+// timeout and commits, so delivery happens outside a database transaction.
+// Only a load's oldest pending message can be claimed, so a load's messages
+// are delivered in order even by several workers: the next one waits until
+// the one before it is delivered or dead-lettered.
+func (w *OutboxWorker) claimBatch() ([]models.OutboxMessage, error) {
+    var messages []models.OutboxMessage
+    now := time.Now()
+
+    tx := w.db.Begin()
+    if err := tx.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+        Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, now).
+        Where("id IN (SELECT DISTINCT ON (load_id) id FROM outbox_messages WHERE status = ? ORDER BY load_id, created_at, id)", models.OutboxPending).
+        Order("created_at").
+        Limit(w.config.BatchSize).
+        Find(&messages).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+    }
+
+    if len(messages) == 0 {
+        tx.Rollback()
+        return nil, nil
+    }
+
+    ids := make([]string, len(messages))
+    for i, message := range messages {
+        ids[i] = message.ID.String()
+    }
+
+    if err := tx.Model(&models.OutboxMessage{}).Where("id IN (?)", ids).
+        Update("next_attempt_at", now.Add(w.config.ClaimTimeout)).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit outbox claim: %w", err)
+    }
+
+    return messages, nil
+}
+
+// deliver pushes the load's current state rather than a snapshot taken at
+// enqueue time, so a retried message never overwrites a newer edit.
+func (w *OutboxWorker) deliver(ctx context.Context, message *models.OutboxMessage) error {
+    var load models.Load
+    if err := w.db.Where("id = ?", message.LoadID).First(&load).Error; err != nil {
+        return fmt.Errorf("failed to get load: %w", err)
+    }
+
+    if message.Operation == models.OutboxOpDeleteShipment {
+        return w.deleteShipment(ctx, &load)
+    }
+    if load.CancelledAt != nil {
+        return nil
+    }
+
+    if err := loadStops(w.db, &load); err != nil {
+        return err
+    }
+
+    shipment, err := convertLoadToShipment(&load)
+    if err != nil {
+        return fmt.Errorf("failed to build shipment: %w", err)
+    }
+
+    providerName, tmsService, err := providerForLoad(w.tmsRegistry, &load)
+    if err != nil {
+        return err
+    }
+
+    updates := map[string]interface{}{
+        "tms_provider": providerName,
+    }
+
+    // A create whose shipment already exists (e.g. a replay after a lost
+    // response) is sent as an update to avoid duplicate shipments.
+    if load.ExternalTMSLoadID == "" {
+        if message.Operation != models.OutboxOpCreateShipment {
+            return fmt.Errorf("load has no TMS shipment to update")
+        }
+        created, err := tmsService.CreateShipment(ctx, shipment)
+        if err != nil {
+            return fmt.Errorf("failed to create shipment in TMS: %w", err)
+        }
+        if created.ID == "" {
+            return fmt.Errorf("TMS response did not include a shipment ID")
+        }
+        updates["external_tms_load_id"] = created.ID
+        updates["tms_custom_id"] = created.CustomID
+    } else {
+        if _, err := tmsService.UpdateShipment(ctx, load.ExternalTMSLoadID, shipment); err != nil {
+            return fmt.Errorf("failed to update shipment in TMS: %w", err)
+        }
+    }
+
+    return w.recordDelivery(ctx, &load, updates)
+}
+
+// deleteShipment removes a cancelled load's shipment. A shipment already
+// gone from the TMS needs no deleting.
+func (w *OutboxWorker) deleteShipment(ctx context.Context, load *models.Load) error {
+    if load.ExternalTMSLoadID == "" {
+        return nil
+    }
+
+    providerName, tmsService, err := providerForLoad(w.tmsRegistry, load)
+    if err != nil {
+        return err
+    }
+    err = tmsService.DeleteShipment(ctx, load.ExternalTMSLoadID)
+    if err != nil && !apperrors.Is(err, apperrors.CodeNotFound) {
+        return fmt.Errorf("failed to delete shipment in TMS: %w", err)
+    }
+
+    return w.recordDelivery(ctx, load, map[string]interface{}{
+        "tms_provider": providerName,
+    })
+}
+
+// recordDelivery saves what a TMS call changed on the load. A load edited or
+// cancelled while the call was in flight may not have queued a message of
+// its own, since it had no shipment yet, so instead of being marked synced
+// it is queued again: as an update, or as a delete once cancelled. Changes
+// such as a new shipment ID are audited as made by the system.
+func (w *OutboxWorker) recordDelivery(ctx context.Context, load *models.Load, updates map[string]interface{}) error {
+    var current models.Load
+
+    tx := w.db.Begin()
+    if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", load.ID).First(&current).Error; err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to get load: %w", err)
+    }
+
+    if current.UpdatedAt.Equal(load.UpdatedAt) {
+        now := time.Now()
+        updates["tms_sync_status"] = models.TMSSyncSynced
+        updates["tms_sync_error"] = ""
+        updates["tms_synced_at"] = &now
+    } else {
+        operation := models.OutboxOpUpdateShipment
+        if current.CancelledAt != nil {
+            operation = models.OutboxOpDeleteShipment
+        }
+        if err := enqueueOutboxMessage(tx, load.ID, operation); err != nil {
+            tx.Rollback()
+            return err
+        }
+    }
+
+    ctx = requestctx.WithSystem(ctx)
+    if err := updateLoadAudited(ctx, tx, &current, updates); err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to record TMS sync state: %w", err)
+    }
+    if err := tx.Commit().Error; err != nil {
+        return fmt.Errorf("failed to commit TMS sync state: %w", err)
+    }
+    return nil
+}
+
+func (w *OutboxWorker) recordSuccess(message *models.OutboxMessage) {
+    now := time.Now()
+    err := w.db.Model(message).Updates(map[string]interface{}{
+        "status":       models.OutboxDelivered,
+        "attempts":     message.Attempts + 1,
+        "last_error":   "",
+        "delivered_at": &now,
+    }).Error
+    if err != nil {
+        log.Printf("Outbox worker: failed to mark message %s delivered: %v", message.ID, err)
+    }
+}
+
+func (w *OutboxWorker) recordFailure(message *models.OutboxMessage, deliveryErr error) {
+    attempts := message.Attempts + 1
+    updates := map[string]interface{}{
+        "attempts":   attempts,
+        "last_error": deliveryErr.Error(),
+    }
+    loadUpdates := map[string]interface{}{
+        "tms_sync_error": deliveryErr.Error(),
+    }
+
+    if attempts >= w.config.MaxAttempts {
+        updates["status"] = models.OutboxDead
+        loadUpdates["tms_sync_status"] = models.TMSSyncFailed
+        log.Printf("Outbox worker: message %s dead-lettered after %d attempts: %v", message.ID, attempts, deliveryErr)
+    } else {
+        updates["next_attempt_at"] = time.Now().Add(w.backoff(attempts))
+    }
+
+    if err := w.db.Model(message).Updates(updates).Error; err != nil {
+        log.Printf("Outbox worker: failed to record failure for message %s: %v", message.ID, err)
+    }
+    // UpdateColumns leaves updated_at alone: a failed delivery is not an
+    // edit, and recordDelivery tells edits apart by updated_at.
+    if err := w.db.Model(&models.Load{}).Where("id = ?", message.LoadID).UpdateColumns(loadUpdates).Error; err != nil {
+        log.Printf("Outbox worker: failed to record sync error for load %s: %v", message.LoadID, err)
+    }
+}
+
+func (w *OutboxWorker) backoff(attempts int) time.Duration {
+    delay := w.config.BaseBackoff
+    for i := 1; i < attempts; i++ {
+        delay *= 2
+        if delay >= w.config.MaxBackoff {
+            return w.config.MaxBackoff
+        }
+    }
+    return delay
+}
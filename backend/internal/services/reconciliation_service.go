@@ -0,0 +1,368 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    tmsDTO "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/jinzhu/gorm"
+)
+
+const reconciliationPageSize = 100
+
+// ReconciliationService compares local loads against TMS shipments and can
+// repair the differences in either direction.
+type ReconciliationService struct {
+    db          *gorm.DB
+    tmsRegistry interfaces.TMSProviderRegistry
+}
+
+func NewReconciliationService(db *gorm.DB, tmsRegistry interfaces.TMSProviderRegistry) *ReconciliationService {
+    return &ReconciliationService{
+        db:          db,
+        tmsRegistry: tmsRegistry,
+    }
+}
+
+type reconciledPair struct {
+    scope     tmsScope
+    provider  string
+    load      *models.Load
+    shipment  *tmsDTO.Shipment
+    matchedBy string
+}
+
+type unmatchedShipment struct {
+    scope    tmsScope
+    provider string
+    shipment *tmsDTO.Shipment
+}
+
+// reconciliation accumulates the outcome across providers.
+type reconciliation struct {
+    report         *dto.ReconciliationReport
+    pairs          []reconciledPair
+    missingInTMS   []*models.Load
+    missingLocally []unmatchedShipment
+}
+
+func (s *ReconciliationService) Reconcile(ctx context.Context, req *dto.ReconciliationRequest) (*dto.ReconciliationReport, error) {
+    if req.Repair != "" && req.Repair != dto.ReconcileRepairLocal && req.Repair != dto.ReconcileRepairTMS {
+        return nil, apperrors.Validation("invalid repair direction",
+            apperrors.FieldError{Field: "repair", Message: "must be either local or tms"})
+    }
+
+    scopes, err := tmsScopes(s.db, s.tmsRegistry)
+    if err != nil {
+        return nil, err
+    }
+
+    r := &reconciliation{
+        report: &dto.ReconciliationReport{
+            GeneratedAt:    time.Now().Format(time.RFC3339),
+            MissingInTMS:   []dto.ReconciliationLoad{},
+            MissingLocally: []dto.ReconciliationLoad{},
+            Mismatches:     []dto.LoadMismatch{},
+        },
+    }
+
+    // Shipments only match loads of the tenants sharing their TMS.
+    for _, scope := range scopes {
+        var loads []models.Load
+        if err := scope.apply(s.db).Where("cancelled_at IS NULL").Find(&loads).Error; err != nil {
+            return nil, fmt.Errorf("failed to list loads: %w", err)
+        }
+        loadPtrs := make([]*models.Load, len(loads))
+        for i := range loads {
+            loadPtrs[i] = &loads[i]
+        }
+        if err := loadStops(s.db, loadPtrs...); err != nil {
+            return nil, err
+        }
+        r.report.LocalLoads += len(loads)
+
+        loadsByProvider := make(map[string][]*models.Load)
+        for i := range loads {
+            name := loads[i].TMSProvider
+            if name == "" {
+                name = scope.registry.ResolveProvider(loads[i].Customer.Name)
+            }
+            loadsByProvider[name] = append(loadsByProvider[name], &loads[i])
+        }
+
+        for _, provider := range scope.registry.ProviderNames() {
+            tmsService, err := scope.registry.Provider(provider)
+            if err != nil {
+                return nil, err
+            }
+
+            shipments, err := listAllShipments(ctx, tmsService)
+            if err != nil {
+                return nil, tmsError(scope.label(provider), err)
+            }
+
+            r.reconcileProvider(scope, provider, loadsByProvider[provider], shipments)
+        }
+    }
+
+    switch req.Repair {
+    case dto.ReconcileRepairLocal:
+        r.report.Repair = s.repairLocal(ctx, r)
+    case dto.ReconcileRepairTMS:
+        r.report.Repair = s.repairTMS(ctx, r)
+    }
+
+    return r.report, nil
+}
+
+func (r *reconciliation) reconcileProvider(scope tmsScope, provider string, loads []*models.Load, shipments []tmsDTO.Shipment) {
+    r.report.TMSShipments += len(shipments)
+
+    byID := make(map[string]*tmsDTO.Shipment, len(shipments))
+    bySourceID := make(map[string]*tmsDTO.Shipment, len(shipments))
+    for i := range shipments {
+        shipment := &shipments[i]
+        byID[shipment.ID] = shipment
+        if shipment.SourceID != "" {
+            bySourceID[shipment.SourceID] = shipment
+        }
+    }
+
+    seen := make(map[string]bool, len(shipments))
+
+    for _, load := range loads {
+        pair := reconciledPair{scope: scope, provider: provider, load: load}
+        if shipment, ok := byID[load.ExternalTMSLoadID]; ok && load.ExternalTMSLoadID != "" {
+            pair.shipment, pair.matchedBy = shipment, "id"
+        } else if shipment, ok := bySourceID[load.FreightLoadID]; ok && load.FreightLoadID != "" {
+            pair.shipment, pair.matchedBy = shipment, "customerOrderSourceId"
+        }
+
+        if pair.shipment == nil {
+            if load.ExternalTMSLoadID == "" && load.TMSSyncStatus == models.TMSSyncPending {
+                r.report.InFlight++
+                continue
+            }
+            r.missingInTMS = append(r.missingInTMS, load)
+            r.report.MissingInTMS = append(r.report.MissingInTMS, dto.ReconciliationLoad{
+                Provider:      scope.label(provider),
+                LoadID:        load.ID.String(),
+                ShipmentID:    load.ExternalTMSLoadID,
+                FreightLoadID: load.FreightLoadID,
+            })
+            continue
+        }
+
+        seen[pair.shipment.ID] = true
+        r.report.Matched++
+
+        fields := compareLoadToShipment(load, pair.shipment)
+        if pair.matchedBy != "id" {
+            fields = append([]dto.FieldMismatch{{
+                Field: "externalTMSLoadID",
+                Local: load.ExternalTMSLoadID,
+                TMS:   pair.shipment.ID,
+            }}, fields...)
+        }
+        if len(fields) > 0 {
+            r.pairs = append(r.pairs, pair)
+            r.report.Mismatches = append(r.report.Mismatches, dto.LoadMismatch{
+                Provider:   scope.label(provider),
+                LoadID:     load.ID.String(),
+                ShipmentID: pair.shipment.ID,
+                MatchedBy:  pair.matchedBy,
+                Fields:     fields,
+            })
+        }
+    }
+
+    for i := range shipments {
+        shipment := &shipments[i]
+        if seen[shipment.ID] {
+            continue
+        }
+        r.missingLocally = append(r.missingLocally, unmatchedShipment{scope: scope, provider: provider, shipment: shipment})
+        r.report.MissingLocally = append(r.report.MissingLocally, dto.ReconciliationLoad{
+            Provider:      scope.label(provider),
+            ShipmentID:    shipment.ID,
+            FreightLoadID: shipment.SourceID,
+        })
+    }
+}
+
+func listAllShipments(ctx context.Context, tmsService interfaces.TMSService) ([]tmsDTO.Shipment, error) {
+    var shipments []tmsDTO.Shipment
+    start := 0
+    for {
+        shipmentPage, err := tmsService.ListShipments(ctx, start, reconciliationPageSize, tmsDTO.ListShipmentsFilter{})
+        if err != nil {
+            return nil, fmt.Errorf("failed to list shipments from TMS: %w", err)
+        }
+
+        shipments = append(shipments, shipmentPage.Shipments...)
+
+        if !shipmentPage.MoreAvailable || len(shipmentPage.Shipments) == 0 {
+            return shipments, nil
+        }
+        start += len(shipmentPage.Shipments)
+    }
+}
+
+// repairLocal overwrites local loads with the TMS copy and imports shipments
+// that have no local load. Loads missing from the TMS cannot be repaired from
+// it and are skipped. Repairs are audited as made by the system.
+func (s *ReconciliationService) repairLocal(ctx context.Context, r *reconciliation) *dto.ReconciliationRepair {
+    repair := &dto.ReconciliationRepair{Direction: dto.ReconcileRepairLocal}
+    repair.Skipped += len(r.missingInTMS)
+
+    apply := func(scope tmsScope, provider string, shipment *tmsDTO.Shipment) {
+        outcome, err := upsertShipment(ctx, s.db, scope, provider, shipment)
+        switch {
+        case err != nil:
+            repair.Errors = append(repair.Errors, fmt.Sprintf("%s shipment %s: %v", scope.label(provider), shipment.ID, err))
+        case outcome == syncSkipped:
+            repair.Skipped++
+        default:
+            repair.Repaired++
+        }
+    }
+
+    for _, pair := range r.pairs {
+        // Link loads matched by source ID first so the upsert finds them
+        // instead of importing the shipment as a new load.
+        if pair.matchedBy != "id" {
+            err := s.updateLoad(ctx, pair.load, map[string]interface{}{
+                "tms_provider":         pair.provider,
+                "external_tms_load_id": pair.shipment.ID,
+            }, "")
+            if err != nil {
+                repair.Errors = append(repair.Errors, fmt.Sprintf("load %s: %v", pair.load.ID, err))
+                continue
+            }
+        }
+        apply(pair.scope, pair.provider, pair.shipment)
+    }
+    for _, unmatched := range r.missingLocally {
+        apply(unmatched.scope, unmatched.provider, unmatched.shipment)
+    }
+
+    return repair
+}
+
+// repairTMS queues outbox deliveries that push local loads to the TMS.
+// Shipments with no local load are left alone rather than deleted.
+func (s *ReconciliationService) repairTMS(ctx context.Context, r *reconciliation) *dto.ReconciliationRepair {
+    repair := &dto.ReconciliationRepair{Direction: dto.ReconcileRepairTMS}
+    repair.Skipped += len(r.missingLocally)
+
+    for _, pair := range r.pairs {
+        err := s.queueRepair(ctx, pair.load, pair.shipment.ID, models.OutboxOpUpdateShipment)
+        if err != nil {
+            repair.Errors = append(repair.Errors, fmt.Sprintf("load %s: %v", pair.load.ID, err))
+            continue
+        }
+        repair.Repaired++
+    }
+
+    // The stored shipment ID, if any, points at nothing, so it is cleared and
+    // the shipment is created again.
+    for _, load := range r.missingInTMS {
+        if err := s.queueRepair(ctx, load, "", models.OutboxOpCreateShipment); err != nil {
+            repair.Errors = append(repair.Errors, fmt.Sprintf("load %s: %v", load.ID, err))
+            continue
+        }
+        repair.Repaired++
+    }
+
+    return repair
+}
+
+func (s *ReconciliationService) queueRepair(ctx context.Context, load *models.Load, shipmentID, operation string) error {
+    return s.updateLoad(ctx, load, map[string]interface{}{
+        "external_tms_load_id": shipmentID,
+        "tms_sync_status":      models.TMSSyncPending,
+    }, operation)
+}
+
+// updateLoad applies a repair to a load, audited as made by the system, and
+// queues the outbox operation, if any, in the same transaction.
+func (s *ReconciliationService) updateLoad(ctx context.Context, load *models.Load, updates map[string]interface{}, operation string) error {
+    var current models.Load
+
+    tx := s.db.Begin()
+    if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", load.ID).First(&current).Error; err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to get load: %w", err)
+    }
+
+    ctx = requestctx.WithSystem(ctx)
+    if err := updateLoadAudited(ctx, tx, &current, updates); err != nil {
+        tx.Rollback()
+        return err
+    }
+
+    if operation != "" {
+        if err := enqueueOutboxMessage(tx, load.ID, operation); err != nil {
+            tx.Rollback()
+            return err
+        }
+    }
+
+    return tx.Commit().Error
+}
+
+// compareLoadToShipment lists the fields where the load and shipment disagree.
+func compareLoadToShipment(load *models.Load, shipment *tmsDTO.Shipment) []dto.FieldMismatch {
+    var fields []dto.FieldMismatch
+
+    compare := func(field, local, tms string) {
+        if !strings.EqualFold(strings.TrimSpace(local), strings.TrimSpace(tms)) {
+            fields = append(fields, dto.FieldMismatch{Field: field, Local: local, TMS: tms})
+        }
+    }
+
+    // Loads stored before statuses were normalized may still hold a TMS code.
+    localStatus := load.Status.Code.Key
+    if status, ok := resolveStatus(localStatus); ok {
+        localStatus = status
+    }
+    compare("status", localStatus, shipment.Status.Code)
+    compare("lane.start", formatLaneEnd(load.Pickup), formatLane(shipment.Origin.City, shipment.Origin.State))
+    compare("lane.end", formatLaneEnd(load.Consignee), formatLane(shipment.Destination.City, shipment.Destination.State))
+    compare("pickup.scheduledTime", normalizeScheduledTime(load.Pickup.ScheduledTime), formatShipmentDate(shipment.Origin.Date))
+    compare("consignee.scheduledTime", normalizeScheduledTime(load.Consignee.ScheduledTime), formatShipmentDate(shipment.Destination.Date))
+    compare("customer.name", load.Customer.Name, shipment.CustomerName)
+    // TMSs without a route of their own only carry origin and destination.
+    if len(shipment.Stops) > 0 && len(load.Stops) > 0 {
+        compare("stops", strconv.Itoa(len(load.Stops)), strconv.Itoa(len(shipment.Stops)))
+    }
+
+    return fields
+}
+
+// normalizeScheduledTime renders an RFC3339 time in UTC so that the same
+// instant in different offsets compares equal. Unparseable values are
+// returned unchanged.
+func normalizeScheduledTime(raw string) string {
+    t, err := models.ParseScheduledTime(raw)
+    if err != nil {
+        return raw
+    }
+    return t.UTC().Format(time.RFC3339)
+}
+
+func formatShipmentDate(date time.Time) string {
+    if date.IsZero() {
+        return ""
+    }
+    return date.UTC().Format(time.RFC3339)
+}
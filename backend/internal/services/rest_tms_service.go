@@ -0,0 +1,430 @@
+package services
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "net/url"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/models"
+)
+
+// Neutral shipment fields that can be mapped onto a provider's JSON payload.
+const (
+    restFieldID             = "id"
+    restFieldCustomID       = "customId"
+    restFieldSourceID       = "sourceId"
+    restFieldLTL            = "ltl"
+    restFieldStatusCode     = "status.code"
+    restFieldStatusLabel    = "status.label"
+    restFieldOriginCity     = "origin.city"
+    restFieldOriginState    = "origin.state"
+    restFieldOriginDate     = "origin.date"
+    restFieldOriginTimeZone = "origin.timeZone"
+    restFieldDestCity       = "destination.city"
+    restFieldDestState      = "destination.state"
+    restFieldDestDate       = "destination.date"
+    restFieldDestTimeZone   = "destination.timeZone"
+    restFieldCustomerName   = "customerName"
+    restFieldCarrierName    = "carrierName"
+    restFieldStops          = "stops"
+    restFieldLastUpdatedOn  = "lastUpdatedOn"
+)
+
+var restShipmentFields = []string{
+    restFieldID, restFieldCustomID, restFieldSourceID, restFieldLTL,
+    restFieldStatusCode, restFieldStatusLabel,
+    restFieldOriginCity, restFieldOriginState, restFieldOriginDate, restFieldOriginTimeZone,
+    restFieldDestCity, restFieldDestState, restFieldDestDate, restFieldDestTimeZone,
+    restFieldCustomerName, restFieldCarrierName, restFieldStops, restFieldLastUpdatedOn,
+}
+
+// RESTTMSConfig describes a generic REST/JSON TMS. Paths may contain an {id}
+// placeholder and header values are expanded from the environment so secrets
+// can stay out of the config file.
+type RESTTMSConfig struct {
+    BaseURL        string            `json:"baseUrl"`
+    Headers        map[string]string `json:"headers"`
+    TimeoutSeconds int               `json:"timeoutSeconds"`
+    Paths          RESTTMSPaths      `json:"paths"`
+    // FieldMapping maps neutral shipment fields (e.g. "origin.city") to dot
+    // paths in the provider's payload. Unmapped fields use the neutral name;
+    // a "-" mapping leaves the field out entirely.
+    FieldMapping map[string]string `json:"fieldMapping"`
+    // ResponsePath unwraps single shipment responses, e.g. "data".
+    ResponsePath string            `json:"responsePath"`
+    List         RESTTMSListConfig `json:"list"`
+    // Transport is configured from the environment, not the config file.
+    Transport TMSTransportConfig `json:"-"`
+}
+
+type RESTTMSPaths struct {
+    Create string `json:"create"`
+    Get    string `json:"get"`
+    List   string `json:"list"`
+    Update string `json:"update"`
+    Delete string `json:"delete"`
+}
+
+type RESTTMSListConfig struct {
+    // ItemsPath locates the shipment array; empty means the body is the array.
+    ItemsPath         string `json:"itemsPath"`
+    MoreAvailablePath string `json:"moreAvailablePath"`
+    StartParam        string `json:"startParam"`
+    PageSizeParam     string `json:"pageSizeParam"`
+    UpdatedSinceParam string `json:"updatedSinceParam"`
+}
+
+// LoadRESTTMSConfig reads and validates a REST TMS config file.
+func LoadRESTTMSConfig(path string) (RESTTMSConfig, error) {
+    var config RESTTMSConfig
+
+    raw, err := os.ReadFile(path)
+    if err != nil {
+        return config, fmt.Errorf("failed to read REST TMS config: %w", err)
+    }
+    if err := json.Unmarshal(raw, &config); err != nil {
+        return config, fmt.Errorf("failed to parse REST TMS config: %w", err)
+    }
+    if config.BaseURL == "" {
+        return config, fmt.Errorf("REST TMS config requires baseUrl")
+    }
+
+    return config, nil
+}
+
+// RESTTMSService is a TMS adapter for providers that expose plain JSON CRUD
+// endpoints authenticated with static headers.
+type RESTTMSService struct {
+    config    RESTTMSConfig
+    client    *http.Client
+    transport *tmsTransport
+}
+
+func NewRESTTMSService(config RESTTMSConfig) *RESTTMSService {
+    defaultString(&config.Paths.Create, "/shipments")
+    defaultString(&config.Paths.Get, "/shipments/{id}")
+    defaultString(&config.Paths.List, "/shipments")
+    defaultString(&config.Paths.Update, "/shipments/{id}")
+    defaultString(&config.Paths.Delete, "/shipments/{id}")
+    defaultString(&config.List.StartParam, "start")
+    defaultString(&config.List.PageSizeParam, "pageSize")
+    defaultString(&config.List.UpdatedSinceParam, "updatedSince")
+    if config.TimeoutSeconds <= 0 {
+        config.TimeoutSeconds = 30
+    }
+    config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+
+    transport := newTMSTransport(TMSProviderREST, config.Transport)
+    return &RESTTMSService{
+        config: config,
+        client: &http.Client{
+            Timeout:   time.Duration(config.TimeoutSeconds) * time.Second,
+            Transport: transport,
+        },
+        transport: transport,
+    }
+}
+
+// Authenticate is a no-op: REST providers authenticate with static headers.
+func (s *RESTTMSService) Authenticate(ctx context.Context) error {
+    return nil
+}
+
+func (s *RESTTMSService) IsTokenValid() bool {
+    return true
+}
+
+func (s *RESTTMSService) RefreshToken(ctx context.Context) error {
+    return nil
+}
+
+func (s *RESTTMSService) CreateShipment(ctx context.Context, shipment dto.Shipment) (*dto.Shipment, error) {
+    body, err := s.do(ctx, "POST", s.path(s.config.Paths.Create, ""), s.toPayload(shipment))
+    if err != nil {
+        return nil, err
+    }
+    return s.decodeShipment(body)
+}
+
+func (s *RESTTMSService) GetShipment(ctx context.Context, id string) (*dto.Shipment, error) {
+    body, err := s.do(ctx, "GET", s.path(s.config.Paths.Get, id), nil)
+    if err != nil {
+        return nil, err
+    }
+    return s.decodeShipment(body)
+}
+
+func (s *RESTTMSService) ListShipments(ctx context.Context, page, pageSize int, filter dto.ListShipmentsFilter) (*dto.ShipmentPage, error) {
+    query := url.Values{}
+    query.Set(s.config.List.StartParam, strconv.Itoa(page))
+    query.Set(s.config.List.PageSizeParam, strconv.Itoa(pageSize))
+    if !filter.UpdatedSince.IsZero() {
+        query.Set(s.config.List.UpdatedSinceParam, filter.UpdatedSince.UTC().Format(time.RFC3339))
+    }
+
+    body, err := s.do(ctx, "GET", s.path(s.config.Paths.List, "")+"?"+query.Encode(), nil)
+    if err != nil {
+        return nil, err
+    }
+
+    var decoded interface{}
+    if err := decodeJSONNumbers(body, &decoded); err != nil {
+        return nil, err
+    }
+
+    items := decoded
+    if s.config.List.ItemsPath != "" {
+        root, _ := decoded.(map[string]interface{})
+        items = jsonValue(root, splitDotPath(s.config.List.ItemsPath)...)
+    }
+    list, ok := items.([]interface{})
+    if !ok && items != nil {
+        return nil, fmt.Errorf("list response has no shipment array at %q", s.config.List.ItemsPath)
+    }
+
+    shipmentPage := &dto.ShipmentPage{Shipments: make([]dto.Shipment, 0, len(list))}
+    for _, item := range list {
+        payload, ok := item.(map[string]interface{})
+        if !ok {
+            continue
+        }
+        shipmentPage.Shipments = append(shipmentPage.Shipments, s.fromPayload(payload))
+    }
+
+    // Without an explicit flag a full page is taken to mean more may follow.
+    if s.config.List.MoreAvailablePath != "" {
+        root, _ := decoded.(map[string]interface{})
+        shipmentPage.MoreAvailable, _ = jsonValue(root, splitDotPath(s.config.List.MoreAvailablePath)...).(bool)
+    } else {
+        shipmentPage.MoreAvailable = len(list) >= pageSize
+    }
+
+    return shipmentPage, nil
+}
+
+func (s *RESTTMSService) UpdateShipment(ctx context.Context, id string, shipment dto.Shipment) (*dto.Shipment, error) {
+    body, err := s.do(ctx, "PUT", s.path(s.config.Paths.Update, id), s.toPayload(shipment))
+    if err != nil {
+        return nil, err
+    }
+    if len(bytes.TrimSpace(body)) == 0 {
+        shipment.ID = id
+        return &shipment, nil
+    }
+    return s.decodeShipment(body)
+}
+
+func (s *RESTTMSService) DeleteShipment(ctx context.Context, id string) error {
+    _, err := s.do(ctx, "DELETE", s.path(s.config.Paths.Delete, id), nil)
+    return err
+}
+
+func (s *RESTTMSService) do(ctx context.Context, method, url string, payload map[string]interface{}) ([]byte, error) {
+    var reqBody io.Reader
+    if payload != nil {
+        jsonData, err := json.Marshal(payload)
+        if err != nil {
+            return nil, fmt.Errorf("failed to marshal request: %w", err)
+        }
+        reqBody = bytes.NewBuffer(jsonData)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create request: %w", err)
+    }
+
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("Accept", "application/json")
+    for name, value := range s.config.Headers {
+        req.Header.Set(name, os.ExpandEnv(value))
+    }
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return nil, apperrors.UpstreamUnavailable("failed to make request", err)
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, apperrors.UpstreamUnavailable("failed to read response body", err)
+    }
+
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        log.Printf("TMS API error (status %d): %s", resp.StatusCode, string(body))
+        return nil, statusError(resp.StatusCode, resp.Header,
+            fmt.Sprintf("API returned status code: %d", resp.StatusCode))
+    }
+
+    return body, nil
+}
+
+func (s *RESTTMSService) path(template, id string) string {
+    return s.config.BaseURL + strings.ReplaceAll(template, "{id}", url.PathEscape(id))
+}
+
+func (s *RESTTMSService) decodeShipment(body []byte) (*dto.Shipment, error) {
+    var payload map[string]interface{}
+    if err := decodeJSONNumbers(body, &payload); err != nil {
+        return nil, err
+    }
+    if s.config.ResponsePath != "" {
+        payload, _ = jsonValue(payload, splitDotPath(s.config.ResponsePath)...).(map[string]interface{})
+        if payload == nil {
+            return nil, fmt.Errorf("response has no shipment at %q", s.config.ResponsePath)
+        }
+    }
+
+    shipment := s.fromPayload(payload)
+    return &shipment, nil
+}
+
+// mappedPath returns the payload path for a neutral field, or nil if the
+// field is excluded.
+func (s *RESTTMSService) mappedPath(field string) []string {
+    path, ok := s.config.FieldMapping[field]
+    if !ok {
+        path = field
+    }
+    if path == "" || path == "-" {
+        return nil
+    }
+    return splitDotPath(path)
+}
+
+func (s *RESTTMSService) toPayload(shipment dto.Shipment) map[string]interface{} {
+    values := map[string]interface{}{
+        restFieldCustomID:       shipment.CustomID,
+        restFieldSourceID:       shipment.SourceID,
+        restFieldLTL:            shipment.LTL,
+        restFieldStatusCode:     shipment.Status.Code,
+        restFieldStatusLabel:    shipment.Status.Label,
+        restFieldOriginCity:     shipment.Origin.City,
+        restFieldOriginState:    shipment.Origin.State,
+        restFieldOriginDate:     formatRESTTime(shipment.Origin.Date),
+        restFieldOriginTimeZone: shipment.Origin.TimeZone,
+        restFieldDestCity:       shipment.Destination.City,
+        restFieldDestState:      shipment.Destination.State,
+        restFieldDestDate:       formatRESTTime(shipment.Destination.Date),
+        restFieldDestTimeZone:   shipment.Destination.TimeZone,
+        restFieldCustomerName:   shipment.CustomerName,
+        restFieldCarrierName:    shipment.CarrierName,
+    }
+    if shipment.ID != "" {
+        values[restFieldID] = shipment.ID
+    }
+    if len(shipment.Stops) > 0 {
+        values[restFieldStops] = shipment.Stops
+    }
+
+    payload := models.JSON{}
+    for _, field := range restShipmentFields {
+        value, ok := values[field]
+        path := s.mappedPath(field)
+        if !ok || path == nil {
+            continue
+        }
+        payload = setJSONPath(payload, value, path...)
+    }
+    return payload
+}
+
+func (s *RESTTMSService) fromPayload(payload map[string]interface{}) dto.Shipment {
+    str := func(field string) string {
+        path := s.mappedPath(field)
+        if path == nil {
+            return ""
+        }
+        switch v := jsonValue(payload, path...).(type) {
+        case string:
+            return v
+        case json.Number:
+            return v.String()
+        case bool:
+            return strconv.FormatBool(v)
+        default:
+            return ""
+        }
+    }
+    timeOf := func(field string) time.Time {
+        t, _ := time.Parse(time.RFC3339, str(field))
+        return t
+    }
+
+    // Stops are copied as a JSON array in the neutral stop shape.
+    var stops []dto.ShipmentStop
+    if path := s.mappedPath(restFieldStops); path != nil {
+        if raw, ok := jsonValue(payload, path...).([]interface{}); ok {
+            if data, err := json.Marshal(raw); err == nil {
+                json.Unmarshal(data, &stops)
+            }
+        }
+    }
+
+    return dto.Shipment{
+        ID:       str(restFieldID),
+        CustomID: str(restFieldCustomID),
+        SourceID: str(restFieldSourceID),
+        LTL:      str(restFieldLTL) == "true",
+        Status: dto.ShipmentStatus{
+            Code:  str(restFieldStatusCode),
+            Label: str(restFieldStatusLabel),
+        },
+        Origin: dto.ShipmentLocation{
+            City:     str(restFieldOriginCity),
+            State:    str(restFieldOriginState),
+            Date:     timeOf(restFieldOriginDate),
+            TimeZone: str(restFieldOriginTimeZone),
+        },
+        Destination: dto.ShipmentLocation{
+            City:     str(restFieldDestCity),
+            State:    str(restFieldDestState),
+            Date:     timeOf(restFieldDestDate),
+            TimeZone: str(restFieldDestTimeZone),
+        },
+        CustomerName:  str(restFieldCustomerName),
+        CarrierName:   str(restFieldCarrierName),
+        Stops:         stops,
+        LastUpdatedOn: timeOf(restFieldLastUpdatedOn),
+    }
+}
+
+// decodeJSONNumbers keeps numeric IDs intact instead of turning them into
+// float64.
+func decodeJSONNumbers(body []byte, v interface{}) error {
+    decoder := json.NewDecoder(bytes.NewReader(body))
+    decoder.UseNumber()
+    if err := decoder.Decode(v); err != nil {
+        return fmt.Errorf("failed to decode response: %w", err)
+    }
+    return nil
+}
+
+func formatRESTTime(t time.Time) string {
+    if t.IsZero() {
+        return ""
+    }
+    return t.Format(time.RFC3339)
+}
+
+func splitDotPath(path string) []string {
+    return strings.Split(path, ".")
+}
+
+func defaultString(value *string, fallback string) {
+    if *value == "" {
+        *value = fallback
+    }
+}
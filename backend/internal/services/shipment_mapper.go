@@ -0,0 +1,218 @@
+package services
+
+import (
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/models"
+)
+
+const defaultShipmentTimeZone = "America/New_York"
+
+// convertLoadToShipment builds the provider-neutral shipment from a stored
+// load, reporting missing or malformed schedule times.
+func convertLoadToShipment(load *models.Load) (dto.Shipment, error) {
+    pickupTime, err := load.Pickup.ScheduledAt()
+    if err != nil {
+        return dto.Shipment{}, fmt.Errorf("invalid pickup: %w", err)
+    }
+    deliveryTime, err := load.Consignee.ScheduledAt()
+    if err != nil {
+        return dto.Shipment{}, fmt.Errorf("invalid consignee: %w", err)
+    }
+
+    return dto.Shipment{
+        ID:       load.ExternalTMSLoadID,
+        CustomID: load.TMSCustomID,
+        SourceID: load.FreightLoadID,
+        LTL:      true,
+        Status: dto.ShipmentStatus{
+            Code:  load.Status.Code.Key,
+            Label: load.Status.Code.Value,
+        },
+        Origin: dto.ShipmentLocation{
+            City:     load.Pickup.Address.City,
+            State:    load.Pickup.Address.State,
+            Date:     pickupTime,
+            TimeZone: defaultShipmentTimeZone,
+        },
+        Destination: dto.ShipmentLocation{
+            City:     load.Consignee.Address.City,
+            State:    load.Consignee.Address.State,
+            Date:     deliveryTime,
+            TimeZone: defaultShipmentTimeZone,
+        },
+        CustomerName: load.Customer.Name,
+        Stops:        toShipmentStops(load.Stops),
+    }, nil
+}
+
+// jsonValue walks nested JSON objects and returns the value at the given
+// path, or nil when any segment is missing.
+func jsonValue(m map[string]interface{}, path ...string) interface{} {
+    var current interface{} = m
+    for _, key := range path {
+        obj, ok := current.(map[string]interface{})
+        if !ok {
+            return nil
+        }
+        current = obj[key]
+    }
+    return current
+}
+
+// applyShipmentToLoad merges the fields a TMS shipment carries into a load,
+// leaving the load's other details untouched.
+func applyShipmentToLoad(load *models.Load, provider string, shipment *dto.Shipment) {
+    load.TMSProvider = provider
+    load.ExternalTMSLoadID = shipment.ID
+    load.TMSCustomID = shipment.CustomID
+
+    if load.FreightLoadID == "" {
+        load.FreightLoadID = shipment.SourceID
+        if load.FreightLoadID == "" {
+            load.FreightLoadID = shipment.CustomID
+        }
+    }
+
+    // A status we cannot place on the lifecycle would let the load move to
+    // any status, so the load keeps its own. A shipment cancelled in the TMS
+    // cancels the load, as CancelLoad would.
+    if status, ok := resolveStatus(shipment.Status.Code); ok {
+        load.Status.Code = models.LoadStatusCode{Key: status, Value: models.StatusLabel(status)}
+        if status == models.StatusCancelled && load.CancelledAt == nil {
+            now := time.Now()
+            load.CancelledAt = &now
+        }
+    } else if shipment.Status.Code != "" {
+        log.Printf("Warning: shipment %s has unknown status %q; keeping the load's status", shipment.ID, shipment.Status.Code)
+    }
+
+    if shipment.CustomerName != "" {
+        load.Customer.Name = shipment.CustomerName
+    }
+    if shipment.CarrierName != "" {
+        load.Carrier.Name = shipment.CarrierName
+    }
+
+    applyShipmentLocation(&load.Pickup, shipment.Origin)
+    applyShipmentLocation(&load.Consignee, shipment.Destination)
+}
+
+func applyShipmentLocation(location *models.Location, shipmentLocation dto.ShipmentLocation) {
+    if shipmentLocation.City != "" {
+        location.Address.City = shipmentLocation.City
+        location.Address.State = shipmentLocation.State
+    }
+    if !shipmentLocation.Date.IsZero() {
+        location.ScheduledTime = shipmentLocation.Date.Format(time.RFC3339)
+    }
+}
+
+// formatLaneEnd renders a location's address as a "City, ST" lane end.
+func formatLaneEnd(location models.Location) string {
+    return formatLane(location.Address.City, location.Address.State)
+}
+
+// splitLane splits a "City, ST" lane end into its city and state.
+func splitLane(lane string) (string, string) {
+    parts := strings.SplitN(lane, ",", 2)
+    city := strings.TrimSpace(parts[0])
+    if len(parts) == 1 {
+        return city, ""
+    }
+    return city, strings.TrimSpace(parts[1])
+}
+
+// setJSONPath sets value at the nested path, creating intermediate objects
+// (and the root, if nil) as needed.
+func setJSONPath(m models.JSON, value interface{}, path ...string) models.JSON {
+    if m == nil {
+        m = models.JSON{}
+    }
+    current := map[string]interface{}(m)
+    for _, key := range path[:len(path)-1] {
+        next, ok := current[key].(map[string]interface{})
+        if !ok {
+            next = map[string]interface{}{}
+            current[key] = next
+        }
+        current = next
+    }
+    current[path[len(path)-1]] = value
+    return m
+}
+
+func toShipmentStops(stops []models.Stop) []dto.ShipmentStop {
+    if len(stops) == 0 {
+        return nil
+    }
+
+    result := make([]dto.ShipmentStop, len(stops))
+    for i, stop := range stops {
+        shipmentStop := dto.ShipmentStop{
+            Sequence:         stop.Sequence,
+            Type:             dto.ShipmentStopDrop,
+            Name:             stop.FacilityName,
+            Street:           stop.Address.Street,
+            City:             stop.Address.City,
+            State:            stop.Address.State,
+            Zip:              stop.Address.ZipCode,
+            AppointmentStart: stop.AppointmentStart,
+            TimeZone:         stop.TimeZone,
+            Notes:            stop.Notes,
+        }
+        if stop.Type == models.StopTypePickup {
+            shipmentStop.Type = dto.ShipmentStopPickup
+        }
+        if stop.AppointmentEnd != nil {
+            shipmentStop.AppointmentEnd = *stop.AppointmentEnd
+        }
+        for _, commodity := range stop.Commodities {
+            shipmentStop.Commodities = append(shipmentStop.Commodities, dto.ShipmentCommodity(commodity))
+        }
+        for _, ref := range stop.ReferenceNumbers {
+            shipmentStop.References = append(shipmentStop.References, dto.ShipmentReference(ref))
+        }
+        result[i] = shipmentStop
+    }
+    return result
+}
+
+func fromShipmentStops(shipmentStops []dto.ShipmentStop) []models.Stop {
+    stops := make([]models.Stop, len(shipmentStops))
+    for i, shipmentStop := range shipmentStops {
+        stop := models.Stop{
+            Sequence:     i + 1,
+            Type:         models.StopTypeDrop,
+            FacilityName: shipmentStop.Name,
+            Address: models.Address{
+                Street:  shipmentStop.Street,
+                City:    shipmentStop.City,
+                State:   shipmentStop.State,
+                ZipCode: shipmentStop.Zip,
+            },
+            AppointmentStart: shipmentStop.AppointmentStart,
+            TimeZone:         shipmentStop.TimeZone,
+            Notes:            shipmentStop.Notes,
+        }
+        if shipmentStop.Type == dto.ShipmentStopPickup {
+            stop.Type = models.StopTypePickup
+        }
+        if !shipmentStop.AppointmentEnd.IsZero() {
+            end := shipmentStop.AppointmentEnd
+            stop.AppointmentEnd = &end
+        }
+        for _, commodity := range shipmentStop.Commodities {
+            stop.Commodities = append(stop.Commodities, models.Commodity(commodity))
+        }
+        for _, ref := range shipmentStop.References {
+            stop.ReferenceNumbers = append(stop.ReferenceNumbers, models.ReferenceNumber(ref))
+        }
+        stops[i] = stop
+    }
+    return stops
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+    "testing"
+
+    "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/models"
+)
+
+func TestApplyShipmentStatus(t *testing.T) {
+    load := &models.Load{Status: models.LoadStatus{Code: models.LoadStatusCode{Key: models.StatusCovered}}}
+
+    applyShipmentToLoad(load, TMSProviderTurvo, &dto.Shipment{ID: "1", Status: dto.ShipmentStatus{Code: "9999", Label: "Mystery"}})
+    if load.Status.Code.Key != models.StatusCovered {
+        t.Errorf("expected an unknown status to be ignored, got %+v", load.Status.Code)
+    }
+
+    applyShipmentToLoad(load, TMSProviderTurvo, &dto.Shipment{ID: "1", Status: dto.ShipmentStatus{Code: "2113"}})
+    if load.Status.Code.Key != models.StatusCancelled || load.CancelledAt == nil {
+        t.Errorf("expected a shipment cancelled in the TMS to cancel the load, got %+v", load.Status.Code)
+    }
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+type ShipmentSyncConfig struct {
+    Interval time.Duration
+    PageSize int
+}
+
+type ShipmentSyncResult struct {
+    Created       int
+    Updated       int
+    Skipped       int
+    HighWaterMark time.Time
+}
+
+// ShipmentSyncJob pulls shipments from every configured TMS into the load
+// table so that shipments created directly in a TMS show up locally. Each run
+// only asks for shipments updated since the provider's last recorded
+// high-water mark.
+type ShipmentSyncJob struct {
+    db          *gorm.DB
+    tmsRegistry interfaces.TMSProviderRegistry
+    config      ShipmentSyncConfig
+}
+
+func NewShipmentSyncJob(db *gorm.DB, tmsRegistry interfaces.TMSProviderRegistry, config ShipmentSyncConfig) *ShipmentSyncJob {
+    if config.Interval <= 0 {
+        config.Interval = 15 * time.Minute
+    }
+    if config.PageSize <= 0 {
+        config.PageSize = 50
+    }
+
+    return &ShipmentSyncJob{
+        db:          db,
+        tmsRegistry: tmsRegistry,
+        config:      config,
+    }
+}
+
+// Run syncs every provider, shared and per tenant, on each interval until
+// ctx is cancelled.
+func (j *ShipmentSyncJob) Run(ctx context.Context) {
+    ticker := time.NewTicker(j.config.Interval)
+    defer ticker.Stop()
+
+    for {
+        scopes, err := tmsScopes(j.db, j.tmsRegistry)
+        if err != nil {
+            log.Printf("Shipment sync: %v", err)
+        }
+        for _, scope := range scopes {
+            for _, provider := range scope.registry.ProviderNames() {
+                result, err := j.syncProvider(ctx, scope, provider)
+                if err != nil {
+                    log.Printf("Shipment sync (%s): %v", scope.label(provider), err)
+                    continue
+                }
+                log.Printf("Shipment sync (%s): %d created, %d updated, %d skipped", scope.label(provider), result.Created, result.Updated, result.Skipped)
+            }
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (j *ShipmentSyncJob) syncProvider(ctx context.Context, scope tmsScope, provider string) (*ShipmentSyncResult, error) {
+    tmsService, err := scope.registry.Provider(provider)
+    if err != nil {
+        return nil, err
+    }
+
+    checkpoint, err := j.loadCheckpoint(scope.label(provider))
+    if err != nil {
+        return nil, err
+    }
+
+    result := &ShipmentSyncResult{HighWaterMark: checkpoint.HighWaterMark}
+    filter := dto.ListShipmentsFilter{UpdatedSince: checkpoint.HighWaterMark}
+
+    start := 0
+    for {
+        shipmentPage, err := tmsService.ListShipments(ctx, start, j.config.PageSize, filter)
+        if err != nil {
+            return nil, fmt.Errorf("failed to list shipments from TMS: %w", err)
+        }
+
+        shipments := shipmentPage.Shipments
+        for i := range shipments {
+            shipment := &shipments[i]
+
+            outcome, err := upsertShipment(ctx, j.db, scope, provider, shipment)
+            if err != nil {
+                return nil, fmt.Errorf("failed to sync shipment %s: %w", shipment.ID, err)
+            }
+            switch outcome {
+            case syncCreated:
+                result.Created++
+            case syncUpdated:
+                result.Updated++
+            default:
+                result.Skipped++
+            }
+
+            if shipment.LastUpdatedOn.After(result.HighWaterMark) {
+                result.HighWaterMark = shipment.LastUpdatedOn
+            }
+        }
+
+        if !shipmentPage.MoreAvailable || len(shipments) == 0 {
+            break
+        }
+        start += len(shipments)
+    }
+
+    // The mark only moves once every page has been applied, so a failed run
+    // is retried from the same point. The filter is inclusive, which makes
+    // boundary shipments reappear; upserts are idempotent so that is fine.
+    checkpoint.HighWaterMark = result.HighWaterMark
+    if err := j.db.Save(checkpoint).Error; err != nil {
+        return nil, fmt.Errorf("failed to save sync checkpoint: %w", err)
+    }
+
+    return result, nil
+}
+
+type syncOutcome int
+
+const (
+    syncSkipped syncOutcome = iota
+    syncCreated
+    syncUpdated
+)
+
+// upsertShipment matches a shipment to a load in scope by TMS ID, falling
+// back to the freight load ID for locally created loads whose outbox
+// delivery has not yet recorded the TMS ID. Unmatched shipments become
+// loads of the scope's tenant.
+// Loads stored before providers were recorded have an empty provider and
+// match any of them. Changes are audited as made by the system.
+func upsertShipment(ctx context.Context, db *gorm.DB, scope tmsScope, provider string, shipment *dto.Shipment) (syncOutcome, error) {
+    if shipment.ID == "" {
+        return syncSkipped, nil
+    }
+    ctx = requestctx.WithSystem(ctx)
+
+    var load models.Load
+    tx := db.Begin()
+
+    err := scope.apply(tx).Set("gorm:query_option", "FOR UPDATE").
+        Where("external_tms_load_id = ? AND tms_provider IN (?, '')", shipment.ID, provider).
+        First(&load).Error
+    if err == gorm.ErrRecordNotFound && shipment.SourceID != "" {
+        err = scope.apply(tx).Set("gorm:query_option", "FOR UPDATE").
+            Where("freight_load_id = ? AND (external_tms_load_id = '' OR external_tms_load_id IS NULL)", shipment.SourceID).
+            First(&load).Error
+    }
+
+    outcome := syncUpdated
+    switch {
+    case err == gorm.ErrRecordNotFound:
+        outcome = syncCreated
+        load = models.Load{ID: uuid.New(), TenantID: scope.tenantID}
+    case err != nil:
+        tx.Rollback()
+        return syncSkipped, err
+    case load.CancelledAt != nil:
+        tx.Rollback()
+        return syncSkipped, nil
+    case load.TMSSyncStatus == models.TMSSyncPending:
+        // Local changes still waiting in the outbox win over the TMS copy.
+        // Linking the shipment ID still lets a pending create be sent as an
+        // update instead of producing a duplicate shipment.
+        if load.ExternalTMSLoadID == "" {
+            err := updateLoadAudited(ctx, tx, &load, map[string]interface{}{
+                "tms_provider":         provider,
+                "external_tms_load_id": shipment.ID,
+                "tms_custom_id":        shipment.CustomID,
+            })
+            if err != nil {
+                tx.Rollback()
+                return syncSkipped, err
+            }
+            return syncSkipped, tx.Commit().Error
+        }
+        tx.Rollback()
+        return syncSkipped, nil
+    }
+
+    var before map[string]interface{}
+    if outcome == syncUpdated {
+        if err := loadStops(tx, &load); err != nil {
+            tx.Rollback()
+            return syncSkipped, err
+        }
+        if before, err = auditSnapshot(&load); err != nil {
+            tx.Rollback()
+            return syncSkipped, err
+        }
+    }
+
+    // The TMS is the record for where a shipment is, so its status is
+    // taken as-is and only recorded, not checked against the lifecycle.
+    previousKey := load.Status.Code.Key
+    previousStatus := previousKey
+    if status, ok := resolveStatus(previousStatus); ok {
+        previousStatus = status
+    }
+    freightLoadID := load.FreightLoadID
+    applyShipmentToLoad(&load, provider, shipment)
+    markTMSSynced(&load)
+
+    // A TMS may hold several shipments for one order. Freight load IDs are
+    // unique per tenant, so only the first load to claim one keeps it.
+    if load.FreightLoadID != freightLoadID && load.FreightLoadID != "" {
+        existing, err := findLoadByFreightID(tx, load.TenantID, load.FreightLoadID, load.ID)
+        if err != nil {
+            tx.Rollback()
+            return syncSkipped, err
+        }
+        if existing != nil {
+            log.Printf("Warning: shipment %s has the freight load ID %s of load %s; syncing it without one", shipment.ID, load.FreightLoadID, existing.ID)
+            load.FreightLoadID = freightLoadID
+        }
+    }
+
+    if outcome == syncCreated {
+        err = tx.Create(&load).Error
+    } else {
+        err = tx.Save(&load).Error
+    }
+    if err != nil {
+        tx.Rollback()
+        return syncSkipped, err
+    }
+
+    // The TMS route replaces ours when it has one; new loads without one get
+    // the two-stop route implied by their origin and destination.
+    var stops []models.Stop
+    if len(shipment.Stops) > 0 {
+        stops = fromShipmentStops(shipment.Stops)
+    } else if outcome == syncCreated {
+        stops, _ = stopsFromLocations(load.Pickup, load.Consignee)
+    }
+    if len(stops) > 0 {
+        if err := replaceStops(tx, &load, stops); err != nil {
+            tx.Rollback()
+            return syncSkipped, err
+        }
+    }
+
+    if load.Status.Code.Key != previousKey && load.Status.Code.Key != previousStatus {
+        err := recordStatusChange(ctx, tx, load.ID, previousStatus, load.Status.Code.Key,
+            "", models.StatusSourceTMS)
+        if err != nil {
+            tx.Rollback()
+            return syncSkipped, err
+        }
+    }
+
+    after, err := auditSnapshot(&load)
+    if err != nil {
+        tx.Rollback()
+        return syncSkipped, err
+    }
+    action := models.AuditActionUpdate
+    if outcome == syncCreated {
+        action = models.AuditActionCreate
+    }
+    if err := recordAudit(ctx, tx, &load, action, before, after); err != nil {
+        tx.Rollback()
+        return syncSkipped, err
+    }
+
+    if err := tx.Commit().Error; err != nil {
+        return syncSkipped, err
+    }
+
+    return outcome, nil
+}
+
+// loadCheckpoint returns the checkpoint for a provider, labelled as by
+// tmsScope.label.
+func (j *ShipmentSyncJob) loadCheckpoint(provider string) (*models.SyncCheckpoint, error) {
+    checkpoint := &models.SyncCheckpoint{Name: models.ShipmentSyncCheckpoint + ":" + provider}
+    err := j.db.Where("name = ?", checkpoint.Name).First(checkpoint).Error
+    if err != nil && err != gorm.ErrRecordNotFound {
+        return nil, fmt.Errorf("failed to load sync checkpoint: %w", err)
+    }
+    return checkpoint, nil
+}
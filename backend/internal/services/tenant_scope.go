@@ -0,0 +1,119 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+    "freight-broker/backend/internal/requestctx"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+// errNoActor is returned when a context reaches a service without an actor,
+// which would otherwise be neither limited to a tenant nor refused one.
+var errNoActor = errors.New("no actor in context")
+
+// contextActor returns the context's actor, or errNoActor.
+func contextActor(ctx context.Context) (requestctx.Actor, error) {
+    actor, ok := requestctx.ActorFrom(ctx)
+    if !ok {
+        return actor, errNoActor
+    }
+    return actor, nil
+}
+
+// actorTenant returns the tenant a request is limited to. Background jobs
+// run as the system actor, which is not limited to a tenant, so scoped is
+// false for them.
+func actorTenant(ctx context.Context) (tenantID uuid.UUID, scoped bool, err error) {
+    actor, err := contextActor(ctx)
+    if err != nil {
+        return uuid.Nil, false, err
+    }
+    if actor.IsSystem() {
+        return uuid.Nil, false, nil
+    }
+    // An actor without a valid tenant matches no rows.
+    id, err := uuid.Parse(actor.TenantID)
+    if err != nil {
+        return uuid.Nil, true, nil
+    }
+    return id, true, nil
+}
+
+// scopeToTenant limits a query on a table with a tenant_id column to the
+// request's tenant.
+func scopeToTenant(ctx context.Context, db *gorm.DB) (*gorm.DB, error) {
+    tenantID, scoped, err := actorTenant(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if scoped {
+        return db.Where("tenant_id = ?", tenantID), nil
+    }
+    return db, nil
+}
+
+// tmsScope is a set of loads that share a TMS registry: the loads of a
+// tenant with its own TMS settings, or those of every other tenant, which
+// share the global settings. Shipments are matched to loads within a
+// scope only.
+type tmsScope struct {
+    // tenant is empty for the shared scope.
+    tenant   string
+    registry interfaces.TMSProviderRegistry
+    // tenantID owns loads first seen in the TMS.
+    tenantID uuid.UUID
+    condition sqlCondition
+}
+
+// tmsScopes returns the shared scope, whose new loads go to the default
+// tenant, followed by one scope per tenant with its own TMS settings.
+func tmsScopes(db *gorm.DB, registry interfaces.TMSProviderRegistry) ([]tmsScope, error) {
+    var defaultTenant models.Tenant
+    if err := db.Where("slug = ?", models.DefaultTenantSlug).First(&defaultTenant).Error; err != nil {
+        return nil, fmt.Errorf("failed to get default tenant: %w", err)
+    }
+
+    tenantIDs := registry.TenantIDs()
+    shared := tmsScope{registry: registry, tenantID: defaultTenant.ID}
+    if len(tenantIDs) > 0 {
+        shared.condition = sqlCondition{sql: "tenant_id IS NULL OR tenant_id NOT IN (?)", args: []interface{}{tenantIDs}}
+    }
+
+    scopes := []tmsScope{shared}
+    for _, id := range tenantIDs {
+        tenantID, err := uuid.Parse(id)
+        if err != nil {
+            return nil, fmt.Errorf("invalid tenant ID %q: %w", id, err)
+        }
+        scopes = append(scopes, tmsScope{
+            tenant:    id,
+            registry:  registry.ForTenant(id),
+            tenantID:  tenantID,
+            condition: sqlCondition{sql: "tenant_id = ?", args: []interface{}{tenantID}},
+        })
+    }
+    return scopes, nil
+}
+
+// apply limits a query on loads to the scope.
+func (s tmsScope) apply(db *gorm.DB) *gorm.DB {
+    if s.condition.sql == "" {
+        return db
+    }
+    return db.Where(s.condition.sql, s.condition.args...)
+}
+
+// label names a provider in logs and reports, with the tenant for a
+// tenant's own provider.
+func (s tmsScope) label(provider string) string {
+    if s.tenant == "" {
+        return provider
+    }
+    return s.tenant + "/" + provider
+}
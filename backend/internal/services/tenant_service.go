@@ -0,0 +1,200 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "regexp"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+)
+
+var tenantSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// TenantService manages the brokerages sharing the deployment. It is only
+// exposed to admins of the default tenant.
+type TenantService struct {
+    db          *gorm.DB
+    userService *UserService
+}
+
+func NewTenantService(db *gorm.DB, userService *UserService) *TenantService {
+    return &TenantService{
+        db:          db,
+        userService: userService,
+    }
+}
+
+// EnsureDefaultTenant creates the default tenant on first start and gives
+// it every load and user stored before tenants existed.
+func (s *TenantService) EnsureDefaultTenant(ctx context.Context) (*models.Tenant, error) {
+    tenant := models.Tenant{
+        ID:     uuid.New(),
+        Slug:   models.DefaultTenantSlug,
+        Name:   "Default",
+        Active: true,
+    }
+
+    tx := s.db.Begin()
+    if err := tx.Where(models.Tenant{Slug: models.DefaultTenantSlug}).FirstOrCreate(&tenant).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to create default tenant: %w", err)
+    }
+    for _, table := range []string{"loads", "users"} {
+        if err := tx.Exec("UPDATE "+table+" SET tenant_id = ? WHERE tenant_id IS NULL", tenant.ID).Error; err != nil {
+            tx.Rollback()
+            return nil, fmt.Errorf("failed to assign %s to default tenant: %w", table, err)
+        }
+    }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit default tenant: %w", err)
+    }
+    return &tenant, nil
+}
+
+// TenantBySlug returns the tenant with a slug.
+func (s *TenantService) TenantBySlug(ctx context.Context, slug string) (*models.Tenant, error) {
+    var tenant models.Tenant
+    if err := s.db.Where("slug = ?", slug).First(&tenant).Error; err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound(fmt.Sprintf("tenant %s not found", slug))
+        }
+        return nil, fmt.Errorf("failed to get tenant: %w", err)
+    }
+    return &tenant, nil
+}
+
+// CreateTenant creates a tenant and its first admin, who manages the
+// tenant's other users.
+func (s *TenantService) CreateTenant(ctx context.Context, req *dto.CreateTenantRequest) (*dto.TenantResponse, error) {
+    slug := strings.ToLower(strings.TrimSpace(req.Slug))
+    if !tenantSlugPattern.MatchString(slug) {
+        return nil, apperrors.Validation("Invalid slug",
+            apperrors.FieldError{Field: "slug", Message: "must contain only lowercase letters, digits and hyphens"})
+    }
+
+    tenant := &models.Tenant{
+        ID:     uuid.New(),
+        Slug:   slug,
+        Name:   strings.TrimSpace(req.Name),
+        Active: true,
+    }
+
+    tx := s.db.Begin()
+    var existing int
+    if err := tx.Model(&models.Tenant{}).Where("slug = ?", slug).Count(&existing).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to check slug: %w", err)
+    }
+    if existing > 0 {
+        tx.Rollback()
+        return nil, apperrors.Conflict("slug is already taken")
+    }
+    if err := tx.Create(tenant).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to create tenant: %w", err)
+    }
+
+    _, err := s.userService.createUser(tx, tenant.ID, &dto.CreateUserRequest{
+        Username: req.AdminUsername,
+        Email:    req.AdminEmail,
+        Password: req.AdminPassword,
+        Role:     models.RoleAdmin,
+    })
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit tenant: %w", err)
+    }
+    return convertToTenantResponse(tenant), nil
+}
+
+func (s *TenantService) ListTenants(ctx context.Context) (*dto.ListTenantsResponse, error) {
+    var tenants []models.Tenant
+    if err := s.db.Order("slug").Find(&tenants).Error; err != nil {
+        return nil, fmt.Errorf("failed to list tenants: %w", err)
+    }
+
+    responses := make([]dto.TenantResponse, len(tenants))
+    for i := range tenants {
+        responses[i] = *convertToTenantResponse(&tenants[i])
+    }
+    return &dto.ListTenantsResponse{Tenants: responses}, nil
+}
+
+func (s *TenantService) GetTenant(ctx context.Context, id string) (*dto.TenantResponse, error) {
+    tenant, err := findTenant(s.db, id)
+    if err != nil {
+        return nil, err
+    }
+    return convertToTenantResponse(tenant), nil
+}
+
+// UpdateTenant renames or (de)activates a tenant. Users of a deactivated
+// tenant cannot sign in and their refresh tokens are revoked; access tokens
+// already issued last until they expire.
+func (s *TenantService) UpdateTenant(ctx context.Context, id string, req *dto.UpdateTenantRequest) (*dto.TenantResponse, error) {
+    tx := s.db.Begin()
+    tenant, err := findTenant(tx.Set("gorm:query_option", "FOR UPDATE"), id)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    if req.Name != nil {
+        tenant.Name = strings.TrimSpace(*req.Name)
+    }
+    if req.Active != nil {
+        if !*req.Active && tenant.Slug == models.DefaultTenantSlug {
+            tx.Rollback()
+            return nil, apperrors.Conflict("the default tenant cannot be deactivated")
+        }
+        tenant.Active = *req.Active
+    }
+
+    if err := tx.Save(tenant).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to update tenant: %w", err)
+    }
+    if !tenant.Active {
+        users := tx.Model(&models.User{}).Select("id").Where("tenant_id = ?", tenant.ID).QueryExpr()
+        if err := revokeRefreshTokens(tx, "user_id IN (?)", users, time.Now()); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit tenant update: %w", err)
+    }
+    return convertToTenantResponse(tenant), nil
+}
+
+func findTenant(db *gorm.DB, id string) (*models.Tenant, error) {
+    var tenant models.Tenant
+    if err := db.Where("id = ?", id).First(&tenant).Error; err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("tenant not found")
+        }
+        return nil, fmt.Errorf("failed to get tenant: %w", err)
+    }
+    return &tenant, nil
+}
+
+func convertToTenantResponse(tenant *models.Tenant) *dto.TenantResponse {
+    return &dto.TenantResponse{
+        ID:        tenant.ID.String(),
+        Slug:      tenant.Slug,
+        Name:      tenant.Name,
+        Active:    tenant.Active,
+        CreatedAt: tenant.CreatedAt.Format(time.RFC3339),
+        UpdatedAt: tenant.UpdatedAt.Format(time.RFC3339),
+    }
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+)
+
+// TenantTMSConfig is a tenant's own TMS settings, replacing the global ones
+// for its loads. String values in Turvo are expanded from the environment
+// so secrets can stay out of the config file.
+type TenantTMSConfig struct {
+    Provider          string             `json:"provider"`
+    CustomerProviders map[string]string  `json:"customerProviders"`
+    Turvo             *TenantTurvoConfig `json:"turvo"`
+    REST              *RESTTMSConfig     `json:"rest"`
+}
+
+type TenantTurvoConfig struct {
+    APIKey       string `json:"apiKey"`
+    ClientID     string `json:"clientId"`
+    ClientSecret string `json:"clientSecret"`
+    Username     string `json:"username"`
+    Password     string `json:"password"`
+    BaseURL      string `json:"baseUrl"`
+    AuthURL      string `json:"authUrl"`
+    Sandbox      bool   `json:"sandbox"`
+}
+
+// LoadTenantTMSConfig reads a file of TMS settings keyed by tenant slug.
+func LoadTenantTMSConfig(path string) (map[string]TenantTMSConfig, error) {
+    raw, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read tenant TMS config: %w", err)
+    }
+    var configs map[string]TenantTMSConfig
+    if err := json.Unmarshal(raw, &configs); err != nil {
+        return nil, fmt.Errorf("failed to parse tenant TMS config: %w", err)
+    }
+
+    for slug, config := range configs {
+        if config.Turvo == nil && config.REST == nil {
+            return nil, fmt.Errorf("tenant %s: no TMS configured", slug)
+        }
+        if config.REST != nil && config.REST.BaseURL == "" {
+            return nil, fmt.Errorf("tenant %s: REST TMS config requires baseUrl", slug)
+        }
+        if config.Provider == "" {
+            config.Provider = TMSProviderTurvo
+            if config.Turvo == nil {
+                config.Provider = TMSProviderREST
+            }
+        }
+        if turvo := config.Turvo; turvo != nil {
+            for _, value := range []*string{&turvo.APIKey, &turvo.ClientID, &turvo.ClientSecret, &turvo.Username, &turvo.Password} {
+                *value = os.ExpandEnv(*value)
+            }
+        }
+        configs[slug] = config
+    }
+    return configs, nil
+}
+
+// NewTenantTMSRegistry builds the registry for a tenant's own settings.
+func NewTenantTMSRegistry(config TenantTMSConfig, transport TMSTransportConfig) *TMSRegistry {
+    registry := NewTMSRegistry(config.Provider, config.CustomerProviders)
+    if turvo := config.Turvo; turvo != nil {
+        registry.Register(TMSProviderTurvo, NewTurvoService(TMSServiceConfig{
+            APIKey:        turvo.APIKey,
+            ClientID:      turvo.ClientID,
+            ClientSecret:  turvo.ClientSecret,
+            IsSandbox:     turvo.Sandbox,
+            TurvoUsername: turvo.Username,
+            TurvoPassword: turvo.Password,
+            BaseURL:       turvo.BaseURL,
+            AuthURL:       turvo.AuthURL,
+            Transport:     transport,
+        }))
+    }
+    if config.REST != nil {
+        restConfig := *config.REST
+        restConfig.Transport = transport
+        registry.Register(TMSProviderREST, NewRESTTMSService(restConfig))
+    }
+    return registry
+}
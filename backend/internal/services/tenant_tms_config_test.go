@@ -0,0 +1,67 @@
+package services
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func writeTenantTMSConfig(t *testing.T, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "tenants.json")
+    if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+        t.Fatalf("failed to write config: %v", err)
+    }
+    return path
+}
+
+func TestLoadTenantTMSConfig(t *testing.T) {
+    t.Setenv("ACME_TURVO_PASSWORD", "s3cret")
+    path := writeTenantTMSConfig(t, `{
+        "acme": {"turvo": {"username": "acme", "password": "${ACME_TURVO_PASSWORD}"}},
+        "globex": {"rest": {"baseUrl": "https://tms.globex.test"}, "customerProviders": {"Initech": "rest"}}
+    }`)
+
+    configs, err := LoadTenantTMSConfig(path)
+    if err != nil {
+        t.Fatalf("unexpected error: %v", err)
+    }
+    if acme := configs["acme"]; acme.Provider != TMSProviderTurvo || acme.Turvo.Password != "s3cret" {
+        t.Errorf("expected acme to default to turvo with its secret expanded, got %+v", acme)
+    }
+    globex := configs["globex"]
+    if globex.Provider != TMSProviderREST {
+        t.Errorf("expected globex to default to rest, got %q", globex.Provider)
+    }
+
+    registry := NewTenantTMSRegistry(globex, TMSTransportConfig{})
+    if err := registry.Validate(); err != nil {
+        t.Errorf("expected the tenant registry to be valid: %v", err)
+    }
+    if provider := registry.ResolveProvider("initech"); provider != TMSProviderREST {
+        t.Errorf("expected the customer mapping to apply, got %q", provider)
+    }
+}
+
+func TestLoadTenantTMSConfigRejectsTenantsWithoutTMS(t *testing.T) {
+    path := writeTenantTMSConfig(t, `{"acme": {"provider": "turvo"}}`)
+    if _, err := LoadTenantTMSConfig(path); err == nil {
+        t.Error("expected a tenant without a TMS to be refused")
+    }
+}
+
+func TestTMSRegistryForTenant(t *testing.T) {
+    shared := NewTMSRegistry(TMSProviderTurvo, nil)
+    tenant := NewTMSRegistry(TMSProviderREST, nil)
+    shared.RegisterTenant("t1", tenant)
+
+    if shared.ForTenant("t1") != tenant {
+        t.Error("expected the tenant's own registry")
+    }
+    if shared.ForTenant("t2") != shared {
+        t.Error("expected tenants without settings to share the registry")
+    }
+    if ids := shared.TenantIDs(); len(ids) != 1 || ids[0] != "t1" {
+        t.Errorf("expected one tenant, got %v", ids)
+    }
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+
+    "freight-broker/backend/internal/interfaces"
+    "freight-broker/backend/internal/models"
+)
+
+const (
+    TMSProviderTurvo = "turvo"
+    TMSProviderREST  = "rest"
+)
+
+// TMSRegistry holds the configured TMS adapters and picks one per customer.
+// Tenants with their own TMS settings get their own registry; the rest
+// share this one.
+type TMSRegistry struct {
+    providers         map[string]interfaces.TMSService
+    defaultProvider   string
+    customerProviders map[string]string
+    tenants           map[string]*TMSRegistry
+}
+
+// NewTMSRegistry creates a registry. customerProviders maps customer names
+// (matched case-insensitively) to provider names.
+func NewTMSRegistry(defaultProvider string, customerProviders map[string]string) *TMSRegistry {
+    normalized := make(map[string]string, len(customerProviders))
+    for customer, provider := range customerProviders {
+        normalized[normalizeCustomerName(customer)] = provider
+    }
+
+    return &TMSRegistry{
+        providers:         make(map[string]interfaces.TMSService),
+        defaultProvider:   defaultProvider,
+        customerProviders: normalized,
+        tenants:           make(map[string]*TMSRegistry),
+    }
+}
+
+func (r *TMSRegistry) Register(name string, provider interfaces.TMSService) {
+    r.providers[name] = provider
+}
+
+// RegisterTenant gives a tenant its own registry.
+func (r *TMSRegistry) RegisterTenant(tenantID string, registry *TMSRegistry) {
+    r.tenants[tenantID] = registry
+}
+
+func (r *TMSRegistry) ForTenant(tenantID string) interfaces.TMSProviderRegistry {
+    if registry, ok := r.tenants[tenantID]; ok {
+        return registry
+    }
+    return r
+}
+
+// TenantIDs returns the tenants with their own registry.
+func (r *TMSRegistry) TenantIDs() []string {
+    ids := make([]string, 0, len(r.tenants))
+    for id := range r.tenants {
+        ids = append(ids, id)
+    }
+    sort.Strings(ids)
+    return ids
+}
+
+func (r *TMSRegistry) Provider(name string) (interfaces.TMSService, error) {
+    provider, ok := r.providers[name]
+    if !ok {
+        return nil, fmt.Errorf("TMS provider %q is not configured", name)
+    }
+    return provider, nil
+}
+
+func (r *TMSRegistry) ProviderNames() []string {
+    names := make([]string, 0, len(r.providers))
+    for name := range r.providers {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+func (r *TMSRegistry) ResolveProvider(customerName string) string {
+    if provider, ok := r.customerProviders[normalizeCustomerName(customerName)]; ok {
+        return provider
+    }
+    return r.defaultProvider
+}
+
+// Validate checks that the default and every customer mapping point at a
+// registered provider, in this registry and every tenant's.
+func (r *TMSRegistry) Validate() error {
+    if _, err := r.Provider(r.defaultProvider); err != nil {
+        return err
+    }
+    for customer, provider := range r.customerProviders {
+        if _, err := r.Provider(provider); err != nil {
+            return fmt.Errorf("customer %q: %w", customer, err)
+        }
+    }
+    for id, tenant := range r.tenants {
+        if err := tenant.Validate(); err != nil {
+            return fmt.Errorf("tenant %s: %w", id, err)
+        }
+    }
+    return nil
+}
+
+func normalizeCustomerName(name string) string {
+    return strings.ToLower(strings.TrimSpace(name))
+}
+
+// providerForLoad returns the adapter that owns a load's shipment. Loads
+// stored before providers were recorded are resolved from their customer.
+func providerForLoad(registry interfaces.TMSProviderRegistry, load *models.Load) (string, interfaces.TMSService, error) {
+    registry = registry.ForTenant(load.TenantID.String())
+    name := load.TMSProvider
+    if name == "" {
+        name = registry.ResolveProvider(load.Customer.Name)
+    }
+
+    provider, err := registry.Provider(name)
+    if err != nil {
+        return "", nil, err
+    }
+    return name, provider, nil
+}
+
+// tmsError adds context to an adapter error.
+func tmsError(message string, err error) error {
+    return fmt.Errorf("%s: %w", message, err)
+}
@@ -0,0 +1,367 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "io"
+    "log"
+    "math"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    "freight-broker/backend/internal/dto"
+)
+
+// ErrTMSCircuitOpen is returned without contacting the TMS while its circuit
+// breaker is open.
+var ErrTMSCircuitOpen = errors.New("TMS circuit breaker is open")
+
+const (
+    CircuitClosed   = "closed"
+    CircuitOpen     = "open"
+    CircuitHalfOpen = "half-open"
+)
+
+// TMSTransportConfig tunes the retry, rate limiting and circuit breaking
+// applied to every request a TMS adapter sends.
+type TMSTransportConfig struct {
+    MaxRetries  int
+    BaseBackoff time.Duration
+    MaxBackoff  time.Duration
+    // RatePerMinute and Burst size the client-side token bucket. Zero
+    // disables rate limiting.
+    RatePerMinute int
+    Burst         int
+    // BreakerThreshold consecutive failures open the circuit for
+    // BreakerCooldown, after which a single probe request is let through.
+    BreakerThreshold int
+    BreakerCooldown  time.Duration
+}
+
+func (c TMSTransportConfig) withDefaults() TMSTransportConfig {
+    if c.MaxRetries < 0 {
+        c.MaxRetries = 0
+    }
+    if c.BaseBackoff <= 0 {
+        c.BaseBackoff = 500 * time.Millisecond
+    }
+    if c.MaxBackoff <= 0 {
+        c.MaxBackoff = 10 * time.Second
+    }
+    if c.Burst <= 0 {
+        c.Burst = 1
+    }
+    if c.BreakerThreshold <= 0 {
+        c.BreakerThreshold = 5
+    }
+    if c.BreakerCooldown <= 0 {
+        c.BreakerCooldown = 30 * time.Second
+    }
+    return c
+}
+
+// tmsTransport is an http.RoundTripper shared by the TMS adapters. It retries
+// 429 and 5xx responses, honouring Retry-After, and network errors. Requests
+// that are not idempotent are only retried when the TMS signals it did not
+// process them (429 or 503), so a retried create cannot duplicate a shipment.
+type tmsTransport struct {
+    name    string
+    base    http.RoundTripper
+    config  TMSTransportConfig
+    limiter *tokenBucket
+    breaker *circuitBreaker
+}
+
+func newTMSTransport(name string, config TMSTransportConfig) *tmsTransport {
+    config = config.withDefaults()
+    return &tmsTransport{
+        name:    name,
+        base:    http.DefaultTransport,
+        config:  config,
+        limiter: newTokenBucket(config.RatePerMinute, config.Burst),
+        breaker: newCircuitBreaker(name, config.BreakerThreshold, config.BreakerCooldown),
+    }
+}
+
+func (t *tmsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+    ctx := req.Context()
+
+    for attempt := 0; ; attempt++ {
+        if err := t.breaker.Allow(); err != nil {
+            return nil, err
+        }
+        if err := t.limiter.Wait(ctx); err != nil {
+            t.breaker.Release()
+            return nil, err
+        }
+
+        attemptReq := req
+        if attempt > 0 {
+            attemptReq = req.Clone(ctx)
+            if req.GetBody != nil {
+                body, err := req.GetBody()
+                if err != nil {
+                    return nil, err
+                }
+                attemptReq.Body = body
+            }
+        }
+
+        resp, err := t.base.RoundTrip(attemptReq)
+        t.record(resp, err)
+
+        if !t.shouldRetry(req, resp, err, attempt) {
+            return resp, err
+        }
+
+        delay := t.backoff(attempt)
+        if resp != nil {
+            if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+                // A server asking for a longer pause than we are willing to
+                // wait gets its response back instead of an early retry.
+                if retryAfter > t.config.MaxBackoff {
+                    return resp, nil
+                }
+                delay = retryAfter
+            }
+            io.Copy(io.Discard, resp.Body)
+            resp.Body.Close()
+        }
+
+        log.Printf("TMS %s: retrying %s %s in %s (attempt %d)", t.name, req.Method, req.URL.Path, delay, attempt+1)
+
+        timer := time.NewTimer(delay)
+        select {
+        case <-ctx.Done():
+            timer.Stop()
+            return nil, ctx.Err()
+        case <-timer.C:
+        }
+    }
+}
+
+// record feeds the breaker. Rate limiting is not a sign of an outage and
+// leaves the breaker untouched.
+func (t *tmsTransport) record(resp *http.Response, err error) {
+    switch {
+    case err != nil && !errors.Is(err, context.Canceled):
+        t.breaker.Failure()
+    case err != nil, resp.StatusCode == http.StatusTooManyRequests:
+        t.breaker.Release()
+    case resp.StatusCode >= 500:
+        t.breaker.Failure()
+    default:
+        t.breaker.Success()
+    }
+}
+
+func (t *tmsTransport) shouldRetry(req *http.Request, resp *http.Response, err error, attempt int) bool {
+    if attempt >= t.config.MaxRetries || req.Context().Err() != nil {
+        return false
+    }
+    if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+        return false
+    }
+
+    idempotent := req.Method != http.MethodPost && req.Method != http.MethodPatch
+    if err != nil {
+        return idempotent
+    }
+
+    switch {
+    case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+        return true
+    case resp.StatusCode >= 500:
+        return idempotent
+    }
+    return false
+}
+
+func (t *tmsTransport) backoff(attempt int) time.Duration {
+    delay := time.Duration(float64(t.config.BaseBackoff) * math.Pow(2, float64(attempt)))
+    if delay > t.config.MaxBackoff {
+        return t.config.MaxBackoff
+    }
+    return delay
+}
+
+func (t *tmsTransport) Health() dto.TMSHealth {
+    return t.breaker.Health()
+}
+
+func (s *TurvoService) TMSHealth() dto.TMSHealth {
+    return s.transport.Health()
+}
+
+func (s *RESTTMSService) TMSHealth() dto.TMSHealth {
+    return s.transport.Health()
+}
+
+// parseRetryAfter accepts both delta-seconds and HTTP-date values.
+func parseRetryAfter(value string) (time.Duration, bool) {
+    if value == "" {
+        return 0, false
+    }
+    if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+        return time.Duration(seconds) * time.Second, true
+    }
+    if at, err := http.ParseTime(value); err == nil {
+        delay := time.Until(at)
+        if delay < 0 {
+            delay = 0
+        }
+        return delay, true
+    }
+    return 0, false
+}
+
+// tokenBucket is a client-side rate limiter holding up to burst tokens,
+// refilled continuously at ratePerMinute.
+type tokenBucket struct {
+    mu       sync.Mutex
+    rate     float64 // tokens per second
+    burst    float64
+    tokens   float64
+    lastFill time.Time
+}
+
+func newTokenBucket(ratePerMinute, burst int) *tokenBucket {
+    return &tokenBucket{
+        rate:     float64(ratePerMinute) / 60,
+        burst:    float64(burst),
+        tokens:   float64(burst),
+        lastFill: time.Now(),
+    }
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) Wait(ctx context.Context) error {
+    for {
+        wait := b.Take()
+        if wait == 0 {
+            return nil
+        }
+
+        timer := time.NewTimer(wait)
+        select {
+        case <-ctx.Done():
+            timer.Stop()
+            return ctx.Err()
+        case <-timer.C:
+        }
+    }
+}
+
+// Take takes a token if one is available. Otherwise it returns how long
+// until one will be.
+func (b *tokenBucket) Take() time.Duration {
+    if b.rate <= 0 {
+        return 0
+    }
+
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    now := time.Now()
+    b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.lastFill).Seconds()*b.rate)
+    b.lastFill = now
+    if b.tokens >= 1 {
+        b.tokens--
+        return 0
+    }
+    // Never round down to zero, which would read as a token taken.
+    return max(time.Duration((1-b.tokens)/b.rate*float64(time.Second)), time.Nanosecond)
+}
+
+// circuitBreaker stops calls to a failing TMS. After threshold consecutive
+// failures it opens for cooldown, then lets one probe through: success
+// closes it, failure opens it again.
+type circuitBreaker struct {
+    name      string
+    mu        sync.Mutex
+    threshold int
+    cooldown  time.Duration
+    state     string
+    failures  int
+    openedAt  time.Time
+    probing   bool
+}
+
+func newCircuitBreaker(name string, threshold int, cooldown time.Duration) *circuitBreaker {
+    return &circuitBreaker{
+        name:      name,
+        threshold: threshold,
+        cooldown:  cooldown,
+        state:     CircuitClosed,
+    }
+}
+
+func (b *circuitBreaker) Allow() error {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    switch b.state {
+    case CircuitOpen:
+        if time.Since(b.openedAt) < b.cooldown {
+            return ErrTMSCircuitOpen
+        }
+        b.state = CircuitHalfOpen
+        b.probing = true
+        return nil
+    case CircuitHalfOpen:
+        if b.probing {
+            return ErrTMSCircuitOpen
+        }
+        b.probing = true
+    }
+    return nil
+}
+
+func (b *circuitBreaker) Success() {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    if b.state != CircuitClosed {
+        log.Printf("TMS %s: circuit breaker closed", b.name)
+    }
+    b.state = CircuitClosed
+    b.failures = 0
+    b.probing = false
+}
+
+func (b *circuitBreaker) Failure() {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    b.failures++
+    b.probing = false
+    if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= b.threshold) {
+        log.Printf("TMS %s: circuit breaker opened after %d consecutive failures", b.name, b.failures)
+        b.state = CircuitOpen
+        b.openedAt = time.Now()
+    }
+}
+
+// Release ends a half-open probe that gave no verdict, such as a cancelled
+// or rate-limited request, so the next call can probe instead.
+func (b *circuitBreaker) Release() {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.probing = false
+}
+
+func (b *circuitBreaker) Health() dto.TMSHealth {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    health := dto.TMSHealth{
+        Circuit:             b.state,
+        ConsecutiveFailures: b.failures,
+    }
+    if b.state != CircuitClosed {
+        health.OpenedAt = b.openedAt.Format(time.RFC3339)
+        health.RetryAt = b.openedAt.Add(b.cooldown).Format(time.RFC3339)
+    }
+    return health
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/faketurvo"
+)
+
+func newResilientTurvoService(t *testing.T, transport TMSTransportConfig) (*faketurvo.Server, *TurvoService) {
+    t.Helper()
+
+    fake := faketurvo.New(faketurvo.Config{})
+    t.Cleanup(fake.Close)
+
+    service := NewTurvoService(TMSServiceConfig{
+        BaseURL:   fake.BaseURL(),
+        AuthURL:   fake.AuthURL(),
+        Transport: transport,
+    })
+    if err := service.Authenticate(context.Background()); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+    return fake, service
+}
+
+func TestTMSTransportRetriesIdempotentServerErrors(t *testing.T) {
+    fake, service := newResilientTurvoService(t, TMSTransportConfig{MaxRetries: 3, BaseBackoff: time.Millisecond})
+    id := fake.AddShipment(toTurvoShipmentRequest(testShipment()))
+
+    fake.InjectFault(faketurvo.RouteGet, faketurvo.Fault{Status: http.StatusBadGateway, Times: 2})
+    if _, err := service.GetShipment(context.Background(), id); err != nil {
+        t.Fatalf("expected GetShipment to succeed after retries, got %v", err)
+    }
+    if got := fake.Requests(faketurvo.RouteGet); got != 3 {
+        t.Errorf("expected 3 get requests, got %d", got)
+    }
+}
+
+func TestTMSTransportDoesNotRetryCreateOnServerError(t *testing.T) {
+    fake, service := newResilientTurvoService(t, TMSTransportConfig{MaxRetries: 3, BaseBackoff: time.Millisecond})
+
+    fake.InjectFault(faketurvo.RouteCreate, faketurvo.Fault{Status: http.StatusInternalServerError, Times: 1})
+    if _, err := service.CreateShipment(context.Background(), testShipment()); err == nil {
+        t.Fatal("expected the 500 to be returned")
+    }
+    if got := fake.Requests(faketurvo.RouteCreate); got != 1 {
+        t.Errorf("expected a single create request, got %d", got)
+    }
+
+    fake.InjectFault(faketurvo.RouteCreate, faketurvo.Fault{Status: http.StatusTooManyRequests, RetryAfter: "0", Times: 1})
+    if _, err := service.CreateShipment(context.Background(), testShipment()); err != nil {
+        t.Fatalf("expected a rate-limited create to be retried, got %v", err)
+    }
+    if got := len(fake.Shipments()); got != 1 {
+        t.Errorf("expected exactly one shipment, got %d", got)
+    }
+}
+
+func TestTMSTransportHonoursLongRetryAfter(t *testing.T) {
+    fake, service := newResilientTurvoService(t, TMSTransportConfig{MaxRetries: 3, MaxBackoff: time.Second})
+
+    fake.InjectFault(faketurvo.RouteList, faketurvo.Fault{Status: http.StatusTooManyRequests, RetryAfter: "120"})
+    start := time.Now()
+    if _, err := service.ListShipments(context.Background(), 0, 10, dto.ListShipmentsFilter{}); err == nil {
+        t.Fatal("expected the 429 to be returned")
+    }
+    if time.Since(start) > 500*time.Millisecond {
+        t.Error("expected no wait when Retry-After exceeds the maximum backoff")
+    }
+    if got := fake.Requests(faketurvo.RouteList); got != 1 {
+        t.Errorf("expected a single list request, got %d", got)
+    }
+}
+
+func TestTMSTransportCircuitBreaker(t *testing.T) {
+    fake, service := newResilientTurvoService(t, TMSTransportConfig{
+        BreakerThreshold: 2,
+        BreakerCooldown:  100 * time.Millisecond,
+    })
+    ctx := context.Background()
+
+    fake.InjectFault(faketurvo.RouteList, faketurvo.Fault{Status: http.StatusInternalServerError})
+    for i := 0; i < 2; i++ {
+        service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{})
+    }
+    if got := service.TMSHealth().Circuit; got != CircuitOpen {
+        t.Fatalf("expected the circuit to be open, got %q", got)
+    }
+
+    _, err := service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{})
+    if !errors.Is(err, ErrTMSCircuitOpen) {
+        t.Fatalf("expected ErrTMSCircuitOpen, got %v", err)
+    }
+    if got := fake.Requests(faketurvo.RouteList); got != 2 {
+        t.Errorf("expected the open circuit to short-circuit requests, got %d", got)
+    }
+
+    fake.ClearFaults()
+    time.Sleep(150 * time.Millisecond)
+    if _, err := service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{}); err != nil {
+        t.Fatalf("expected the probe to succeed, got %v", err)
+    }
+    if got := service.TMSHealth().Circuit; got != CircuitClosed {
+        t.Errorf("expected the circuit to close after a successful probe, got %q", got)
+    }
+}
+
+func TestTokenBucketLimitsRate(t *testing.T) {
+    bucket := newTokenBucket(600, 1)
+    ctx := context.Background()
+
+    start := time.Now()
+    for i := 0; i < 3; i++ {
+        if err := bucket.Wait(ctx); err != nil {
+            t.Fatalf("Wait: %v", err)
+        }
+    }
+    if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+        t.Errorf("expected 3 requests at 10/s with burst 1 to take ~200ms, took %s", elapsed)
+    }
+}
+
+func TestParseRetryAfter(t *testing.T) {
+    if delay, ok := parseRetryAfter("3"); !ok || delay != 3*time.Second {
+        t.Errorf("expected 3s, got %s (%v)", delay, ok)
+    }
+    at := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+    if delay, ok := parseRetryAfter(at); !ok || delay <= 0 || delay > time.Minute {
+        t.Errorf("expected about a minute for an HTTP date, got %s (%v)", delay, ok)
+    }
+    if _, ok := parseRetryAfter("soon"); ok {
+        t.Error("expected an invalid value to be rejected")
+    }
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+    "strconv"
+
+    "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/models"
+)
+
+// Turvo stop type codes used on globalRoute stops.
+var (
+    turvoStopPickup   = dto.StatusCode{Key: "1500", Value: "Pickup"}
+    turvoStopDelivery = dto.StatusCode{Key: "1501", Value: "Delivery"}
+)
+
+const turvoPOReferenceType = "po"
+
+// turvoStatuses maps our load statuses to the Turvo shipment status codes
+// sent on create and update.
+var turvoStatuses = map[string]dto.StatusCode{
+    models.StatusQuoted:     {Key: "2100", Value: "Quote active"},
+    models.StatusTendered:   {Key: "2101", Value: "Tendered"},
+    models.StatusCovered:    {Key: "2102", Value: "Covered"},
+    models.StatusDispatched: {Key: "2103", Value: "Dispatched"},
+    models.StatusAtPickup:   {Key: "2104", Value: "At pickup"},
+    models.StatusInTransit:  {Key: "2105", Value: "En route"},
+    models.StatusDelivered:  {Key: "2107", Value: "Delivered"},
+    models.StatusInvoiced:   {Key: "2108", Value: "Ready for billing"},
+    models.StatusCancelled:  {Key: "2113", Value: "Cancelled"},
+}
+
+// turvoStatusKeys maps Turvo status codes back to our statuses. Turvo tracks
+// a shipment in finer steps than we do, so several codes share a status.
+var turvoStatusKeys = map[string]string{
+    "2106": models.StatusInTransit, // Picked up
+    "2114": models.StatusInTransit, // At delivery
+    "2115": models.StatusDelivered, // Route complete
+    "2109": models.StatusInvoiced,  // Processing
+    "2110": models.StatusInvoiced,  // Carrier paid
+    "2111": models.StatusInvoiced,  // Customer paid
+    "2112": models.StatusInvoiced,  // Completed
+}
+
+func init() {
+    for status, code := range turvoStatuses {
+        turvoStatusKeys[code.Key] = status
+    }
+}
+
+// toTurvoStatus returns the Turvo code for one of our statuses. Anything
+// else is passed through, as loads stored before statuses were normalized
+// hold Turvo codes already.
+func toTurvoStatus(status dto.ShipmentStatus) dto.StatusCode {
+    if code, ok := turvoStatuses[status.Code]; ok {
+        return code
+    }
+    return dto.StatusCode{Key: status.Code, Value: status.Label}
+}
+
+func fromTurvoStatus(code dto.StatusCode) dto.ShipmentStatus {
+    if status, ok := turvoStatusKeys[code.Key]; ok {
+        return dto.ShipmentStatus{Code: status, Label: models.StatusLabel(status)}
+    }
+    return dto.ShipmentStatus{Code: code.Key, Label: code.Value}
+}
+
+// toTurvoShipmentRequest translates the neutral shipment into Turvo's
+// create/update payload.
+func toTurvoShipmentRequest(shipment dto.Shipment) dto.CreateShipmentRequest {
+    return dto.CreateShipmentRequest{
+        LTLShipment: shipment.LTL,
+        StartDate: dto.DateInfo{
+            Date:     shipment.Origin.Date,
+            TimeZone: shipment.Origin.TimeZone,
+        },
+        EndDate: dto.DateInfo{
+            Date:     shipment.Destination.Date,
+            TimeZone: shipment.Destination.TimeZone,
+        },
+        Status: dto.Status{
+            Code: toTurvoStatus(shipment.Status),
+        },
+        Lane: dto.Lane{
+            Start: formatLane(shipment.Origin.City, shipment.Origin.State),
+            End:   formatLane(shipment.Destination.City, shipment.Destination.State),
+        },
+        GlobalRoute: toTurvoRoute(shipment.Stops),
+        CustomerOrder: []dto.CustomerOrder{{
+            CustomerOrderSourceId: shipment.SourceID,
+            Customer: dto.CustomerInfo{
+                Name: shipment.CustomerName,
+            },
+        }},
+    }
+}
+
+func fromTurvoShipment(resp *dto.ShipmentResponse) *dto.Shipment {
+    shipment := &dto.Shipment{
+        CustomID: resp.CustomID,
+        Status:   fromTurvoStatus(resp.Status.Code),
+        Origin: dto.ShipmentLocation{
+            Date:     resp.StartDate.Date,
+            TimeZone: resp.StartDate.TimeZone,
+        },
+        Destination: dto.ShipmentLocation{
+            Date:     resp.EndDate.Date,
+            TimeZone: resp.EndDate.TimeZone,
+        },
+        LastUpdatedOn: resp.LastUpdatedOn,
+    }
+
+    if resp.ID != 0 {
+        shipment.ID = strconv.Itoa(resp.ID)
+    }
+
+    shipment.Origin.City, shipment.Origin.State = splitLane(resp.Lane.Start)
+    shipment.Destination.City, shipment.Destination.State = splitLane(resp.Lane.End)
+
+    for _, order := range resp.CustomerOrder {
+        if shipment.SourceID == "" {
+            shipment.SourceID = order.CustomerOrderSourceId
+        }
+        if shipment.CustomerName == "" {
+            shipment.CustomerName = order.Customer.Name
+        }
+    }
+    if len(resp.CarrierOrder) > 0 {
+        shipment.CarrierName = resp.CarrierOrder[0].Carrier.Name
+    }
+    shipment.Stops = fromTurvoRoute(resp.GlobalRoute)
+
+    return shipment
+}
+
+// toTurvoRoute maps stops onto Turvo's globalRoute. PO references become the
+// stop's poNumbers and other references its externalIds.
+func toTurvoRoute(stops []dto.ShipmentStop) []dto.RouteStop {
+    if len(stops) == 0 {
+        return nil
+    }
+
+    route := make([]dto.RouteStop, len(stops))
+    for i, stop := range stops {
+        routeStop := dto.RouteStop{
+            Sequence:        stop.Sequence,
+            SegmentSequence: stop.Sequence,
+            StopType:        turvoStopDelivery,
+            Name:            stop.Name,
+            Timezone:        stop.TimeZone,
+            Address: dto.RouteAddress{
+                Line1: stop.Street,
+                City:  stop.City,
+                State: stop.State,
+                Zip:   stop.Zip,
+            },
+            Appointment: dto.Appointment{
+                Start:    stop.AppointmentStart,
+                Timezone: stop.TimeZone,
+            },
+            Notes: stop.Notes,
+        }
+        if stop.Type == dto.ShipmentStopPickup {
+            routeStop.StopType = turvoStopPickup
+        }
+        if !stop.AppointmentEnd.IsZero() {
+            end := stop.AppointmentEnd
+            routeStop.Appointment.End = &end
+        }
+        for _, ref := range stop.References {
+            if ref.Type == turvoPOReferenceType {
+                routeStop.PONumbers = append(routeStop.PONumbers, ref.Value)
+                continue
+            }
+            routeStop.ExternalIDs = append(routeStop.ExternalIDs, dto.ExternalID{
+                Type:  dto.StatusCode{Key: ref.Type, Value: ref.Type},
+                Value: ref.Value,
+            })
+        }
+        for _, commodity := range stop.Commodities {
+            routeStop.Items = append(routeStop.Items, dto.RouteItem{
+                Name: commodity.Description,
+                Qty:  commodity.Quantity,
+                Unit: dto.StatusCode{Key: commodity.PackageType, Value: commodity.PackageType},
+                GrossWeight: dto.GrossWeight{
+                    Weight: commodity.Weight,
+                    Unit:   commodity.WeightUnit,
+                },
+            })
+        }
+        route[i] = routeStop
+    }
+    return route
+}
+
+func fromTurvoRoute(route []dto.RouteStop) []dto.ShipmentStop {
+    if len(route) == 0 {
+        return nil
+    }
+
+    stops := make([]dto.ShipmentStop, len(route))
+    for i, routeStop := range route {
+        stop := dto.ShipmentStop{
+            Sequence:         routeStop.Sequence,
+            Type:             dto.ShipmentStopDrop,
+            Name:             routeStop.Name,
+            Street:           routeStop.Address.Line1,
+            City:             routeStop.Address.City,
+            State:            routeStop.Address.State,
+            Zip:              routeStop.Address.Zip,
+            AppointmentStart: routeStop.Appointment.Start,
+            TimeZone:         routeStop.Timezone,
+            Notes:            routeStop.Notes,
+        }
+        if routeStop.StopType.Key == turvoStopPickup.Key {
+            stop.Type = dto.ShipmentStopPickup
+        }
+        if routeStop.Appointment.End != nil {
+            stop.AppointmentEnd = *routeStop.Appointment.End
+        }
+        for _, po := range routeStop.PONumbers {
+            stop.References = append(stop.References, dto.ShipmentReference{Type: turvoPOReferenceType, Value: po})
+        }
+        for _, id := range routeStop.ExternalIDs {
+            stop.References = append(stop.References, dto.ShipmentReference{Type: id.Type.Key, Value: id.Value})
+        }
+        for _, item := range routeStop.Items {
+            stop.Commodities = append(stop.Commodities, dto.ShipmentCommodity{
+                Description: item.Name,
+                Quantity:    item.Qty,
+                PackageType: item.Unit.Key,
+                Weight:      item.GrossWeight.Weight,
+                WeightUnit:  item.GrossWeight.Unit,
+            })
+        }
+        stops[i] = stop
+    }
+
+    sort.SliceStable(stops, func(i, j int) bool {
+        return stops[i].Sequence < stops[j].Sequence
+    })
+    return stops
+}
+
+// formatLane renders a city and state as a Turvo "City, ST" lane end.
+func formatLane(city, state string) string {
+    return fmt.Sprintf("%s, %s", city, state)
+}
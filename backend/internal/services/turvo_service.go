@@ -8,9 +8,12 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"sync"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
+	"freight-broker/backend/internal/apperrors"
 	"freight-broker/backend/internal/dto/tms"
 )
 
@@ -29,52 +32,77 @@ type TMSServiceConfig struct {
     IsSandbox    bool
     TurvoUsername string
     TurvoPassword string
+    // BaseURL and AuthURL override the sandbox/production endpoints, e.g. to
+    // point at a fake Turvo in tests.
+    BaseURL      string
+    AuthURL      string
+    Timeout      time.Duration
+    Transport    TMSTransportConfig
 }
 
 type TurvoService struct {
-    config       TMSServiceConfig
-    client       *http.Client
-    authToken    string
-    tokenExpiry  time.Time
-    mu           sync.RWMutex
+    config    TMSServiceConfig
+    client    *http.Client
+    transport *tmsTransport
+    tokens    *turvoTokenManager
 }
 
 func NewTurvoService(config TMSServiceConfig) *TurvoService {
-    return &TurvoService{
-        config: config,
-        client: &http.Client{
-            Timeout: time.Second * 30,
-        },
+    if config.Timeout <= 0 {
+        config.Timeout = time.Second * 30
     }
+
+    s := &TurvoService{
+        config:    config,
+        transport: newTMSTransport(TMSProviderTurvo, config.Transport),
+    }
+    s.client = &http.Client{
+        Timeout:   config.Timeout,
+        Transport: s.transport,
+    }
+    s.tokens = newTurvoTokenManager(s.requestToken, dto.TurvoAuthRequest{
+        GrantType:    "password",
+        ClientID:     config.ClientID,
+        ClientSecret: config.ClientSecret,
+        Username:     config.TurvoUsername,
+        Password:     config.TurvoPassword,
+        Scope:        "read+trust+write",
+        Type:         "business",
+    })
+    return s
 }
 
 func (s *TurvoService) Authenticate(ctx context.Context) error {
+    return s.tokens.Login(ctx)
+}
 
-    authURL := prodAuthURL
-    if s.config.IsSandbox {
-        authURL = sandboxAuthURL
-    }
+func (s *TurvoService) IsTokenValid() bool {
+    return s.tokens.Valid()
+}
 
+func (s *TurvoService) RefreshToken(ctx context.Context) error {
+    return s.tokens.Refresh(ctx)
+}
 
-    authReq := dto.TurvoAuthRequest{
-        GrantType:    "password",
-        ClientID:     s.config.ClientID,
-        ClientSecret: s.config.ClientSecret,
-        Username: s.config.TurvoUsername,
-        Password: s.config.TurvoPassword,
-        Scope:        "read+trust+write",
-        Type:         "business",
-    }
+func (s *TurvoService) GetAuthToken() string {
+    return s.tokens.current()
+}
 
+// RunTokenRefresh keeps the access token fresh in the background until ctx
+// is cancelled.
+func (s *TurvoService) RunTokenRefresh(ctx context.Context) {
+    s.tokens.Run(ctx)
+}
 
+func (s *TurvoService) requestToken(ctx context.Context, authReq dto.TurvoAuthRequest) (*dto.TurvoAuthResponse, error) {
     jsonBody, err := json.Marshal(authReq)
     if err != nil {
-        return fmt.Errorf("failed to marshal auth request: %w", err)
+        return nil, fmt.Errorf("failed to marshal auth request: %w", err)
     }
 
-    req, err := http.NewRequestWithContext(ctx, "POST", authURL, bytes.NewBuffer(jsonBody))
+    req, err := http.NewRequestWithContext(ctx, "POST", s.getAuthURL(), bytes.NewBuffer(jsonBody))
     if err != nil {
-        return fmt.Errorf("failed to create request: %w", err)
+        return nil, fmt.Errorf("failed to create request: %w", err)
     }
 
     req.Header.Set("Content-Type", "application/json")
@@ -82,234 +110,274 @@ func (s *TurvoService) Authenticate(ctx context.Context) error {
 
     resp, err := s.client.Do(req)
     if err != nil {
-        return fmt.Errorf("failed to make request: %w", err)
+        return nil, apperrors.UpstreamUnavailable("failed to make request", err)
     }
     defer resp.Body.Close()
 
     bodyBytes, err := io.ReadAll(resp.Body)
     if err != nil {
-        return fmt.Errorf("failed to read response body: %w", err)
+        return nil, apperrors.UpstreamUnavailable("failed to read response body", err)
     }
 
     if resp.StatusCode != http.StatusOK {
-        return fmt.Errorf("authentication failed with status: %d, body: %s", resp.StatusCode, string(bodyBytes))
+        log.Printf("Turvo authentication failed (status %d): %s", resp.StatusCode, string(bodyBytes))
+        return nil, statusError(resp.StatusCode, resp.Header,
+            fmt.Sprintf("authentication failed with status: %d", resp.StatusCode))
     }
 
     var authResp dto.TurvoAuthResponse
     if err := json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(&authResp); err != nil {
-        return fmt.Errorf("failed to decode response: %w", err)
+        return nil, fmt.Errorf("failed to decode response: %w", err)
     }
-
-    s.mu.Lock()
-    s.authToken = authResp.AccessToken
-    s.tokenExpiry = time.Now().Add(time.Second * time.Duration(authResp.ExpiresIn))
-    s.mu.Unlock()
-
-
-    return nil
-}
-
-func (s *TurvoService) IsTokenValid() bool {
-    s.mu.RLock()
-    defer s.mu.RUnlock()
-    
-    if s.authToken == "" {
-        return false
+    if authResp.AccessToken == "" {
+        return nil, fmt.Errorf("authentication response did not include an access token")
     }
-    
-    return time.Now().Add(5 * time.Minute).Before(s.tokenExpiry)
-}
 
-func (s *TurvoService) RefreshToken(ctx context.Context) error {
-    return s.Authenticate(ctx)
-}
-
-func (s *TurvoService) GetAuthToken() string {
-    s.mu.RLock()
-    defer s.mu.RUnlock()
-    return s.authToken
+    return &authResp, nil
 }
 
-func (s *TurvoService) CreateShipment(ctx context.Context, req dto.CreateShipmentRequest) (*dto.ShipmentResponse, error) {
+func (s *TurvoService) CreateShipment(ctx context.Context, shipment dto.Shipment) (*dto.Shipment, error) {
     url := s.getBaseURL() + baseShipmentsURL
     
-    jsonData, err := json.Marshal(req)
+    jsonData, err := json.Marshal(toTurvoShipmentRequest(shipment))
     if err != nil {
         return nil, fmt.Errorf("failed to marshal request: %w", err)
     }
     
     log.Printf("Creating shipment with payload: %s", string(jsonData))
 
-    httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-    if err != nil {
-        return nil, fmt.Errorf("failed to create request: %w", err)
-    }
-
-    s.setAuthHeaders(httpReq)
-
-    resp, err := s.client.Do(httpReq)
-    if err != nil {
-        return nil, fmt.Errorf("failed to make request: %w", err)
-    }
-    defer resp.Body.Close()
-
-    bodyBytes, err := io.ReadAll(resp.Body)
+    resp, err := s.do(ctx, "POST", url, jsonData)
     if err != nil {
-        return nil, fmt.Errorf("failed to read response body: %w", err)
+        return nil, err
     }
     
     log.Printf("Response status: %d", resp.StatusCode)
-    log.Printf("Response body: %s", string(bodyBytes))
+    log.Printf("Response body: %s", string(resp.Body))
 
-    if resp.StatusCode != http.StatusOK {
-        bodyBytes, _ := io.ReadAll(resp.Body)
+    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
         log.Printf("Failed request payload: %s", string(jsonData))
-        log.Printf("Error response: %s", string(bodyBytes))
-        return nil, fmt.Errorf("API returned status code: %d, body: %s", resp.StatusCode, string(bodyBytes))
+        return nil, resp.apiError()
     }
 
-    var shipmentResp dto.ShipmentResponse
-    if err := json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(&shipmentResp); err != nil {
-        return nil, fmt.Errorf("failed to decode response: %w", err)
+    shipmentResp, err := decodeShipmentResponse(resp.Body)
+    if err != nil {
+        return nil, err
     }
 
-    return &shipmentResp, nil
+    return fromTurvoShipment(shipmentResp), nil
 }
 
-func (s *TurvoService) ListShipments(ctx context.Context, page, pageSize int) (*dto.ListShipmentsResponse, error) {
-    url := fmt.Sprintf("%s%s/list?start=%d&pageSize=%d", 
-        s.getBaseURL(), baseShipmentsURL, page, pageSize)
-    
-    req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create request: %w", err)
+func (s *TurvoService) ListShipments(ctx context.Context, page, pageSize int, filter dto.ListShipmentsFilter) (*dto.ShipmentPage, error) {
+    query := url.Values{}
+    query.Set("start", strconv.Itoa(page))
+    query.Set("pageSize", strconv.Itoa(pageSize))
+    if !filter.UpdatedSince.IsZero() {
+        query.Set("lastUpdatedOn[gte]", filter.UpdatedSince.UTC().Format(time.RFC3339))
     }
 
-    s.setAuthHeaders(req)
+    listURL := fmt.Sprintf("%s%s/list?%s", s.getBaseURL(), baseShipmentsURL, query.Encode())
 
-    resp, err := s.client.Do(req)
+    resp, err := s.do(ctx, "GET", listURL, nil)
     if err != nil {
-        return nil, fmt.Errorf("failed to make request: %w", err)
+        return nil, err
     }
-    defer resp.Body.Close()
 
     if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("API returned status code: %d", resp.StatusCode)
+        return nil, resp.apiError()
     }
 
     var listResp dto.ListShipmentsResponse
-    if err := json.NewDecoder(resp.Body).Decode(&listResp); err != nil {
+    if err := json.Unmarshal(resp.Body, &listResp); err != nil {
         return nil, fmt.Errorf("failed to decode response: %w", err)
     }
 
-    return &listResp, nil
+    shipmentPage := &dto.ShipmentPage{
+        Shipments:     make([]dto.Shipment, len(listResp.Details.Shipments)),
+        MoreAvailable: listResp.Details.Pagination.MoreAvailable,
+    }
+    for i := range listResp.Details.Shipments {
+        shipmentPage.Shipments[i] = *fromTurvoShipment(&listResp.Details.Shipments[i])
+    }
+
+    return shipmentPage, nil
 }
 
-func (s *TurvoService) GetShipment(ctx context.Context, id string) (*dto.ShipmentResponse, error) {
+func (s *TurvoService) GetShipment(ctx context.Context, id string) (*dto.Shipment, error) {
     url := fmt.Sprintf("%s%s/%s", s.getBaseURL(), baseShipmentsURL, id)
     
-    req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+    resp, err := s.do(ctx, "GET", url, nil)
     if err != nil {
-        return nil, fmt.Errorf("failed to create request: %w", err)
+        return nil, err
     }
 
-    s.setAuthHeaders(req)
+    if resp.StatusCode != http.StatusOK {
+        return nil, resp.apiError()
+    }
 
-    resp, err := s.client.Do(req)
+    shipment, err := decodeShipmentResponse(resp.Body)
     if err != nil {
-        return nil, fmt.Errorf("failed to make request: %w", err)
+        return nil, err
+    }
+
+    return fromTurvoShipment(shipment), nil
+}
+func (s *TurvoService) UpdateShipment(ctx context.Context, id string, shipment dto.Shipment) (*dto.Shipment, error) {
+    url := fmt.Sprintf("%s%s/%s", s.getBaseURL(), baseShipmentsURL, id)
+    
+    jsonData, err := json.Marshal(toTurvoShipmentRequest(shipment))
+    if err != nil {
+        return nil, fmt.Errorf("failed to marshal request: %w", err)
+    }
+
+    resp, err := s.do(ctx, "PUT", url, jsonData)
+    if err != nil {
+        return nil, err
     }
-    defer resp.Body.Close()
 
     if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("API returned status code: %d", resp.StatusCode)
+        return nil, resp.apiError()
     }
 
-    var shipment dto.ShipmentResponse
-    if err := json.NewDecoder(resp.Body).Decode(&shipment); err != nil {
-        return nil, fmt.Errorf("failed to decode response: %w", err)
+    shipmentResp, err := decodeShipmentResponse(resp.Body)
+    if err != nil {
+        return nil, err
     }
 
-    return &shipment, nil
+    return fromTurvoShipment(shipmentResp), nil
 }
-func (s *TurvoService) UpdateShipment(ctx context.Context, id string, req dto.CreateShipmentRequest) (*dto.ShipmentResponse, error) {
+
+func (s *TurvoService) DeleteShipment(ctx context.Context, id string) error {
     url := fmt.Sprintf("%s%s/%s", s.getBaseURL(), baseShipmentsURL, id)
     
-    jsonData, err := json.Marshal(req)
+    resp, err := s.do(ctx, "DELETE", url, nil)
     if err != nil {
-        return nil, fmt.Errorf("failed to marshal request: %w", err)
+        return err
     }
 
-    httpReq, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonData))
-    if err != nil {
-        return nil, fmt.Errorf("failed to create request: %w", err)
+    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+        return resp.apiError()
     }
 
-    s.setAuthHeaders(httpReq)
+    return nil
+}
+
+// Helper methods
 
-    resp, err := s.client.Do(httpReq)
-    if err != nil {
-        return nil, fmt.Errorf("failed to make request: %w", err)
+// decodeShipmentResponse accepts both the bare shipment and the
+// {"Status", "details"} envelope, and surfaces enveloped errors that Turvo
+// reports with a 200 status. Their bodies are only logged.
+func decodeShipmentResponse(body []byte) (*dto.ShipmentResponse, error) {
+    var envelope struct {
+        Status  string          `json:"Status"`
+        Details json.RawMessage `json:"details"`
+    }
+    if err := json.Unmarshal(body, &envelope); err != nil {
+        return nil, fmt.Errorf("failed to decode response: %w", err)
     }
-    defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusOK {
-        var errResp struct {
-            Message string `json:"message"`
-            Details string `json:"details,omitempty"`
+    payload := body
+    if envelope.Status != "" {
+        if envelope.Status != "SUCCESS" {
+            log.Printf("Turvo API error (status %s): %s", envelope.Status, string(body))
+            appErr := apperrors.UpstreamRejected(http.StatusOK)
+            appErr.Details = "TMS reported an error in a successful response"
+            return nil, appErr
         }
-        if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
-            return nil, fmt.Errorf("API returned status code: %d", resp.StatusCode)
-        }
-        return nil, fmt.Errorf("API error: %s - %s", errResp.Message, errResp.Details)
+        payload = envelope.Details
     }
 
     var shipmentResp dto.ShipmentResponse
-    if err := json.NewDecoder(resp.Body).Decode(&shipmentResp); err != nil {
+    if err := json.Unmarshal(payload, &shipmentResp); err != nil {
         return nil, fmt.Errorf("failed to decode response: %w", err)
     }
 
     return &shipmentResp, nil
 }
 
-func (s *TurvoService) DeleteShipment(ctx context.Context, id string) error {
-    url := fmt.Sprintf("%s%s/%s", s.getBaseURL(), baseShipmentsURL, id)
-    
-    req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+type turvoResponse struct {
+    StatusCode int
+    Header     http.Header
+    Body       []byte
+}
+
+// apiError turns a failed response into a typed error. Turvo's error body
+// may echo our request or name internal hosts, so it is only logged.
+func (r *turvoResponse) apiError() error {
+    log.Printf("Turvo API error (status %d): %s", r.StatusCode, string(r.Body))
+    return statusError(r.StatusCode, r.Header, fmt.Sprintf("API returned status code: %d", r.StatusCode))
+}
+
+// statusError classifies an upstream status, carrying over Retry-After.
+func statusError(statusCode int, header http.Header, message string) error {
+    appErr := apperrors.FromHTTPStatus(statusCode, message)
+    if appErr.Code == apperrors.CodeRateLimited {
+        appErr.RetryAfter, _ = parseRetryAfter(header.Get("Retry-After"))
+    }
+    return appErr
+}
+
+// do sends an authenticated request. A 401 refreshes the token and retries
+// once, since Turvo may revoke a token before its advertised expiry.
+func (s *TurvoService) do(ctx context.Context, method, url string, body []byte) (*turvoResponse, error) {
+    token, err := s.tokens.Token(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to authenticate with TMS: %w", err)
+    }
+
+    resp, err := s.send(ctx, method, url, body, token)
+    if err != nil || resp.StatusCode != http.StatusUnauthorized {
+        return resp, err
+    }
+
+    token, err = s.tokens.Invalidate(ctx, token)
     if err != nil {
-        return fmt.Errorf("failed to create request: %w", err)
+        return nil, fmt.Errorf("failed to refresh TMS token after 401: %w", err)
     }
+    return s.send(ctx, method, url, body, token)
+}
 
-    s.setAuthHeaders(req)
+func (s *TurvoService) send(ctx context.Context, method, url string, body []byte, token string) (*turvoResponse, error) {
+    req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create request: %w", err)
+    }
+
+    req.Header.Set("Content-Type", "application/json")
+    req.Header.Set("x-api-key", s.config.APIKey)
+    req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
     resp, err := s.client.Do(req)
     if err != nil {
-        return fmt.Errorf("failed to make request: %w", err)
+        return nil, apperrors.UpstreamUnavailable("failed to make request", err)
     }
     defer resp.Body.Close()
 
-    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
-        var errResp struct {
-            Message string `json:"message"`
-            Details string `json:"details,omitempty"`
-        }
-        if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
-            return fmt.Errorf("API returned status code: %d", resp.StatusCode)
-        }
-        return fmt.Errorf("API error: %s - %s", errResp.Message, errResp.Details)
+    respBody, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, apperrors.UpstreamUnavailable("failed to read response body", err)
     }
 
-    return nil
+    return &turvoResponse{
+        StatusCode: resp.StatusCode,
+        Header:     resp.Header,
+        Body:       respBody,
+    }, nil
 }
 
-// Helper methods
-func (s *TurvoService) setAuthHeaders(req *http.Request) {
-    req.Header.Set("Content-Type", "application/json")
-    req.Header.Set("x-api-key", s.config.APIKey)
-    req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.GetAuthToken()))
+func (s *TurvoService) getAuthURL() string {
+    if s.config.AuthURL != "" {
+        return s.config.AuthURL
+    }
+    if s.config.IsSandbox {
+        return sandboxAuthURL
+    }
+    return prodAuthURL
 }
 
 func (s *TurvoService) getBaseURL() string {
+    if s.config.BaseURL != "" {
+        return strings.TrimRight(s.config.BaseURL, "/")
+    }
     if s.config.IsSandbox {
         return "https://my-sandbox-publicapi.turvo.com/v1"
     }
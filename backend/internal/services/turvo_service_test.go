@@ -0,0 +1,363 @@
+package services
+
+import (
+    "context"
+    "net/http"
+    "strings"
+    "sync"
+    "testing"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto/tms"
+    "freight-broker/backend/internal/faketurvo"
+    "freight-broker/backend/internal/models"
+)
+
+func newFakeTurvoService(t *testing.T, fakeConfig faketurvo.Config, timeout time.Duration) (*faketurvo.Server, *TurvoService) {
+    t.Helper()
+
+    fake := faketurvo.New(fakeConfig)
+    t.Cleanup(fake.Close)
+
+    service := NewTurvoService(TMSServiceConfig{
+        APIKey:        "api-key",
+        ClientID:      "client",
+        ClientSecret:  "secret",
+        TurvoUsername: "user",
+        TurvoPassword: "password",
+        BaseURL:       fake.BaseURL(),
+        AuthURL:       fake.AuthURL(),
+        Timeout:       timeout,
+    })
+    return fake, service
+}
+
+func testShipment() dto.Shipment {
+    pickup := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+    return dto.Shipment{
+        SourceID: "FL-1",
+        LTL:      true,
+        Status:   dto.ShipmentStatus{Code: models.StatusTendered, Label: "Tendered"},
+        Origin: dto.ShipmentLocation{
+            City: "Chicago", State: "IL", Date: pickup, TimeZone: defaultShipmentTimeZone,
+        },
+        Destination: dto.ShipmentLocation{
+            City: "Dallas", State: "TX", Date: pickup.Add(48 * time.Hour), TimeZone: defaultShipmentTimeZone,
+        },
+        CustomerName: "Acme",
+    }
+}
+
+func TestTurvoServiceShipmentLifecycle(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{APIKey: "api-key", Username: "user"}, 0)
+    ctx := context.Background()
+
+    if err := service.Authenticate(ctx); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+    if !service.IsTokenValid() {
+        t.Fatal("expected a valid token after authenticating")
+    }
+
+    created, err := service.CreateShipment(ctx, testShipment())
+    if err != nil {
+        t.Fatalf("CreateShipment: %v", err)
+    }
+    if created.ID == "" || created.CustomID == "" {
+        t.Fatalf("expected shipment IDs, got %+v", created)
+    }
+    if created.SourceID != "FL-1" || created.Origin.City != "Chicago" || created.Destination.State != "TX" {
+        t.Errorf("created shipment does not match request: %+v", created)
+    }
+    if created.Status.Code != models.StatusTendered {
+        t.Errorf("expected status %q back from Turvo, got %q", models.StatusTendered, created.Status.Code)
+    }
+    if stored, _ := fake.Shipment(created.ID); stored.Status.Code.Key != "2101" {
+        t.Errorf("expected Turvo status code 2101, got %q", stored.Status.Code.Key)
+    }
+
+    update := testShipment()
+    update.Destination.City, update.Destination.State = "Austin", "TX"
+    if _, err := service.UpdateShipment(ctx, created.ID, update); err != nil {
+        t.Fatalf("UpdateShipment: %v", err)
+    }
+
+    got, err := service.GetShipment(ctx, created.ID)
+    if err != nil {
+        t.Fatalf("GetShipment: %v", err)
+    }
+    if got.Destination.City != "Austin" {
+        t.Errorf("expected updated destination, got %q", got.Destination.City)
+    }
+
+    page, err := service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{})
+    if err != nil {
+        t.Fatalf("ListShipments: %v", err)
+    }
+    if len(page.Shipments) != 1 || page.MoreAvailable {
+        t.Errorf("expected one shipment on a single page, got %d (more=%v)", len(page.Shipments), page.MoreAvailable)
+    }
+
+    if err := service.DeleteShipment(ctx, created.ID); err != nil {
+        t.Fatalf("DeleteShipment: %v", err)
+    }
+    if _, ok := fake.Shipment(created.ID); ok {
+        t.Error("expected shipment to be deleted from the fake")
+    }
+}
+
+func TestTurvoServiceMapsMultiStopRoutes(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{}, 0)
+    ctx := context.Background()
+
+    shipment := testShipment()
+    shipment.Stops = []dto.ShipmentStop{
+        {Sequence: 1, Type: dto.ShipmentStopPickup, City: "Chicago", State: "IL", AppointmentStart: shipment.Origin.Date,
+            References: []dto.ShipmentReference{{Type: "po", Value: "PO-1"}, {Type: "bol", Value: "BOL-1"}}},
+        {Sequence: 2, Type: dto.ShipmentStopDrop, City: "Memphis", State: "TN", AppointmentStart: shipment.Origin.Date.Add(24 * time.Hour),
+            AppointmentEnd: shipment.Origin.Date.Add(26 * time.Hour),
+            Commodities:    []dto.ShipmentCommodity{{Description: "Widgets", Quantity: 10, PackageType: "pallet", Weight: 900, WeightUnit: "lb"}}},
+        {Sequence: 3, Type: dto.ShipmentStopDrop, City: "Dallas", State: "TX", AppointmentStart: shipment.Destination.Date},
+    }
+
+    created, err := service.CreateShipment(ctx, shipment)
+    if err != nil {
+        t.Fatalf("CreateShipment: %v", err)
+    }
+
+    stored, _ := fake.Shipment(created.ID)
+    if len(stored.GlobalRoute) != 3 {
+        t.Fatalf("expected 3 globalRoute stops in Turvo, got %d", len(stored.GlobalRoute))
+    }
+    if stored.GlobalRoute[0].StopType.Value != "Pickup" || stored.GlobalRoute[0].PONumbers[0] != "PO-1" {
+        t.Errorf("unexpected first Turvo stop: %+v", stored.GlobalRoute[0])
+    }
+
+    got, err := service.GetShipment(ctx, created.ID)
+    if err != nil {
+        t.Fatalf("GetShipment: %v", err)
+    }
+    if len(got.Stops) != 3 {
+        t.Fatalf("expected 3 stops back, got %d", len(got.Stops))
+    }
+    middle := got.Stops[1]
+    if middle.City != "Memphis" || middle.AppointmentEnd.IsZero() || len(middle.Commodities) != 1 || middle.Commodities[0].Quantity != 10 {
+        t.Errorf("unexpected middle stop: %+v", middle)
+    }
+    if refs := got.Stops[0].References; len(refs) != 2 || refs[1].Type != "bol" {
+        t.Errorf("expected PO and BOL references, got %+v", refs)
+    }
+}
+
+func TestTurvoServiceListShipmentsPaginatesAndFilters(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{}, 0)
+    ctx := context.Background()
+
+    for i := 0; i < 3; i++ {
+        fake.AddShipment(toTurvoShipmentRequest(testShipment()))
+    }
+    if err := service.Authenticate(ctx); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+
+    page, err := service.ListShipments(ctx, 0, 2, dto.ListShipmentsFilter{})
+    if err != nil {
+        t.Fatalf("ListShipments: %v", err)
+    }
+    if len(page.Shipments) != 2 || !page.MoreAvailable {
+        t.Fatalf("expected a full first page with more available, got %d (more=%v)", len(page.Shipments), page.MoreAvailable)
+    }
+
+    page, err = service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{UpdatedSince: time.Now().Add(time.Hour)})
+    if err != nil {
+        t.Fatalf("ListShipments: %v", err)
+    }
+    if len(page.Shipments) != 0 {
+        t.Errorf("expected no shipments updated in the future, got %d", len(page.Shipments))
+    }
+}
+
+func TestTurvoServiceAuthenticateRejectsBadCredentials(t *testing.T) {
+    _, service := newFakeTurvoService(t, faketurvo.Config{Password: "other"}, 0)
+
+    err := service.Authenticate(context.Background())
+    if err == nil || !strings.Contains(err.Error(), "401") {
+        t.Fatalf("expected a 401 authentication error, got %v", err)
+    }
+    if service.IsTokenValid() {
+        t.Error("expected no valid token after a failed authentication")
+    }
+}
+
+func TestTurvoServiceSurfacesInjectedFaults(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{}, 200*time.Millisecond)
+    ctx := context.Background()
+
+    if err := service.Authenticate(ctx); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+
+    fake.InjectFault(faketurvo.RouteCreate, faketurvo.Fault{Status: http.StatusInternalServerError, Times: 1})
+    if _, err := service.CreateShipment(ctx, testShipment()); err == nil || !strings.Contains(err.Error(), "500") {
+        t.Fatalf("expected a 500 error, got %v", err)
+    }
+    if _, err := service.CreateShipment(ctx, testShipment()); err != nil {
+        t.Fatalf("expected the fault to clear after one request, got %v", err)
+    }
+
+    fake.InjectFault(faketurvo.RouteList, faketurvo.Fault{Delay: time.Second})
+    if _, err := service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{}); err == nil {
+        t.Fatal("expected a timeout from a slow response")
+    }
+    if got := fake.Requests(faketurvo.RouteCreate); got != 2 {
+        t.Errorf("expected 2 create requests, got %d", got)
+    }
+}
+
+func TestTurvoServiceReturnsTypedErrors(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{}, 0)
+    ctx := context.Background()
+
+    if err := service.Authenticate(ctx); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+
+    if _, err := service.GetShipment(ctx, "does-not-exist"); !apperrors.Is(err, apperrors.CodeNotFound) {
+        t.Errorf("expected NOT_FOUND for an unknown shipment, got %v", err)
+    }
+
+    fake.InjectFault(faketurvo.RouteCreate, faketurvo.Fault{Status: http.StatusTooManyRequests, RetryAfter: "60", Times: 1})
+    _, err := service.CreateShipment(ctx, testShipment())
+    appErr, ok := apperrors.As(err)
+    if !ok || appErr.Code != apperrors.CodeRateLimited || appErr.RetryAfter != time.Minute {
+        t.Errorf("expected RATE_LIMITED with a one minute Retry-After, got %v", err)
+    }
+
+    // Turvo refusing our request is not the caller's error, and its body
+    // stays out of the error.
+    for _, status := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusConflict} {
+        fake.InjectFault(faketurvo.RouteUpdate, faketurvo.Fault{Status: status, Times: 1})
+        _, err = service.UpdateShipment(ctx, "1", testShipment())
+        if !apperrors.Is(err, apperrors.CodeUpstreamRejected) || strings.Contains(err.Error(), "injected fault") {
+            t.Errorf("expected UPSTREAM_REJECTED without the body for a %d, got %v", status, err)
+        }
+    }
+
+    fake.InjectFault(faketurvo.RouteList, faketurvo.Fault{Status: http.StatusBadGateway, Times: 1})
+    if _, err := service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{}); !apperrors.Is(err, apperrors.CodeUpstreamUnavailable) {
+        t.Errorf("expected UPSTREAM_UNAVAILABLE for a 502, got %v", err)
+    }
+}
+
+func TestTurvoServiceRetriesOnceAfterUnauthorized(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{}, 0)
+    ctx := context.Background()
+
+    if err := service.Authenticate(ctx); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+
+    fake.ExpireTokens()
+    if _, err := service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{}); err != nil {
+        t.Fatalf("expected the call to succeed after a token refresh, got %v", err)
+    }
+    if got := fake.Grants("refresh_token"); got != 1 {
+        t.Errorf("expected one refresh_token grant, got %d", got)
+    }
+    if got := fake.Requests(faketurvo.RouteList); got != 2 {
+        t.Errorf("expected the list request to be retried once, got %d requests", got)
+    }
+
+    fake.InjectFault(faketurvo.RouteGet, faketurvo.Fault{Status: http.StatusUnauthorized})
+    if _, err := service.GetShipment(ctx, "1"); err == nil || !strings.Contains(err.Error(), "401") {
+        t.Fatalf("expected a persistent 401 to be returned, got %v", err)
+    }
+    if got := fake.Requests(faketurvo.RouteGet); got != 2 {
+        t.Errorf("expected exactly one retry, got %d requests", got)
+    }
+}
+
+func TestTurvoServiceDeduplicatesConcurrentRefreshes(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{}, 0)
+    ctx := context.Background()
+
+    if err := service.Authenticate(ctx); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+    fake.ExpireTokens()
+    fake.InjectFault(faketurvo.RouteToken, faketurvo.Fault{Delay: 50 * time.Millisecond, Times: 1})
+
+    var wg sync.WaitGroup
+    errs := make(chan error, 20)
+    for i := 0; i < 20; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            _, err := service.ListShipments(ctx, 0, 10, dto.ListShipmentsFilter{})
+            errs <- err
+        }()
+    }
+    wg.Wait()
+    close(errs)
+
+    for err := range errs {
+        if err != nil {
+            t.Fatalf("ListShipments: %v", err)
+        }
+    }
+    if got := fake.Grants("refresh_token"); got != 1 {
+        t.Errorf("expected a single shared refresh, got %d", got)
+    }
+}
+
+func TestTurvoServiceFallsBackToPasswordGrant(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{}, 0)
+    ctx := context.Background()
+
+    if err := service.Authenticate(ctx); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+    fake.RevokeRefreshTokens()
+
+    if err := service.RefreshToken(ctx); err != nil {
+        t.Fatalf("RefreshToken: %v", err)
+    }
+    if got := fake.Grants("password"); got != 2 {
+        t.Errorf("expected a second password grant, got %d", got)
+    }
+}
+
+func TestTurvoServiceRefreshesProactively(t *testing.T) {
+    fake, service := newFakeTurvoService(t, faketurvo.Config{TokenTTL: 2 * time.Second}, 0)
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    if err := service.Authenticate(ctx); err != nil {
+        t.Fatalf("Authenticate: %v", err)
+    }
+    go service.RunTokenRefresh(ctx)
+
+    deadline := time.Now().Add(3 * time.Second)
+    for fake.Grants("refresh_token") == 0 {
+        if time.Now().After(deadline) {
+            t.Fatal("expected a background refresh before the token expired")
+        }
+        time.Sleep(20 * time.Millisecond)
+    }
+    if !service.IsTokenValid() {
+        t.Error("expected a valid token after the background refresh")
+    }
+}
+
+func TestDecodeShipmentResponseHidesEnvelopedErrors(t *testing.T) {
+    _, err := decodeShipmentResponse([]byte(`{"Status": "ERROR", "details": {"errorMessage": "customer 42 is on credit hold"}}`))
+    if !apperrors.Is(err, apperrors.CodeUpstreamRejected) || strings.Contains(err.Error(), "credit hold") {
+        t.Errorf("expected UPSTREAM_REJECTED without the body, got %v", err)
+    }
+
+    resp, err := decodeShipmentResponse([]byte(`{"Status": "SUCCESS", "details": {"id": 7}}`))
+    if err != nil || resp == nil {
+        t.Errorf("expected the enveloped shipment, got %+v (%v)", resp, err)
+    }
+}
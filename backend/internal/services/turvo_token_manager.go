@@ -0,0 +1,214 @@
+package services
+
+import (
+    "context"
+    "log"
+    "sync"
+    "time"
+
+    "freight-broker/backend/internal/dto/tms"
+)
+
+const (
+    // tokenExpirySkew treats a token as expired slightly early so it is not
+    // sent just as Turvo stops accepting it. Like tokenRefreshAhead it is
+    // capped relative to the token lifetime.
+    tokenExpirySkew = 30 * time.Second
+    // tokenRefreshAhead is how long before expiry the background loop
+    // refreshes, capped at half the token lifetime for short-lived tokens.
+    tokenRefreshAhead = 5 * time.Minute
+    tokenRetryDelay   = 30 * time.Second
+)
+
+// tokenGrant performs one OAuth token request.
+type tokenGrant func(ctx context.Context, req dto.TurvoAuthRequest) (*dto.TurvoAuthResponse, error)
+
+// tokenRefresh is an in-flight refresh that concurrent callers wait on.
+type tokenRefresh struct {
+    done chan struct{}
+    err  error
+}
+
+// turvoTokenManager owns the Turvo access and refresh tokens. Concurrent
+// callers needing a new token share a single token request.
+type turvoTokenManager struct {
+    grant        tokenGrant
+    passwordAuth dto.TurvoAuthRequest
+
+    mu           sync.Mutex
+    accessToken  string
+    refreshToken string
+    issuedAt     time.Time
+    expiry       time.Time
+    inflight     *tokenRefresh
+}
+
+func newTurvoTokenManager(grant tokenGrant, passwordAuth dto.TurvoAuthRequest) *turvoTokenManager {
+    return &turvoTokenManager{
+        grant:        grant,
+        passwordAuth: passwordAuth,
+    }
+}
+
+// Token returns a usable access token, refreshing it first if it has expired.
+func (m *turvoTokenManager) Token(ctx context.Context) (string, error) {
+    m.mu.Lock()
+    if m.validLocked() {
+        token := m.accessToken
+        m.mu.Unlock()
+        return token, nil
+    }
+    m.mu.Unlock()
+
+    if err := m.refresh(ctx, "", false); err != nil {
+        return "", err
+    }
+    return m.current(), nil
+}
+
+// Invalidate is called after Turvo rejected staleToken. It refreshes unless
+// another caller already replaced that token.
+func (m *turvoTokenManager) Invalidate(ctx context.Context, staleToken string) (string, error) {
+    if err := m.refresh(ctx, staleToken, false); err != nil {
+        return "", err
+    }
+    return m.current(), nil
+}
+
+// Login discards any stored tokens and runs the password grant.
+func (m *turvoTokenManager) Login(ctx context.Context) error {
+    return m.refresh(ctx, "", true)
+}
+
+// Refresh renews the access token, preferring the refresh_token grant.
+func (m *turvoTokenManager) Refresh(ctx context.Context) error {
+    return m.refresh(ctx, m.current(), false)
+}
+
+func (m *turvoTokenManager) Valid() bool {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.validLocked()
+}
+
+func (m *turvoTokenManager) current() string {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.accessToken
+}
+
+func (m *turvoTokenManager) validLocked() bool {
+    skew := tokenExpirySkew
+    if lifetime := m.expiry.Sub(m.issuedAt); skew > lifetime/4 {
+        skew = lifetime / 4
+    }
+    return m.accessToken != "" && time.Now().Add(skew).Before(m.expiry)
+}
+
+// refresh joins an in-flight refresh or starts one. When staleToken is set
+// and the stored token has already moved on, nothing is requested.
+func (m *turvoTokenManager) refresh(ctx context.Context, staleToken string, forceLogin bool) error {
+    m.mu.Lock()
+    if staleToken != "" && m.accessToken != staleToken && m.validLocked() {
+        m.mu.Unlock()
+        return nil
+    }
+
+    inflight := m.inflight
+    if inflight == nil {
+        inflight = &tokenRefresh{done: make(chan struct{})}
+        m.inflight = inflight
+        refreshToken := m.refreshToken
+        if forceLogin {
+            refreshToken = ""
+        }
+
+        // The request outlives any single caller's context so one cancelled
+        // caller does not fail everyone waiting on the same refresh.
+        go m.runRefresh(context.WithoutCancel(ctx), inflight, refreshToken)
+    }
+    m.mu.Unlock()
+
+    select {
+    case <-inflight.done:
+        return inflight.err
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+func (m *turvoTokenManager) runRefresh(ctx context.Context, inflight *tokenRefresh, refreshToken string) {
+    var resp *dto.TurvoAuthResponse
+    var err error
+
+    if refreshToken != "" {
+        resp, err = m.grant(ctx, dto.TurvoAuthRequest{
+            GrantType:    "refresh_token",
+            ClientID:     m.passwordAuth.ClientID,
+            ClientSecret: m.passwordAuth.ClientSecret,
+            RefreshToken: refreshToken,
+        })
+        if err != nil {
+            log.Printf("Turvo refresh_token grant failed, falling back to password grant: %v", err)
+        }
+    }
+    if resp == nil {
+        resp, err = m.grant(ctx, m.passwordAuth)
+    }
+
+    m.mu.Lock()
+    if err == nil {
+        now := time.Now()
+        m.accessToken = resp.AccessToken
+        if resp.RefreshToken != "" {
+            m.refreshToken = resp.RefreshToken
+        }
+        m.issuedAt = now
+        m.expiry = now.Add(time.Second * time.Duration(resp.ExpiresIn))
+    }
+    m.inflight = nil
+    m.mu.Unlock()
+
+    inflight.err = err
+    close(inflight.done)
+}
+
+// Run refreshes the token ahead of expiry until ctx is cancelled.
+func (m *turvoTokenManager) Run(ctx context.Context) {
+    for {
+        timer := time.NewTimer(m.nextRefreshIn())
+        select {
+        case <-ctx.Done():
+            timer.Stop()
+            return
+        case <-timer.C:
+        }
+
+        if err := m.Refresh(ctx); err != nil && ctx.Err() == nil {
+            log.Printf("Turvo background token refresh failed: %v", err)
+            select {
+            case <-ctx.Done():
+                return
+            case <-time.After(tokenRetryDelay):
+            }
+        }
+    }
+}
+
+func (m *turvoTokenManager) nextRefreshIn() time.Duration {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+
+    if m.accessToken == "" {
+        return tokenRetryDelay
+    }
+
+    ahead := tokenRefreshAhead
+    if lifetime := m.expiry.Sub(m.issuedAt); ahead > lifetime/2 {
+        ahead = lifetime / 2
+    }
+    if wait := time.Until(m.expiry.Add(-ahead)); wait > 0 {
+        return wait
+    }
+    return 0
+}
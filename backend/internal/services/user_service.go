@@ -0,0 +1,546 @@
+package services
+
+import (
+    "context"
+    "crypto/rand"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/hex"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+    "freight-broker/backend/internal/apperrors"
+    "freight-broker/backend/internal/dto"
+    "freight-broker/backend/internal/models"
+
+    "github.com/google/uuid"
+    "github.com/jinzhu/gorm"
+    "golang.org/x/crypto/bcrypt"
+)
+
+type UserServiceConfig struct {
+    // MaxFailedLogins consecutive failed sign-ins lock an account for
+    // LockoutDuration.
+    MaxFailedLogins int
+    LockoutDuration time.Duration
+    ResetTokenTTL   time.Duration
+    BcryptCost      int
+}
+
+type UserService struct {
+    db     *gorm.DB
+    config UserServiceConfig
+    // dummyHash is compared against when a username is unknown, so failed
+    // sign-ins take as long whether or not the account exists.
+    dummyHash []byte
+}
+
+func NewUserService(db *gorm.DB, config UserServiceConfig) *UserService {
+    if config.MaxFailedLogins <= 0 {
+        config.MaxFailedLogins = 5
+    }
+    if config.LockoutDuration <= 0 {
+        config.LockoutDuration = 15 * time.Minute
+    }
+    if config.ResetTokenTTL <= 0 {
+        config.ResetTokenTTL = time.Hour
+    }
+    if config.BcryptCost == 0 {
+        config.BcryptCost = bcrypt.DefaultCost
+    }
+
+    dummyHash, _ := bcrypt.GenerateFromPassword([]byte("not-a-real-password"), config.BcryptCost)
+    return &UserService{
+        db:        db,
+        config:    config,
+        dummyHash: dummyHash,
+    }
+}
+
+var errInvalidCredentials = apperrors.Unauthorized("invalid credentials")
+
+// Authenticate checks a username and password. Repeated failures lock the
+// account; a locked or deactivated account, or one whose tenant is
+// deactivated, cannot sign in even with the right password. All of them are
+// refused as invalid credentials, like unknown usernames, so sign-in does
+// not reveal which accounts exist; lockouts are only logged.
+func (s *UserService) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+    var user models.User
+    err := s.db.Where("username = ?", normalizeUsername(username)).First(&user).Error
+    if err == gorm.ErrRecordNotFound {
+        bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+        return nil, errInvalidCredentials
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+
+    now := time.Now()
+    if user.IsLocked(now) {
+        // The password is not checked while locked, but hashed all the same
+        // so the refusal takes as long as any other.
+        bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+        log.Printf("Refused sign-in for locked user %s until %s", user.ID, user.LockedUntil.Format(time.RFC3339))
+        return nil, errInvalidCredentials
+    }
+
+    if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+        if err := s.recordFailedLogin(&user, now); err != nil {
+            return nil, err
+        }
+        return nil, errInvalidCredentials
+    }
+    if !user.Active {
+        return nil, errInvalidCredentials
+    }
+    var tenant models.Tenant
+    err = s.db.Where("id = ?", user.TenantID).First(&tenant).Error
+    if err != nil && err != gorm.ErrRecordNotFound {
+        return nil, fmt.Errorf("failed to get tenant: %w", err)
+    }
+    if err != nil || !tenant.Active {
+        return nil, errInvalidCredentials
+    }
+
+    err = s.db.Model(&user).Updates(map[string]interface{}{
+        "failed_logins": 0,
+        "locked_until":  nil,
+        "last_login_at": now,
+    }).Error
+    if err != nil {
+        return nil, fmt.Errorf("failed to record sign-in: %w", err)
+    }
+    return &user, nil
+}
+
+// recordFailedLogin counts a failed sign-in, locking the account when it
+// reaches the limit. The count is incremented in the database so concurrent
+// attempts are all counted.
+func (s *UserService) recordFailedLogin(user *models.User, now time.Time) error {
+    err := s.db.Model(user).UpdateColumn("failed_logins", gorm.Expr("failed_logins + 1")).Error
+    if err != nil {
+        return fmt.Errorf("failed to record failed sign-in: %w", err)
+    }
+    lockedUntil := now.Add(s.config.LockoutDuration)
+    err = s.db.Model(&models.User{}).
+        Where("id = ? AND failed_logins >= ?", user.ID, s.config.MaxFailedLogins).
+        UpdateColumns(map[string]interface{}{"failed_logins": 0, "locked_until": lockedUntil}).Error
+    if err != nil {
+        return fmt.Errorf("failed to lock user: %w", err)
+    }
+    return nil
+}
+
+// CreateUser adds a user to the signed-in admin's tenant.
+func (s *UserService) CreateUser(ctx context.Context, req *dto.CreateUserRequest) (*dto.UserResponse, error) {
+    tenantID, scoped, err := actorTenant(ctx)
+    if err != nil {
+        return nil, err
+    }
+    if !scoped || tenantID == uuid.Nil {
+        return nil, apperrors.Forbidden("a tenant is required to create users")
+    }
+    user, err := s.createUser(s.db, tenantID, req)
+    if err != nil {
+        return nil, err
+    }
+    return convertToUserResponse(user), nil
+}
+
+func (s *UserService) createUser(db *gorm.DB, tenantID uuid.UUID, req *dto.CreateUserRequest) (*models.User, error) {
+    if err := checkRole(req.Role); err != nil {
+        return nil, err
+    }
+    if err := checkParty(req.Role, req.PartyID); err != nil {
+        return nil, err
+    }
+    hash, err := s.hashPassword(req.Password)
+    if err != nil {
+        return nil, err
+    }
+
+    now := time.Now()
+    user := &models.User{
+        ID:                uuid.New(),
+        TenantID:          tenantID,
+        Username:          normalizeUsername(req.Username),
+        Email:             req.Email,
+        PasswordHash:      hash,
+        Role:              req.Role,
+        PartyID:           req.PartyID,
+        Active:            true,
+        PasswordChangedAt: &now,
+    }
+
+    var existing int
+    if err := db.Model(&models.User{}).Where("username = ?", user.Username).Count(&existing).Error; err != nil {
+        return nil, fmt.Errorf("failed to check username: %w", err)
+    }
+    if existing > 0 {
+        return nil, apperrors.Conflict("username is already taken")
+    }
+
+    if err := db.Create(user).Error; err != nil {
+        return nil, fmt.Errorf("failed to create user: %w", err)
+    }
+    return user, nil
+}
+
+// EnsureAdmin creates an admin account in a tenant that has no users yet,
+// so a fresh install can be signed in to.
+func (s *UserService) EnsureAdmin(ctx context.Context, tenantID uuid.UUID, username, password string) (bool, error) {
+    var count int
+    if err := s.db.Model(&models.User{}).Where("tenant_id = ?", tenantID).Count(&count).Error; err != nil {
+        return false, fmt.Errorf("failed to count users: %w", err)
+    }
+    if count > 0 {
+        return false, nil
+    }
+    _, err := s.createUser(s.db, tenantID, &dto.CreateUserRequest{Username: username, Password: password, Role: models.RoleAdmin})
+    return err == nil, err
+}
+
+func (s *UserService) GetUser(ctx context.Context, id string) (*dto.UserResponse, error) {
+    db, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    user, err := s.findUser(db, id)
+    if err != nil {
+        return nil, err
+    }
+    return convertToUserResponse(user), nil
+}
+
+func (s *UserService) ListUsers(ctx context.Context, page, pageSize int) (*dto.ListUsersResponse, error) {
+    var users []models.User
+    var total int64
+
+    query, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    if err := query.Model(&models.User{}).Count(&total).Error; err != nil {
+        return nil, fmt.Errorf("failed to count users: %w", err)
+    }
+    offset := (page - 1) * pageSize
+    if err := query.Order("username").Offset(offset).Limit(pageSize).Find(&users).Error; err != nil {
+        return nil, fmt.Errorf("failed to list users: %w", err)
+    }
+
+    responses := make([]dto.UserResponse, len(users))
+    for i := range users {
+        responses[i] = *convertToUserResponse(&users[i])
+    }
+    return &dto.ListUsersResponse{
+        Users: responses,
+        Total: total,
+    }, nil
+}
+
+func (s *UserService) UpdateUser(ctx context.Context, id string, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
+    if req.Role != nil {
+        if err := checkRole(*req.Role); err != nil {
+            return nil, err
+        }
+    }
+
+    tx := s.db.Begin()
+    scoped, err := scopeToTenant(ctx, tx)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    user, err := s.findUser(scoped.Set("gorm:query_option", "FOR UPDATE"), id)
+    if err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    if req.Email != nil {
+        user.Email = *req.Email
+    }
+    if req.Role != nil {
+        user.Role = *req.Role
+    }
+    if req.PartyID != nil {
+        user.PartyID = *req.PartyID
+    }
+    if err := checkParty(user.Role, user.PartyID); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+    if req.Active != nil {
+        user.Active = *req.Active
+    }
+    if req.Unlock {
+        user.FailedLogins = 0
+        user.LockedUntil = nil
+    }
+    if err := s.checkAdminRemains(tx, user); err != nil {
+        tx.Rollback()
+        return nil, err
+    }
+
+    if err := tx.Save(user).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to update user: %w", err)
+    }
+    if !user.Active {
+        if err := revokeRefreshTokens(tx, "user_id = ?", user.ID, time.Now()); err != nil {
+            tx.Rollback()
+            return nil, err
+        }
+    }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit user update: %w", err)
+    }
+    return convertToUserResponse(user), nil
+}
+
+func (s *UserService) DeleteUser(ctx context.Context, id string) error {
+    tx := s.db.Begin()
+    scoped, err := scopeToTenant(ctx, tx)
+    if err != nil {
+        tx.Rollback()
+        return err
+    }
+    user, err := s.findUser(scoped.Set("gorm:query_option", "FOR UPDATE"), id)
+    if err != nil {
+        tx.Rollback()
+        return err
+    }
+
+    user.Active = false
+    if err := s.checkAdminRemains(tx, user); err != nil {
+        tx.Rollback()
+        return err
+    }
+
+    if err := tx.Where("user_id = ?", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to delete reset tokens: %w", err)
+    }
+    if err := tx.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{}).Error; err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to delete refresh tokens: %w", err)
+    }
+    if err := tx.Delete(user).Error; err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to delete user: %w", err)
+    }
+    return tx.Commit().Error
+}
+
+// checkAdminRemains refuses a change that would leave a tenant without an
+// active admin.
+func (s *UserService) checkAdminRemains(tx *gorm.DB, changed *models.User) error {
+    if changed.Role == models.RoleAdmin && changed.Active {
+        return nil
+    }
+    var admins int
+    err := tx.Model(&models.User{}).
+        Where("tenant_id = ? AND role = ? AND active AND id <> ?", changed.TenantID, models.RoleAdmin, changed.ID).
+        Count(&admins).Error
+    if err != nil {
+        return fmt.Errorf("failed to count admins: %w", err)
+    }
+    if admins == 0 {
+        return apperrors.Conflict("at least one active admin must remain")
+    }
+    return nil
+}
+
+// ChangePassword sets a new password for a signed-in user who knows the
+// current one.
+func (s *UserService) ChangePassword(ctx context.Context, userID string, req *dto.ChangePasswordRequest) error {
+    db, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return err
+    }
+    user, err := s.findUser(db, userID)
+    if err != nil {
+        return err
+    }
+    if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)) != nil {
+        return apperrors.Validation("Invalid password",
+            apperrors.FieldError{Field: "currentPassword", Message: "is incorrect"})
+    }
+    return s.setPassword(s.db, user, req.NewPassword)
+}
+
+// CreateResetToken issues a one-time password reset token for a user,
+// replacing any earlier unused one.
+func (s *UserService) CreateResetToken(ctx context.Context, userID string) (*dto.PasswordResetTokenResponse, error) {
+    db, err := scopeToTenant(ctx, s.db)
+    if err != nil {
+        return nil, err
+    }
+    user, err := s.findUser(db, userID)
+    if err != nil {
+        return nil, err
+    }
+
+    raw := make([]byte, 32)
+    if _, err := rand.Read(raw); err != nil {
+        return nil, fmt.Errorf("failed to generate reset token: %w", err)
+    }
+    token := base64.RawURLEncoding.EncodeToString(raw)
+    resetToken := models.PasswordResetToken{
+        ID:        uuid.New(),
+        UserID:    user.ID,
+        TokenHash: hashToken(token),
+        ExpiresAt: time.Now().Add(s.config.ResetTokenTTL),
+    }
+
+    tx := s.db.Begin()
+    if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to delete reset tokens: %w", err)
+    }
+    if err := tx.Create(&resetToken).Error; err != nil {
+        tx.Rollback()
+        return nil, fmt.Errorf("failed to create reset token: %w", err)
+    }
+    if err := tx.Commit().Error; err != nil {
+        return nil, fmt.Errorf("failed to commit reset token: %w", err)
+    }
+
+    return &dto.PasswordResetTokenResponse{
+        Token:     token,
+        ExpiresAt: resetToken.ExpiresAt.Format(time.RFC3339),
+    }, nil
+}
+
+// ResetPassword sets a new password with a reset token, which is used up.
+// It also lifts any lockout.
+func (s *UserService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+    invalid := apperrors.Validation("Invalid reset token",
+        apperrors.FieldError{Field: "token", Message: "is invalid or has expired"})
+
+    var resetToken models.PasswordResetToken
+    tx := s.db.Begin()
+    err := tx.Set("gorm:query_option", "FOR UPDATE").
+        Where("token_hash = ?", hashToken(req.Token)).First(&resetToken).Error
+    if err == gorm.ErrRecordNotFound {
+        tx.Rollback()
+        return invalid
+    }
+    if err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to get reset token: %w", err)
+    }
+
+    now := time.Now()
+    if resetToken.UsedAt != nil || now.After(resetToken.ExpiresAt) {
+        tx.Rollback()
+        return invalid
+    }
+
+    user, err := s.findUser(tx, resetToken.UserID.String())
+    if err != nil {
+        tx.Rollback()
+        return err
+    }
+    user.FailedLogins = 0
+    user.LockedUntil = nil
+    if err := s.setPassword(tx, user, req.NewPassword); err != nil {
+        tx.Rollback()
+        return err
+    }
+
+    if err := tx.Model(&resetToken).Update("used_at", now).Error; err != nil {
+        tx.Rollback()
+        return fmt.Errorf("failed to use reset token: %w", err)
+    }
+    return tx.Commit().Error
+}
+
+func (s *UserService) setPassword(db *gorm.DB, user *models.User, password string) error {
+    hash, err := s.hashPassword(password)
+    if err != nil {
+        return err
+    }
+    now := time.Now()
+    user.PasswordHash = hash
+    user.PasswordChangedAt = &now
+    if err := db.Save(user).Error; err != nil {
+        return fmt.Errorf("failed to update password: %w", err)
+    }
+    // Sessions started with the old password end when their access
+    // tokens expire.
+    return revokeRefreshTokens(db, "user_id = ?", user.ID, now)
+}
+
+func (s *UserService) hashPassword(password string) (string, error) {
+    hash, err := bcrypt.GenerateFromPassword([]byte(password), s.config.BcryptCost)
+    if err != nil {
+        return "", fmt.Errorf("failed to hash password: %w", err)
+    }
+    return string(hash), nil
+}
+
+func (s *UserService) findUser(db *gorm.DB, id string) (*models.User, error) {
+    var user models.User
+    if err := db.Where("id = ?", id).First(&user).Error; err != nil {
+        if err == gorm.ErrRecordNotFound {
+            return nil, apperrors.NotFound("user not found")
+        }
+        return nil, fmt.Errorf("failed to get user: %w", err)
+    }
+    return &user, nil
+}
+
+func checkRole(role string) error {
+    if models.IsRole(role) {
+        return nil
+    }
+    return apperrors.Validation("Invalid role",
+        apperrors.FieldError{Field: "role", Message: "must be one of " + strings.Join(models.Roles(), ", ")})
+}
+
+func normalizeUsername(username string) string {
+    return strings.ToLower(strings.TrimSpace(username))
+}
+
+// hashToken returns the hex SHA-256 of a token. Tokens are random, so a
+// fast hash is enough to keep them out of the database.
+func hashToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
+
+// checkParty requires a party for the roles limited to one, since without
+// it they would see no loads.
+func checkParty(role, partyID string) error {
+    if partyID == "" && models.IsPartyRole(role) {
+        return apperrors.Validation("Invalid party",
+            apperrors.FieldError{Field: "partyId", Message: "is required for the " + role + " role"})
+    }
+    return nil
+}
+
+func convertToUserResponse(user *models.User) *dto.UserResponse {
+    resp := &dto.UserResponse{
+        ID:        user.ID.String(),
+        Username:  user.Username,
+        Email:     user.Email,
+        Role:      user.Role,
+        PartyID:   user.PartyID,
+        Active:    user.Active,
+        CreatedAt: user.CreatedAt.Format(time.RFC3339),
+        UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
+    }
+    if user.IsLocked(time.Now()) {
+        resp.LockedUntil = user.LockedUntil.Format(time.RFC3339)
+    }
+    if user.LastLoginAt != nil {
+        resp.LastLoginAt = user.LastLoginAt.Format(time.RFC3339)
+    }
+    if user.PasswordChangedAt != nil {
+        resp.PasswordChangedAt = user.PasswordChangedAt.Format(time.RFC3339)
+    }
+    return resp
+}
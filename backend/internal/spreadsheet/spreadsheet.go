@@ -0,0 +1,118 @@
+// Package spreadsheet reads and writes the tables loads are imported from
+// and exported to: CSV and single-sheet XLSX workbooks. Only what those
+// need is supported; cells are read as text, and written as text or numbers.
+package spreadsheet
+
+import (
+    "bytes"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+)
+
+// Format is a file format a table can be read from or written as.
+type Format string
+
+const (
+    FormatCSV  Format = "csv"
+    FormatXLSX Format = "xlsx"
+)
+
+// ParseFormat accepts a format name or a file name ending in one.
+func ParseFormat(name string) (Format, error) {
+    name = strings.ToLower(strings.TrimSpace(name))
+    if i := strings.LastIndex(name, "."); i >= 0 {
+        name = name[i+1:]
+    }
+    switch Format(name) {
+    case FormatCSV, FormatXLSX:
+        return Format(name), nil
+    }
+    return "", fmt.Errorf("unsupported format %q; use csv or xlsx", name)
+}
+
+// ContentType is the MIME type of a file in the format.
+func (f Format) ContentType() string {
+    if f == FormatXLSX {
+        return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+    }
+    return "text/csv; charset=utf-8"
+}
+
+// Read returns the rows of a CSV file or of the first sheet of a workbook.
+// Rows may have different lengths.
+func Read(r io.Reader, format Format) ([][]string, error) {
+    data, err := io.ReadAll(r)
+    if err != nil {
+        return nil, err
+    }
+
+    if format == FormatXLSX {
+        return readXLSX(data)
+    }
+
+    // Excel saves CSV files as UTF-8 with a byte order mark.
+    reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+    reader.FieldsPerRecord = -1
+    rows, err := reader.ReadAll()
+    if err != nil {
+        return nil, fmt.Errorf("invalid CSV: %w", err)
+    }
+    return rows, nil
+}
+
+// Writer writes a table row by row, so large tables are never held in
+// memory. Close must be called to complete the file.
+type Writer interface {
+    WriteRow(row []string) error
+    // WriteValues writes a row of strings, numbers (float64 or int) and
+    // nils, which leave their cell empty. XLSX stores numbers as numbers.
+    WriteValues(values []interface{}) error
+    Close() error
+}
+
+// NewWriter returns a writer of the format to w.
+func NewWriter(w io.Writer, format Format) Writer {
+    if format == FormatXLSX {
+        return newXLSXWriter(w)
+    }
+    return &csvWriter{w: csv.NewWriter(w)}
+}
+
+type csvWriter struct {
+    w *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(row []string) error {
+    return c.w.Write(row)
+}
+
+func (c *csvWriter) WriteValues(values []interface{}) error {
+    row := make([]string, len(values))
+    for i, value := range values {
+        row[i] = formatValue(value)
+    }
+    return c.w.Write(row)
+}
+
+func (c *csvWriter) Close() error {
+    c.w.Flush()
+    return c.w.Error()
+}
+
+// formatValue renders a WriteValues value as text.
+func formatValue(value interface{}) string {
+    switch v := value.(type) {
+    case nil:
+        return ""
+    case string:
+        return v
+    case float64:
+        return strconv.FormatFloat(v, 'f', -1, 64)
+    case int:
+        return strconv.Itoa(v)
+    }
+    return fmt.Sprint(value)
+}
@@ -0,0 +1,155 @@
+package spreadsheet
+
+import (
+    "archive/zip"
+    "bytes"
+    "io"
+    "reflect"
+    "strings"
+    "testing"
+)
+
+func TestWriteAndReadBack(t *testing.T) {
+    rows := [][]string{
+        {"freightLoadID", "customer.name", "notes"},
+        {"FL-1", "Acme & Sons", "line one\nline two"},
+        {"FL-2", "", " padded "},
+    }
+
+    for _, format := range []Format{FormatCSV, FormatXLSX} {
+        var buf bytes.Buffer
+        w := NewWriter(&buf, format)
+        for _, row := range rows {
+            if err := w.WriteRow(row); err != nil {
+                t.Fatalf("%s: failed to write row: %v", format, err)
+            }
+        }
+        if err := w.Close(); err != nil {
+            t.Fatalf("%s: failed to close: %v", format, err)
+        }
+
+        got, err := Read(&buf, format)
+        if err != nil {
+            t.Fatalf("%s: failed to read: %v", format, err)
+        }
+        if !reflect.DeepEqual(got, rows) {
+            t.Errorf("%s: expected %q, got %q", format, rows, got)
+        }
+    }
+}
+
+func TestWriteValues(t *testing.T) {
+    values := []interface{}{"FL-1", 1250.5, nil, 3, ""}
+    want := []string{"FL-1", "1250.5", "", "3"}
+
+    for _, format := range []Format{FormatCSV, FormatXLSX} {
+        var buf bytes.Buffer
+        w := NewWriter(&buf, format)
+        if err := w.WriteValues(values); err != nil {
+            t.Fatalf("%s: failed to write values: %v", format, err)
+        }
+        if err := w.Close(); err != nil {
+            t.Fatalf("%s: failed to close: %v", format, err)
+        }
+        if format == FormatXLSX && !strings.Contains(sheetXML(t, buf.Bytes()), `<c r="B1"><v>1250.5</v></c>`) {
+            t.Errorf("expected the rate to be a numeric cell")
+        }
+
+        got, err := Read(&buf, format)
+        if err != nil {
+            t.Fatalf("%s: failed to read: %v", format, err)
+        }
+        // A CSV row keeps its trailing empty cells; a sheet row does not.
+        if len(got) != 1 || !reflect.DeepEqual(got[0][:len(want)], want) {
+            t.Errorf("%s: expected %q, got %q", format, want, got)
+        }
+    }
+}
+
+// sheetXML returns the worksheet of a workbook written by NewWriter.
+func sheetXML(t *testing.T, data []byte) string {
+    archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+    if err != nil {
+        t.Fatalf("invalid workbook: %v", err)
+    }
+    for _, file := range archive.File {
+        if file.Name != "xl/worksheets/sheet1.xml" {
+            continue
+        }
+        r, err := file.Open()
+        if err != nil {
+            t.Fatalf("failed to open sheet: %v", err)
+        }
+        defer r.Close()
+        var b strings.Builder
+        if _, err := io.Copy(&b, r); err != nil {
+            t.Fatalf("failed to read sheet: %v", err)
+        }
+        return b.String()
+    }
+    t.Fatalf("workbook has no sheet")
+    return ""
+}
+
+func TestReadCSVWithByteOrderMark(t *testing.T) {
+    rows, err := Read(strings.NewReader("\ufefffreightLoadID,poNums\nFL-1,PO-1\n"), FormatCSV)
+    if err != nil || rows[0][0] != "freightLoadID" || rows[1][1] != "PO-1" {
+        t.Errorf("expected the byte order mark to be dropped, got %q (%v)", rows, err)
+    }
+}
+
+// TestReadXLSXAsSavedByExcel reads a workbook laid out as Excel writes one:
+// shared strings, rich text, numbers, and rows and cells left out.
+func TestReadXLSXAsSavedByExcel(t *testing.T) {
+    parts := map[string]string{
+        "xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+            <sheets><sheet name="Loads" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+        "xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+            <Relationship Id="rId3" Target="/xl/worksheets/loads.xml"/></Relationships>`,
+        "xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+            <si><t>freightLoadID</t></si><si><t>totalRate</t></si><si><r><t>FL-</t></r><r><t>7</t></r></si></sst>`,
+        "xl/worksheets/loads.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+            <row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>
+            <row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3" t="b"><v>1</v></c><c r="C3"><v>1250.5</v></c></row>
+            </sheetData></worksheet>`,
+    }
+
+    var buf bytes.Buffer
+    archive := zip.NewWriter(&buf)
+    for name, body := range parts {
+        w, _ := archive.Create(name)
+        w.Write([]byte(body))
+    }
+    archive.Close()
+
+    rows, err := Read(&buf, FormatXLSX)
+    if err != nil {
+        t.Fatalf("failed to read: %v", err)
+    }
+    want := [][]string{{"freightLoadID", "", "totalRate"}, nil, {"FL-7", "TRUE", "1250.5"}}
+    if !reflect.DeepEqual(rows, want) {
+        t.Errorf("expected %q, got %q", want, rows)
+    }
+}
+
+func TestColumnNames(t *testing.T) {
+    for column, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+        if got := columnName(column); got != name {
+            t.Errorf("expected column %d to be %s, got %s", column, name, got)
+        }
+        if got, err := columnIndex(name + "12"); err != nil || got != column {
+            t.Errorf("expected %s12 to be column %d, got %d (%v)", name, column, got, err)
+        }
+    }
+}
+
+func TestParseFormat(t *testing.T) {
+    for name, want := range map[string]Format{"csv": FormatCSV, "XLSX": FormatXLSX, "loads.week12.xlsx": FormatXLSX} {
+        if got, err := ParseFormat(name); err != nil || got != want {
+            t.Errorf("expected %q to be %s, got %s (%v)", name, want, got, err)
+        }
+    }
+    if _, err := ParseFormat("loads.xls"); err == nil {
+        t.Error("expected xls to be refused")
+    }
+}
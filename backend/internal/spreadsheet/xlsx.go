@@ -0,0 +1,333 @@
+package spreadsheet
+
+import (
+    "archive/zip"
+    "bytes"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "path"
+    "strconv"
+    "strings"
+)
+
+// maxXLSXPartSize caps how much of a compressed workbook part is read, so
+// a small upload cannot expand without bound.
+const maxXLSXPartSize = 64 << 20
+
+// maxXLSXRows is the most rows a sheet can have in Excel.
+const maxXLSXRows = 1 << 20
+
+type xlsxRelationships struct {
+    Relationships []struct {
+        ID     string `xml:"Id,attr"`
+        Target string `xml:"Target,attr"`
+    } `xml:"Relationship"`
+}
+
+type xlsxWorkbook struct {
+    Sheets []struct {
+        // The relationship ID is the sheet's r:id attribute, whose
+        // namespace differs between transitional and strict workbooks.
+        Attrs []xml.Attr `xml:",any,attr"`
+    } `xml:"sheets>sheet"`
+}
+
+// xlsxText is a shared or inline string: plain text or rich text runs.
+type xlsxText struct {
+    T    string `xml:"t"`
+    Runs []struct {
+        T string `xml:"t"`
+    } `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+    if len(t.Runs) == 0 {
+        return t.T
+    }
+    var b strings.Builder
+    for _, run := range t.Runs {
+        b.WriteString(run.T)
+    }
+    return b.String()
+}
+
+type xlsxSharedStrings struct {
+    Items []xlsxText `xml:"si"`
+}
+
+type xlsxSheet struct {
+    Rows []struct {
+        R     int `xml:"r,attr"`
+        Cells []struct {
+            Ref    string   `xml:"r,attr"`
+            Type   string   `xml:"t,attr"`
+            Value  string   `xml:"v"`
+            Inline xlsxText `xml:"is"`
+        } `xml:"c"`
+    } `xml:"sheetData>row"`
+}
+
+// readXLSX returns the rows of the first sheet of a workbook.
+func readXLSX(data []byte) ([][]string, error) {
+    archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+    if err != nil {
+        return nil, fmt.Errorf("invalid XLSX: %w", err)
+    }
+    parts := make(map[string]*zip.File, len(archive.File))
+    for _, file := range archive.File {
+        parts[file.Name] = file
+    }
+
+    sheetPath, err := firstSheetPath(parts)
+    if err != nil {
+        return nil, err
+    }
+
+    var shared xlsxSharedStrings
+    if _, ok := parts["xl/sharedStrings.xml"]; ok {
+        if err := decodeXLSXPart(parts, "xl/sharedStrings.xml", &shared); err != nil {
+            return nil, err
+        }
+    }
+
+    var sheet xlsxSheet
+    if err := decodeXLSXPart(parts, sheetPath, &sheet); err != nil {
+        return nil, err
+    }
+
+    var rows [][]string
+    for _, row := range sheet.Rows {
+        // Empty rows and cells may be left out, so positions come from the
+        // row and cell references when they are given.
+        number := len(rows) + 1
+        if row.R > 0 {
+            number = row.R
+        }
+        if number < len(rows)+1 || number > maxXLSXRows {
+            return nil, fmt.Errorf("invalid XLSX: row %d is out of order", number)
+        }
+        for len(rows) < number-1 {
+            rows = append(rows, nil)
+        }
+
+        var values []string
+        for _, cell := range row.Cells {
+            column := len(values)
+            if cell.Ref != "" {
+                if column, err = columnIndex(cell.Ref); err != nil {
+                    return nil, err
+                }
+            }
+            if column < len(values) {
+                return nil, fmt.Errorf("invalid XLSX: cell %s is out of order", cell.Ref)
+            }
+            for len(values) < column {
+                values = append(values, "")
+            }
+
+            value := cell.Value
+            switch cell.Type {
+            case "s":
+                index, err := strconv.Atoi(cell.Value)
+                if err != nil || index < 0 || index >= len(shared.Items) {
+                    return nil, fmt.Errorf("invalid XLSX: cell %s refers to a missing string", cell.Ref)
+                }
+                value = shared.Items[index].String()
+            case "inlineStr":
+                value = cell.Inline.String()
+            case "b":
+                value = map[string]string{"0": "FALSE", "1": "TRUE"}[cell.Value]
+            }
+            values = append(values, value)
+        }
+        rows = append(rows, values)
+    }
+    return rows, nil
+}
+
+// firstSheetPath finds the part holding the workbook's first sheet.
+func firstSheetPath(parts map[string]*zip.File) (string, error) {
+    var workbook xlsxWorkbook
+    if err := decodeXLSXPart(parts, "xl/workbook.xml", &workbook); err != nil {
+        return "", err
+    }
+    var rels xlsxRelationships
+    if err := decodeXLSXPart(parts, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+        return "", err
+    }
+    if len(workbook.Sheets) == 0 {
+        return "", fmt.Errorf("invalid XLSX: the workbook has no sheets")
+    }
+
+    var id string
+    for _, attr := range workbook.Sheets[0].Attrs {
+        if attr.Name.Local == "id" {
+            id = attr.Value
+        }
+    }
+    for _, rel := range rels.Relationships {
+        if rel.ID != id {
+            continue
+        }
+        // Targets are relative to xl/ unless they start at the root.
+        if strings.HasPrefix(rel.Target, "/") {
+            return path.Clean(rel.Target[1:]), nil
+        }
+        return path.Join("xl", rel.Target), nil
+    }
+    return "", fmt.Errorf("invalid XLSX: the first sheet is missing")
+}
+
+func decodeXLSXPart(parts map[string]*zip.File, name string, v interface{}) error {
+    file, ok := parts[name]
+    if !ok {
+        return fmt.Errorf("invalid XLSX: %s is missing", name)
+    }
+    r, err := file.Open()
+    if err != nil {
+        return fmt.Errorf("invalid XLSX: %w", err)
+    }
+    defer r.Close()
+
+    data, err := io.ReadAll(io.LimitReader(r, maxXLSXPartSize+1))
+    if err != nil {
+        return fmt.Errorf("invalid XLSX: %w", err)
+    }
+    if len(data) > maxXLSXPartSize {
+        return fmt.Errorf("invalid XLSX: %s is too large", name)
+    }
+    if err := xml.Unmarshal(data, v); err != nil {
+        return fmt.Errorf("invalid XLSX: %s: %w", name, err)
+    }
+    return nil
+}
+
+// columnIndex returns the zero-based column of a cell reference such as
+// "AB12".
+func columnIndex(ref string) (int, error) {
+    column := 0
+    i := 0
+    for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+        column = column*26 + int(ref[i]-'A'+1)
+    }
+    if i == 0 || column > 16384 {
+        return 0, fmt.Errorf("invalid XLSX: bad cell reference %q", ref)
+    }
+    return column - 1, nil
+}
+
+// columnName returns the letters of a zero-based column, e.g. 27 is "AB".
+func columnName(column int) string {
+    var name []byte
+    for column++; column > 0; column = (column - 1) / 26 {
+        name = append([]byte{byte('A' + (column-1)%26)}, name...)
+    }
+    return string(name)
+}
+
+// The fixed parts of a workbook with one sheet.
+var xlsxParts = []struct {
+    name string
+    body string
+}{
+    {"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+        `<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+        `<Default Extension="xml" ContentType="application/xml"/>` +
+        `<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+        `<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+        `<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+        `</Types>`},
+    {"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+        `<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+        `</Relationships>`},
+    {"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+        `<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+        `</workbook>`},
+    {"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+        `<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+        `<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+        `</Relationships>`},
+    {"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+        `<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+        `<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+        `<borders count="1"><border/></borders>` +
+        `<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+        `<cellXfs count="1"><xf/></cellXfs>` +
+        `</styleSheet>`},
+}
+
+// xlsxWriter streams rows into the sheet of a workbook, with text as inline
+// strings so no shared string table has to be built up in memory.
+type xlsxWriter struct {
+    zip   *zip.Writer
+    sheet io.Writer
+    rows  int
+    err   error
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+    x := &xlsxWriter{zip: zip.NewWriter(w)}
+    for _, part := range xlsxParts {
+        var file io.Writer
+        if file, x.err = x.zip.Create(part.name); x.err != nil {
+            return x
+        }
+        if _, x.err = io.WriteString(file, part.body); x.err != nil {
+            return x
+        }
+    }
+    if x.sheet, x.err = x.zip.Create("xl/worksheets/sheet1.xml"); x.err != nil {
+        return x
+    }
+    _, x.err = io.WriteString(x.sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+    return x
+}
+
+func (x *xlsxWriter) WriteRow(row []string) error {
+    values := make([]interface{}, len(row))
+    for i, value := range row {
+        values[i] = value
+    }
+    return x.WriteValues(values)
+}
+
+func (x *xlsxWriter) WriteValues(values []interface{}) error {
+    if x.err != nil {
+        return x.err
+    }
+    if x.rows >= maxXLSXRows {
+        return fmt.Errorf("a sheet holds at most %d rows", maxXLSXRows)
+    }
+    x.rows++
+
+    var b bytes.Buffer
+    fmt.Fprintf(&b, `<row r="%d">`, x.rows)
+    for i, value := range values {
+        switch value.(type) {
+        case float64, int:
+            fmt.Fprintf(&b, `<c r="%s%d"><v>%s</v></c>`, columnName(i), x.rows, formatValue(value))
+            continue
+        }
+        text := formatValue(value)
+        if text == "" {
+            continue
+        }
+        fmt.Fprintf(&b, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">`, columnName(i), x.rows)
+        xml.EscapeText(&b, []byte(text))
+        b.WriteString(`</t></is></c>`)
+    }
+    b.WriteString(`</row>`)
+    _, x.err = x.sheet.Write(b.Bytes())
+    return x.err
+}
+
+func (x *xlsxWriter) Close() error {
+    if x.err == nil {
+        _, x.err = io.WriteString(x.sheet, `</sheetData></worksheet>`)
+    }
+    if err := x.zip.Close(); x.err == nil {
+        x.err = err
+    }
+    return x.err
+}
@@ -48,7 +48,7 @@ const initialFormData: Partial<Load> = {
   freightLoadID: "",
   status: {
     code: {
-      key: "2102",
+      key: "covered",
       value: "Covered"
     },
     notes: "",
@@ -42,6 +42,10 @@ const InfoRow: React.FC<InfoRowProps> = ({ label, value }) => (
     </Grid2>
   );
 
+// Rates the user may not see are missing and shown as N/A.
+const formatRate = (value?: number) =>
+  value === undefined ? undefined : `$${value.toLocaleString('en-US', { minimumFractionDigits: 2 })}`;
+
 export const LoadDetail = () => {
   const { id } = useParams<{ id: string }>();
   const navigate = useNavigate();
@@ -171,19 +175,22 @@ export const LoadDetail = () => {
           <Grid2 size={6}>
             <InfoRow 
               label="Base Rate" 
-              value={`$${load.rateData.baseRate.toLocaleString('en-US', { minimumFractionDigits: 2 })}`} 
+              value={formatRate(load.rateData.baseRate)} 
             />
             <InfoRow 
               label="Fuel Surcharge" 
-              value={`$${load.rateData.fuelSurcharge.toLocaleString('en-US', { minimumFractionDigits: 2 })}`} 
+              value={formatRate(load.rateData.fuelSurcharge)} 
             />
             <InfoRow 
               label="Total Rate" 
-              value={`$${load.rateData.totalRate.toLocaleString('en-US', { minimumFractionDigits: 2 })}`} 
+              value={formatRate(load.rateData.totalRate)} 
             />
           </Grid2>
           <Grid2 size={6}>
             <InfoRow label="Currency" value={load.rateData.currency} />
+            {load.rateData.carrierRate && (
+              <InfoRow label="Carrier Rate" value={formatRate(load.rateData.carrierRate.totalRate)} />
+            )}
           </Grid2>
         </Grid2>
       </DetailSection>
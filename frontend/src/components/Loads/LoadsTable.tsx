@@ -53,7 +53,9 @@ const columns: GridColDef<Load>[] = [
       width: 130,
       type: 'number',
       valueGetter: (params: { row: Load }) => params.row.rateData.totalRate,
-      renderCell: (params: { row: Load }) => `$${params.row.rateData.totalRate.toLocaleString('en-US', { minimumFractionDigits: 2 })}`
+      renderCell: (params: { row: Load }) => params.row.rateData.totalRate === undefined
+        ? '—'
+        : `$${params.row.rateData.totalRate.toLocaleString('en-US', { minimumFractionDigits: 2 })}`
     },
     {
       field: 'createdAt',
@@ -82,7 +84,7 @@ export const LoadsTable = () => {
           paginationModel.pageSize
         );
         setLoads(response.loads);
-        setTotalRows(response.total);
+        setTotalRows(response.total ?? 0);
       } catch (error) {
         console.error('Error fetching loads:', error);
         // TODO: Add error handling/notification
@@ -1,5 +1,5 @@
 import axios, { AxiosInstance } from 'axios';
-import { Load, LoadsResponse } from '../types/load.types';
+import { Load, LoadFilters, LoadsResponse, LoadStatusKey, StatusHistoryResponse } from '../types/load.types';
 
 const createApiInstance = (): AxiosInstance => {
   const instance = axios.create({
@@ -14,25 +14,71 @@ const createApiInstance = (): AxiosInstance => {
     return config;
   });
 
+  // Access tokens are short-lived: on a 401, trade the refresh token for
+  // new tokens once and retry the request.
+  instance.interceptors.response.use(undefined, async (error) => {
+    const original = error.config;
+    const refreshToken = localStorage.getItem('refreshToken');
+    if (error.response?.status !== 401 || !refreshToken || original._retried || original.url === '/auth/refresh') {
+      return Promise.reject(error);
+    }
+    original._retried = true;
+    try {
+      const response = await instance.post('/auth/refresh', { refreshToken });
+      storeTokens(response.data);
+    } catch {
+      clearTokens();
+      return Promise.reject(error);
+    }
+    return instance(original);
+  });
+
   return instance;
 };
 
+const storeTokens = (tokens: { token: string; refreshToken: string }) => {
+  localStorage.setItem('token', tokens.token);
+  localStorage.setItem('refreshToken', tokens.refreshToken);
+};
+
+const clearTokens = () => {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refreshToken');
+};
+
 const api = createApiInstance();
 
 export const authService = {
-  login: async () => {
-    const response = await api.post('/auth/login', {
-      username: 'admin',
-      password: 'password'
-    });
-    localStorage.setItem('token', response.data.token);
+  login: async (username: string, password: string) => {
+    const response = await api.post('/auth/login', { username, password });
+    storeTokens(response.data);
     return response.data;
+  },
+
+  logout: async () => {
+    try {
+      await api.post('/auth/logout', { refreshToken: localStorage.getItem('refreshToken') ?? '' });
+    } finally {
+      clearTokens();
+    }
+  },
+
+  changePassword: async (currentPassword: string, newPassword: string): Promise<void> => {
+    await api.post('/auth/password', { currentPassword, newPassword });
   }
 };
 
 export const loadService = {
-  getLoads: async (page: number = 1, size: number = 10): Promise<LoadsResponse> => {
-    const response = await api.get<LoadsResponse>(`/loads?page=${page}&size=${size}`);
+  getLoads: async (page: number = 1, size: number = 10, filters: LoadFilters = {}): Promise<LoadsResponse> => {
+    const params = new URLSearchParams({ page: String(page), size: String(size) });
+    Object.entries(filters).forEach(([key, value]) => {
+      if (Array.isArray(value)) {
+        if (value.length > 0) params.set(key, value.join(','));
+      } else if (value) {
+        params.set(key, value);
+      }
+    });
+    const response = await api.get<LoadsResponse>(`/loads?${params.toString()}`);
     return response.data;
   },
 
@@ -45,4 +91,24 @@ export const loadService = {
     const response = await api.post<Load>('/loads', load);
     return response.data;
   },
+
+  updateLoad: async (id: string, changes: Partial<Omit<Load, 'id' | 'createdAt' | 'updatedAt'>>): Promise<Load> => {
+    const response = await api.patch<Load>(`/loads/${id}`, changes);
+    return response.data;
+  },
+
+  cancelLoad: async (id: string, reason?: string): Promise<Load> => {
+    const response = await api.delete<Load>(`/loads/${id}`, { data: { reason } });
+    return response.data;
+  },
+
+  changeStatus: async (id: string, status: LoadStatusKey, reason?: string): Promise<Load> => {
+    const response = await api.post<Load>(`/loads/${id}/status`, { status, reason });
+    return response.data;
+  },
+
+  getStatusHistory: async (id: string): Promise<StatusHistoryResponse> => {
+    const response = await api.get<StatusHistoryResponse>(`/loads/${id}/status/history`);
+    return response.data;
+  },
 };
\ No newline at end of file
@@ -0,0 +1,22 @@
+export type ApiErrorCode =
+  | 'VALIDATION_FAILED'
+  | 'UNAUTHORIZED'
+  | 'FORBIDDEN'
+  | 'NOT_FOUND'
+  | 'CONFLICT'
+  | 'RATE_LIMITED'
+  | 'UPSTREAM_UNAVAILABLE'
+  | 'INTERNAL_ERROR';
+
+export interface ApiFieldError {
+  field: string;
+  message: string;
+}
+
+export interface ApiError {
+  status: number;
+  code: ApiErrorCode;
+  error: string;
+  details?: string;
+  fields?: ApiFieldError[];
+}
@@ -13,10 +13,36 @@ export interface Address {
     phone: string;
   }
   
+  export type LoadStatusKey =
+    | 'quoted'
+    | 'tendered'
+    | 'covered'
+    | 'dispatched'
+    | 'at_pickup'
+    | 'in_transit'
+    | 'delivered'
+    | 'invoiced'
+    | 'cancelled';
+
   export interface StatusCode {
-    key: string;
+    key: LoadStatusKey | string;
     value: string;
   }
+
+  export interface StatusHistoryEntry {
+    fromStatus?: LoadStatusKey;
+    toStatus: LoadStatusKey;
+    changedBy: string;
+    changedByName: string;
+    source: 'api' | 'tms';
+    reason?: string;
+    changedAt: string;
+  }
+
+  export interface StatusHistoryResponse {
+    loadId: string;
+    history: StatusHistoryEntry[];
+  }
   
   export interface Status {
     code: StatusCode;
@@ -50,12 +76,21 @@ export interface Address {
     scac: string;
   }
   
-  export interface RateData {
+  export interface Rate {
     baseRate: number;
-    currency: string;
     fuelSurcharge: number;
     totalRate: number;
   }
+
+  // The customer rate fields or carrierRate are left out for roles that may
+  // not see them.
+  export interface RateData {
+    baseRate?: number;
+    currency: string;
+    fuelSurcharge?: number;
+    totalRate?: number;
+    carrierRate?: Rate;
+  }
   
   export interface Temperature {
     max: number;
@@ -69,6 +104,41 @@ export interface Address {
     temperature: Temperature;
   }
   
+  export interface Commodity {
+    description: string;
+    quantity: number;
+    packageType: string;
+    weight: number;
+    weightUnit: 'lb' | 'kg' | '';
+  }
+
+  export interface ReferenceNumber {
+    type: string;
+    value: string;
+  }
+
+  export interface Stop {
+    id?: string;
+    sequence: number;
+    type: 'pickup' | 'drop';
+    facilityName: string;
+    address: Address;
+    contact: Contact;
+    appointmentStart: string;
+    appointmentEnd?: string;
+    timeZone?: string;
+    commodities: Commodity[];
+    referenceNumbers: ReferenceNumber[];
+    notes: string;
+  }
+
+  export interface TMSSync {
+    status: 'pending' | 'synced' | 'failed' | '';
+    customId?: string;
+    error?: string;
+    syncedAt?: string;
+  }
+
   export interface Load {
     id: string;
     externalTMSLoadID: string;
@@ -78,6 +148,7 @@ export interface Address {
     billTo: Customer;
     pickup: Location;
     consignee: Location;
+    stops?: Stop[];
     carrier: Carrier;
     rateData: RateData;
     specifications: Specifications;
@@ -89,13 +160,34 @@ export interface Address {
     poNums: string;
     operator: string;
     routeMiles: number;
+    cancelledAt?: string;
+    tmsSync?: TMSSync;
     createdAt: string;
     updatedAt: string;
   }
   
+  export interface LoadFilters {
+    status?: LoadStatusKey[];
+    customer?: string;
+    carrier?: string;
+    operator?: string;
+    originState?: string;
+    destinationState?: string;
+    pickupFrom?: string;
+    pickupTo?: string;
+    deliveryFrom?: string;
+    deliveryTo?: string;
+    po?: string;
+    q?: string;
+    sort?: string;
+  }
+
   export interface LoadsResponse {
     loads: Load[];
-    total: number;
-    page: number;
+    total?: number;
+    totalEstimated?: boolean;
+    page?: number;
     size: number;
+    nextCursor?: string;
+    prevCursor?: string;
   }
\ No newline at end of file